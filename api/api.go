@@ -1,34 +1,59 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"github.com/ONSdigital/dp-collection-api/config"
 	"github.com/ONSdigital/log.go/v2/log"
 	"github.com/gofrs/uuid"
 	"github.com/gorilla/mux"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"time"
 )
 
 //API provides a struct to wrap the api around
 type API struct {
-	Router          *mux.Router
-	paginator       Paginator
-	collectionStore CollectionStore
+	Router              *mux.Router
+	paginator           Paginator
+	collectionStore     CollectionStore
+	idempotencyStore    IdempotencyStore
+	subscriptionStore   SubscriptionStore
+	versionStore        VersionStore
+	listeners           []EventListener
+	txListeners         []TransactionalListener
+	outbox              Outbox
+	eventBroker         EventBroker
+	writeTimeout        time.Duration
+	maxRequestBodyBytes int64
+	idempotencyKeyTTL   time.Duration
+	allowPrivateHooks   bool
 }
 
 //Setup function sets up the api and returns an api
-func Setup(ctx context.Context, r *mux.Router, paginator Paginator, collectionStore CollectionStore) *API {
+func Setup(ctx context.Context, cfg *config.Config, r *mux.Router, paginator Paginator, collectionStore CollectionStore, idempotencyStore IdempotencyStore) *API {
 	api := &API{
-		Router:          r,
-		paginator:       paginator,
-		collectionStore: collectionStore,
+		Router:              r,
+		paginator:           paginator,
+		collectionStore:     collectionStore,
+		idempotencyStore:    idempotencyStore,
+		maxRequestBodyBytes: cfg.MaxRequestBodyBytes,
+		idempotencyKeyTTL:   cfg.IdempotencyKeyTTL,
+		writeTimeout:        cfg.HTTPWriteTimeout,
+		allowPrivateHooks:   cfg.WebhookAllowPrivateIPs,
 	}
 
-	r.HandleFunc("/collections", api.PostCollectionHandler).Methods(http.MethodPost)
+	r.HandleFunc("/collections", api.idempotent(api.PostCollectionHandler)).Methods(http.MethodPost)
 	r.HandleFunc("/collections", api.GetCollectionsHandler).Methods(http.MethodGet)
+	r.HandleFunc("/collections/export", api.GetExportHandler).Methods(http.MethodGet)
+	r.HandleFunc("/collections/import", api.PostImportHandler).Methods(http.MethodPost)
 	r.HandleFunc("/collections/{collection_id}", api.GetCollectionHandler).Methods(http.MethodGet)
 	r.HandleFunc("/collections/{collection_id}", api.PutCollectionHandler).Methods(http.MethodPut)
 	r.HandleFunc("/collections/{collection_id}/events", api.GetEventsHandler).Methods(http.MethodGet)
+	r.HandleFunc("/swagger.yaml", api.GetSwaggerYAMLHandler).Methods(http.MethodGet)
+	r.HandleFunc("/swagger.json", api.GetSwaggerJSONHandler).Methods(http.MethodGet)
 	return api
 }
 
@@ -37,7 +62,7 @@ func WriteJSONBody(ctx context.Context, v interface{}, w http.ResponseWriter, da
 
 	body, err := json.Marshal(v)
 	if err != nil {
-		handleError(ctx, err, w, data)
+		handleError(ctx, err, w, nil, data)
 		return err
 	}
 
@@ -48,6 +73,26 @@ func WriteJSONBody(ctx context.Context, v interface{}, w http.ResponseWriter, da
 	return nil
 }
 
+// bufferBody reads the request body, up to one byte over maxBytes so that the handler can still reject it as too large,
+// and replaces it so that the handler can read it again
+func bufferBody(req *http.Request, maxBytes int64) ([]byte, error) {
+	var reader io.Reader = req.Body
+	if maxBytes > 0 {
+		reader = io.LimitReader(reader, maxBytes+1)
+	}
+
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// Now returns the current time, and can be replaced in tests
+var Now = time.Now
+
 // NewID returns a new UUID
 var NewID = func() (string, error) {
 	uuid, err := uuid.NewV4()
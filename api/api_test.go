@@ -5,6 +5,7 @@ import (
 	"github.com/ONSdigital/dp-collection-api/api"
 	"github.com/ONSdigital/dp-collection-api/api/mock"
 	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/config"
 	"github.com/ONSdigital/dp-collection-api/models"
 	"net/http"
 	"net/http/httptest"
@@ -31,10 +32,14 @@ func TestSetup(t *testing.T) {
 	Convey("Given an API instance", t, func() {
 		r := mux.NewRouter()
 		ctx := context.Background()
-		api := api.Setup(ctx, r, paginator, collectionStore)
+		api := api.Setup(ctx, &config.Config{}, r, paginator, collectionStore, nil)
 
 		Convey("When created the following routes should have been added", func() {
 			So(hasRoute(api.Router, "/collections", "GET"), ShouldBeTrue)
+			So(hasRoute(api.Router, "/collections/export", "GET"), ShouldBeTrue)
+			So(hasRoute(api.Router, "/collections/import", "POST"), ShouldBeTrue)
+			So(hasRoute(api.Router, "/swagger.yaml", "GET"), ShouldBeTrue)
+			So(hasRoute(api.Router, "/swagger.json", "GET"), ShouldBeTrue)
 		})
 	})
 }
@@ -6,6 +6,9 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/ONSdigital/dp-collection-api/collections"
 	"github.com/ONSdigital/dp-collection-api/models"
@@ -22,14 +25,14 @@ func (api *API) GetCollectionsHandler(w http.ResponseWriter, req *http.Request)
 
 	queryParams, err := readCollectionsQueryParams(req, api.paginator)
 	if err != nil {
-		handleError(ctx, err, w, logData)
+		handleError(ctx, err, w, req, logData)
 		return
 	}
 	logData["query_params"] = queryParams
 
 	collections, totalCount, err := api.collectionStore.GetCollections(ctx, *queryParams)
 	if err != nil {
-		handleError(ctx, err, w, logData)
+		handleError(ctx, err, w, req, logData)
 		return
 	}
 
@@ -58,13 +61,13 @@ func (api *API) GetCollectionHandler(w http.ResponseWriter, req *http.Request) {
 
 	err := ValidateUUID(collectionID)
 	if err != nil {
-		handleError(ctx, collections.ErrInvalidID, w, logData)
+		handleError(ctx, collections.ErrInvalidID, w, req, logData)
 		return
 	}
 
 	collection, err := api.collectionStore.GetCollectionByID(ctx, collectionID, eTag)
 	if err != nil {
-		handleError(ctx, err, w, logData)
+		handleError(ctx, err, w, req, logData)
 		return
 	}
 
@@ -79,26 +82,26 @@ func (api *API) PostCollectionHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	logData := log.Data{}
 
-	collection, err := ParseCollection(ctx, r.Body)
+	collection, err := ParseCollection(ctx, r.Body, api.maxRequestBodyBytes)
 	if err != nil {
-		handleError(ctx, err, w, logData)
+		handleError(ctx, err, w, r, logData)
 		return
 	}
 
-	collection.ID, err = NewID()
+	err = api.validateCollection(ctx, collection)
 	if err != nil {
-		handleError(ctx, err, w, logData)
+		handleError(ctx, err, w, r, logData)
 		return
 	}
 
-	err = api.validateCollection(ctx, collection)
+	collection.ID, err = NewID()
 	if err != nil {
-		handleError(ctx, err, w, logData)
+		handleError(ctx, err, w, r, logData)
 		return
 	}
 
-	if err = api.collectionStore.AddCollection(ctx, collection); err != nil {
-		handleError(ctx, err, w, logData)
+	if err = api.addCollection(ctx, collection); err != nil {
+		handleError(ctx, err, w, r, logData)
 		return
 	}
 
@@ -106,7 +109,7 @@ func (api *API) PostCollectionHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusCreated)
 	err = WriteJSONBody(ctx, collection, w, logData)
 	if err != nil {
-		handleError(ctx, err, w, logData)
+		handleError(ctx, err, w, r, logData)
 		return
 	}
 
@@ -125,13 +128,7 @@ func (api *API) PutCollectionHandler(w http.ResponseWriter, req *http.Request) {
 
 	err := ValidateUUID(collectionID)
 	if err != nil {
-		handleError(ctx, collections.ErrInvalidID, w, logData)
-		return
-	}
-
-	_, err = api.collectionStore.GetCollectionByID(ctx, collectionID, models.AnyETag)
-	if err != nil {
-		handleError(ctx, err, w, logData)
+		handleError(ctx, collections.ErrInvalidID, w, req, logData)
 		return
 	}
 
@@ -139,21 +136,35 @@ func (api *API) PutCollectionHandler(w http.ResponseWriter, req *http.Request) {
 	eTag, err := getIfMatchForce(req)
 	if err != nil {
 		log.Error(ctx, "missing header", err, log.Data{"error": err.Error()})
-		handleError(ctx, err, w, logData)
+		handleError(ctx, err, w, req, logData)
 		return
 	}
 	logData["e_tag"] = eTag
 
-	collection, err := ParseCollection(ctx, req.Body)
+	// the collection is read with the client's eTag, so that a cached copy is only used if it is the version being
+	// replaced, and the next version number is counted from that version
+	existing, err := api.collectionStore.GetCollectionByID(ctx, collectionID, eTag)
+	if err != nil {
+		handleError(ctx, err, w, req, logData)
+		return
+	}
+
+	collection, err := ParseCollection(ctx, req.Body, api.maxRequestBodyBytes)
 	if err != nil {
-		handleError(ctx, err, w, logData)
+		handleError(ctx, err, w, req, logData)
+		return
+	}
+
+	// an ID in the body is optional, but must match the URL if it is provided
+	if len(collection.ID) > 0 && collection.ID != collectionID {
+		handleError(ctx, collections.ErrCollectionIDMismatch, w, req, logData)
 		return
 	}
 
 	collection.ID = collectionID
 
-	if err := api.collectionStore.ReplaceCollection(ctx, collection, eTag); err != nil {
-		handleError(ctx, err, w, logData)
+	if err := api.replaceCollection(ctx, existing, collection, eTag); err != nil {
+		handleError(ctx, err, w, req, logData)
 		return
 	}
 
@@ -161,51 +172,136 @@ func (api *API) PutCollectionHandler(w http.ResponseWriter, req *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	err = WriteJSONBody(ctx, collection, w, logData)
 	if err != nil {
-		handleError(ctx, err, w, logData)
+		handleError(ctx, err, w, req, logData)
 		return
 	}
 
 	log.Info(ctx, "put collection request completed successfully", logData)
 }
 
+// validateCollection checks a new collection, returning ValidationErrors containing every problem found
 func (api *API) validateCollection(ctx context.Context, collection *models.Collection) error {
 
 	if collection == nil {
 		return collections.ErrNilCollection
 	}
 
-	if len(collection.Name) == 0 {
-		return collections.ErrCollectionNameEmpty
+	var errs ValidationErrors
+
+	// the collection ID is always generated by the API
+	if len(collection.ID) > 0 {
+		errs = append(errs, collections.ErrCollectionIDReadOnly)
 	}
 
-	_, err := api.collectionStore.GetCollectionByName(ctx, collection.Name)
-	if err != nil && err != collections.ErrCollectionNotFound {
-		return err
+	if collection.PublishDate != nil && !collection.PublishDate.After(Now()) {
+		errs = append(errs, collections.ErrPublishDateInPast)
 	}
-	if err == nil {
-		return collections.ErrCollectionNameAlreadyExists
+
+	if len(collection.Name) == 0 {
+		errs = append(errs, collections.ErrCollectionNameEmpty)
+	} else {
+		_, err := api.collectionStore.GetCollectionByName(ctx, collection.Name)
+		if err != nil && err != collections.ErrCollectionNotFound {
+			return err
+		}
+		if err == nil {
+			errs = append(errs, collections.ErrCollectionNameAlreadyExists)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
 	}
 
 	return nil
 }
 
-func ParseCollection(ctx context.Context, reader io.Reader) (*models.Collection, error) {
+// readOnlyFields lists the JSON fields of a collection that can not be set by a client, and the error returned if they are
+var readOnlyFields = []struct {
+	name string
+	err  error
+}{
+	{name: "e_tag", err: collections.ErrETagReadOnly},
+	{name: "last_updated", err: collections.ErrLastUpdatedReadOnly},
+	{name: "publish_result", err: collections.ErrPublishResultReadOnly},
+	{name: "version", err: collections.ErrVersionReadOnly},
+}
+
+// collectionFields is the set of JSON fields that a collection request body may contain
+var collectionFields = jsonFieldNames(models.Collection{})
+
+func jsonFieldNames(v interface{}) map[string]bool {
+	names := map[string]bool{}
+	t := reflect.TypeOf(v)
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// ParseCollection strictly decodes a collection from the given reader. Unknown and read-only fields are rejected,
+// and the body is limited to maxBytes (a value of zero or less disables the limit).
+func ParseCollection(ctx context.Context, reader io.Reader, maxBytes int64) (*models.Collection, error) {
+
+	if maxBytes > 0 {
+		reader = io.LimitReader(reader, maxBytes+1)
+	}
 
 	b, err := ioutil.ReadAll(reader)
 	if err != nil {
 		return nil, err
 	}
 
+	if maxBytes > 0 && int64(len(b)) > maxBytes {
+		return nil, ErrRequestBodyTooLarge
+	}
+
+	// the body is first decoded generically, so that every unknown or read-only field can be reported
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(b, &fields); err != nil {
+		log.Error(ctx, "failed to parse collection json body", err)
+		return nil, ErrUnableToParseJSON
+	}
+
+	var errs ValidationErrors
+
+	for _, field := range readOnlyFields {
+		if _, ok := fields[field.name]; ok {
+			errs = append(errs, field.err)
+		}
+	}
+
+	unknownFields := make([]string, 0)
+	for name := range fields {
+		if !collectionFields[name] && !isReadOnlyField(name) {
+			unknownFields = append(unknownFields, name)
+		}
+	}
+	sort.Strings(unknownFields)
+	for _, name := range unknownFields {
+		errs = append(errs, FieldError{Err: ErrUnknownJSONField, Field: name})
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
 	var collection models.Collection
 
 	err = json.Unmarshal(b, &collection)
 	if err != nil {
-		log.Error(ctx, "failed to parse collection json body", err)
+		log.Error(ctx, "failed to decode collection json body", err)
 		return nil, ErrUnableToParseJSON
 	}
 
-	// set eTag value to current hash of the collection
-	collection.ETag, err = collection.Hash(nil)
+	// set eTag value to current hash of the collection, ignoring any ID provided in the body
+	// so that the same content results in the same eTag whether or not the ID was included
+	withoutID := collection
+	withoutID.ID = ""
+	collection.ETag, err = withoutID.Hash(nil)
 	if err != nil {
 		return nil, err
 	}
@@ -213,6 +309,15 @@ func ParseCollection(ctx context.Context, reader io.Reader) (*models.Collection,
 	return &collection, nil
 }
 
+func isReadOnlyField(name string) bool {
+	for _, field := range readOnlyFields {
+		if field.name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func readCollectionsQueryParams(req *http.Request, paginator Paginator) (*collections.QueryParams, error) {
 
 	offset, limit, err := paginator.ReadPaginationParameters(req)
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"github.com/ONSdigital/dp-collection-api/api/mock"
 	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/config"
 	"github.com/ONSdigital/dp-collection-api/models"
 	"github.com/ONSdigital/dp-collection-api/pagination"
 	"github.com/gorilla/mux"
@@ -13,6 +14,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -27,6 +29,7 @@ var (
 	totalCount = 3
 )
 var collectionID = "00112233-4455-6677-8899-aabbccddeeff"
+var now = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
 var invalidCollectionID = "abc123"
 
 var expectedCollection = models.Collection{
@@ -46,7 +49,7 @@ func TestGetCollection(t *testing.T) {
 
 		Convey("When the request is sent to the API", func() {
 
-			api := api.Setup(context.Background(), mux.NewRouter(), &pagination.Paginator{}, collectionStore)
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), &pagination.Paginator{}, collectionStore, nil)
 
 			r = mux.SetURLVars(r, map[string]string{
 				"collection_id": collectionID,
@@ -87,7 +90,7 @@ func TestGetCollection_invalidUUID(t *testing.T) {
 
 		Convey("When the request is sent to the API", func() {
 
-			api := api.Setup(context.Background(), mux.NewRouter(), &pagination.Paginator{}, collectionStore)
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), &pagination.Paginator{}, collectionStore, nil)
 
 			expectedUrlVars := map[string]string{
 				"collection_id": invalidCollectionID,
@@ -112,12 +115,84 @@ func TestGetCollection_invalidUUID(t *testing.T) {
 				So(err, ShouldBeNil)
 				So(len(response.Errors), ShouldEqual, 1)
 				So(response.Errors[0].Message, ShouldEqual, collections.ErrInvalidID.Error())
+				So(response.Errors[0].Code, ShouldEqual, models.ErrCodeInvalidID)
+				So(response.Errors[0].Field, ShouldEqual, "collection_id")
 			})
 		})
 	})
 
 }
 
+func TestGetCollection_problemJSON(t *testing.T) {
+
+	testCases := []struct {
+		accept              string
+		expectedContentType string
+	}{
+		{accept: "application/problem+json", expectedContentType: "application/problem+json; charset=utf-8"},
+		{accept: "application/json;q=0.5, application/problem+json", expectedContentType: "application/problem+json; charset=utf-8"},
+		{accept: "application/json, application/problem+json;q=0.5", expectedContentType: "application/json; charset=utf-8"},
+		{accept: "*/*", expectedContentType: "application/json; charset=utf-8"},
+		{accept: "", expectedContentType: "application/json; charset=utf-8"},
+	}
+
+	for _, tc := range testCases {
+
+		Convey("Given a request to GET a collection with an invalid UUID and Accept header '"+tc.accept+"'", t, func() {
+			collectionStore := mockCollectionStore()
+
+			r := httptest.NewRequest("GET", "http://localhost:26000/collections/"+invalidCollectionID, nil)
+			r.Header.Set("Accept", tc.accept)
+			w := httptest.NewRecorder()
+
+			Convey("When the request is sent to the API", func() {
+
+				api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), &pagination.Paginator{}, collectionStore, nil)
+				api.Router.ServeHTTP(w, r)
+
+				Convey("Then the response has the expected status code and content type", func() {
+					So(w.Code, ShouldEqual, http.StatusBadRequest)
+					So(w.Header().Get("Content-Type"), ShouldEqual, tc.expectedContentType)
+				})
+			})
+		})
+	}
+
+	Convey("Given a request to GET a collection with an invalid UUID that accepts problem details", t, func() {
+		collectionStore := mockCollectionStore()
+
+		r := httptest.NewRequest("GET", "http://localhost:26000/collections/"+invalidCollectionID+"?a=b", nil)
+		r.Header.Set("Accept", "application/problem+json")
+		w := httptest.NewRecorder()
+
+		Convey("When the request is sent to the API", func() {
+
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), &pagination.Paginator{}, collectionStore, nil)
+			api.Router.ServeHTTP(w, r)
+
+			Convey("Then the response body should be an RFC 7807 document that includes the errors", func() {
+				response := models.ProblemDetails{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				So(err, ShouldBeNil)
+				So(response, ShouldResemble, models.ProblemDetails{
+					Type:     "about:blank",
+					Title:    "Bad Request",
+					Status:   http.StatusBadRequest,
+					Detail:   collections.ErrInvalidID.Error(),
+					Instance: "/collections/" + invalidCollectionID + "?a=b",
+					Errors: []models.ErrorResponse{
+						{
+							Code:    models.ErrCodeInvalidID,
+							Message: collections.ErrInvalidID.Error(),
+							Field:   "collection_id",
+						},
+					},
+				})
+			})
+		})
+	})
+}
+
 func TestGetCollections(t *testing.T) {
 
 	Convey("Given a request to GET collections", t, func() {
@@ -130,7 +205,7 @@ func TestGetCollections(t *testing.T) {
 
 		Convey("When the request is sent to the API", func() {
 
-			api := api.Setup(context.Background(), mux.NewRouter(), paginator, collectionStore)
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
 			api.GetCollectionsHandler(w, r)
 
 			Convey("Then the paginator is called to extract pagination parameters", func() {
@@ -179,7 +254,7 @@ func TestGetCollections_orderByPublishDate(t *testing.T) {
 
 		Convey("When the request is sent to the API", func() {
 
-			api := api.Setup(context.Background(), mux.NewRouter(), paginator, collectionStore)
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
 			api.GetCollectionsHandler(w, r)
 
 			Convey("Then the collection store is called with the expected orderBy value", func() {
@@ -206,7 +281,7 @@ func TestGetCollections_nameSearch(t *testing.T) {
 
 		Convey("When the request is sent to the API", func() {
 
-			api := api.Setup(context.Background(), mux.NewRouter(), paginator, collectionStore)
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
 			api.GetCollectionsHandler(w, r)
 
 			Convey("Then the collection store is called with the expected orderBy value", func() {
@@ -233,7 +308,7 @@ func TestGetCollections_nameSearchTooLong(t *testing.T) {
 
 		Convey("When the request is sent to the API", func() {
 
-			api := api.Setup(context.Background(), mux.NewRouter(), paginator, collectionStore)
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
 			api.GetCollectionsHandler(w, r)
 
 			Convey("Then the expected error code is returned", func() {
@@ -265,7 +340,7 @@ func TestGetCollections_emptyOrderBy(t *testing.T) {
 
 		Convey("When the request is sent to the API", func() {
 
-			api := api.Setup(context.Background(), mux.NewRouter(), paginator, collectionStore)
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
 			api.GetCollectionsHandler(w, r)
 
 			Convey("Then the collection store is called with the expected orderBy value", func() {
@@ -293,7 +368,7 @@ func TestGetCollections_paginationError(t *testing.T) {
 			r := httptest.NewRequest("GET", "http://localhost:26000/collections", nil)
 			w := httptest.NewRecorder()
 
-			api := api.Setup(context.Background(), mux.NewRouter(), paginator, collectionStore)
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
 			api.GetCollectionsHandler(w, r)
 
 			Convey("Then the paginator is called to extract pagination parameters", func() {
@@ -324,7 +399,7 @@ func TestGetCollections_collectionStoreError(t *testing.T) {
 			r := httptest.NewRequest("GET", "http://localhost:26000/collections", nil)
 			w := httptest.NewRecorder()
 
-			api := api.Setup(context.Background(), mux.NewRouter(), paginator, collectionStore)
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
 			api.GetCollectionsHandler(w, r)
 
 			Convey("Then the paginator is called to extract pagination parameters", func() {
@@ -351,7 +426,7 @@ func TestGetCollections_invalidOrderByError(t *testing.T) {
 			r := httptest.NewRequest("GET", "http://localhost:26000/collections?order_by=fubar", nil)
 			w := httptest.NewRecorder()
 
-			api := api.Setup(context.Background(), mux.NewRouter(), paginator, collectionStore)
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
 			api.GetCollectionsHandler(w, r)
 
 			Convey("Then the paginator is called to extract pagination parameters", func() {
@@ -392,7 +467,7 @@ func TestGetCollections_internalError(t *testing.T) {
 			r := httptest.NewRequest("GET", "http://localhost:26000/collections", nil)
 			w := httptest.NewRecorder()
 
-			api := api.Setup(context.Background(), mux.NewRouter(), paginator, collectionStore)
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
 			api.GetCollectionsHandler(w, r)
 
 			Convey("Then the paginator is called to extract pagination parameters", func() {
@@ -421,6 +496,10 @@ func TestPostCollection(t *testing.T) {
 		return expectedID, nil
 	}
 
+	api.Now = func() time.Time {
+		return now
+	}
+
 	Convey("Given a request to POST a collection", t, func() {
 
 		paginator := mockPaginator()
@@ -431,7 +510,7 @@ func TestPostCollection(t *testing.T) {
 
 		Convey("When the request is sent to the API", func() {
 
-			api := api.Setup(context.Background(), mux.NewRouter(), paginator, collectionStore)
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
 			api.PostCollectionHandler(w, r)
 
 			Convey("Then the collection store is called", func() {
@@ -479,6 +558,10 @@ func TestPostCollection_CollectionNameAlreadyExists(t *testing.T) {
 		return expectedID, nil
 	}
 
+	api.Now = func() time.Time {
+		return now
+	}
+
 	Convey("Given a request to POST a collection with a name that already exists", t, func() {
 
 		paginator := mockPaginator()
@@ -492,7 +575,7 @@ func TestPostCollection_CollectionNameAlreadyExists(t *testing.T) {
 
 		Convey("When the request is sent to the API", func() {
 
-			api := api.Setup(context.Background(), mux.NewRouter(), paginator, collectionStore)
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
 			api.PostCollectionHandler(w, r)
 
 			Convey("Then the collection store is called", func() {
@@ -527,7 +610,7 @@ func TestPostCollection_EmptyCollectionNameError(t *testing.T) {
 
 		Convey("When the request is sent to the API", func() {
 
-			api := api.Setup(context.Background(), mux.NewRouter(), paginator, collectionStore)
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
 			api.PostCollectionHandler(w, r)
 
 			Convey("Then the response has the expected status code", func() {
@@ -549,6 +632,10 @@ func TestPostCollection_CollectionNameLookupError(t *testing.T) {
 		return expectedID, nil
 	}
 
+	api.Now = func() time.Time {
+		return now
+	}
+
 	Convey("Given a request to POST a collection with a failed collection name lookup", t, func() {
 
 		paginator := mockPaginator()
@@ -562,7 +649,7 @@ func TestPostCollection_CollectionNameLookupError(t *testing.T) {
 
 		Convey("When the request is sent to the API", func() {
 
-			api := api.Setup(context.Background(), mux.NewRouter(), paginator, collectionStore)
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
 			api.PostCollectionHandler(w, r)
 
 			Convey("Then the collection store is called", func() {
@@ -584,6 +671,10 @@ func TestPostCollection_storeError(t *testing.T) {
 		"publish_date": "2020-05-05T14:58:29.317Z"
 	}`
 
+	api.Now = func() time.Time {
+		return now
+	}
+
 	Convey("Given a request to POST a collection", t, func() {
 
 		paginator := mockPaginator()
@@ -598,7 +689,7 @@ func TestPostCollection_storeError(t *testing.T) {
 
 		Convey("When the request is sent to the API and an error is returned from the DB", func() {
 
-			api := api.Setup(context.Background(), mux.NewRouter(), paginator, collectionStore)
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
 			api.PostCollectionHandler(w, r)
 
 			Convey("Then the response has the expected status code", func() {
@@ -622,7 +713,7 @@ func TestPostCollection_invalidRequestBody(t *testing.T) {
 
 		Convey("When the request is sent to the API", func() {
 
-			api := api.Setup(context.Background(), mux.NewRouter(), paginator, collectionStore)
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
 			api.PostCollectionHandler(w, r)
 
 			Convey("Then the response has the expected status code", func() {
@@ -632,6 +723,194 @@ func TestPostCollection_invalidRequestBody(t *testing.T) {
 	})
 }
 
+func TestPostCollection_strictValidation(t *testing.T) {
+
+	testCases := []struct {
+		description    string
+		body           string
+		expectedStatus int
+		expectedError  error
+	}{
+		{
+			description:    "an unknown field",
+			body:           `{"name": "Coronavirus key indicators", "unknown": "value"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  api.ErrUnknownJSONField,
+		},
+		{
+			description:    "an id field",
+			body:           `{"id": "` + collectionID + `", "name": "Coronavirus key indicators"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  collections.ErrCollectionIDReadOnly,
+		},
+		{
+			description:    "an e_tag field",
+			body:           `{"name": "Coronavirus key indicators", "e_tag": "1234"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  collections.ErrETagReadOnly,
+		},
+		{
+			description:    "a last_updated field",
+			body:           `{"name": "Coronavirus key indicators", "last_updated": "2020-05-05T14:58:29.317Z"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  collections.ErrLastUpdatedReadOnly,
+		},
+		{
+			description:    "a publish_result field",
+			body:           `{"name": "Coronavirus", "publish_result": {}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  collections.ErrPublishResultReadOnly,
+		},
+		{
+			description:    "a version field",
+			body:           `{"name": "Coronavirus", "version": 2}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  collections.ErrVersionReadOnly,
+		},
+		{
+			description:    "trailing data after the json object",
+			body:           `{"name": "Coronavirus key indicators"} {}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  api.ErrUnableToParseJSON,
+		},
+		{
+			description:    "a body larger than the maximum allowed",
+			body:           `{"name": "` + strings.Repeat("a", 100) + `"}`,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedError:  api.ErrRequestBodyTooLarge,
+		},
+	}
+
+	for _, tc := range testCases {
+
+		Convey("Given a request to POST a collection with "+tc.description, t, func() {
+
+			paginator := mockPaginator()
+			collectionStore := mockCollectionStore()
+			cfg := &config.Config{MaxRequestBodyBytes: 100}
+
+			r := httptest.NewRequest("POST", "http://localhost:26000/collections", bytes.NewBufferString(tc.body))
+			w := httptest.NewRecorder()
+
+			Convey("When the request is sent to the API", func() {
+
+				api := api.Setup(context.Background(), cfg, mux.NewRouter(), paginator, collectionStore, nil)
+				api.PostCollectionHandler(w, r)
+
+				Convey("Then the response has the expected status code", func() {
+					So(w.Code, ShouldEqual, tc.expectedStatus)
+				})
+
+				Convey("Then the response body should contain the expected error response", func() {
+					response := models.ErrorsResponse{}
+					err := json.Unmarshal(w.Body.Bytes(), &response)
+					So(err, ShouldBeNil)
+					So(len(response.Errors), ShouldEqual, 1)
+					So(response.Errors[0].Message, ShouldEqual, tc.expectedError.Error())
+				})
+
+				Convey("Then the collection is not added", func() {
+					So(len(collectionStore.AddCollectionCalls()), ShouldEqual, 0)
+				})
+			})
+		})
+	}
+}
+
+func TestPostCollection_multipleValidationErrors(t *testing.T) {
+
+	invalidValuesJson := `{
+		"name": "",
+		"publish_date": "2019-05-05T14:58:29.317Z"
+	}`
+	invalidFieldsJson := `{
+		"name": "Coronavirus key indicators",
+		"colour": "blue",
+		"e_tag": "1234"
+	}`
+	api.Now = func() time.Time {
+		return now
+	}
+
+	Convey("Given a request to POST a collection with an empty name and a publish date in the past", t, func() {
+
+		paginator := mockPaginator()
+		collectionStore := mockCollectionStore()
+
+		r := httptest.NewRequest("POST", "http://localhost:26000/collections", bytes.NewBufferString(invalidValuesJson))
+		w := httptest.NewRecorder()
+
+		Convey("When the request is sent to the API", func() {
+
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
+			api.PostCollectionHandler(w, r)
+
+			Convey("Then the response has the expected status code", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+			})
+
+			Convey("Then the response body should contain every validation error, with codes and fields", func() {
+				response := models.ErrorsResponse{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				So(err, ShouldBeNil)
+				So(response.Errors, ShouldResemble, []models.ErrorResponse{
+					{
+						Code:    models.ErrCodePublishDateInPast,
+						Message: collections.ErrPublishDateInPast.Error(),
+						Field:   "publish_date",
+					},
+					{
+						Code:    models.ErrCodeCollectionNameEmpty,
+						Message: collections.ErrCollectionNameEmpty.Error(),
+						Field:   "name",
+					},
+				})
+			})
+
+			Convey("Then the collection is not added", func() {
+				So(len(collectionStore.AddCollectionCalls()), ShouldEqual, 0)
+			})
+		})
+	})
+
+	Convey("Given a request to POST a collection with an unknown field and a read-only field", t, func() {
+
+		paginator := mockPaginator()
+		collectionStore := mockCollectionStore()
+
+		r := httptest.NewRequest("POST", "http://localhost:26000/collections", bytes.NewBufferString(invalidFieldsJson))
+		w := httptest.NewRecorder()
+
+		Convey("When the request is sent to the API", func() {
+
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
+			api.PostCollectionHandler(w, r)
+
+			Convey("Then the response has the expected status code", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+			})
+
+			Convey("Then the response body should contain both body errors, with codes and fields", func() {
+				response := models.ErrorsResponse{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				So(err, ShouldBeNil)
+				So(response.Errors, ShouldResemble, []models.ErrorResponse{
+					{
+						Code:    models.ErrCodeReadOnlyField,
+						Message: collections.ErrETagReadOnly.Error(),
+						Field:   "e_tag",
+					},
+					{
+						Code:    models.ErrCodeUnknownField,
+						Message: "json body contains an unknown field",
+						Field:   "colour",
+					},
+				})
+			})
+		})
+	})
+}
+
 func TestPutCollection(t *testing.T) {
 
 	collectionJson := `{
@@ -653,7 +932,7 @@ func TestPutCollection(t *testing.T) {
 
 		Convey("When the request is sent to the API", func() {
 
-			api := api.Setup(context.Background(), mux.NewRouter(), paginator, collectionStore)
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
 
 			r = mux.SetURLVars(r, map[string]string{
 				"collection_id": collectionID,
@@ -664,6 +943,7 @@ func TestPutCollection(t *testing.T) {
 			Convey("Then the collection store is called with the expected values", func() {
 				So(len(collectionStore.GetCollectionByIDCalls()), ShouldEqual, 1)
 				So(collectionStore.GetCollectionByIDCalls()[0].ID, ShouldEqual, collectionID)
+				So(collectionStore.GetCollectionByIDCalls()[0].ETagSelector, ShouldEqual, expectedETag)
 
 				So(len(collectionStore.ReplaceCollectionCalls()), ShouldEqual, 1)
 
@@ -697,6 +977,93 @@ func TestPutCollection(t *testing.T) {
 	})
 }
 
+func TestPutCollection_matchingIDInBody(t *testing.T) {
+
+	collectionJson := `{
+		"id": "` + collectionID + `",
+		"name": "Coronavirus key indicators",
+		"publish_date": "2020-05-05T14:58:29.317Z"
+	}`
+	expectedETag := "8945d466e009a6e5bb94b5a3b54fe91e81d24267"
+
+	Convey("Given a request to PUT a collection with an ID in the body that matches the URL", t, func() {
+
+		paginator := mockPaginator()
+		collectionStore := mockCollectionStore()
+
+		r := httptest.NewRequest("PUT", "http://localhost:26000/collections", bytes.NewBufferString(collectionJson))
+		w := httptest.NewRecorder()
+
+		r.Header.Add("If-Match", expectedETag)
+
+		Convey("When the request is sent to the API", func() {
+
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
+
+			r = mux.SetURLVars(r, map[string]string{
+				"collection_id": collectionID,
+			})
+
+			api.PutCollectionHandler(w, r)
+
+			Convey("Then the response has the expected status code", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+			})
+
+			Convey("Then the eTag is the same as for a body without an ID", func() {
+				So(len(collectionStore.ReplaceCollectionCalls()), ShouldEqual, 1)
+				So(collectionStore.ReplaceCollectionCalls()[0].Collection.ETag, ShouldEqual, expectedETag)
+			})
+		})
+	})
+}
+
+func TestPutCollection_mismatchedIDInBody(t *testing.T) {
+
+	collectionJson := `{
+		"id": "ffeeddcc-bbaa-9988-7766-554433221100",
+		"name": "Coronavirus key indicators"
+	}`
+
+	Convey("Given a request to PUT a collection with an ID in the body that does not match the URL", t, func() {
+
+		paginator := mockPaginator()
+		collectionStore := mockCollectionStore()
+
+		r := httptest.NewRequest("PUT", "http://localhost:26000/collections", bytes.NewBufferString(collectionJson))
+		w := httptest.NewRecorder()
+
+		r.Header.Add("If-Match", "eTag")
+
+		Convey("When the request is sent to the API", func() {
+
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
+
+			r = mux.SetURLVars(r, map[string]string{
+				"collection_id": collectionID,
+			})
+
+			api.PutCollectionHandler(w, r)
+
+			Convey("Then the response has the expected status code", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+			})
+
+			Convey("Then the response body should contain the expected error response", func() {
+				response := models.ErrorsResponse{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				So(err, ShouldBeNil)
+				So(len(response.Errors), ShouldEqual, 1)
+				So(response.Errors[0].Message, ShouldEqual, collections.ErrCollectionIDMismatch.Error())
+			})
+
+			Convey("Then the collection is not replaced", func() {
+				So(len(collectionStore.ReplaceCollectionCalls()), ShouldEqual, 0)
+			})
+		})
+	})
+}
+
 func TestPutCollection_invalidCollectionID(t *testing.T) {
 
 	collectionJson := `{
@@ -714,7 +1081,7 @@ func TestPutCollection_invalidCollectionID(t *testing.T) {
 
 		Convey("When the request is sent to the API", func() {
 
-			api := api.Setup(context.Background(), mux.NewRouter(), paginator, collectionStore)
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
 
 			r = mux.SetURLVars(r, map[string]string{
 				"collection_id": invalidCollectionID,
@@ -756,7 +1123,7 @@ func TestPutCollection_emptyIfMatchHeaderError(t *testing.T) {
 
 		Convey("When the request is sent to the API", func() {
 
-			api := api.Setup(context.Background(), mux.NewRouter(), paginator, collectionStore)
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
 
 			r = mux.SetURLVars(r, map[string]string{
 				"collection_id": collectionID,
@@ -788,7 +1155,7 @@ func TestPutCollection_noETagInRequest(t *testing.T) {
 
 		Convey("When the request is sent to the API", func() {
 
-			api := api.Setup(context.Background(), mux.NewRouter(), paginator, collectionStore)
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
 
 			r = mux.SetURLVars(r, map[string]string{
 				"collection_id": collectionID,
@@ -836,7 +1203,7 @@ func TestPutCollection_storeError(t *testing.T) {
 
 		Convey("When the request is sent to the API", func() {
 
-			api := api.Setup(context.Background(), mux.NewRouter(), paginator, collectionStore)
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
 
 			r = mux.SetURLVars(r, map[string]string{
 				"collection_id": collectionID,
@@ -899,6 +1266,9 @@ func mockCollectionStore() *mock.CollectionStoreMock {
 		ReplaceCollectionFunc: func(ctx context.Context, collection *models.Collection, eTagSelector string) error {
 			return nil
 		},
+		AddEventFunc: func(ctx context.Context, event *models.Event) error {
+			return nil
+		},
 	}
 
 	return collectionStore
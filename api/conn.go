@@ -0,0 +1,27 @@
+package api
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// connKey is the context key of the connection that a request was made on
+type connKey struct{}
+
+// ConnContext adds the connection to the context of the requests made on it. It is used as the ConnContext of the
+// HTTP server, so that a response that is streamed can extend the server's write timeout as it is written.
+func ConnContext(ctx context.Context, conn net.Conn) context.Context {
+	return context.WithValue(ctx, connKey{}, conn)
+}
+
+// extendWriteDeadline moves the write deadline of the request's connection to the timeout from now, so that a
+// response written over longer than the server's write timeout is not cut off. It returns false if the connection is
+// not known, in which case the response must end before the server's write timeout.
+func extendWriteDeadline(ctx context.Context, timeout time.Duration) bool {
+	conn, ok := ctx.Value(connKey{}).(net.Conn)
+	if !ok {
+		return false
+	}
+	return conn.SetWriteDeadline(time.Now().Add(timeout)) == nil
+}
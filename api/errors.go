@@ -6,64 +6,289 @@ import (
 	"github.com/ONSdigital/dp-collection-api/collections"
 	"github.com/ONSdigital/dp-collection-api/models"
 	"github.com/ONSdigital/dp-collection-api/pagination"
+	"github.com/ONSdigital/dp-collection-api/zebedee"
 	"github.com/ONSdigital/log.go/v2/log"
+	"math"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	problemJSONMediaType   = "application/problem+json"
+	problemJSONContentType = problemJSONMediaType + "; charset=utf-8"
 )
 
 var (
 	// errors that should return a 400 status
 	badRequest = map[error]bool{
-		pagination.ErrInvalidLimitParameter:  true,
-		pagination.ErrInvalidOffsetParameter: true,
-		pagination.ErrLimitOverMax:           true,
-		collections.ErrInvalidOrderBy:        true,
-		collections.ErrNameSearchTooLong:     true,
-		collections.ErrCollectionNameEmpty:   true,
-		collections.ErrInvalidID:             true,
-		collections.ErrNoIfMatchHeader:       true,
-		ErrUnableToParseJSON:                 true,
+		pagination.ErrInvalidLimitParameter:   true,
+		pagination.ErrInvalidOffsetParameter:  true,
+		pagination.ErrLimitOverMax:            true,
+		collections.ErrInvalidOrderBy:         true,
+		collections.ErrNameSearchTooLong:      true,
+		collections.ErrCollectionNameEmpty:    true,
+		collections.ErrPublishDateInPast:      true,
+		collections.ErrInvalidID:              true,
+		collections.ErrNoIfMatchHeader:        true,
+		collections.ErrCollectionIDMismatch:   true,
+		collections.ErrCollectionIDReadOnly:   true,
+		collections.ErrETagReadOnly:           true,
+		collections.ErrLastUpdatedReadOnly:    true,
+		collections.ErrPublishResultReadOnly:  true,
+		collections.ErrVersionReadOnly:        true,
+		collections.ErrInvalidVersion:         true,
+		collections.ErrIdempotencyKeyTooLong:  true,
+		collections.ErrInvalidConflictPolicy:  true,
+		collections.ErrInvalidEventsParameter: true,
+		collections.ErrInvalidSubscriptionID:  true,
+		collections.ErrInvalidCallbackURL:     true,
+		collections.ErrInvalidEventType:       true,
+		collections.ErrInvalidDeliveryStatus:  true,
+		zebedee.ErrUnknownType:                true,
+		zebedee.ErrPublishDateRequired:        true,
+		zebedee.ErrInvalidPublishDate:         true,
+		zebedee.ErrUnschedule:                 true,
+		ErrUnableToParseJSON:                  true,
+		ErrUnknownJSONField:                   true,
+		ErrInvalidParameter:                   true,
+		ErrInvalidRequestBody:                 true,
+		ErrUnsupportedContentType:             true,
 	}
 
 	notFound = map[error]bool{
-		collections.ErrCollectionNotFound: true,
+		collections.ErrCollectionNotFound:   true,
+		collections.ErrSubscriptionNotFound: true,
+		collections.ErrVersionNotFound:      true,
 	}
 
 	conflictRequest = map[error]bool{
 		collections.ErrCollectionNameAlreadyExists: true,
 		collections.ErrCollectionConflict:          true,
+		collections.ErrCollectionAlreadyExists:     true,
+		collections.ErrIdempotencyKeyInProgress:    true,
+		collections.ErrVersionAlreadyExists:        true,
+	}
+
+	// errors that should return a 422 status
+	unprocessableEntity = map[error]bool{
+		collections.ErrIdempotencyKeyReused: true,
 	}
 
-	ErrUnableToParseJSON = errors.New("failed to parse json body")
+	// errors that should return a 413 status
+	requestTooLarge = map[error]bool{
+		ErrRequestBodyTooLarge: true,
+	}
+
+	// errorDetails defines the code, and field where relevant, that is returned for each known error.
+	// Any error that is not listed here is returned with the internal error code.
+	errorDetails = map[error]models.ErrorResponse{
+		pagination.ErrInvalidLimitParameter:        {Code: models.ErrCodeInvalidLimit, Field: "limit"},
+		pagination.ErrInvalidOffsetParameter:       {Code: models.ErrCodeInvalidOffset, Field: "offset"},
+		pagination.ErrLimitOverMax:                 {Code: models.ErrCodeLimitOverMax, Field: "limit"},
+		collections.ErrInvalidOrderBy:              {Code: models.ErrCodeInvalidOrderBy, Field: "order_by"},
+		collections.ErrNameSearchTooLong:           {Code: models.ErrCodeNameSearchTooLong, Field: "name"},
+		collections.ErrCollectionNameEmpty:         {Code: models.ErrCodeCollectionNameEmpty, Field: "name"},
+		collections.ErrCollectionNameAlreadyExists: {Code: models.ErrCodeCollectionNameAlreadyExists, Field: "name"},
+		collections.ErrPublishDateInPast:           {Code: models.ErrCodePublishDateInPast, Field: "publish_date"},
+		collections.ErrInvalidID:                   {Code: models.ErrCodeInvalidID, Field: "collection_id"},
+		collections.ErrCollectionIDMismatch:        {Code: models.ErrCodeCollectionIDMismatch, Field: "id"},
+		collections.ErrCollectionIDReadOnly:        {Code: models.ErrCodeReadOnlyField, Field: "id"},
+		collections.ErrETagReadOnly:                {Code: models.ErrCodeReadOnlyField, Field: "e_tag"},
+		collections.ErrLastUpdatedReadOnly:         {Code: models.ErrCodeReadOnlyField, Field: "last_updated"},
+		collections.ErrPublishResultReadOnly:       {Code: models.ErrCodeReadOnlyField, Field: "publish_result"},
+		collections.ErrVersionReadOnly:             {Code: models.ErrCodeReadOnlyField, Field: "version"},
+		collections.ErrNoIfMatchHeader:             {Code: models.ErrCodeIfMatchHeaderRequired, Field: "If-Match"},
+		collections.ErrCollectionNotFound:          {Code: models.ErrCodeCollectionNotFound},
+		collections.ErrCollectionConflict:          {Code: models.ErrCodeCollectionConflict},
+		collections.ErrCollectionAlreadyExists:     {Code: models.ErrCodeCollectionAlreadyExists, Field: "id"},
+		collections.ErrInvalidConflictPolicy:       {Code: models.ErrCodeInvalidParameter, Field: "on_conflict"},
+		collections.ErrInvalidEventsParameter:      {Code: models.ErrCodeInvalidParameter, Field: "events"},
+		collections.ErrSubscriptionNotFound:        {Code: models.ErrCodeSubscriptionNotFound},
+		collections.ErrVersionNotFound:             {Code: models.ErrCodeVersionNotFound},
+		collections.ErrInvalidVersion:              {Code: models.ErrCodeInvalidParameter, Field: "version"},
+		collections.ErrVersionAlreadyExists:        {Code: models.ErrCodeCollectionConflict},
+		collections.ErrInvalidSubscriptionID:       {Code: models.ErrCodeInvalidID, Field: "subscription_id"},
+		collections.ErrInvalidCallbackURL:          {Code: models.ErrCodeInvalidCallbackURL, Field: "url"},
+		collections.ErrInvalidEventType:            {Code: models.ErrCodeInvalidEventType, Field: "event_types"},
+		collections.ErrInvalidDeliveryStatus:       {Code: models.ErrCodeInvalidParameter, Field: "status"},
+		collections.ErrIdempotencyKeyTooLong:       {Code: models.ErrCodeIdempotencyKeyTooLong, Field: "Idempotency-Key"},
+		collections.ErrIdempotencyKeyReused:        {Code: models.ErrCodeIdempotencyKeyReused, Field: "Idempotency-Key"},
+		collections.ErrIdempotencyKeyInProgress:    {Code: models.ErrCodeIdempotencyKeyInProgress, Field: "Idempotency-Key"},
+		zebedee.ErrUnknownType:                     {Code: models.ErrCodeInvalidRequestBody, Field: "type"},
+		zebedee.ErrPublishDateRequired:             {Code: models.ErrCodeInvalidRequestBody, Field: "publishDate"},
+		zebedee.ErrInvalidPublishDate:              {Code: models.ErrCodeInvalidRequestBody, Field: "publishDate"},
+		zebedee.ErrUnschedule:                      {Code: models.ErrCodeInvalidRequestBody, Field: "type"},
+		ErrUnableToParseJSON:                       {Code: models.ErrCodeInvalidJSON},
+		ErrUnknownJSONField:                        {Code: models.ErrCodeUnknownField},
+		ErrRequestBodyTooLarge:                     {Code: models.ErrCodeRequestBodyTooLarge},
+		ErrInvalidParameter:                        {Code: models.ErrCodeInvalidParameter},
+		ErrInvalidRequestBody:                      {Code: models.ErrCodeInvalidRequestBody},
+		ErrUnsupportedContentType:                  {Code: models.ErrCodeUnsupportedContentType, Field: "Content-Type"},
+	}
+
+	ErrUnableToParseJSON      = collections.ErrUnableToParseJSON
+	ErrUnknownJSONField       = collections.ErrUnknownJSONField
+	ErrRequestBodyTooLarge    = collections.ErrRequestBodyTooLarge
+	ErrInvalidParameter       = errors.New("parameter does not match the API specification")
+	ErrInvalidRequestBody     = errors.New("request body does not match the API specification")
+	ErrUnsupportedContentType = collections.ErrUnsupportedContentType
 )
 
-func handleError(ctx context.Context, err error, w http.ResponseWriter, logData log.Data) {
-	var status int
-	switch {
+// FieldError associates an error with the request field that caused it, where the field is only known at runtime
+type FieldError struct {
+	Err   error
+	Field string
+}
 
+func (e FieldError) Error() string {
+	return e.Err.Error()
+}
+
+func (e FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors represents all of the problems found when validating a request, so they can be returned together
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// cause returns the underlying error value, so that it can be looked up in the error maps.
+// Known errors may be wrapped, e.g. in a FieldError or with extra detail added to the message.
+func cause(err error) error {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if _, ok := errorDetails[e]; ok {
+			return e
+		}
+	}
+	return err
+}
+
+func getStatus(err error) int {
+	err = cause(err)
+	switch {
 	case badRequest[err]:
-		status = http.StatusBadRequest
+		return http.StatusBadRequest
 	case notFound[err]:
-		status = http.StatusNotFound
+		return http.StatusNotFound
 	case conflictRequest[err]:
-		status = http.StatusConflict
+		return http.StatusConflict
+	case unprocessableEntity[err]:
+		return http.StatusUnprocessableEntity
+	case requestTooLarge[err]:
+		return http.StatusRequestEntityTooLarge
 	default:
-		status = http.StatusInternalServerError
+		return http.StatusInternalServerError
+	}
+}
+
+func newErrorResponse(err error) models.ErrorResponse {
+	response, ok := errorDetails[cause(err)]
+	if !ok {
+		response = models.ErrorResponse{Code: models.ErrCodeInternalError}
 	}
 
+	var fieldErr FieldError
+	if errors.As(err, &fieldErr) {
+		response.Field = fieldErr.Field
+	}
+
+	response.Message = err.Error()
+	return response
+}
+
+func handleError(ctx context.Context, err error, w http.ResponseWriter, req *http.Request, logData log.Data) {
+
+	errs := []error{err}
+	if validationErrs, ok := err.(ValidationErrors); ok && len(validationErrs) > 0 {
+		errs = validationErrs
+	}
+
+	status := errorsStatus(errs)
+
 	if logData == nil {
 		logData = log.Data{}
 	}
 
-	response := models.ErrorsResponse{
-		Errors: []models.ErrorResponse{
-			{
-				Message: err.Error(),
-			},
-		},
+	errorResponses := make([]models.ErrorResponse, 0, len(errs))
+	for _, e := range errs {
+		errorResponses = append(errorResponses, newErrorResponse(e))
+	}
+
+	var response interface{} = models.ErrorsResponse{
+		Errors: errorResponses,
+	}
+	contentType := "application/json; charset=utf-8"
+
+	if acceptsProblemJSON(req) {
+		response = models.ProblemDetails{
+			Type:     "about:blank",
+			Title:    http.StatusText(status),
+			Status:   status,
+			Detail:   err.Error(),
+			Instance: req.URL.RequestURI(),
+			Errors:   errorResponses,
+		}
+		contentType = problemJSONContentType
 	}
 
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(status)
 	WriteJSONBody(ctx, response, w, logData)
 	log.Error(ctx, "request unsuccessful", err, logData)
 }
+
+// errorsStatus returns the status of a response containing the given errors. All errors in a single response share a
+// status, so if they disagree, the request as a whole is bad.
+func errorsStatus(errs []error) int {
+	status := getStatus(errs[0])
+	for _, e := range errs[1:] {
+		if getStatus(e) != status {
+			return http.StatusBadRequest
+		}
+	}
+	return status
+}
+
+// acceptsProblemJSON returns true if the request's Accept header prefers an RFC 7807 problem details document
+// over plain JSON. Wildcards are not treated as a preference, so that existing clients get the default format.
+func acceptsProblemJSON(req *http.Request) bool {
+	if req == nil {
+		return false
+	}
+
+	problemQuality, jsonQuality := 0.0, 0.0
+
+	for _, value := range req.Header.Values("Accept") {
+		for _, part := range strings.Split(value, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+
+			quality := 1.0
+			if q, ok := params["q"]; ok {
+				if quality, err = strconv.ParseFloat(q, 64); err != nil {
+					continue
+				}
+			}
+
+			switch mediaType {
+			case problemJSONMediaType:
+				problemQuality = math.Max(problemQuality, quality)
+			case "application/json":
+				jsonQuality = math.Max(jsonQuality, quality)
+			}
+		}
+	}
+
+	return problemQuality > 0 && problemQuality >= jsonQuality
+}
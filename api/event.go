@@ -1,12 +1,14 @@
 package api
 
 import (
+	"context"
 	"github.com/ONSdigital/dp-collection-api/collections"
 	"github.com/ONSdigital/dp-collection-api/models"
 	"github.com/ONSdigital/dp-collection-api/pagination"
 	"github.com/ONSdigital/log.go/v2/log"
 	"github.com/gorilla/mux"
 	"net/http"
+	"time"
 )
 
 // GetEventsHandler handles HTTP requests for the get collection events endpoint
@@ -16,20 +18,20 @@ func (api *API) GetEventsHandler(w http.ResponseWriter, req *http.Request) {
 
 	queryParams, err := readEventsQueryParams(req, api.paginator)
 	if err != nil {
-		handleError(ctx, err, w, logData)
+		handleError(ctx, err, w, req, logData)
 		return
 	}
 	logData["query_params"] = queryParams
 
 	_, err = api.collectionStore.GetCollectionByID(ctx, queryParams.CollectionID, models.AnyETag)
 	if err != nil {
-		handleError(ctx, collections.ErrCollectionNotFound, w, logData)
+		handleError(ctx, collections.ErrCollectionNotFound, w, req, logData)
 		return
 	}
 
 	events, totalCount, err := api.collectionStore.GetCollectionEvents(ctx, *queryParams)
 	if err != nil {
-		handleError(ctx, err, w, logData)
+		handleError(ctx, err, w, req, logData)
 		return
 	}
 
@@ -67,3 +69,178 @@ func readEventsQueryParams(req *http.Request, paginator Paginator) (*collections
 		CollectionID: collectionID,
 	}, nil
 }
+
+// AddEventListener adds a listener that is notified of each lifecycle event recorded by the API
+func (api *API) AddEventListener(listener EventListener) {
+	api.listeners = append(api.listeners, listener)
+}
+
+// AddTransactionalListener adds a listener that is notified of each lifecycle event in the transaction that records
+// it, if there is an outbox, and otherwise once the write has been made, in the same way as any other listener
+func (api *API) AddTransactionalListener(listener TransactionalListener) {
+	api.txListeners = append(api.txListeners, listener)
+}
+
+// SetOutbox makes each write to a collection store a message in the outbox, in the same transaction as the write and
+// its lifecycle event
+func (api *API) SetOutbox(outbox Outbox) {
+	api.outbox = outbox
+}
+
+// addCollection adds a new collection as its first version, and records its lifecycle event. Any history, such as the
+// events of an imported collection, is added in the same write.
+func (api *API) addCollection(ctx context.Context, collection *models.Collection, history ...models.Event) error {
+	collection.Version = 1
+	return api.writeCollection(ctx, lifecycleEventType(nil, collection, Now()), collection, func(ctx context.Context) error {
+		if err := api.collectionStore.AddCollection(ctx, collection); err != nil {
+			return err
+		}
+		for i := range history {
+			if err := api.collectionStore.AddEvent(ctx, &history[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// replaceCollection replaces an existing collection with the update, and records its lifecycle event. The fields of the
+// update are set on the existing collection in the same way as the store, so that the event has the collection as
+// written. The update is the version after the existing collection.
+func (api *API) replaceCollection(ctx context.Context, existing, update *models.Collection, eTag string) error {
+	update.Version = existing.Version + 1
+	written := *existing
+	written.Set(update)
+	return api.writeCollection(ctx, lifecycleEventType(existing, &written, Now()), &written, func(ctx context.Context) error {
+		return api.collectionStore.ReplaceCollection(ctx, update, eTag)
+	})
+}
+
+// writeCollection makes a write to a collection, adds an event of the given type and a snapshot of the version written,
+// and notifies the listeners once the write has been committed. Without an outbox, a failure to add the event or the
+// snapshot, or of a transactional listener, is logged rather than returned to the client, as the write has already
+// succeeded, and the listeners are still notified of the write. A snapshot whose event could not be added has no
+// event ID. With an outbox, the write, the event, the snapshot, the outbox message and the transactional listeners
+// are made in one transaction, and any failure is returned.
+func (api *API) writeCollection(ctx context.Context, eventType string, written *models.Collection, write func(ctx context.Context) error) error {
+	logData := log.Data{"collection_id": written.ID, "event_type": eventType}
+
+	eventID, err := NewID()
+	if err != nil {
+		return err
+	}
+
+	event := &models.Event{
+		ID:           eventID,
+		Type:         eventType,
+		Date:         Now().UTC(),
+		CollectionID: written.ID,
+		Version:      written.Version,
+	}
+
+	if api.outbox == nil {
+		if err := write(ctx); err != nil {
+			return err
+		}
+		versionEvent := *event
+		if err := api.collectionStore.AddEvent(ctx, event); err != nil {
+			log.Error(ctx, "failed to record collection event", err, logData)
+			versionEvent.ID = ""
+		}
+		if err := api.addVersion(ctx, &versionEvent, written); err != nil {
+			log.Error(ctx, "failed to record collection version", err, logData)
+		}
+		for _, listener := range api.txListeners {
+			if err := listener.HandleCollectionEvent(ctx, event, written); err != nil {
+				log.Error(ctx, "collection event listener failed", err, logData)
+			}
+		}
+	} else {
+		messageID, err := NewID()
+		if err != nil {
+			return err
+		}
+
+		err = api.outbox.WithTransaction(ctx, func(ctx context.Context) error {
+			if err := write(ctx); err != nil {
+				return err
+			}
+			if err := api.collectionStore.AddEvent(ctx, event); err != nil {
+				return err
+			}
+			if err := api.addVersion(ctx, event, written); err != nil {
+				return err
+			}
+			err := api.outbox.AddOutboxMessage(ctx, &models.OutboxMessage{
+				ID:         messageID,
+				Event:      *event,
+				Collection: *written,
+				CreatedAt:  Now().UTC(),
+			})
+			if err != nil {
+				return err
+			}
+			for _, listener := range api.txListeners {
+				if err := listener.HandleCollectionEvent(ctx, event, written); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, listener := range api.txListeners {
+		listener.Committed(ctx)
+	}
+
+	for _, listener := range api.listeners {
+		if err := listener.HandleCollectionEvent(ctx, event, written); err != nil {
+			log.Error(ctx, "collection event listener failed", err, logData)
+		}
+	}
+	return nil
+}
+
+// addVersion adds a snapshot of the collection as it was written, linked to the event that recorded the write. Nothing
+// is added if versions are not set up. A version that already has a snapshot was numbered from a collection that has
+// since been changed, so it is reported as a conflict.
+func (api *API) addVersion(ctx context.Context, event *models.Event, written *models.Collection) error {
+	if api.versionStore == nil {
+		return nil
+	}
+
+	err := api.versionStore.AddVersion(ctx, &models.Version{
+		ID:           models.VersionID(written.ID, written.Version),
+		CollectionID: written.ID,
+		Version:      written.Version,
+		EventID:      event.ID,
+		EventType:    event.Type,
+		Date:         event.Date,
+		Collection:   *written,
+	})
+	if err == collections.ErrVersionAlreadyExists {
+		return collections.ErrCollectionConflict
+	}
+	return err
+}
+
+// lifecycleEventType returns the type of the event recorded when a collection is changed from previous to current. A
+// collection with no previous value has been created.
+func lifecycleEventType(previous, current *models.Collection, now time.Time) string {
+	if previous == nil {
+		return models.EventCreated
+	}
+
+	state := current.State(now)
+	switch {
+	case state == previous.State(now):
+		return models.EventUpdated
+	case state == models.StatePublished:
+		return models.EventPublished
+	default:
+		return models.EventStateChanged
+	}
+}
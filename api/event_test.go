@@ -6,6 +6,8 @@ import (
 	"github.com/ONSdigital/dp-collection-api/api"
 	"github.com/ONSdigital/dp-collection-api/api/mock"
 	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/config"
+	"github.com/ONSdigital/dp-collection-api/memory"
 	"github.com/ONSdigital/dp-collection-api/models"
 	"github.com/ONSdigital/dp-collection-api/pagination"
 	"github.com/gorilla/mux"
@@ -13,7 +15,9 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -30,7 +34,7 @@ func TestGetEvents(t *testing.T) {
 
 		Convey("When the request is sent to the API", func() {
 
-			api := api.Setup(context.Background(), mux.NewRouter(), paginator, collectionStore)
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
 			api.Router.ServeHTTP(w, r)
 
 			Convey("Then the paginator is called to extract pagination parameters", func() {
@@ -83,7 +87,7 @@ func TestGetEvents_paginationError(t *testing.T) {
 			r := httptest.NewRequest("GET", "http://localhost:26000/collections/123/events", nil)
 			w := httptest.NewRecorder()
 
-			api := api.Setup(context.Background(), mux.NewRouter(), paginator, collectionStore)
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
 			api.Router.ServeHTTP(w, r)
 
 			Convey("Then the paginator is called to extract pagination parameters", func() {
@@ -116,7 +120,7 @@ func TestGetEvents_collectionStoreError(t *testing.T) {
 			r := httptest.NewRequest("GET", "http://localhost:26000/collections/123/events", nil)
 			w := httptest.NewRecorder()
 
-			api := api.Setup(context.Background(), mux.NewRouter(), paginator, collectionStore)
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
 			api.Router.ServeHTTP(w, r)
 
 			Convey("Then the paginator is called to extract pagination parameters", func() {
@@ -146,7 +150,7 @@ func TestGetEvents_internalError(t *testing.T) {
 			r := httptest.NewRequest("GET", "http://localhost:26000/collections/123/events", nil)
 			w := httptest.NewRecorder()
 
-			api := api.Setup(context.Background(), mux.NewRouter(), paginator, collectionStore)
+			api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), paginator, collectionStore, nil)
 			api.Router.ServeHTTP(w, r)
 
 			Convey("Then the paginator is called to extract pagination parameters", func() {
@@ -159,3 +163,276 @@ func TestGetEvents_internalError(t *testing.T) {
 		})
 	})
 }
+
+func TestCollectionLifecycleEvents(t *testing.T) {
+
+	api.NewID = func() (string, error) {
+		return collectionID, nil
+	}
+	api.Now = func() time.Time {
+		return time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	}
+
+	Convey("Given an API with an event listener", t, func() {
+		ctx := context.Background()
+		store := memory.New()
+		listener := &mock.EventListenerMock{
+			HandleCollectionEventFunc: func(ctx context.Context, event *models.Event, collection *models.Collection) error {
+				return nil
+			},
+		}
+		r := mux.NewRouter()
+		api.Setup(ctx, &config.Config{}, r, &pagination.Paginator{}, store, nil).AddEventListener(listener)
+
+		Convey("When a collection is created", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/collections", strings.NewReader(`{"name":"collection 1"}`)))
+			So(w.Code, ShouldEqual, http.StatusCreated)
+
+			var created models.Collection
+			So(json.Unmarshal(w.Body.Bytes(), &created), ShouldBeNil)
+
+			Convey("Then a CREATED event is recorded, and the listener is notified", func() {
+				events, _, err := store.GetCollectionEvents(ctx, collections.EventsQueryParams{CollectionID: created.ID, Limit: 10})
+				So(err, ShouldBeNil)
+				So(events, ShouldHaveLength, 1)
+				So(events[0].Type, ShouldEqual, models.EventCreated)
+				So(events[0].Date.Equal(api.Now()), ShouldBeTrue)
+
+				So(listener.HandleCollectionEventCalls(), ShouldHaveLength, 1)
+				So(listener.HandleCollectionEventCalls()[0].Event.Type, ShouldEqual, models.EventCreated)
+				So(listener.HandleCollectionEventCalls()[0].Collection.Name, ShouldEqual, "collection 1")
+			})
+
+			Convey("And it is renamed", func() {
+				w := replaceCollection(r, &created, `{"name":"collection 2"}`)
+
+				Convey("Then an UPDATED event is recorded", func() {
+					So(w.Code, ShouldEqual, http.StatusOK)
+					So(listener.HandleCollectionEventCalls(), ShouldHaveLength, 2)
+					So(listener.HandleCollectionEventCalls()[1].Event.Type, ShouldEqual, models.EventUpdated)
+				})
+			})
+
+			Convey("And it is scheduled", func() {
+				w := replaceCollection(r, &created, `{"name":"collection 1","publish_date":"2030-01-01T09:30:00Z"}`)
+
+				Convey("Then a STATE_CHANGED event is recorded", func() {
+					So(w.Code, ShouldEqual, http.StatusOK)
+					So(listener.HandleCollectionEventCalls(), ShouldHaveLength, 2)
+					So(listener.HandleCollectionEventCalls()[1].Event.Type, ShouldEqual, models.EventStateChanged)
+				})
+			})
+		})
+
+		Convey("When a collection is created and the listener fails", func() {
+			listener.HandleCollectionEventFunc = func(ctx context.Context, event *models.Event, collection *models.Collection) error {
+				return errors.New("listener is broken")
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/collections", strings.NewReader(`{"name":"collection 1"}`)))
+
+			Convey("Then the collection is still created", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(listener.HandleCollectionEventCalls(), ShouldHaveLength, 1)
+			})
+		})
+	})
+}
+
+func TestCollectionLifecycleEvents_eventStoreError(t *testing.T) {
+
+	api.NewID = func() (string, error) {
+		return collectionID, nil
+	}
+	api.Now = func() time.Time {
+		return time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	}
+
+	Convey("Given an API whose store cannot record events", t, func() {
+		ctx := context.Background()
+		store := memory.New()
+		collectionStore := &mock.CollectionStoreMock{
+			GetCollectionByNameFunc: store.GetCollectionByName,
+			AddCollectionFunc:       store.AddCollection,
+			AddEventFunc: func(ctx context.Context, event *models.Event) error {
+				return errors.New("events are broken")
+			},
+		}
+		listener := &mock.EventListenerMock{
+			HandleCollectionEventFunc: func(ctx context.Context, event *models.Event, collection *models.Collection) error {
+				return nil
+			},
+		}
+		r := mux.NewRouter()
+		a := api.Setup(ctx, &config.Config{}, r, &pagination.Paginator{}, collectionStore, nil)
+		a.SetupVersions(store)
+		a.AddEventListener(listener)
+
+		Convey("When a collection is created", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/collections", strings.NewReader(`{"name":"collection 1"}`)))
+
+			Convey("Then the collection is created, and its snapshot is added without an event ID", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+
+				version, err := store.GetVersion(ctx, collectionID, 1)
+				So(err, ShouldBeNil)
+				So(version.EventID, ShouldBeEmpty)
+				So(version.EventType, ShouldEqual, models.EventCreated)
+			})
+
+			Convey("Then the listener is still notified of the write", func() {
+				So(listener.HandleCollectionEventCalls(), ShouldHaveLength, 1)
+			})
+		})
+	})
+}
+
+func TestCollectionLifecycleEvents_transactionalListenerError(t *testing.T) {
+
+	api.NewID = func() (string, error) {
+		return collectionID, nil
+	}
+
+	Convey("Given an API without an outbox, and a transactional listener that fails", t, func() {
+		store := memory.New()
+		txListener := &mock.TransactionalListenerMock{
+			HandleCollectionEventFunc: func(ctx context.Context, event *models.Event, collection *models.Collection) error {
+				return errors.New("deliveries are broken")
+			},
+			CommittedFunc: func(ctx context.Context) {},
+		}
+		r := mux.NewRouter()
+		a := api.Setup(context.Background(), &config.Config{}, r, &pagination.Paginator{}, store, nil)
+		a.AddTransactionalListener(txListener)
+
+		Convey("When a collection is created", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/collections", strings.NewReader(`{"name":"collection 1"}`)))
+
+			Convey("Then the collection is created, as the failure is only logged, and the listener is told", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(txListener.HandleCollectionEventCalls(), ShouldHaveLength, 1)
+				So(txListener.CommittedCalls(), ShouldHaveLength, 1)
+			})
+		})
+	})
+}
+
+func TestCollectionLifecycleEvents_outbox(t *testing.T) {
+
+	api.NewID = func() (string, error) {
+		return collectionID, nil
+	}
+	api.Now = func() time.Time {
+		return time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	}
+
+	Convey("Given an API with an outbox", t, func() {
+		ctx := context.Background()
+		store := memory.New()
+		outbox := &mock.OutboxMock{
+			WithTransactionFunc: func(ctx context.Context, fn func(ctx context.Context) error) error {
+				return fn(ctx)
+			},
+			AddOutboxMessageFunc: func(ctx context.Context, message *models.OutboxMessage) error {
+				return nil
+			},
+		}
+		listener := &mock.EventListenerMock{
+			HandleCollectionEventFunc: func(ctx context.Context, event *models.Event, collection *models.Collection) error {
+				return nil
+			},
+		}
+		r := mux.NewRouter()
+		a := api.Setup(ctx, &config.Config{}, r, &pagination.Paginator{}, store, nil)
+		a.SetOutbox(outbox)
+		a.AddEventListener(listener)
+
+		Convey("When a collection is created", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/collections", strings.NewReader(`{"name":"collection 1"}`)))
+
+			Convey("Then the collection, its event and an outbox message are written in one transaction", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(outbox.WithTransactionCalls(), ShouldHaveLength, 1)
+				So(outbox.AddOutboxMessageCalls(), ShouldHaveLength, 1)
+
+				message := outbox.AddOutboxMessageCalls()[0].Message
+				So(message.ID, ShouldNotBeEmpty)
+				So(message.Event.Type, ShouldEqual, models.EventCreated)
+				So(message.Collection.Name, ShouldEqual, "collection 1")
+				So(message.CreatedAt.Equal(api.Now()), ShouldBeTrue)
+
+				events, _, err := store.GetCollectionEvents(ctx, collections.EventsQueryParams{CollectionID: collectionID, Limit: 10})
+				So(err, ShouldBeNil)
+				So(events, ShouldHaveLength, 1)
+			})
+
+			Convey("Then the listener is notified once the transaction has committed", func() {
+				So(listener.HandleCollectionEventCalls(), ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When a collection is created, with a transactional listener", func() {
+			txListener := &mock.TransactionalListenerMock{
+				HandleCollectionEventFunc: func(ctx context.Context, event *models.Event, collection *models.Collection) error {
+					So(outbox.AddOutboxMessageCalls(), ShouldHaveLength, 1)
+					return nil
+				},
+				CommittedFunc: func(ctx context.Context) {},
+			}
+			a.AddTransactionalListener(txListener)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/collections", strings.NewReader(`{"name":"collection 1"}`)))
+
+			Convey("Then it is notified in the transaction, and told once the transaction has committed", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(outbox.WithTransactionCalls(), ShouldHaveLength, 1)
+				So(txListener.HandleCollectionEventCalls(), ShouldHaveLength, 1)
+				So(txListener.HandleCollectionEventCalls()[0].Event.Type, ShouldEqual, models.EventCreated)
+				So(txListener.CommittedCalls(), ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When a collection is created, and a transactional listener fails", func() {
+			txListener := &mock.TransactionalListenerMock{
+				HandleCollectionEventFunc: func(ctx context.Context, event *models.Event, collection *models.Collection) error {
+					return errors.New("deliveries are broken")
+				},
+				CommittedFunc: func(ctx context.Context) {},
+			}
+			a.AddTransactionalListener(txListener)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/collections", strings.NewReader(`{"name":"collection 1"}`)))
+
+			Convey("Then the write fails, and no listener is told of it", func() {
+				So(w.Code, ShouldEqual, http.StatusInternalServerError)
+				So(txListener.CommittedCalls(), ShouldBeEmpty)
+				So(listener.HandleCollectionEventCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When a collection is created and the outbox message cannot be written", func() {
+			outbox.AddOutboxMessageFunc = func(ctx context.Context, message *models.OutboxMessage) error {
+				return errors.New("outbox is broken")
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/collections", strings.NewReader(`{"name":"collection 1"}`)))
+
+			Convey("Then an internal error is returned, and the listener is not notified", func() {
+				So(w.Code, ShouldEqual, http.StatusInternalServerError)
+				So(listener.HandleCollectionEventCalls(), ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+func replaceCollection(r http.Handler, collection *models.Collection, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/collections/"+collection.ID, strings.NewReader(body))
+	req.Header.Set("If-Match", collection.ETag)
+	r.ServeHTTP(w, req)
+	return w
+}
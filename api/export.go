@@ -0,0 +1,322 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+	dphttp "github.com/ONSdigital/dp-net/v2/http"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+const (
+	ndjsonMediaType   = "application/x-ndjson"
+	ndjsonContentType = ndjsonMediaType + "; charset=utf-8"
+
+	// exportPageSize is the number of collections, or events, read from the store at a time during an export
+	exportPageSize = 100
+
+	// defaultMaxImportLineBytes limits the length of an import line when no request body limit is configured
+	defaultMaxImportLineBytes = 1024 * 1024
+)
+
+// GetExportHandler streams every collection as NDJSON, one collection per line, with its events if requested. The
+// collections are read a page at a time in ID order, starting after the last ID of the previous page, so that a
+// collection added during the export does not move others between pages. The server's write timeout is extended as
+// each collection is written, so that a large export is not cut off.
+func (api *API) GetExportHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logData := log.Data{}
+
+	includeEvents := false
+	if value := req.URL.Query().Get("events"); len(value) > 0 {
+		var err error
+		if includeEvents, err = strconv.ParseBool(value); err != nil {
+			handleError(ctx, collections.ErrInvalidEventsParameter, w, req, logData)
+			return
+		}
+	}
+	logData["events"] = includeEvents
+
+	// the first page is read before the response is started, so that a store failure can still be returned as an error
+	queryParams := collections.QueryParams{Limit: exportPageSize, OrderBy: collections.OrderByID}
+	page, _, err := api.collectionStore.GetCollections(ctx, queryParams)
+	if err != nil {
+		handleError(ctx, err, w, req, logData)
+		return
+	}
+
+	w.Header().Set("Content-Type", ndjsonContentType)
+	encoder := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+
+	for len(page) > 0 {
+		for i := range page {
+			if api.writeTimeout > 0 {
+				extendWriteDeadline(ctx, api.writeTimeout)
+			}
+			exported := models.ExportedCollection{Collection: page[i]}
+			if includeEvents {
+				if exported.Events, err = api.allEvents(ctx, page[i].ID); err != nil {
+					log.Error(ctx, "export stopped, failed to read events", err, logData)
+					return
+				}
+			}
+			if err := encoder.Encode(exported); err != nil {
+				log.Error(ctx, "export stopped, failed to write collection", err, logData)
+				return
+			}
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		if len(page) < exportPageSize {
+			break
+		}
+		queryParams.AfterID = page[len(page)-1].ID
+		if page, _, err = api.collectionStore.GetCollections(ctx, queryParams); err != nil {
+			log.Error(ctx, "export stopped, failed to read collections", err, logData)
+			return
+		}
+	}
+
+	log.Info(ctx, "export request completed successfully", logData)
+}
+
+// allEvents reads every event for a collection, a page at a time
+func (api *API) allEvents(ctx context.Context, collectionID string) ([]models.Event, error) {
+	var events []models.Event
+
+	for {
+		page, totalCount, err := api.collectionStore.GetCollectionEvents(ctx, collections.EventsQueryParams{
+			CollectionID: collectionID,
+			Offset:       len(events),
+			Limit:        exportPageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, page...)
+		if len(page) == 0 || len(events) >= totalCount {
+			return events, nil
+		}
+	}
+}
+
+// PostImportHandler imports collections from an NDJSON body in the format of an export, and returns a report with
+// the result of each line. The on_conflict query parameter decides what happens to a collection with the ID or name
+// of an existing collection. Lines are imported in turn, so the lines before a failure are still imported.
+func (api *API) PostImportHandler(w http.ResponseWriter, req *http.Request) {
+	defer dphttp.DrainBody(req)
+
+	ctx := req.Context()
+	logData := log.Data{}
+
+	policy, err := collections.ParseConflictPolicy(req.URL.Query().Get("on_conflict"))
+	if err != nil {
+		handleError(ctx, err, w, req, logData)
+		return
+	}
+	logData["on_conflict"] = policy
+
+	maxLineBytes := api.maxRequestBodyBytes
+	if maxLineBytes <= 0 {
+		maxLineBytes = defaultMaxImportLineBytes
+	}
+
+	// the larger of the buffer capacity and the maximum is used as the limit, so the buffer is never larger
+	bufferBytes := int64(bufio.MaxScanTokenSize)
+	if maxLineBytes < bufferBytes {
+		bufferBytes = maxLineBytes
+	}
+	scanner := bufio.NewScanner(req.Body)
+	scanner.Buffer(make([]byte, 0, bufferBytes), int(maxLineBytes))
+
+	report := models.ImportReport{Results: []models.ImportResult{}}
+	line := 0
+
+	for scanner.Scan() {
+		line++
+		b := bytes.TrimSpace(scanner.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+
+		result := api.importCollection(ctx, line, b, policy)
+		report.Add(result)
+		if result.Result == models.ImportFailed && policy == collections.ConflictPolicyFail {
+			report.Stopped = true
+			break
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		if err == bufio.ErrTooLong {
+			err = ErrRequestBodyTooLarge
+		}
+		report.Add(failedImport(models.ImportResult{Line: line + 1}, err))
+		report.Stopped = true
+	}
+
+	logData["created"] = report.Created
+	logData["updated"] = report.Updated
+	logData["skipped"] = report.Skipped
+	logData["failed"] = report.Failed
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	WriteJSONBody(ctx, report, w, logData)
+
+	log.Info(ctx, "import request completed", logData)
+}
+
+// importCollection imports a single line, applying the conflict policy to a collection that already exists
+func (api *API) importCollection(ctx context.Context, line int, b []byte, policy collections.ConflictPolicy) models.ImportResult {
+	result := models.ImportResult{Line: line}
+
+	exported, err := parseExportedCollection(b)
+	if err != nil {
+		return failedImport(result, err)
+	}
+	result.ID = exported.ID
+	result.Name = exported.Name
+
+	collection, err := importedCollection(exported)
+	if err != nil {
+		return failedImport(result, err)
+	}
+	result.ID = collection.ID
+
+	existing, err := api.collectionStore.GetCollectionByID(ctx, collection.ID, models.AnyETag)
+	if err != nil && err != collections.ErrCollectionNotFound {
+		return failedImport(result, err)
+	}
+
+	sameName, err := api.collectionStore.GetCollectionByName(ctx, collection.Name)
+	if err != nil && err != collections.ErrCollectionNotFound {
+		return failedImport(result, err)
+	}
+
+	// names are unique, so a collection is never imported over a different collection with the same name
+	if sameName != nil && sameName.ID != collection.ID {
+		if policy == collections.ConflictPolicySkip {
+			return skippedImport(result, collections.ErrCollectionNameAlreadyExists)
+		}
+		return failedImport(result, collections.ErrCollectionNameAlreadyExists)
+	}
+
+	if existing != nil {
+		switch policy {
+		case collections.ConflictPolicySkip:
+			return skippedImport(result, collections.ErrCollectionAlreadyExists)
+		case collections.ConflictPolicyFail:
+			return failedImport(result, collections.ErrCollectionAlreadyExists)
+		}
+
+		if existing.ETag == collection.ETag {
+			result.Result = models.ImportUnchanged
+			return result
+		}
+
+		// the collection is replaced only if it is unchanged since it was read above
+		if err := api.replaceCollection(ctx, existing, collection, existing.ETag); err != nil {
+			return failedImport(result, err)
+		}
+		result.Result = models.ImportUpdated
+		return result
+	}
+
+	history := make([]models.Event, 0, len(exported.Events))
+	for _, event := range exported.Events {
+		event.CollectionID = collection.ID
+		history = append(history, event)
+	}
+
+	if err := api.addCollection(ctx, collection, history...); err != nil {
+		return failedImport(result, err)
+	}
+
+	result.Result = models.ImportCreated
+	return result
+}
+
+// parseExportedCollection strictly decodes a line of an import, rejecting unknown fields
+func parseExportedCollection(b []byte) (*models.ExportedCollection, error) {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+
+	var exported models.ExportedCollection
+	if err := decoder.Decode(&exported); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnableToParseJSON, err.Error())
+	}
+	return &exported, nil
+}
+
+// importedCollection validates an imported collection, and returns the collection to store. The ID is kept, so that
+// the collection can be found by the same ID in each environment, or generated if there is none. The ETag is
+// calculated in the same way as for a new collection, so that an unchanged collection has the same ETag.
+func importedCollection(exported *models.ExportedCollection) (*models.Collection, error) {
+	var errs ValidationErrors
+
+	if len(exported.Name) == 0 {
+		errs = append(errs, collections.ErrCollectionNameEmpty)
+	}
+	if len(exported.ID) > 0 && ValidateUUID(exported.ID) != nil {
+		errs = append(errs, collections.ErrInvalidID)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	collection := &models.Collection{
+		Name:        exported.Name,
+		PublishDate: exported.PublishDate,
+	}
+
+	eTag, err := collection.Hash(nil)
+	if err != nil {
+		return nil, err
+	}
+	collection.ETag = eTag
+
+	collection.ID = exported.ID
+	if len(collection.ID) == 0 {
+		if collection.ID, err = NewID(); err != nil {
+			return nil, err
+		}
+	}
+
+	return collection, nil
+}
+
+func skippedImport(result models.ImportResult, err error) models.ImportResult {
+	result.Result = models.ImportSkipped
+	result.Errors = importErrors(err)
+	return result
+}
+
+func failedImport(result models.ImportResult, err error) models.ImportResult {
+	result.Result = models.ImportFailed
+	result.Errors = importErrors(err)
+	return result
+}
+
+func importErrors(err error) []models.ErrorResponse {
+	errs := []error{err}
+	if validationErrs, ok := err.(ValidationErrors); ok {
+		errs = validationErrs
+	}
+
+	responses := make([]models.ErrorResponse, 0, len(errs))
+	for _, e := range errs {
+		responses = append(responses, newErrorResponse(e))
+	}
+	return responses
+}
@@ -0,0 +1,281 @@
+package api_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/api"
+	"github.com/ONSdigital/dp-collection-api/api/mock"
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/config"
+	"github.com/ONSdigital/dp-collection-api/memory"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/dp-collection-api/pagination"
+	"github.com/gorilla/mux"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const (
+	importID1 = "00000000-0000-0000-0000-000000000001"
+	importID2 = "00000000-0000-0000-0000-000000000002"
+)
+
+// newExportAPI returns an API backed by an in-memory store
+func newExportAPI(store api.CollectionStore, maxRequestBodyBytes int64) *mux.Router {
+	r := mux.NewRouter()
+	api.Setup(context.Background(), &config.Config{MaxRequestBodyBytes: maxRequestBodyBytes}, r, &pagination.Paginator{}, store, nil)
+	return r
+}
+
+func export(r http.Handler, query string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/collections/export"+query, nil))
+	return w
+}
+
+func importCollections(r http.Handler, query string, body string) (*httptest.ResponseRecorder, models.ImportReport) {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/collections/import"+query, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	r.ServeHTTP(w, req)
+
+	var report models.ImportReport
+	json.Unmarshal(w.Body.Bytes(), &report)
+	return w, report
+}
+
+func exportedLines(body string) []models.ExportedCollection {
+	var lines []models.ExportedCollection
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		var exported models.ExportedCollection
+		So(json.Unmarshal(scanner.Bytes(), &exported), ShouldBeNil)
+		lines = append(lines, exported)
+	}
+	return lines
+}
+
+func TestGetExport(t *testing.T) {
+
+	Convey("Given a store with more collections than fit on one page", t, func() {
+		ctx := context.Background()
+		store := memory.New()
+		for i := 0; i < 150; i++ {
+			id := collectionID[:len(collectionID)-3] + string(rune('a'+i/100)) + string(rune('a'+i/10%10)) + string(rune('a'+i%10))
+			So(store.AddCollection(ctx, &models.Collection{ID: id, Name: "collection " + id, ETag: "etag"}), ShouldBeNil)
+		}
+		So(store.AddEvent(ctx, &models.Event{CollectionID: collectionID[:len(collectionID)-3] + "aaa", Type: "CREATED"}), ShouldBeNil)
+		r := newExportAPI(store, 0)
+
+		Convey("When the collections are exported", func() {
+			w := export(r, "")
+
+			Convey("Then every collection is streamed as NDJSON, without events", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Content-Type"), ShouldEqual, "application/x-ndjson; charset=utf-8")
+
+				lines := exportedLines(w.Body.String())
+				So(lines, ShouldHaveLength, 150)
+				So(lines[0].ETag, ShouldEqual, "etag")
+				So(lines[0].Events, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the collections are exported with their events", func() {
+			w := export(r, "?events=true")
+
+			Convey("Then each collection includes its events", func() {
+				lines := exportedLines(w.Body.String())
+				So(lines, ShouldHaveLength, 150)
+				So(lines[0].Events, ShouldHaveLength, 1)
+				So(lines[0].Events[0].Type, ShouldEqual, "CREATED")
+				So(lines[1].Events, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the collections are exported after more are added out of ID order", func() {
+			for i := 0; i < 10; i++ {
+				id := collectionID[:len(collectionID)-3] + "0" + string(rune('9'-i)) + "0"
+				So(store.AddCollection(ctx, &models.Collection{ID: id, Name: "collection " + id, ETag: "etag"}), ShouldBeNil)
+			}
+			w := export(r, "")
+
+			Convey("Then every collection is streamed once, in ID order", func() {
+				lines := exportedLines(w.Body.String())
+				So(lines, ShouldHaveLength, 160)
+				for i := 1; i < len(lines); i++ {
+					So(lines[i-1].ID, ShouldBeLessThan, lines[i].ID)
+				}
+			})
+		})
+
+		Convey("When the events parameter is not a boolean", func() {
+			w := export(r, "?events=maybe")
+
+			Convey("Then a bad request is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(w.Body.String(), ShouldContainSubstring, `"field":"events"`)
+			})
+		})
+	})
+
+	Convey("Given a store that cannot be read", t, func() {
+		store := &mock.CollectionStoreMock{
+			GetCollectionsFunc: func(ctx context.Context, queryParams collections.QueryParams) ([]models.Collection, int, error) {
+				return nil, 0, errors.New("db is broken")
+			},
+		}
+
+		Convey("When the collections are exported", func() {
+			w := export(newExportAPI(store, 0), "")
+
+			Convey("Then an internal error is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusInternalServerError)
+			})
+		})
+	})
+}
+
+func TestPostImport(t *testing.T) {
+
+	publishDate := time.Date(2030, 1, 1, 9, 30, 0, 0, time.UTC)
+	line1 := `{"id":"` + importID1 + `","name":"collection 1","publish_date":"2030-01-01T09:30:00Z","events":[{"type":"CREATED","email":"a@b.c","date":"2021-06-01T09:00:00Z"}]}`
+	line2 := `{"id":"` + importID2 + `","name":"collection 2"}`
+
+	Convey("Given an empty store", t, func() {
+		ctx := context.Background()
+		store := memory.New()
+		r := newExportAPI(store, 0)
+
+		Convey("When collections are imported", func() {
+			w, report := importCollections(r, "", line1+"\n\n"+line2+"\n")
+
+			Convey("Then each collection is created with its ID and events", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(report.Created, ShouldEqual, 2)
+				So(report.Results, ShouldHaveLength, 2)
+				So(report.Results[0].Line, ShouldEqual, 1)
+				So(report.Results[1].Line, ShouldEqual, 3)
+
+				collection, err := store.GetCollectionByID(ctx, importID1, models.AnyETag)
+				So(err, ShouldBeNil)
+				So(collection.Name, ShouldEqual, "collection 1")
+				So(collection.PublishDate.Equal(publishDate), ShouldBeTrue)
+				So(collection.ETag, ShouldNotBeEmpty)
+
+				events, _, err := store.GetCollectionEvents(ctx, collections.EventsQueryParams{CollectionID: importID1, Limit: 10})
+				So(err, ShouldBeNil)
+				So(events, ShouldHaveLength, 2)
+				So(events[0].Email, ShouldEqual, "a@b.c")
+				So(events[1].Type, ShouldEqual, models.EventCreated)
+				So(events[1].Version, ShouldEqual, 1)
+				So(collection.Version, ShouldEqual, 1)
+			})
+
+			Convey("And the export is imported again", func() {
+				exported := export(r, "?events=true").Body.String()
+
+				Convey("Then every collection is unchanged when overwriting", func() {
+					_, report := importCollections(r, "?on_conflict=overwrite", exported)
+					So(report.Unchanged, ShouldEqual, 2)
+				})
+
+				Convey("Then every collection is skipped when skipping", func() {
+					_, report := importCollections(r, "?on_conflict=skip", exported)
+					So(report.Skipped, ShouldEqual, 2)
+					So(report.Results[0].Errors[0].Code, ShouldEqual, models.ErrCodeCollectionAlreadyExists)
+				})
+
+				Convey("Then the import stops at the first collection by default", func() {
+					_, report := importCollections(r, "", exported)
+					So(report.Failed, ShouldEqual, 1)
+					So(report.Stopped, ShouldBeTrue)
+					So(report.Results, ShouldHaveLength, 1)
+				})
+			})
+
+			Convey("And a changed collection is imported with the overwrite policy", func() {
+				_, report := importCollections(r, "?on_conflict=overwrite", `{"id":"`+importID2+`","name":"renamed"}`)
+
+				Convey("Then the collection is updated as its next version, and the update is recorded", func() {
+					So(report.Updated, ShouldEqual, 1)
+					collection, err := store.GetCollectionByID(ctx, importID2, models.AnyETag)
+					So(err, ShouldBeNil)
+					So(collection.Name, ShouldEqual, "renamed")
+					So(collection.Version, ShouldEqual, 2)
+
+					events, _, err := store.GetCollectionEvents(ctx, collections.EventsQueryParams{CollectionID: importID2, Limit: 10})
+					So(err, ShouldBeNil)
+					So(events[len(events)-1].Type, ShouldEqual, models.EventUpdated)
+				})
+			})
+
+			Convey("And a different collection with an existing name is imported with the overwrite policy", func() {
+				_, report := importCollections(r, "?on_conflict=overwrite", `{"name":"collection 1"}`)
+
+				Convey("Then it is not imported, as names are unique", func() {
+					So(report.Failed, ShouldEqual, 1)
+					So(report.Results[0].Errors[0].Code, ShouldEqual, models.ErrCodeCollectionNameAlreadyExists)
+				})
+			})
+		})
+
+		Convey("When a collection without an ID is imported", func() {
+			_, report := importCollections(r, "", `{"name":"collection 3"}`)
+
+			Convey("Then it is created with a new ID", func() {
+				So(report.Created, ShouldEqual, 1)
+				So(report.Results[0].ID, ShouldNotBeEmpty)
+			})
+		})
+
+		Convey("When invalid lines are imported with the skip policy", func() {
+			_, report := importCollections(r, "?on_conflict=skip", strings.Join([]string{
+				`not json`,
+				`{"name":"collection 3","unknown":true}`,
+				`{"id":"abc"}`,
+				line2,
+			}, "\n"))
+
+			Convey("Then each invalid line fails, and the rest are imported", func() {
+				So(report.Failed, ShouldEqual, 3)
+				So(report.Created, ShouldEqual, 1)
+				So(report.Stopped, ShouldBeFalse)
+
+				So(report.Results[0].Errors[0].Code, ShouldEqual, models.ErrCodeInvalidJSON)
+				So(report.Results[1].Errors[0].Code, ShouldEqual, models.ErrCodeInvalidJSON)
+				So(report.Results[2].Errors, ShouldHaveLength, 2)
+				So(report.Results[2].Errors[0].Code, ShouldEqual, models.ErrCodeCollectionNameEmpty)
+				So(report.Results[2].Errors[1].Code, ShouldEqual, models.ErrCodeInvalidID)
+			})
+		})
+
+		Convey("When a line is longer than the maximum request body size", func() {
+			_, report := importCollections(newExportAPI(store, 100), "?on_conflict=skip", line2+"\n"+line1)
+
+			Convey("Then the import stops at that line", func() {
+				So(report.Created, ShouldEqual, 1)
+				So(report.Failed, ShouldEqual, 1)
+				So(report.Stopped, ShouldBeTrue)
+				So(report.Results[1].Errors[0].Code, ShouldEqual, models.ErrCodeRequestBodyTooLarge)
+			})
+		})
+
+		Convey("When an unknown conflict policy is given", func() {
+			w, _ := importCollections(r, "?on_conflict=replace", line1)
+
+			Convey("Then a bad request is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(w.Body.String(), ShouldContainSubstring, `"field":"on_conflict"`)
+			})
+		})
+	})
+}
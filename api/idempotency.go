@@ -0,0 +1,155 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+
+	// idempotencyInProgressTTL limits how long a request that never completes (e.g. due to a crash) blocks its key
+	idempotencyInProgressTTL = time.Minute
+)
+
+// idempotent wraps a handler so that requests with an Idempotency-Key header are only processed once.
+// The first response is stored and replayed for any retry with the same key and an identical request.
+// Requests without the header, or when no idempotency store is configured, are passed straight to the handler.
+func (api *API) idempotent(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		key := req.Header.Get(idempotencyKeyHeader)
+		if len(key) == 0 || api.idempotencyStore == nil {
+			handler(w, req)
+			return
+		}
+
+		ctx := req.Context()
+		logData := log.Data{"idempotency_key": key}
+
+		if len(key) > maxIdempotencyKeyLength {
+			handleError(ctx, collections.ErrIdempotencyKeyTooLong, w, req, logData)
+			return
+		}
+
+		// the body is read to identify the request
+		body, err := bufferBody(req, api.maxRequestBodyBytes)
+		if err != nil {
+			handleError(ctx, err, w, req, logData)
+			return
+		}
+
+		now := Now()
+		record := &models.IdempotencyRecord{
+			Key:         key,
+			RequestHash: hashRequest(req, body),
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(idempotencyInProgressTTL),
+		}
+
+		err = api.idempotencyStore.AddIdempotencyRecord(ctx, record)
+		if err == collections.ErrIdempotencyKeyExists {
+			api.replay(w, req, record.RequestHash, logData)
+			return
+		}
+		if err != nil {
+			handleError(ctx, err, w, req, logData)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		handler(recorder, req)
+
+		// server errors are not stored, so that the request can be retried
+		if recorder.statusCode >= http.StatusInternalServerError {
+			if err := api.idempotencyStore.DeleteIdempotencyRecord(ctx, key); err != nil {
+				log.Error(ctx, "failed to delete idempotency record", err, logData)
+			}
+			return
+		}
+
+		record.StatusCode = recorder.statusCode
+		record.Header = w.Header().Clone()
+		record.Body = recorder.body.Bytes()
+		record.ExpiresAt = Now().Add(api.idempotencyKeyTTL)
+
+		if err := api.idempotencyStore.UpdateIdempotencyRecord(ctx, record); err != nil {
+			log.Error(ctx, "failed to store idempotent response", err, logData)
+		}
+	}
+}
+
+// replay writes the stored response for a request that has already been made with the same Idempotency-Key
+func (api *API) replay(w http.ResponseWriter, req *http.Request, requestHash string, logData log.Data) {
+	ctx := req.Context()
+
+	record, err := api.idempotencyStore.GetIdempotencyRecord(ctx, req.Header.Get(idempotencyKeyHeader))
+	if err == collections.ErrIdempotencyRecordNotFound {
+		// the record expired between being added and read, so treat it as in progress and let the client retry
+		err = collections.ErrIdempotencyKeyInProgress
+	}
+	if err != nil {
+		handleError(ctx, err, w, req, logData)
+		return
+	}
+
+	if record.RequestHash != requestHash {
+		handleError(ctx, collections.ErrIdempotencyKeyReused, w, req, logData)
+		return
+	}
+
+	if !record.IsComplete() {
+		handleError(ctx, collections.ErrIdempotencyKeyInProgress, w, req, logData)
+		return
+	}
+
+	for name, values := range record.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(idempotentReplayedHeader, "true")
+	w.WriteHeader(record.StatusCode)
+	if _, err := w.Write(record.Body); err != nil {
+		log.Error(ctx, "failed to write replayed response", err, logData)
+		return
+	}
+
+	log.Info(ctx, "replayed idempotent response", logData)
+}
+
+// hashRequest identifies a request by its method, path and body
+func hashRequest(req *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(req.Method + " " + req.URL.Path + "\n"))
+	h.Write(body)
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// responseRecorder captures the status code and body written by a handler, while passing them through to the client
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	if !r.wroteHeader {
+		r.statusCode = statusCode
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
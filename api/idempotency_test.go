@@ -0,0 +1,213 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/api"
+	"github.com/ONSdigital/dp-collection-api/api/mock"
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/config"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const idempotencyKey = "c3a3e1e4-4b8f-4bfa-9a43-4c1ab1d9b6f2"
+
+func TestPostCollection_idempotencyKey(t *testing.T) {
+
+	newCollectionJson := `{
+		"name": "Coronavirus key indicators",
+		"publish_date": "2020-05-05T14:58:29.317Z"
+	}`
+	api.NewID = func() (string, error) {
+		return "12345", nil
+	}
+	api.Now = func() time.Time {
+		return now
+	}
+
+	postCollection := func(a *api.API, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "http://localhost:26000/collections", bytes.NewBufferString(body))
+		r.Header.Set("Idempotency-Key", idempotencyKey)
+		w := httptest.NewRecorder()
+		a.Router.ServeHTTP(w, r)
+		return w
+	}
+
+	Convey("Given a request to POST a collection with an Idempotency-Key", t, func() {
+
+		collectionStore := mockCollectionStore()
+		idempotencyStore := mockIdempotencyStore()
+		a := api.Setup(context.Background(), &config.Config{IdempotencyKeyTTL: time.Hour}, mux.NewRouter(), mockPaginator(), collectionStore, idempotencyStore)
+
+		Convey("When the request is sent to the API", func() {
+			w := postCollection(a, newCollectionJson)
+
+			Convey("Then the collection is created", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(len(collectionStore.AddCollectionCalls()), ShouldEqual, 1)
+			})
+
+			Convey("Then the response is stored against the key", func() {
+				So(len(idempotencyStore.UpdateIdempotencyRecordCalls()), ShouldEqual, 1)
+				record := idempotencyStore.UpdateIdempotencyRecordCalls()[0].Record
+				So(record.Key, ShouldEqual, idempotencyKey)
+				So(record.StatusCode, ShouldEqual, http.StatusCreated)
+				So(record.Body, ShouldResemble, w.Body.Bytes())
+				So(http.Header(record.Header).Get("ETag"), ShouldEqual, w.Header().Get("ETag"))
+				So(record.ExpiresAt, ShouldEqual, now.Add(time.Hour))
+			})
+
+			Convey("And the same request is retried", func() {
+				w2 := postCollection(a, newCollectionJson)
+
+				Convey("Then the original response is replayed", func() {
+					So(w2.Code, ShouldEqual, http.StatusCreated)
+					So(w2.Body.Bytes(), ShouldResemble, w.Body.Bytes())
+					So(w2.Header().Get("ETag"), ShouldEqual, w.Header().Get("ETag"))
+					So(w2.Header().Get("Idempotent-Replayed"), ShouldEqual, "true")
+				})
+
+				Convey("Then the collection is not created again", func() {
+					So(len(collectionStore.AddCollectionCalls()), ShouldEqual, 1)
+				})
+			})
+
+			Convey("And the key is reused with a different request body", func() {
+				w2 := postCollection(a, strings.Replace(newCollectionJson, "Coronavirus", "Economy", 1))
+
+				Convey("Then a 422 status is returned", func() {
+					So(w2.Code, ShouldEqual, http.StatusUnprocessableEntity)
+					So(errorCode(w2), ShouldEqual, models.ErrCodeIdempotencyKeyReused)
+				})
+
+				Convey("Then the collection is not created again", func() {
+					So(len(collectionStore.AddCollectionCalls()), ShouldEqual, 1)
+				})
+			})
+		})
+	})
+
+	Convey("Given a request with the same Idempotency-Key is still being processed", t, func() {
+
+		collectionStore := mockCollectionStore()
+		idempotencyStore := mockIdempotencyStore()
+		a := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), mockPaginator(), collectionStore, idempotencyStore)
+
+		r := httptest.NewRequest("POST", "http://localhost:26000/collections", bytes.NewBufferString(newCollectionJson))
+		r.Header.Set("Idempotency-Key", idempotencyKey)
+		collectionStore.AddCollectionFunc = func(ctx context.Context, collection *models.Collection) error {
+			// retry while the first request is in progress
+			w := postCollection(a, newCollectionJson)
+
+			Convey("Then a conflict status is returned for the retry", func() {
+				So(w.Code, ShouldEqual, http.StatusConflict)
+				So(errorCode(w), ShouldEqual, models.ErrCodeIdempotencyKeyInProgress)
+			})
+			return nil
+		}
+
+		Convey("When the request is sent to the API", func() {
+			w := httptest.NewRecorder()
+			a.Router.ServeHTTP(w, r)
+
+			Convey("Then the first request completes", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+			})
+		})
+	})
+
+	Convey("Given a request to POST a collection with an Idempotency-Key that results in a server error", t, func() {
+
+		collectionStore := mockCollectionStore()
+		collectionStore.AddCollectionFunc = func(ctx context.Context, collection *models.Collection) error {
+			return errors.New("db is broken")
+		}
+		idempotencyStore := mockIdempotencyStore()
+		a := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), mockPaginator(), collectionStore, idempotencyStore)
+
+		Convey("When the request is sent to the API", func() {
+			w := postCollection(a, newCollectionJson)
+
+			Convey("Then the error is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusInternalServerError)
+			})
+
+			Convey("Then the key is released so that the request can be retried", func() {
+				So(len(idempotencyStore.DeleteIdempotencyRecordCalls()), ShouldEqual, 1)
+				So(len(idempotencyStore.UpdateIdempotencyRecordCalls()), ShouldEqual, 0)
+			})
+		})
+	})
+
+	Convey("Given a request to POST a collection with an Idempotency-Key that is too long", t, func() {
+
+		collectionStore := mockCollectionStore()
+		idempotencyStore := mockIdempotencyStore()
+		a := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), mockPaginator(), collectionStore, idempotencyStore)
+
+		r := httptest.NewRequest("POST", "http://localhost:26000/collections", bytes.NewBufferString(newCollectionJson))
+		r.Header.Set("Idempotency-Key", strings.Repeat("a", 256))
+		w := httptest.NewRecorder()
+
+		Convey("When the request is sent to the API", func() {
+			a.Router.ServeHTTP(w, r)
+
+			Convey("Then a bad request status is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(errorCode(w), ShouldEqual, models.ErrCodeIdempotencyKeyTooLong)
+			})
+
+			Convey("Then the collection is not created", func() {
+				So(len(collectionStore.AddCollectionCalls()), ShouldEqual, 0)
+			})
+		})
+	})
+}
+
+// mockIdempotencyStore returns a mock that stores records in memory, in the same way as the real store
+func mockIdempotencyStore() *mock.IdempotencyStoreMock {
+	records := map[string]models.IdempotencyRecord{}
+
+	return &mock.IdempotencyStoreMock{
+		AddIdempotencyRecordFunc: func(ctx context.Context, record *models.IdempotencyRecord) error {
+			if _, ok := records[record.Key]; ok {
+				return collections.ErrIdempotencyKeyExists
+			}
+			records[record.Key] = *record
+			return nil
+		},
+		GetIdempotencyRecordFunc: func(ctx context.Context, key string) (*models.IdempotencyRecord, error) {
+			record, ok := records[key]
+			if !ok {
+				return nil, collections.ErrIdempotencyRecordNotFound
+			}
+			return &record, nil
+		},
+		UpdateIdempotencyRecordFunc: func(ctx context.Context, record *models.IdempotencyRecord) error {
+			records[record.Key] = *record
+			return nil
+		},
+		DeleteIdempotencyRecordFunc: func(ctx context.Context, key string) error {
+			delete(records, key)
+			return nil
+		},
+	}
+}
+
+func errorCode(w *httptest.ResponseRecorder) string {
+	errs := errorResponses(w)
+	if len(errs) == 0 {
+		return ""
+	}
+	return errs[0].Code
+}
@@ -9,6 +9,13 @@ import (
 
 //go:generate moq -out mock/paginator.go -pkg mock . Paginator
 //go:generate moq -out mock/collectionstore.go -pkg mock . CollectionStore
+//go:generate moq -out mock/idempotencystore.go -pkg mock . IdempotencyStore
+//go:generate moq -out mock/subscriptionstore.go -pkg mock . SubscriptionStore
+//go:generate moq -out mock/versionstore.go -pkg mock . VersionStore
+//go:generate moq -out mock/eventlistener.go -pkg mock . EventListener
+//go:generate moq -out mock/transactionallistener.go -pkg mock . TransactionalListener
+//go:generate moq -out mock/outbox.go -pkg mock . Outbox
+//go:generate moq -out mock/eventbroker.go -pkg mock . EventBroker
 
 // Paginator defines the required methods from the paginator package
 type Paginator interface {
@@ -23,4 +30,57 @@ type CollectionStore interface {
 	GetCollectionByID(ctx context.Context, id string, eTagSelector string) (*models.Collection, error)
 	GetCollectionByName(ctx context.Context, name string) (*models.Collection, error)
 	GetCollectionEvents(ctx context.Context, queryParams collections.EventsQueryParams) ([]models.Event, int, error)
+	AddEvent(ctx context.Context, event *models.Event) error
+}
+
+// IdempotencyStore defines the required methods from the data store of idempotency records
+type IdempotencyStore interface {
+	AddIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error
+	GetIdempotencyRecord(ctx context.Context, key string) (*models.IdempotencyRecord, error)
+	UpdateIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error
+	DeleteIdempotencyRecord(ctx context.Context, key string) error
+}
+
+// SubscriptionStore defines the required methods from the data store of webhook subscriptions and their deliveries
+type SubscriptionStore interface {
+	GetSubscriptions(ctx context.Context, offset, limit int) (subscriptions []models.Subscription, totalCount int, err error)
+	GetSubscriptionByID(ctx context.Context, id string) (*models.Subscription, error)
+	AddSubscription(ctx context.Context, subscription *models.Subscription) error
+	DeleteSubscription(ctx context.Context, id string) error
+	GetDeliveries(ctx context.Context, queryParams collections.DeliveriesQueryParams) (deliveries []models.Delivery, totalCount int, err error)
+}
+
+// VersionStore defines the required methods from the data store of collection version snapshots. A snapshot is never
+// changed once added, and adding a second snapshot for the same version of a collection returns
+// collections.ErrVersionAlreadyExists.
+type VersionStore interface {
+	AddVersion(ctx context.Context, version *models.Version) error
+	GetVersions(ctx context.Context, queryParams collections.VersionsQueryParams) (versions []models.Version, totalCount int, err error)
+	GetVersion(ctx context.Context, collectionID string, version int) (*models.Version, error)
+}
+
+// EventListener is notified of each lifecycle event recorded by the API, with the collection as it was written
+type EventListener interface {
+	HandleCollectionEvent(ctx context.Context, event *models.Event, collection *models.Collection) error
+}
+
+// TransactionalListener is notified of each lifecycle event in the same transaction as the write, if there is an
+// outbox, so that what it stores is committed with the write, and a failure aborts the write. It is told once the
+// write has been committed.
+type TransactionalListener interface {
+	EventListener
+	Committed(ctx context.Context)
+}
+
+// Outbox stores a message for each lifecycle event in the same transaction as the write that produced it, so that the
+// message is stored if and only if the write is committed
+type Outbox interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	AddOutboxMessage(ctx context.Context, message *models.OutboxMessage) error
+}
+
+// EventBroker passes each event recorded for a collection to the subscribers to that collection. The channel of
+// events is closed when the subscriber is dropped, for example because it has not kept up with its events.
+type EventBroker interface {
+	Subscribe(collectionID string) (events <-chan models.Event, unsubscribe func())
 }
@@ -17,38 +17,44 @@ var _ api.CollectionStore = &CollectionStoreMock{}
 
 // CollectionStoreMock is a mock implementation of api.CollectionStore.
 //
-//     func TestSomethingThatUsesCollectionStore(t *testing.T) {
+//	func TestSomethingThatUsesCollectionStore(t *testing.T) {
 //
-//         // make and configure a mocked api.CollectionStore
-//         mockedCollectionStore := &CollectionStoreMock{
-//             AddCollectionFunc: func(ctx context.Context, collection *models.Collection) error {
-// 	               panic("mock out the AddCollection method")
-//             },
-//             GetCollectionByIDFunc: func(ctx context.Context, id string, eTagSelector string) (*models.Collection, error) {
-// 	               panic("mock out the GetCollectionByID method")
-//             },
-//             GetCollectionByNameFunc: func(ctx context.Context, name string) (*models.Collection, error) {
-// 	               panic("mock out the GetCollectionByName method")
-//             },
-//             GetCollectionEventsFunc: func(ctx context.Context, queryParams collections.EventsQueryParams) ([]models.Event, int, error) {
-// 	               panic("mock out the GetCollectionEvents method")
-//             },
-//             GetCollectionsFunc: func(ctx context.Context, queryParams collections.QueryParams) ([]models.Collection, int, error) {
-// 	               panic("mock out the GetCollections method")
-//             },
-//             ReplaceCollectionFunc: func(ctx context.Context, collection *models.Collection, eTagSelector string) error {
-// 	               panic("mock out the ReplaceCollection method")
-//             },
-//         }
+//		// make and configure a mocked api.CollectionStore
+//		mockedCollectionStore := &CollectionStoreMock{
+//			AddCollectionFunc: func(ctx context.Context, collection *models.Collection) error {
+//				panic("mock out the AddCollection method")
+//			},
+//			AddEventFunc: func(ctx context.Context, event *models.Event) error {
+//				panic("mock out the AddEvent method")
+//			},
+//			GetCollectionByIDFunc: func(ctx context.Context, id string, eTagSelector string) (*models.Collection, error) {
+//				panic("mock out the GetCollectionByID method")
+//			},
+//			GetCollectionByNameFunc: func(ctx context.Context, name string) (*models.Collection, error) {
+//				panic("mock out the GetCollectionByName method")
+//			},
+//			GetCollectionEventsFunc: func(ctx context.Context, queryParams collections.EventsQueryParams) ([]models.Event, int, error) {
+//				panic("mock out the GetCollectionEvents method")
+//			},
+//			GetCollectionsFunc: func(ctx context.Context, queryParams collections.QueryParams) ([]models.Collection, int, error) {
+//				panic("mock out the GetCollections method")
+//			},
+//			ReplaceCollectionFunc: func(ctx context.Context, collection *models.Collection, eTagSelector string) error {
+//				panic("mock out the ReplaceCollection method")
+//			},
+//		}
 //
-//         // use mockedCollectionStore in code that requires api.CollectionStore
-//         // and then make assertions.
+//		// use mockedCollectionStore in code that requires api.CollectionStore
+//		// and then make assertions.
 //
-//     }
+//	}
 type CollectionStoreMock struct {
 	// AddCollectionFunc mocks the AddCollection method.
 	AddCollectionFunc func(ctx context.Context, collection *models.Collection) error
 
+	// AddEventFunc mocks the AddEvent method.
+	AddEventFunc func(ctx context.Context, event *models.Event) error
+
 	// GetCollectionByIDFunc mocks the GetCollectionByID method.
 	GetCollectionByIDFunc func(ctx context.Context, id string, eTagSelector string) (*models.Collection, error)
 
@@ -73,6 +79,13 @@ type CollectionStoreMock struct {
 			// Collection is the collection argument value.
 			Collection *models.Collection
 		}
+		// AddEvent holds details about calls to the AddEvent method.
+		AddEvent []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Event is the event argument value.
+			Event *models.Event
+		}
 		// GetCollectionByID holds details about calls to the GetCollectionByID method.
 		GetCollectionByID []struct {
 			// Ctx is the ctx argument value.
@@ -114,6 +127,7 @@ type CollectionStoreMock struct {
 		}
 	}
 	lockAddCollection       sync.RWMutex
+	lockAddEvent            sync.RWMutex
 	lockGetCollectionByID   sync.RWMutex
 	lockGetCollectionByName sync.RWMutex
 	lockGetCollectionEvents sync.RWMutex
@@ -141,7 +155,8 @@ func (mock *CollectionStoreMock) AddCollection(ctx context.Context, collection *
 
 // AddCollectionCalls gets all the calls that were made to AddCollection.
 // Check the length with:
-//     len(mockedCollectionStore.AddCollectionCalls())
+//
+//	len(mockedCollectionStore.AddCollectionCalls())
 func (mock *CollectionStoreMock) AddCollectionCalls() []struct {
 	Ctx        context.Context
 	Collection *models.Collection
@@ -156,6 +171,42 @@ func (mock *CollectionStoreMock) AddCollectionCalls() []struct {
 	return calls
 }
 
+// AddEvent calls AddEventFunc.
+func (mock *CollectionStoreMock) AddEvent(ctx context.Context, event *models.Event) error {
+	if mock.AddEventFunc == nil {
+		panic("CollectionStoreMock.AddEventFunc: method is nil but CollectionStore.AddEvent was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Event *models.Event
+	}{
+		Ctx:   ctx,
+		Event: event,
+	}
+	mock.lockAddEvent.Lock()
+	mock.calls.AddEvent = append(mock.calls.AddEvent, callInfo)
+	mock.lockAddEvent.Unlock()
+	return mock.AddEventFunc(ctx, event)
+}
+
+// AddEventCalls gets all the calls that were made to AddEvent.
+// Check the length with:
+//
+//	len(mockedCollectionStore.AddEventCalls())
+func (mock *CollectionStoreMock) AddEventCalls() []struct {
+	Ctx   context.Context
+	Event *models.Event
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Event *models.Event
+	}
+	mock.lockAddEvent.RLock()
+	calls = mock.calls.AddEvent
+	mock.lockAddEvent.RUnlock()
+	return calls
+}
+
 // GetCollectionByID calls GetCollectionByIDFunc.
 func (mock *CollectionStoreMock) GetCollectionByID(ctx context.Context, id string, eTagSelector string) (*models.Collection, error) {
 	if mock.GetCollectionByIDFunc == nil {
@@ -178,7 +229,8 @@ func (mock *CollectionStoreMock) GetCollectionByID(ctx context.Context, id strin
 
 // GetCollectionByIDCalls gets all the calls that were made to GetCollectionByID.
 // Check the length with:
-//     len(mockedCollectionStore.GetCollectionByIDCalls())
+//
+//	len(mockedCollectionStore.GetCollectionByIDCalls())
 func (mock *CollectionStoreMock) GetCollectionByIDCalls() []struct {
 	Ctx          context.Context
 	ID           string
@@ -215,7 +267,8 @@ func (mock *CollectionStoreMock) GetCollectionByName(ctx context.Context, name s
 
 // GetCollectionByNameCalls gets all the calls that were made to GetCollectionByName.
 // Check the length with:
-//     len(mockedCollectionStore.GetCollectionByNameCalls())
+//
+//	len(mockedCollectionStore.GetCollectionByNameCalls())
 func (mock *CollectionStoreMock) GetCollectionByNameCalls() []struct {
 	Ctx  context.Context
 	Name string
@@ -250,7 +303,8 @@ func (mock *CollectionStoreMock) GetCollectionEvents(ctx context.Context, queryP
 
 // GetCollectionEventsCalls gets all the calls that were made to GetCollectionEvents.
 // Check the length with:
-//     len(mockedCollectionStore.GetCollectionEventsCalls())
+//
+//	len(mockedCollectionStore.GetCollectionEventsCalls())
 func (mock *CollectionStoreMock) GetCollectionEventsCalls() []struct {
 	Ctx         context.Context
 	QueryParams collections.EventsQueryParams
@@ -285,7 +339,8 @@ func (mock *CollectionStoreMock) GetCollections(ctx context.Context, queryParams
 
 // GetCollectionsCalls gets all the calls that were made to GetCollections.
 // Check the length with:
-//     len(mockedCollectionStore.GetCollectionsCalls())
+//
+//	len(mockedCollectionStore.GetCollectionsCalls())
 func (mock *CollectionStoreMock) GetCollectionsCalls() []struct {
 	Ctx         context.Context
 	QueryParams collections.QueryParams
@@ -322,7 +377,8 @@ func (mock *CollectionStoreMock) ReplaceCollection(ctx context.Context, collecti
 
 // ReplaceCollectionCalls gets all the calls that were made to ReplaceCollection.
 // Check the length with:
-//     len(mockedCollectionStore.ReplaceCollectionCalls())
+//
+//	len(mockedCollectionStore.ReplaceCollectionCalls())
 func (mock *CollectionStoreMock) ReplaceCollectionCalls() []struct {
 	Ctx          context.Context
 	Collection   *models.Collection
@@ -0,0 +1,76 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"github.com/ONSdigital/dp-collection-api/api"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"sync"
+)
+
+// Ensure, that EventBrokerMock does implement api.EventBroker.
+// If this is not the case, regenerate this file with moq.
+var _ api.EventBroker = &EventBrokerMock{}
+
+// EventBrokerMock is a mock implementation of api.EventBroker.
+//
+//	func TestSomethingThatUsesEventBroker(t *testing.T) {
+//
+//		// make and configure a mocked api.EventBroker
+//		mockedEventBroker := &EventBrokerMock{
+//			SubscribeFunc: func(collectionID string) (<-chan models.Event, func()) {
+//				panic("mock out the Subscribe method")
+//			},
+//		}
+//
+//		// use mockedEventBroker in code that requires api.EventBroker
+//		// and then make assertions.
+//
+//	}
+type EventBrokerMock struct {
+	// SubscribeFunc mocks the Subscribe method.
+	SubscribeFunc func(collectionID string) (<-chan models.Event, func())
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Subscribe holds details about calls to the Subscribe method.
+		Subscribe []struct {
+			// CollectionID is the collectionID argument value.
+			CollectionID string
+		}
+	}
+	lockSubscribe sync.RWMutex
+}
+
+// Subscribe calls SubscribeFunc.
+func (mock *EventBrokerMock) Subscribe(collectionID string) (<-chan models.Event, func()) {
+	if mock.SubscribeFunc == nil {
+		panic("EventBrokerMock.SubscribeFunc: method is nil but EventBroker.Subscribe was just called")
+	}
+	callInfo := struct {
+		CollectionID string
+	}{
+		CollectionID: collectionID,
+	}
+	mock.lockSubscribe.Lock()
+	mock.calls.Subscribe = append(mock.calls.Subscribe, callInfo)
+	mock.lockSubscribe.Unlock()
+	return mock.SubscribeFunc(collectionID)
+}
+
+// SubscribeCalls gets all the calls that were made to Subscribe.
+// Check the length with:
+//
+//	len(mockedEventBroker.SubscribeCalls())
+func (mock *EventBrokerMock) SubscribeCalls() []struct {
+	CollectionID string
+} {
+	var calls []struct {
+		CollectionID string
+	}
+	mock.lockSubscribe.RLock()
+	calls = mock.calls.Subscribe
+	mock.lockSubscribe.RUnlock()
+	return calls
+}
@@ -0,0 +1,89 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/ONSdigital/dp-collection-api/api"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"sync"
+)
+
+// Ensure, that EventListenerMock does implement api.EventListener.
+// If this is not the case, regenerate this file with moq.
+var _ api.EventListener = &EventListenerMock{}
+
+// EventListenerMock is a mock implementation of api.EventListener.
+//
+//	func TestSomethingThatUsesEventListener(t *testing.T) {
+//
+//		// make and configure a mocked api.EventListener
+//		mockedEventListener := &EventListenerMock{
+//			HandleCollectionEventFunc: func(ctx context.Context, event *models.Event, collection *models.Collection) error {
+//				panic("mock out the HandleCollectionEvent method")
+//			},
+//		}
+//
+//		// use mockedEventListener in code that requires api.EventListener
+//		// and then make assertions.
+//
+//	}
+type EventListenerMock struct {
+	// HandleCollectionEventFunc mocks the HandleCollectionEvent method.
+	HandleCollectionEventFunc func(ctx context.Context, event *models.Event, collection *models.Collection) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// HandleCollectionEvent holds details about calls to the HandleCollectionEvent method.
+		HandleCollectionEvent []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Event is the event argument value.
+			Event *models.Event
+			// Collection is the collection argument value.
+			Collection *models.Collection
+		}
+	}
+	lockHandleCollectionEvent sync.RWMutex
+}
+
+// HandleCollectionEvent calls HandleCollectionEventFunc.
+func (mock *EventListenerMock) HandleCollectionEvent(ctx context.Context, event *models.Event, collection *models.Collection) error {
+	if mock.HandleCollectionEventFunc == nil {
+		panic("EventListenerMock.HandleCollectionEventFunc: method is nil but EventListener.HandleCollectionEvent was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Event      *models.Event
+		Collection *models.Collection
+	}{
+		Ctx:        ctx,
+		Event:      event,
+		Collection: collection,
+	}
+	mock.lockHandleCollectionEvent.Lock()
+	mock.calls.HandleCollectionEvent = append(mock.calls.HandleCollectionEvent, callInfo)
+	mock.lockHandleCollectionEvent.Unlock()
+	return mock.HandleCollectionEventFunc(ctx, event, collection)
+}
+
+// HandleCollectionEventCalls gets all the calls that were made to HandleCollectionEvent.
+// Check the length with:
+//
+//	len(mockedEventListener.HandleCollectionEventCalls())
+func (mock *EventListenerMock) HandleCollectionEventCalls() []struct {
+	Ctx        context.Context
+	Event      *models.Event
+	Collection *models.Collection
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Event      *models.Event
+		Collection *models.Collection
+	}
+	mock.lockHandleCollectionEvent.RLock()
+	calls = mock.calls.HandleCollectionEvent
+	mock.lockHandleCollectionEvent.RUnlock()
+	return calls
+}
@@ -0,0 +1,233 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/ONSdigital/dp-collection-api/api"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"sync"
+)
+
+// Ensure, that IdempotencyStoreMock does implement api.IdempotencyStore.
+// If this is not the case, regenerate this file with moq.
+var _ api.IdempotencyStore = &IdempotencyStoreMock{}
+
+// IdempotencyStoreMock is a mock implementation of api.IdempotencyStore.
+//
+//	func TestSomethingThatUsesIdempotencyStore(t *testing.T) {
+//
+//		// make and configure a mocked api.IdempotencyStore
+//		mockedIdempotencyStore := &IdempotencyStoreMock{
+//			AddIdempotencyRecordFunc: func(ctx context.Context, record *models.IdempotencyRecord) error {
+//				panic("mock out the AddIdempotencyRecord method")
+//			},
+//			DeleteIdempotencyRecordFunc: func(ctx context.Context, key string) error {
+//				panic("mock out the DeleteIdempotencyRecord method")
+//			},
+//			GetIdempotencyRecordFunc: func(ctx context.Context, key string) (*models.IdempotencyRecord, error) {
+//				panic("mock out the GetIdempotencyRecord method")
+//			},
+//			UpdateIdempotencyRecordFunc: func(ctx context.Context, record *models.IdempotencyRecord) error {
+//				panic("mock out the UpdateIdempotencyRecord method")
+//			},
+//		}
+//
+//		// use mockedIdempotencyStore in code that requires api.IdempotencyStore
+//		// and then make assertions.
+//
+//	}
+type IdempotencyStoreMock struct {
+	// AddIdempotencyRecordFunc mocks the AddIdempotencyRecord method.
+	AddIdempotencyRecordFunc func(ctx context.Context, record *models.IdempotencyRecord) error
+
+	// DeleteIdempotencyRecordFunc mocks the DeleteIdempotencyRecord method.
+	DeleteIdempotencyRecordFunc func(ctx context.Context, key string) error
+
+	// GetIdempotencyRecordFunc mocks the GetIdempotencyRecord method.
+	GetIdempotencyRecordFunc func(ctx context.Context, key string) (*models.IdempotencyRecord, error)
+
+	// UpdateIdempotencyRecordFunc mocks the UpdateIdempotencyRecord method.
+	UpdateIdempotencyRecordFunc func(ctx context.Context, record *models.IdempotencyRecord) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// AddIdempotencyRecord holds details about calls to the AddIdempotencyRecord method.
+		AddIdempotencyRecord []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Record is the record argument value.
+			Record *models.IdempotencyRecord
+		}
+		// DeleteIdempotencyRecord holds details about calls to the DeleteIdempotencyRecord method.
+		DeleteIdempotencyRecord []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Key is the key argument value.
+			Key string
+		}
+		// GetIdempotencyRecord holds details about calls to the GetIdempotencyRecord method.
+		GetIdempotencyRecord []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Key is the key argument value.
+			Key string
+		}
+		// UpdateIdempotencyRecord holds details about calls to the UpdateIdempotencyRecord method.
+		UpdateIdempotencyRecord []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Record is the record argument value.
+			Record *models.IdempotencyRecord
+		}
+	}
+	lockAddIdempotencyRecord    sync.RWMutex
+	lockDeleteIdempotencyRecord sync.RWMutex
+	lockGetIdempotencyRecord    sync.RWMutex
+	lockUpdateIdempotencyRecord sync.RWMutex
+}
+
+// AddIdempotencyRecord calls AddIdempotencyRecordFunc.
+func (mock *IdempotencyStoreMock) AddIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	if mock.AddIdempotencyRecordFunc == nil {
+		panic("IdempotencyStoreMock.AddIdempotencyRecordFunc: method is nil but IdempotencyStore.AddIdempotencyRecord was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Record *models.IdempotencyRecord
+	}{
+		Ctx:    ctx,
+		Record: record,
+	}
+	mock.lockAddIdempotencyRecord.Lock()
+	mock.calls.AddIdempotencyRecord = append(mock.calls.AddIdempotencyRecord, callInfo)
+	mock.lockAddIdempotencyRecord.Unlock()
+	return mock.AddIdempotencyRecordFunc(ctx, record)
+}
+
+// AddIdempotencyRecordCalls gets all the calls that were made to AddIdempotencyRecord.
+// Check the length with:
+//
+//	len(mockedIdempotencyStore.AddIdempotencyRecordCalls())
+func (mock *IdempotencyStoreMock) AddIdempotencyRecordCalls() []struct {
+	Ctx    context.Context
+	Record *models.IdempotencyRecord
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Record *models.IdempotencyRecord
+	}
+	mock.lockAddIdempotencyRecord.RLock()
+	calls = mock.calls.AddIdempotencyRecord
+	mock.lockAddIdempotencyRecord.RUnlock()
+	return calls
+}
+
+// DeleteIdempotencyRecord calls DeleteIdempotencyRecordFunc.
+func (mock *IdempotencyStoreMock) DeleteIdempotencyRecord(ctx context.Context, key string) error {
+	if mock.DeleteIdempotencyRecordFunc == nil {
+		panic("IdempotencyStoreMock.DeleteIdempotencyRecordFunc: method is nil but IdempotencyStore.DeleteIdempotencyRecord was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Key string
+	}{
+		Ctx: ctx,
+		Key: key,
+	}
+	mock.lockDeleteIdempotencyRecord.Lock()
+	mock.calls.DeleteIdempotencyRecord = append(mock.calls.DeleteIdempotencyRecord, callInfo)
+	mock.lockDeleteIdempotencyRecord.Unlock()
+	return mock.DeleteIdempotencyRecordFunc(ctx, key)
+}
+
+// DeleteIdempotencyRecordCalls gets all the calls that were made to DeleteIdempotencyRecord.
+// Check the length with:
+//
+//	len(mockedIdempotencyStore.DeleteIdempotencyRecordCalls())
+func (mock *IdempotencyStoreMock) DeleteIdempotencyRecordCalls() []struct {
+	Ctx context.Context
+	Key string
+} {
+	var calls []struct {
+		Ctx context.Context
+		Key string
+	}
+	mock.lockDeleteIdempotencyRecord.RLock()
+	calls = mock.calls.DeleteIdempotencyRecord
+	mock.lockDeleteIdempotencyRecord.RUnlock()
+	return calls
+}
+
+// GetIdempotencyRecord calls GetIdempotencyRecordFunc.
+func (mock *IdempotencyStoreMock) GetIdempotencyRecord(ctx context.Context, key string) (*models.IdempotencyRecord, error) {
+	if mock.GetIdempotencyRecordFunc == nil {
+		panic("IdempotencyStoreMock.GetIdempotencyRecordFunc: method is nil but IdempotencyStore.GetIdempotencyRecord was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Key string
+	}{
+		Ctx: ctx,
+		Key: key,
+	}
+	mock.lockGetIdempotencyRecord.Lock()
+	mock.calls.GetIdempotencyRecord = append(mock.calls.GetIdempotencyRecord, callInfo)
+	mock.lockGetIdempotencyRecord.Unlock()
+	return mock.GetIdempotencyRecordFunc(ctx, key)
+}
+
+// GetIdempotencyRecordCalls gets all the calls that were made to GetIdempotencyRecord.
+// Check the length with:
+//
+//	len(mockedIdempotencyStore.GetIdempotencyRecordCalls())
+func (mock *IdempotencyStoreMock) GetIdempotencyRecordCalls() []struct {
+	Ctx context.Context
+	Key string
+} {
+	var calls []struct {
+		Ctx context.Context
+		Key string
+	}
+	mock.lockGetIdempotencyRecord.RLock()
+	calls = mock.calls.GetIdempotencyRecord
+	mock.lockGetIdempotencyRecord.RUnlock()
+	return calls
+}
+
+// UpdateIdempotencyRecord calls UpdateIdempotencyRecordFunc.
+func (mock *IdempotencyStoreMock) UpdateIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	if mock.UpdateIdempotencyRecordFunc == nil {
+		panic("IdempotencyStoreMock.UpdateIdempotencyRecordFunc: method is nil but IdempotencyStore.UpdateIdempotencyRecord was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Record *models.IdempotencyRecord
+	}{
+		Ctx:    ctx,
+		Record: record,
+	}
+	mock.lockUpdateIdempotencyRecord.Lock()
+	mock.calls.UpdateIdempotencyRecord = append(mock.calls.UpdateIdempotencyRecord, callInfo)
+	mock.lockUpdateIdempotencyRecord.Unlock()
+	return mock.UpdateIdempotencyRecordFunc(ctx, record)
+}
+
+// UpdateIdempotencyRecordCalls gets all the calls that were made to UpdateIdempotencyRecord.
+// Check the length with:
+//
+//	len(mockedIdempotencyStore.UpdateIdempotencyRecordCalls())
+func (mock *IdempotencyStoreMock) UpdateIdempotencyRecordCalls() []struct {
+	Ctx    context.Context
+	Record *models.IdempotencyRecord
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Record *models.IdempotencyRecord
+	}
+	mock.lockUpdateIdempotencyRecord.RLock()
+	calls = mock.calls.UpdateIdempotencyRecord
+	mock.lockUpdateIdempotencyRecord.RUnlock()
+	return calls
+}
@@ -0,0 +1,290 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/ONSdigital/dp-collection-api/api"
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"sync"
+)
+
+// Ensure, that SubscriptionStoreMock does implement api.SubscriptionStore.
+// If this is not the case, regenerate this file with moq.
+var _ api.SubscriptionStore = &SubscriptionStoreMock{}
+
+// SubscriptionStoreMock is a mock implementation of api.SubscriptionStore.
+//
+//	func TestSomethingThatUsesSubscriptionStore(t *testing.T) {
+//
+//		// make and configure a mocked api.SubscriptionStore
+//		mockedSubscriptionStore := &SubscriptionStoreMock{
+//			AddSubscriptionFunc: func(ctx context.Context, subscription *models.Subscription) error {
+//				panic("mock out the AddSubscription method")
+//			},
+//			DeleteSubscriptionFunc: func(ctx context.Context, id string) error {
+//				panic("mock out the DeleteSubscription method")
+//			},
+//			GetDeliveriesFunc: func(ctx context.Context, queryParams collections.DeliveriesQueryParams) ([]models.Delivery, int, error) {
+//				panic("mock out the GetDeliveries method")
+//			},
+//			GetSubscriptionByIDFunc: func(ctx context.Context, id string) (*models.Subscription, error) {
+//				panic("mock out the GetSubscriptionByID method")
+//			},
+//			GetSubscriptionsFunc: func(ctx context.Context, offset int, limit int) ([]models.Subscription, int, error) {
+//				panic("mock out the GetSubscriptions method")
+//			},
+//		}
+//
+//		// use mockedSubscriptionStore in code that requires api.SubscriptionStore
+//		// and then make assertions.
+//
+//	}
+type SubscriptionStoreMock struct {
+	// AddSubscriptionFunc mocks the AddSubscription method.
+	AddSubscriptionFunc func(ctx context.Context, subscription *models.Subscription) error
+
+	// DeleteSubscriptionFunc mocks the DeleteSubscription method.
+	DeleteSubscriptionFunc func(ctx context.Context, id string) error
+
+	// GetDeliveriesFunc mocks the GetDeliveries method.
+	GetDeliveriesFunc func(ctx context.Context, queryParams collections.DeliveriesQueryParams) ([]models.Delivery, int, error)
+
+	// GetSubscriptionByIDFunc mocks the GetSubscriptionByID method.
+	GetSubscriptionByIDFunc func(ctx context.Context, id string) (*models.Subscription, error)
+
+	// GetSubscriptionsFunc mocks the GetSubscriptions method.
+	GetSubscriptionsFunc func(ctx context.Context, offset int, limit int) ([]models.Subscription, int, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// AddSubscription holds details about calls to the AddSubscription method.
+		AddSubscription []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Subscription is the subscription argument value.
+			Subscription *models.Subscription
+		}
+		// DeleteSubscription holds details about calls to the DeleteSubscription method.
+		DeleteSubscription []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// GetDeliveries holds details about calls to the GetDeliveries method.
+		GetDeliveries []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// QueryParams is the queryParams argument value.
+			QueryParams collections.DeliveriesQueryParams
+		}
+		// GetSubscriptionByID holds details about calls to the GetSubscriptionByID method.
+		GetSubscriptionByID []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// GetSubscriptions holds details about calls to the GetSubscriptions method.
+		GetSubscriptions []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Offset is the offset argument value.
+			Offset int
+			// Limit is the limit argument value.
+			Limit int
+		}
+	}
+	lockAddSubscription     sync.RWMutex
+	lockDeleteSubscription  sync.RWMutex
+	lockGetDeliveries       sync.RWMutex
+	lockGetSubscriptionByID sync.RWMutex
+	lockGetSubscriptions    sync.RWMutex
+}
+
+// AddSubscription calls AddSubscriptionFunc.
+func (mock *SubscriptionStoreMock) AddSubscription(ctx context.Context, subscription *models.Subscription) error {
+	if mock.AddSubscriptionFunc == nil {
+		panic("SubscriptionStoreMock.AddSubscriptionFunc: method is nil but SubscriptionStore.AddSubscription was just called")
+	}
+	callInfo := struct {
+		Ctx          context.Context
+		Subscription *models.Subscription
+	}{
+		Ctx:          ctx,
+		Subscription: subscription,
+	}
+	mock.lockAddSubscription.Lock()
+	mock.calls.AddSubscription = append(mock.calls.AddSubscription, callInfo)
+	mock.lockAddSubscription.Unlock()
+	return mock.AddSubscriptionFunc(ctx, subscription)
+}
+
+// AddSubscriptionCalls gets all the calls that were made to AddSubscription.
+// Check the length with:
+//
+//	len(mockedSubscriptionStore.AddSubscriptionCalls())
+func (mock *SubscriptionStoreMock) AddSubscriptionCalls() []struct {
+	Ctx          context.Context
+	Subscription *models.Subscription
+} {
+	var calls []struct {
+		Ctx          context.Context
+		Subscription *models.Subscription
+	}
+	mock.lockAddSubscription.RLock()
+	calls = mock.calls.AddSubscription
+	mock.lockAddSubscription.RUnlock()
+	return calls
+}
+
+// DeleteSubscription calls DeleteSubscriptionFunc.
+func (mock *SubscriptionStoreMock) DeleteSubscription(ctx context.Context, id string) error {
+	if mock.DeleteSubscriptionFunc == nil {
+		panic("SubscriptionStoreMock.DeleteSubscriptionFunc: method is nil but SubscriptionStore.DeleteSubscription was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockDeleteSubscription.Lock()
+	mock.calls.DeleteSubscription = append(mock.calls.DeleteSubscription, callInfo)
+	mock.lockDeleteSubscription.Unlock()
+	return mock.DeleteSubscriptionFunc(ctx, id)
+}
+
+// DeleteSubscriptionCalls gets all the calls that were made to DeleteSubscription.
+// Check the length with:
+//
+//	len(mockedSubscriptionStore.DeleteSubscriptionCalls())
+func (mock *SubscriptionStoreMock) DeleteSubscriptionCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockDeleteSubscription.RLock()
+	calls = mock.calls.DeleteSubscription
+	mock.lockDeleteSubscription.RUnlock()
+	return calls
+}
+
+// GetDeliveries calls GetDeliveriesFunc.
+func (mock *SubscriptionStoreMock) GetDeliveries(ctx context.Context, queryParams collections.DeliveriesQueryParams) ([]models.Delivery, int, error) {
+	if mock.GetDeliveriesFunc == nil {
+		panic("SubscriptionStoreMock.GetDeliveriesFunc: method is nil but SubscriptionStore.GetDeliveries was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		QueryParams collections.DeliveriesQueryParams
+	}{
+		Ctx:         ctx,
+		QueryParams: queryParams,
+	}
+	mock.lockGetDeliveries.Lock()
+	mock.calls.GetDeliveries = append(mock.calls.GetDeliveries, callInfo)
+	mock.lockGetDeliveries.Unlock()
+	return mock.GetDeliveriesFunc(ctx, queryParams)
+}
+
+// GetDeliveriesCalls gets all the calls that were made to GetDeliveries.
+// Check the length with:
+//
+//	len(mockedSubscriptionStore.GetDeliveriesCalls())
+func (mock *SubscriptionStoreMock) GetDeliveriesCalls() []struct {
+	Ctx         context.Context
+	QueryParams collections.DeliveriesQueryParams
+} {
+	var calls []struct {
+		Ctx         context.Context
+		QueryParams collections.DeliveriesQueryParams
+	}
+	mock.lockGetDeliveries.RLock()
+	calls = mock.calls.GetDeliveries
+	mock.lockGetDeliveries.RUnlock()
+	return calls
+}
+
+// GetSubscriptionByID calls GetSubscriptionByIDFunc.
+func (mock *SubscriptionStoreMock) GetSubscriptionByID(ctx context.Context, id string) (*models.Subscription, error) {
+	if mock.GetSubscriptionByIDFunc == nil {
+		panic("SubscriptionStoreMock.GetSubscriptionByIDFunc: method is nil but SubscriptionStore.GetSubscriptionByID was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockGetSubscriptionByID.Lock()
+	mock.calls.GetSubscriptionByID = append(mock.calls.GetSubscriptionByID, callInfo)
+	mock.lockGetSubscriptionByID.Unlock()
+	return mock.GetSubscriptionByIDFunc(ctx, id)
+}
+
+// GetSubscriptionByIDCalls gets all the calls that were made to GetSubscriptionByID.
+// Check the length with:
+//
+//	len(mockedSubscriptionStore.GetSubscriptionByIDCalls())
+func (mock *SubscriptionStoreMock) GetSubscriptionByIDCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockGetSubscriptionByID.RLock()
+	calls = mock.calls.GetSubscriptionByID
+	mock.lockGetSubscriptionByID.RUnlock()
+	return calls
+}
+
+// GetSubscriptions calls GetSubscriptionsFunc.
+func (mock *SubscriptionStoreMock) GetSubscriptions(ctx context.Context, offset int, limit int) ([]models.Subscription, int, error) {
+	if mock.GetSubscriptionsFunc == nil {
+		panic("SubscriptionStoreMock.GetSubscriptionsFunc: method is nil but SubscriptionStore.GetSubscriptions was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Offset int
+		Limit  int
+	}{
+		Ctx:    ctx,
+		Offset: offset,
+		Limit:  limit,
+	}
+	mock.lockGetSubscriptions.Lock()
+	mock.calls.GetSubscriptions = append(mock.calls.GetSubscriptions, callInfo)
+	mock.lockGetSubscriptions.Unlock()
+	return mock.GetSubscriptionsFunc(ctx, offset, limit)
+}
+
+// GetSubscriptionsCalls gets all the calls that were made to GetSubscriptions.
+// Check the length with:
+//
+//	len(mockedSubscriptionStore.GetSubscriptionsCalls())
+func (mock *SubscriptionStoreMock) GetSubscriptionsCalls() []struct {
+	Ctx    context.Context
+	Offset int
+	Limit  int
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Offset int
+		Limit  int
+	}
+	mock.lockGetSubscriptions.RLock()
+	calls = mock.calls.GetSubscriptions
+	mock.lockGetSubscriptions.RUnlock()
+	return calls
+}
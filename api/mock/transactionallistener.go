@@ -0,0 +1,133 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/ONSdigital/dp-collection-api/api"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"sync"
+)
+
+// Ensure, that TransactionalListenerMock does implement api.TransactionalListener.
+// If this is not the case, regenerate this file with moq.
+var _ api.TransactionalListener = &TransactionalListenerMock{}
+
+// TransactionalListenerMock is a mock implementation of api.TransactionalListener.
+//
+//	func TestSomethingThatUsesTransactionalListener(t *testing.T) {
+//
+//		// make and configure a mocked api.TransactionalListener
+//		mockedTransactionalListener := &TransactionalListenerMock{
+//			CommittedFunc: func(ctx context.Context)  {
+//				panic("mock out the Committed method")
+//			},
+//			HandleCollectionEventFunc: func(ctx context.Context, event *models.Event, collection *models.Collection) error {
+//				panic("mock out the HandleCollectionEvent method")
+//			},
+//		}
+//
+//		// use mockedTransactionalListener in code that requires api.TransactionalListener
+//		// and then make assertions.
+//
+//	}
+type TransactionalListenerMock struct {
+	// CommittedFunc mocks the Committed method.
+	CommittedFunc func(ctx context.Context)
+
+	// HandleCollectionEventFunc mocks the HandleCollectionEvent method.
+	HandleCollectionEventFunc func(ctx context.Context, event *models.Event, collection *models.Collection) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Committed holds details about calls to the Committed method.
+		Committed []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// HandleCollectionEvent holds details about calls to the HandleCollectionEvent method.
+		HandleCollectionEvent []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Event is the event argument value.
+			Event *models.Event
+			// Collection is the collection argument value.
+			Collection *models.Collection
+		}
+	}
+	lockCommitted             sync.RWMutex
+	lockHandleCollectionEvent sync.RWMutex
+}
+
+// Committed calls CommittedFunc.
+func (mock *TransactionalListenerMock) Committed(ctx context.Context) {
+	if mock.CommittedFunc == nil {
+		panic("TransactionalListenerMock.CommittedFunc: method is nil but TransactionalListener.Committed was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockCommitted.Lock()
+	mock.calls.Committed = append(mock.calls.Committed, callInfo)
+	mock.lockCommitted.Unlock()
+	mock.CommittedFunc(ctx)
+}
+
+// CommittedCalls gets all the calls that were made to Committed.
+// Check the length with:
+//
+//	len(mockedTransactionalListener.CommittedCalls())
+func (mock *TransactionalListenerMock) CommittedCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockCommitted.RLock()
+	calls = mock.calls.Committed
+	mock.lockCommitted.RUnlock()
+	return calls
+}
+
+// HandleCollectionEvent calls HandleCollectionEventFunc.
+func (mock *TransactionalListenerMock) HandleCollectionEvent(ctx context.Context, event *models.Event, collection *models.Collection) error {
+	if mock.HandleCollectionEventFunc == nil {
+		panic("TransactionalListenerMock.HandleCollectionEventFunc: method is nil but TransactionalListener.HandleCollectionEvent was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Event      *models.Event
+		Collection *models.Collection
+	}{
+		Ctx:        ctx,
+		Event:      event,
+		Collection: collection,
+	}
+	mock.lockHandleCollectionEvent.Lock()
+	mock.calls.HandleCollectionEvent = append(mock.calls.HandleCollectionEvent, callInfo)
+	mock.lockHandleCollectionEvent.Unlock()
+	return mock.HandleCollectionEventFunc(ctx, event, collection)
+}
+
+// HandleCollectionEventCalls gets all the calls that were made to HandleCollectionEvent.
+// Check the length with:
+//
+//	len(mockedTransactionalListener.HandleCollectionEventCalls())
+func (mock *TransactionalListenerMock) HandleCollectionEventCalls() []struct {
+	Ctx        context.Context
+	Event      *models.Event
+	Collection *models.Collection
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Event      *models.Event
+		Collection *models.Collection
+	}
+	mock.lockHandleCollectionEvent.RLock()
+	calls = mock.calls.HandleCollectionEvent
+	mock.lockHandleCollectionEvent.RUnlock()
+	return calls
+}
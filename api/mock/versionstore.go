@@ -0,0 +1,190 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/ONSdigital/dp-collection-api/api"
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"sync"
+)
+
+// Ensure, that VersionStoreMock does implement api.VersionStore.
+// If this is not the case, regenerate this file with moq.
+var _ api.VersionStore = &VersionStoreMock{}
+
+// VersionStoreMock is a mock implementation of api.VersionStore.
+//
+//	func TestSomethingThatUsesVersionStore(t *testing.T) {
+//
+//		// make and configure a mocked api.VersionStore
+//		mockedVersionStore := &VersionStoreMock{
+//			AddVersionFunc: func(ctx context.Context, version *models.Version) error {
+//				panic("mock out the AddVersion method")
+//			},
+//			GetVersionFunc: func(ctx context.Context, collectionID string, version int) (*models.Version, error) {
+//				panic("mock out the GetVersion method")
+//			},
+//			GetVersionsFunc: func(ctx context.Context, queryParams collections.VersionsQueryParams) ([]models.Version, int, error) {
+//				panic("mock out the GetVersions method")
+//			},
+//		}
+//
+//		// use mockedVersionStore in code that requires api.VersionStore
+//		// and then make assertions.
+//
+//	}
+type VersionStoreMock struct {
+	// AddVersionFunc mocks the AddVersion method.
+	AddVersionFunc func(ctx context.Context, version *models.Version) error
+
+	// GetVersionFunc mocks the GetVersion method.
+	GetVersionFunc func(ctx context.Context, collectionID string, version int) (*models.Version, error)
+
+	// GetVersionsFunc mocks the GetVersions method.
+	GetVersionsFunc func(ctx context.Context, queryParams collections.VersionsQueryParams) ([]models.Version, int, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// AddVersion holds details about calls to the AddVersion method.
+		AddVersion []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Version is the version argument value.
+			Version *models.Version
+		}
+		// GetVersion holds details about calls to the GetVersion method.
+		GetVersion []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// CollectionID is the collectionID argument value.
+			CollectionID string
+			// Version is the version argument value.
+			Version int
+		}
+		// GetVersions holds details about calls to the GetVersions method.
+		GetVersions []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// QueryParams is the queryParams argument value.
+			QueryParams collections.VersionsQueryParams
+		}
+	}
+	lockAddVersion  sync.RWMutex
+	lockGetVersion  sync.RWMutex
+	lockGetVersions sync.RWMutex
+}
+
+// AddVersion calls AddVersionFunc.
+func (mock *VersionStoreMock) AddVersion(ctx context.Context, version *models.Version) error {
+	if mock.AddVersionFunc == nil {
+		panic("VersionStoreMock.AddVersionFunc: method is nil but VersionStore.AddVersion was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Version *models.Version
+	}{
+		Ctx:     ctx,
+		Version: version,
+	}
+	mock.lockAddVersion.Lock()
+	mock.calls.AddVersion = append(mock.calls.AddVersion, callInfo)
+	mock.lockAddVersion.Unlock()
+	return mock.AddVersionFunc(ctx, version)
+}
+
+// AddVersionCalls gets all the calls that were made to AddVersion.
+// Check the length with:
+//
+//	len(mockedVersionStore.AddVersionCalls())
+func (mock *VersionStoreMock) AddVersionCalls() []struct {
+	Ctx     context.Context
+	Version *models.Version
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Version *models.Version
+	}
+	mock.lockAddVersion.RLock()
+	calls = mock.calls.AddVersion
+	mock.lockAddVersion.RUnlock()
+	return calls
+}
+
+// GetVersion calls GetVersionFunc.
+func (mock *VersionStoreMock) GetVersion(ctx context.Context, collectionID string, version int) (*models.Version, error) {
+	if mock.GetVersionFunc == nil {
+		panic("VersionStoreMock.GetVersionFunc: method is nil but VersionStore.GetVersion was just called")
+	}
+	callInfo := struct {
+		Ctx          context.Context
+		CollectionID string
+		Version      int
+	}{
+		Ctx:          ctx,
+		CollectionID: collectionID,
+		Version:      version,
+	}
+	mock.lockGetVersion.Lock()
+	mock.calls.GetVersion = append(mock.calls.GetVersion, callInfo)
+	mock.lockGetVersion.Unlock()
+	return mock.GetVersionFunc(ctx, collectionID, version)
+}
+
+// GetVersionCalls gets all the calls that were made to GetVersion.
+// Check the length with:
+//
+//	len(mockedVersionStore.GetVersionCalls())
+func (mock *VersionStoreMock) GetVersionCalls() []struct {
+	Ctx          context.Context
+	CollectionID string
+	Version      int
+} {
+	var calls []struct {
+		Ctx          context.Context
+		CollectionID string
+		Version      int
+	}
+	mock.lockGetVersion.RLock()
+	calls = mock.calls.GetVersion
+	mock.lockGetVersion.RUnlock()
+	return calls
+}
+
+// GetVersions calls GetVersionsFunc.
+func (mock *VersionStoreMock) GetVersions(ctx context.Context, queryParams collections.VersionsQueryParams) ([]models.Version, int, error) {
+	if mock.GetVersionsFunc == nil {
+		panic("VersionStoreMock.GetVersionsFunc: method is nil but VersionStore.GetVersions was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		QueryParams collections.VersionsQueryParams
+	}{
+		Ctx:         ctx,
+		QueryParams: queryParams,
+	}
+	mock.lockGetVersions.Lock()
+	mock.calls.GetVersions = append(mock.calls.GetVersions, callInfo)
+	mock.lockGetVersions.Unlock()
+	return mock.GetVersionsFunc(ctx, queryParams)
+}
+
+// GetVersionsCalls gets all the calls that were made to GetVersions.
+// Check the length with:
+//
+//	len(mockedVersionStore.GetVersionsCalls())
+func (mock *VersionStoreMock) GetVersionsCalls() []struct {
+	Ctx         context.Context
+	QueryParams collections.VersionsQueryParams
+} {
+	var calls []struct {
+		Ctx         context.Context
+		QueryParams collections.VersionsQueryParams
+	}
+	mock.lockGetVersions.RLock()
+	calls = mock.calls.GetVersions
+	mock.lockGetVersions.RUnlock()
+	return calls
+}
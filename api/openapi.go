@@ -0,0 +1,210 @@
+package api
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/ghodss/yaml"
+)
+
+// swaggerSpec is the API specification, embedded so that the spec served always matches the running service
+//
+//go:embed swagger.yaml
+var swaggerSpec []byte
+
+// GetSwaggerYAMLHandler returns the API specification in its original YAML format
+func (api *API) GetSwaggerYAMLHandler(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	if _, err := w.Write(swaggerSpec); err != nil {
+		log.Error(req.Context(), "failed to write swagger spec", err)
+	}
+}
+
+// GetSwaggerJSONHandler returns the API specification converted to JSON
+func (api *API) GetSwaggerJSONHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	body, err := yaml.YAMLToJSON(swaggerSpec)
+	if err != nil {
+		handleError(ctx, err, w, req, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if _, err := w.Write(body); err != nil {
+		log.Error(ctx, "failed to write swagger spec", err)
+	}
+}
+
+// RequestValidator checks incoming requests against the API specification before they reach the handlers
+type RequestValidator struct {
+	router              routers.Router
+	maxRequestBodyBytes int64
+}
+
+// NewRequestValidator loads the embedded API specification and returns a validator for it.
+// Request bodies over maxRequestBodyBytes are not validated, and are left for the handler to reject.
+func NewRequestValidator(ctx context.Context, maxRequestBodyBytes int64) (*RequestValidator, error) {
+
+	// the spec uses the uuid format, which is not one of the formats known to the validator
+	openapi3.DefineStringFormatCallback("uuid", ValidateUUID)
+
+	// an import body is validated as a string, leaving each line to be parsed by the handler
+	openapi3filter.RegisterBodyDecoder(ndjsonMediaType, openapi3filter.FileBodyDecoder)
+
+	var spec openapi2.T
+	if err := yaml.Unmarshal(swaggerSpec, &spec); err != nil {
+		return nil, err
+	}
+
+	doc, err := openapi2conv.ToV3(&spec)
+	if err != nil {
+		return nil, err
+	}
+
+	// requests reach the service with the basePath already removed, so routes are matched without a server prefix
+	doc.Servers = nil
+
+	if err := doc.Validate(ctx); err != nil {
+		return nil, err
+	}
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RequestValidator{
+		router:              router,
+		maxRequestBodyBytes: maxRequestBodyBytes,
+	}, nil
+}
+
+// Middleware returns a 400 status, in the standard error format, for any request that does not match the API
+// specification. Requests for paths and methods that are not in the specification are passed through to the router.
+func (v *RequestValidator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+
+		route, pathParams, err := v.router.FindRoute(req)
+		if err != nil {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		body, err := bufferBody(req, v.maxRequestBodyBytes)
+		if err != nil {
+			handleError(ctx, err, w, req, nil)
+			return
+		}
+		if v.maxRequestBodyBytes > 0 && int64(len(body)) > v.maxRequestBodyBytes {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		err = openapi3filter.ValidateRequest(ctx, &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				MultiError:         true,
+				AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			},
+		})
+		if err != nil {
+			handleError(ctx, specValidationErrors(err), w, req, log.Data{"operation_id": route.Operation.OperationID})
+			return
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
+
+// specValidationErrors converts the errors returned by the validator into the API's own errors,
+// so that each one is returned with a code and, where known, the field it relates to
+func specValidationErrors(err error) ValidationErrors {
+	var validationErrs ValidationErrors
+
+	for _, e := range multiErrors(err) {
+		requestErr, ok := e.(*openapi3filter.RequestError)
+		if !ok {
+			validationErrs = append(validationErrs, e)
+			continue
+		}
+
+		switch {
+		case requestErr.Parameter != nil:
+			for _, reason := range specErrorReasons(requestErr) {
+				validationErrs = append(validationErrs, FieldError{
+					Err:   fmt.Errorf("%w: %s", ErrInvalidParameter, reason),
+					Field: requestErr.Parameter.Name,
+				})
+			}
+		case requestErr.Err == nil:
+			// the only body error without an underlying cause is an unexpected content type
+			validationErrs = append(validationErrs, fmt.Errorf("%w: %s", ErrUnsupportedContentType, requestErr.Reason))
+		default:
+			for _, bodyErr := range multiErrors(requestErr.Err) {
+				validationErrs = append(validationErrs, bodyFieldError(bodyErr))
+			}
+		}
+	}
+
+	return validationErrs
+}
+
+// bodyFieldError returns a request body error, with the field set to the path of the invalid value where there is one
+func bodyFieldError(err error) error {
+	schemaErr, ok := err.(*openapi3.SchemaError)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrInvalidRequestBody, err.Error())
+	}
+
+	bodyErr := fmt.Errorf("%w: %s", ErrInvalidRequestBody, schemaErrorReason(schemaErr))
+	if path := schemaErr.JSONPointer(); len(path) > 0 {
+		return FieldError{Err: bodyErr, Field: strings.Join(path, ".")}
+	}
+	return bodyErr
+}
+
+// specErrorReasons returns a short description of each problem with a request value
+func specErrorReasons(requestErr *openapi3filter.RequestError) []string {
+	if requestErr.Err == nil {
+		return []string{requestErr.Reason}
+	}
+
+	var reasons []string
+	for _, e := range multiErrors(requestErr.Err) {
+		if schemaErr, ok := e.(*openapi3.SchemaError); ok {
+			reasons = append(reasons, schemaErrorReason(schemaErr))
+			continue
+		}
+		reasons = append(reasons, e.Error())
+	}
+	return reasons
+}
+
+// schemaErrorReason describes a schema error without the schema and value details included in its message
+func schemaErrorReason(err *openapi3.SchemaError) string {
+	if len(err.Reason) > 0 {
+		return err.Reason
+	}
+	return fmt.Sprintf("value does not match the schema %q", err.SchemaField)
+}
+
+func multiErrors(err error) []error {
+	if multiErr, ok := err.(openapi3.MultiError); ok {
+		return multiErr
+	}
+	return []error{err}
+}
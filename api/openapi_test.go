@@ -0,0 +1,213 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/api"
+	"github.com/ONSdigital/dp-collection-api/config"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/gorilla/mux"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetSwagger(t *testing.T) {
+
+	Convey("Given an API instance", t, func() {
+		a := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), mockPaginator(), mockCollectionStore(), nil)
+
+		Convey("When the swagger spec is requested as YAML", func() {
+			r := httptest.NewRequest("GET", "http://localhost:26000/swagger.yaml", nil)
+			w := httptest.NewRecorder()
+			a.Router.ServeHTTP(w, r)
+
+			Convey("Then the spec is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Content-Type"), ShouldEqual, "application/yaml")
+				So(w.Body.String(), ShouldStartWith, `swagger: "2.0"`)
+			})
+		})
+
+		Convey("When the swagger spec is requested as JSON", func() {
+			r := httptest.NewRequest("GET", "http://localhost:26000/swagger.json", nil)
+			w := httptest.NewRecorder()
+			a.Router.ServeHTTP(w, r)
+
+			Convey("Then the spec is returned as a JSON document", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Content-Type"), ShouldEqual, "application/json; charset=utf-8")
+
+				spec := map[string]interface{}{}
+				So(json.Unmarshal(w.Body.Bytes(), &spec), ShouldBeNil)
+				So(spec["swagger"], ShouldEqual, "2.0")
+				So(spec["paths"], ShouldContainKey, "/collections")
+			})
+		})
+	})
+}
+
+func TestRequestValidator(t *testing.T) {
+
+	api.NewID = func() (string, error) {
+		return collectionID, nil
+	}
+	api.Now = func() time.Time {
+		return now
+	}
+
+	Convey("Given an API with request validation enabled", t, func() {
+		ctx := context.Background()
+		collectionStore := mockCollectionStore()
+
+		validator, err := api.NewRequestValidator(ctx, 64)
+		So(err, ShouldBeNil)
+
+		r := mux.NewRouter()
+		r.Use(validator.Middleware)
+		a := api.Setup(ctx, &config.Config{MaxRequestBodyBytes: 64}, r, mockPaginator(), collectionStore, nil)
+
+		send := func(method, target, contentType, body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(method, "http://localhost:26000"+target, bytes.NewBufferString(body))
+			if len(contentType) > 0 {
+				req.Header.Set("Content-Type", contentType)
+			}
+			w := httptest.NewRecorder()
+			a.Router.ServeHTTP(w, req)
+			return w
+		}
+
+		Convey("When a request that matches the spec is sent", func() {
+			w := send("POST", "/collections", "application/json", `{"name":"collection 1","publish_date":"2020-05-05T14:58:29Z"}`)
+
+			Convey("Then the request is handled", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(len(collectionStore.AddCollectionCalls()), ShouldEqual, 1)
+			})
+		})
+
+		Convey("When a request with invalid query parameters is sent", func() {
+			w := send("GET", "/collections?limit=-1&order_by=name", "", "")
+
+			Convey("Then a 400 status is returned with an error for each parameter", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				errs := errorResponses(w)
+				So(errs, ShouldHaveLength, 2)
+				So(errs[0].Code, ShouldEqual, models.ErrCodeInvalidParameter)
+				So(errs[0].Field, ShouldEqual, "limit")
+				So(errs[1].Code, ShouldEqual, models.ErrCodeInvalidParameter)
+				So(errs[1].Field, ShouldEqual, "order_by")
+			})
+
+			Convey("Then the handler is not called", func() {
+				So(len(collectionStore.GetCollectionsCalls()), ShouldEqual, 0)
+			})
+		})
+
+		Convey("When a request with an invalid path parameter is sent", func() {
+			w := send("GET", "/collections/"+invalidCollectionID, "", "")
+
+			Convey("Then a 400 status is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				errs := errorResponses(w)
+				So(errs, ShouldHaveLength, 1)
+				So(errs[0].Code, ShouldEqual, models.ErrCodeInvalidParameter)
+				So(errs[0].Field, ShouldEqual, "collection_id")
+			})
+		})
+
+		Convey("When a request body that does not match the schema is sent", func() {
+			w := send("POST", "/collections", "application/json", `{"name":123,"publish_date":"tomorrow"}`)
+
+			Convey("Then a 400 status is returned with an error for each field", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				errs := errorResponses(w)
+				So(errs, ShouldHaveLength, 2)
+				fields := []string{errs[0].Field, errs[1].Field}
+				So(fields, ShouldContain, "name")
+				So(fields, ShouldContain, "publish_date")
+				So(errs[0].Code, ShouldEqual, models.ErrCodeInvalidRequestBody)
+				So(errs[1].Code, ShouldEqual, models.ErrCodeInvalidRequestBody)
+			})
+
+			Convey("Then the handler is not called", func() {
+				So(len(collectionStore.AddCollectionCalls()), ShouldEqual, 0)
+			})
+		})
+
+		Convey("When a request body with a field that is not in the spec is sent", func() {
+			w := send("POST", "/collections", "application/json", `{"name":"collection 1","colour":"red"}`)
+
+			Convey("Then a 400 status is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				errs := errorResponses(w)
+				So(errs, ShouldHaveLength, 1)
+				So(errs[0].Code, ShouldEqual, models.ErrCodeInvalidRequestBody)
+				So(errs[0].Message, ShouldContainSubstring, "colour")
+			})
+		})
+
+		Convey("When a request body with an unsupported content type is sent", func() {
+			w := send("POST", "/collections", "text/plain", `{"name":"collection 1"}`)
+
+			Convey("Then a 400 status is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				errs := errorResponses(w)
+				So(errs, ShouldHaveLength, 1)
+				So(errs[0].Code, ShouldEqual, models.ErrCodeUnsupportedContentType)
+				So(errs[0].Field, ShouldEqual, "Content-Type")
+			})
+		})
+
+		Convey("When a request body over the maximum size is sent", func() {
+			w := send("POST", "/collections", "application/json", `{"name":"`+strings.Repeat("a", 64)+`"}`)
+
+			Convey("Then it is left for the handler to reject", func() {
+				So(w.Code, ShouldEqual, http.StatusRequestEntityTooLarge)
+			})
+		})
+
+		Convey("When an import that matches the spec is sent", func() {
+			w := send("POST", "/collections/import?on_conflict=skip", "application/x-ndjson", `{"name":"collection 1"}`)
+
+			Convey("Then the request is handled", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(len(collectionStore.GetCollectionByIDCalls()), ShouldEqual, 1)
+			})
+		})
+
+		Convey("When an import with an unknown conflict policy is sent", func() {
+			w := send("POST", "/collections/import?on_conflict=replace", "application/x-ndjson", `{"name":"collection 1"}`)
+
+			Convey("Then a 400 status is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				errs := errorResponses(w)
+				So(errs, ShouldHaveLength, 1)
+				So(errs[0].Code, ShouldEqual, models.ErrCodeInvalidParameter)
+				So(errs[0].Field, ShouldEqual, "on_conflict")
+			})
+		})
+
+		Convey("When a request for a path that is not in the spec is sent", func() {
+			w := send("GET", "/swagger.yaml", "", "")
+
+			Convey("Then the request is handled", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+			})
+		})
+	})
+}
+
+func errorResponses(w *httptest.ResponseRecorder) []models.ErrorResponse {
+	response := models.ErrorsResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		return nil
+	}
+	return response.Errors
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// publishResultAttempts is the number of times a publish result is applied to a collection that is changed by another
+// request at the same time
+const publishResultAttempts = 3
+
+// SetPublishResult stores the outcome of publishing a collection, as reported by the publishing services, and records
+// it as an event. A result that does not supersede the collection's current result, because it has already been
+// applied or is older, is ignored, so that results can be received more than once and out of order.
+//
+// A completed publish makes the collection published, by bringing its publish date forward to the time it was
+// published if it was unscheduled or scheduled for later. A failed publish leaves the publish date unchanged.
+func (api *API) SetPublishResult(ctx context.Context, collectionID string, result *models.PublishResult) error {
+	logData := log.Data{"collection_id": collectionID, "message_id": result.MessageID, "publish_status": result.Status}
+
+	eventType := models.EventPublishFailed
+	if result.Status == models.PublishCompleted {
+		eventType = models.EventPublishCompleted
+	}
+
+	for attempt := 1; ; attempt++ {
+		existing, err := api.collectionStore.GetCollectionByID(ctx, collectionID, models.AnyETag)
+		if err != nil {
+			return err
+		}
+
+		if !result.Supersedes(existing.PublishResult) {
+			log.Info(ctx, "publish result ignored, as it has already been applied or is older than the current result", logData)
+			return nil
+		}
+
+		update := &models.Collection{ID: collectionID, PublishResult: result, Version: existing.Version + 1}
+		if result.Status == models.PublishCompleted && (existing.PublishDate == nil || existing.PublishDate.After(result.Date)) {
+			publishDate := result.Date
+			update.PublishDate = &publishDate
+		}
+
+		written := *existing
+		written.Set(update)
+		if update.ETag, err = written.Hash(nil); err != nil {
+			return err
+		}
+		written.ETag = update.ETag
+
+		err = api.writeCollection(ctx, eventType, &written, func(ctx context.Context) error {
+			return api.collectionStore.ReplaceCollection(ctx, update, existing.ETag)
+		})
+		if err == collections.ErrCollectionConflict && attempt < publishResultAttempts {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		log.Info(ctx, "publish result applied", logData)
+		return nil
+	}
+}
@@ -0,0 +1,131 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/api"
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/config"
+	"github.com/ONSdigital/dp-collection-api/memory"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/dp-collection-api/pagination"
+	"github.com/gorilla/mux"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSetPublishResult(t *testing.T) {
+
+	api.NewID = func() (string, error) {
+		return collectionID, nil
+	}
+	api.Now = func() time.Time {
+		return time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	}
+	scheduled := time.Date(2021, 6, 2, 9, 30, 0, 0, time.UTC)
+	published := time.Date(2021, 6, 2, 9, 30, 5, 0, time.UTC)
+
+	Convey("Given a collection scheduled to be published", t, func() {
+		ctx := context.Background()
+		store := memory.New()
+		r := mux.NewRouter()
+		a := api.Setup(ctx, &config.Config{}, r, &pagination.Paginator{}, store, nil)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/collections", strings.NewReader(`{"name":"collection 1","publish_date":"2021-06-02T09:30:00Z"}`)))
+		So(w.Code, ShouldEqual, http.StatusCreated)
+
+		events := func() []models.Event {
+			events, _, err := store.GetCollectionEvents(ctx, collections.EventsQueryParams{CollectionID: collectionID, Limit: 10})
+			So(err, ShouldBeNil)
+			return events
+		}
+
+		Convey("When a completed publish is reported", func() {
+			result := &models.PublishResult{Status: models.PublishCompleted, Date: published, MessageID: "message 1"}
+			err := a.SetPublishResult(ctx, collectionID, result)
+
+			Convey("Then the result is stored, and the publish date is unchanged", func() {
+				So(err, ShouldBeNil)
+				collection, err := store.GetCollectionByID(ctx, collectionID, models.AnyETag)
+				So(err, ShouldBeNil)
+				So(collection.PublishResult, ShouldResemble, result)
+				So(collection.PublishDate.Equal(scheduled), ShouldBeTrue)
+				So(collection.Version, ShouldEqual, 2)
+			})
+
+			Convey("Then a PUBLISH_COMPLETED event is recorded", func() {
+				So(events(), ShouldHaveLength, 2)
+				So(events()[1].Type, ShouldEqual, models.EventPublishCompleted)
+			})
+
+			Convey("And it is reported again", func() {
+				err := a.SetPublishResult(ctx, collectionID, &models.PublishResult{Status: models.PublishCompleted, Date: published, MessageID: "message 1"})
+
+				Convey("Then it is ignored", func() {
+					So(err, ShouldBeNil)
+					So(events(), ShouldHaveLength, 2)
+				})
+			})
+
+			Convey("And an older failed publish is reported after it", func() {
+				err := a.SetPublishResult(ctx, collectionID, &models.PublishResult{Status: models.PublishFailed, Date: scheduled, Error: "timed out", MessageID: "message 0"})
+
+				Convey("Then it is ignored", func() {
+					So(err, ShouldBeNil)
+					collection, err := store.GetCollectionByID(ctx, collectionID, models.AnyETag)
+					So(err, ShouldBeNil)
+					So(collection.PublishResult.Status, ShouldEqual, models.PublishCompleted)
+					So(events(), ShouldHaveLength, 2)
+				})
+			})
+		})
+
+		Convey("When a completed publish is reported before the collection's publish date", func() {
+			early := scheduled.Add(-time.Hour)
+			err := a.SetPublishResult(ctx, collectionID, &models.PublishResult{Status: models.PublishCompleted, Date: early, MessageID: "message 1"})
+
+			Convey("Then the publish date is brought forward, so that the collection is published", func() {
+				So(err, ShouldBeNil)
+				collection, err := store.GetCollectionByID(ctx, collectionID, models.AnyETag)
+				So(err, ShouldBeNil)
+				So(collection.PublishDate.Equal(early), ShouldBeTrue)
+
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/collections/"+collectionID, nil))
+				var response models.Collection
+				So(json.Unmarshal(w.Body.Bytes(), &response), ShouldBeNil)
+				So(response.ETag, ShouldEqual, collection.ETag)
+				So(response.PublishResult.Status, ShouldEqual, models.PublishCompleted)
+			})
+		})
+
+		Convey("When a failed publish is reported", func() {
+			err := a.SetPublishResult(ctx, collectionID, &models.PublishResult{Status: models.PublishFailed, Date: published, Error: "timed out", MessageID: "message 1"})
+
+			Convey("Then the error is stored, and a PUBLISH_FAILED event is recorded", func() {
+				So(err, ShouldBeNil)
+				collection, err := store.GetCollectionByID(ctx, collectionID, models.AnyETag)
+				So(err, ShouldBeNil)
+				So(collection.PublishResult.Status, ShouldEqual, models.PublishFailed)
+				So(collection.PublishResult.Error, ShouldEqual, "timed out")
+				So(collection.PublishDate.Equal(scheduled), ShouldBeTrue)
+				So(events()[1].Type, ShouldEqual, models.EventPublishFailed)
+			})
+		})
+
+		Convey("When a publish result is reported for a collection that does not exist", func() {
+			err := a.SetPublishResult(ctx, "unknown", &models.PublishResult{Status: models.PublishCompleted, Date: published, MessageID: "message 1"})
+
+			Convey("Then the collection not found error is returned", func() {
+				So(err, ShouldEqual, collections.ErrCollectionNotFound)
+			})
+		})
+	})
+}
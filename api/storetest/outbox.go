@@ -0,0 +1,195 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/api"
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/kafka"
+	"github.com/ONSdigital/dp-collection-api/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// OutboxStore is the outbox store under test, with the methods used by the API and by the Kafka relay
+type OutboxStore interface {
+	api.CollectionStore
+	api.Outbox
+	kafka.OutboxStore
+}
+
+// NewOutboxStoreFunc returns an empty outbox store, in the same way as NewStoreFunc
+type NewOutboxStoreFunc func(t *testing.T) OutboxStore
+
+// TestOutboxStore runs the conformance suite for the outbox against the stores returned by newStore
+func TestOutboxStore(t *testing.T, newStore NewOutboxStoreFunc) {
+	t.Run("WithTransaction", func(t *testing.T) { testWithTransaction(t, newStore) })
+	t.Run("ClaimOutboxMessages", func(t *testing.T) { testClaimOutboxMessages(t, newStore) })
+	t.Run("DeleteOutboxMessage", func(t *testing.T) { testDeleteOutboxMessage(t, newStore) })
+}
+
+func testWithTransaction(t *testing.T, newStore NewOutboxStoreFunc) {
+
+	Convey("Given an empty store", t, func() {
+		store := newStore(t)
+
+		Convey("When a collection and its outbox message are written in a transaction", func() {
+			err := store.WithTransaction(ctx, func(ctx context.Context) error {
+				if err := store.AddCollection(ctx, &models.Collection{ID: "id1", Name: "collection 1", ETag: "etag"}); err != nil {
+					return err
+				}
+				return store.AddOutboxMessage(ctx, &models.OutboxMessage{ID: "m1", Collection: models.Collection{ID: "id1"}, CreatedAt: may})
+			})
+
+			Convey("Then both are stored once the transaction commits", func() {
+				So(err, ShouldBeNil)
+
+				_, err := store.GetCollectionByID(ctx, "id1", models.AnyETag)
+				So(err, ShouldBeNil)
+
+				values, err := store.ClaimOutboxMessages(ctx, june, time.Minute, 10)
+				So(err, ShouldBeNil)
+				So(outboxIDs(values), ShouldResemble, []string{"m1"})
+			})
+		})
+
+		Convey("When the function called in a transaction fails", func() {
+			errWrite := errors.New("write failed")
+			err := store.WithTransaction(ctx, func(ctx context.Context) error {
+				return errWrite
+			})
+
+			Convey("Then its error is returned", func() {
+				So(err, ShouldEqual, errWrite)
+			})
+		})
+
+		Convey("When the function called in a transaction fails after writing", func() {
+			So(store.AddCollection(ctx, &models.Collection{ID: "id2", Name: "collection 2", ETag: "etag"}), ShouldBeNil)
+
+			errWrite := errors.New("write failed")
+			err := store.WithTransaction(ctx, func(ctx context.Context) error {
+				if err := store.AddCollection(ctx, &models.Collection{ID: "id1", Name: "collection 1", ETag: "etag"}); err != nil {
+					return err
+				}
+				if err := store.ReplaceCollection(ctx, &models.Collection{ID: "id2", Name: "collection 3", ETag: "etag2"}, "etag"); err != nil {
+					return err
+				}
+				if err := store.AddEvent(ctx, &models.Event{ID: "e1", CollectionID: "id1", Type: models.EventCreated, Date: may}); err != nil {
+					return err
+				}
+				if err := store.AddOutboxMessage(ctx, &models.OutboxMessage{ID: "m1", Collection: models.Collection{ID: "id1"}, CreatedAt: may}); err != nil {
+					return err
+				}
+				return errWrite
+			})
+
+			Convey("Then none of its writes are visible", func() {
+				So(err, ShouldEqual, errWrite)
+
+				_, err := store.GetCollectionByID(ctx, "id1", models.AnyETag)
+				So(err, ShouldEqual, collections.ErrCollectionNotFound)
+
+				collection, err := store.GetCollectionByID(ctx, "id2", models.AnyETag)
+				So(err, ShouldBeNil)
+				So(collection.Name, ShouldEqual, "collection 2")
+				So(collection.ETag, ShouldEqual, "etag")
+
+				events, _, err := store.GetCollectionEvents(ctx, collections.EventsQueryParams{CollectionID: "id1", Limit: 10})
+				So(err, ShouldBeNil)
+				So(events, ShouldBeEmpty)
+
+				values, err := store.ClaimOutboxMessages(ctx, june, time.Minute, 10)
+				So(err, ShouldBeNil)
+				So(values, ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+func testClaimOutboxMessages(t *testing.T, newStore NewOutboxStoreFunc) {
+
+	publishDate := july
+
+	Convey("Given a store containing outbox messages", t, func() {
+		store := newStore(t)
+		addOutboxMessages(store,
+			&models.OutboxMessage{ID: "m1", Event: models.Event{Type: models.EventCreated, Date: may}, Collection: models.Collection{ID: "id1", Name: "collection 1", PublishDate: &publishDate}, CreatedAt: may},
+			&models.OutboxMessage{ID: "m2", Event: models.Event{Type: models.EventUpdated, Date: june}, Collection: models.Collection{ID: "id1", Name: "collection 1"}, CreatedAt: june},
+			&models.OutboxMessage{ID: "m3", Event: models.Event{Type: models.EventCreated, Date: july}, Collection: models.Collection{ID: "id2", Name: "collection 2"}, CreatedAt: july},
+		)
+		now := july.Add(time.Hour)
+
+		Convey("When the messages are claimed", func() {
+			values, err := store.ClaimOutboxMessages(ctx, now, time.Minute, 2)
+
+			Convey("Then the messages are returned in the order they were added, with their event and collection", func() {
+				So(err, ShouldBeNil)
+				So(outboxIDs(values), ShouldResemble, []string{"m1", "m2"})
+				So(values[0].Event.Type, ShouldEqual, models.EventCreated)
+				So(values[0].Event.Date.Equal(may), ShouldBeTrue)
+				So(values[0].Collection.Name, ShouldEqual, "collection 1")
+				So(values[0].Collection.PublishDate.Equal(publishDate), ShouldBeTrue)
+				So(values[0].ClaimedUntil.Equal(now.Add(time.Minute)), ShouldBeTrue)
+			})
+
+			Convey("Then they are not claimed again until the lease has expired", func() {
+				values, err := store.ClaimOutboxMessages(ctx, now, time.Minute, 10)
+				So(err, ShouldBeNil)
+				So(outboxIDs(values), ShouldResemble, []string{"m3"})
+
+				values, err = store.ClaimOutboxMessages(ctx, now.Add(time.Minute), time.Minute, 10)
+				So(err, ShouldBeNil)
+				So(outboxIDs(values), ShouldResemble, []string{"m1", "m2", "m3"})
+			})
+		})
+	})
+}
+
+func testDeleteOutboxMessage(t *testing.T, newStore NewOutboxStoreFunc) {
+
+	Convey("Given a store containing outbox messages", t, func() {
+		store := newStore(t)
+		addOutboxMessages(store,
+			&models.OutboxMessage{ID: "m1", CreatedAt: may},
+			&models.OutboxMessage{ID: "m2", CreatedAt: june},
+		)
+
+		Convey("When a message is deleted", func() {
+			err := store.DeleteOutboxMessage(ctx, "m1")
+
+			Convey("Then it is no longer claimed", func() {
+				So(err, ShouldBeNil)
+
+				values, err := store.ClaimOutboxMessages(ctx, july, time.Minute, 10)
+				So(err, ShouldBeNil)
+				So(outboxIDs(values), ShouldResemble, []string{"m2"})
+			})
+		})
+
+		Convey("When a message that does not exist is deleted", func() {
+			err := store.DeleteOutboxMessage(ctx, "m3")
+
+			Convey("Then no error is returned, as it may have been published by another relay", func() {
+				So(err, ShouldBeNil)
+			})
+		})
+	})
+}
+
+func addOutboxMessages(store OutboxStore, values ...*models.OutboxMessage) {
+	for _, message := range values {
+		m := *message
+		So(store.AddOutboxMessage(ctx, &m), ShouldBeNil)
+	}
+}
+
+func outboxIDs(values []models.OutboxMessage) []string {
+	result := []string{}
+	for _, value := range values {
+		result = append(result, value.ID)
+	}
+	return result
+}
@@ -0,0 +1,489 @@
+// Package storetest provides a conformance suite for implementations of api.CollectionStore, so that every datastore
+// can be shown to behave in the same way as the others.
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/api"
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// Store is the collection store under test
+type Store interface {
+	api.CollectionStore
+}
+
+// NewStoreFunc returns an empty store, and is responsible for cleaning it up when the test ends. It is called
+// for every path through the suite's Convey blocks, so each path starts with an empty store.
+type NewStoreFunc func(t *testing.T) Store
+
+var (
+	ctx = context.Background()
+
+	// dates are whole milliseconds, as that is the precision stored by MongoDB
+	may  = time.Date(2021, 5, 1, 9, 30, 0, 0, time.UTC)
+	june = time.Date(2021, 6, 1, 9, 30, 0, 0, time.UTC)
+	july = time.Date(2021, 7, 1, 9, 30, 0, 0, time.UTC)
+)
+
+// TestCollectionStore runs the conformance suite against the stores returned by newStore
+func TestCollectionStore(t *testing.T, newStore NewStoreFunc) {
+	t.Run("GetCollectionByID", func(t *testing.T) { testGetCollectionByID(t, newStore) })
+	t.Run("GetCollectionByName", func(t *testing.T) { testGetCollectionByName(t, newStore) })
+	t.Run("AddCollection", func(t *testing.T) { testAddCollection(t, newStore) })
+	t.Run("ReplaceCollection", func(t *testing.T) { testReplaceCollection(t, newStore) })
+	t.Run("GetCollections", func(t *testing.T) { testGetCollections(t, newStore) })
+	t.Run("GetCollectionEvents", func(t *testing.T) { testGetCollectionEvents(t, newStore) })
+}
+
+func testGetCollectionByID(t *testing.T, newStore NewStoreFunc) {
+
+	Convey("Given a store containing a collection", t, func() {
+		store := newStore(t)
+		addCollections(store, &models.Collection{ID: "id1", Name: "Economy", PublishDate: &june, ETag: "etag1"})
+
+		Convey("When the collection is retrieved with any eTag", func() {
+			collection, err := store.GetCollectionByID(ctx, "id1", models.AnyETag)
+
+			Convey("Then the collection is returned", func() {
+				So(err, ShouldBeNil)
+				So(collection.ID, ShouldEqual, "id1")
+				So(collection.Name, ShouldEqual, "Economy")
+				So(collection.ETag, ShouldEqual, "etag1")
+				So(collection.PublishDate, ShouldNotBeNil)
+				So(collection.PublishDate.Equal(june), ShouldBeTrue)
+			})
+		})
+
+		Convey("When the collection is retrieved with its current eTag", func() {
+			collection, err := store.GetCollectionByID(ctx, "id1", "etag1")
+
+			Convey("Then the collection is returned", func() {
+				So(err, ShouldBeNil)
+				So(collection.ID, ShouldEqual, "id1")
+			})
+		})
+
+		Convey("When the collection is retrieved with a different eTag", func() {
+			collection, err := store.GetCollectionByID(ctx, "id1", "etag2")
+
+			Convey("Then a conflict error is returned", func() {
+				So(err, ShouldEqual, collections.ErrCollectionConflict)
+				So(collection, ShouldBeNil)
+			})
+		})
+
+		Convey("When a collection that does not exist is retrieved", func() {
+			collection, err := store.GetCollectionByID(ctx, "id2", models.AnyETag)
+
+			Convey("Then a not found error is returned", func() {
+				So(err, ShouldEqual, collections.ErrCollectionNotFound)
+				So(collection, ShouldBeNil)
+			})
+		})
+	})
+}
+
+func testGetCollectionByName(t *testing.T, newStore NewStoreFunc) {
+
+	Convey("Given a store containing some collections", t, func() {
+		store := newStore(t)
+		addCollections(store,
+			&models.Collection{ID: "id1", Name: "Economy", ETag: "etag1"},
+			&models.Collection{ID: "id2", Name: "Population", ETag: "etag2"},
+		)
+
+		Convey("When a collection is retrieved by its name", func() {
+			collection, err := store.GetCollectionByName(ctx, "Population")
+
+			Convey("Then the collection is returned", func() {
+				So(err, ShouldBeNil)
+				So(collection.ID, ShouldEqual, "id2")
+			})
+		})
+
+		Convey("When a collection is retrieved by a name that differs only in case", func() {
+			collection, err := store.GetCollectionByName(ctx, "population")
+
+			Convey("Then a not found error is returned, as names are matched exactly", func() {
+				So(err, ShouldEqual, collections.ErrCollectionNotFound)
+				So(collection, ShouldBeNil)
+			})
+		})
+
+		Convey("When a collection is retrieved by part of its name", func() {
+			collection, err := store.GetCollectionByName(ctx, "Econ")
+
+			Convey("Then a not found error is returned", func() {
+				So(err, ShouldEqual, collections.ErrCollectionNotFound)
+				So(collection, ShouldBeNil)
+			})
+		})
+	})
+}
+
+func testAddCollection(t *testing.T, newStore NewStoreFunc) {
+
+	Convey("Given an empty store", t, func() {
+		store := newStore(t)
+
+		Convey("When a collection without a publish date is added", func() {
+			err := store.AddCollection(ctx, &models.Collection{ID: "id1", Name: "Economy", ETag: "etag1"})
+			So(err, ShouldBeNil)
+
+			Convey("Then it is returned without a publish date", func() {
+				collection, err := store.GetCollectionByID(ctx, "id1", models.AnyETag)
+				So(err, ShouldBeNil)
+				So(collection.Name, ShouldEqual, "Economy")
+				So(collection.PublishDate, ShouldBeNil)
+			})
+
+			Convey("Then its creation and update times are recorded", func() {
+				collection, err := store.GetCollectionByID(ctx, "id1", models.AnyETag)
+				So(err, ShouldBeNil)
+				So(collection.CreatedAt.IsZero(), ShouldBeFalse)
+				So(collection.LastUpdated.IsZero(), ShouldBeFalse)
+			})
+		})
+
+		Convey("When a collection is added", func() {
+			collection := &models.Collection{ID: "id1", Name: "Economy", PublishDate: &june, ETag: "etag1"}
+			So(store.AddCollection(ctx, collection), ShouldBeNil)
+
+			Convey("And the collection is then modified by the caller", func() {
+				collection.Name = "Changed"
+
+				Convey("Then the stored collection is unchanged", func() {
+					stored, err := store.GetCollectionByID(ctx, "id1", models.AnyETag)
+					So(err, ShouldBeNil)
+					So(stored.Name, ShouldEqual, "Economy")
+				})
+			})
+
+			Convey("And a collection with the same ID is added", func() {
+				err := store.AddCollection(ctx, &models.Collection{ID: "id1", Name: "Economy 2021", ETag: "etag2"})
+				So(err, ShouldBeNil)
+
+				Convey("Then the collection is updated, keeping the fields that were not provided", func() {
+					stored, err := store.GetCollectionByID(ctx, "id1", models.AnyETag)
+					So(err, ShouldBeNil)
+					So(stored.Name, ShouldEqual, "Economy 2021")
+					So(stored.ETag, ShouldEqual, "etag2")
+					So(stored.PublishDate, ShouldNotBeNil)
+					So(stored.PublishDate.Equal(june), ShouldBeTrue)
+				})
+
+				Convey("Then there is still only one collection", func() {
+					_, totalCount, err := store.GetCollections(ctx, collections.QueryParams{Limit: 10})
+					So(err, ShouldBeNil)
+					So(totalCount, ShouldEqual, 1)
+				})
+			})
+		})
+	})
+}
+
+func testReplaceCollection(t *testing.T, newStore NewStoreFunc) {
+
+	Convey("Given a store containing a collection", t, func() {
+		store := newStore(t)
+		addCollections(store, &models.Collection{ID: "id1", Name: "Economy", PublishDate: &june, ETag: "etag1"})
+
+		Convey("When the collection is replaced with its current eTag", func() {
+			err := store.ReplaceCollection(ctx, &models.Collection{ID: "id1", Name: "Economy 2021", ETag: "etag2"}, "etag1")
+
+			Convey("Then the collection is updated, keeping the fields that were not provided", func() {
+				So(err, ShouldBeNil)
+				stored, err := store.GetCollectionByID(ctx, "id1", models.AnyETag)
+				So(err, ShouldBeNil)
+				So(stored.Name, ShouldEqual, "Economy 2021")
+				So(stored.ETag, ShouldEqual, "etag2")
+				So(stored.PublishDate, ShouldNotBeNil)
+				So(stored.PublishDate.Equal(june), ShouldBeTrue)
+			})
+
+			Convey("Then a second replacement using the original eTag is rejected", func() {
+				err := store.ReplaceCollection(ctx, &models.Collection{ID: "id1", Name: "Lost update", ETag: "etag3"}, "etag1")
+				So(err, ShouldEqual, collections.ErrCollectionConflict)
+
+				stored, err := store.GetCollectionByID(ctx, "id1", models.AnyETag)
+				So(err, ShouldBeNil)
+				So(stored.Name, ShouldEqual, "Economy 2021")
+			})
+		})
+
+		Convey("When the collection is replaced with a different eTag", func() {
+			err := store.ReplaceCollection(ctx, &models.Collection{ID: "id1", Name: "Economy 2021", ETag: "etag2"}, "etag0")
+
+			Convey("Then a conflict error is returned, and the collection is unchanged", func() {
+				So(err, ShouldEqual, collections.ErrCollectionConflict)
+				stored, err := store.GetCollectionByID(ctx, "id1", models.AnyETag)
+				So(err, ShouldBeNil)
+				So(stored.Name, ShouldEqual, "Economy")
+				So(stored.ETag, ShouldEqual, "etag1")
+			})
+		})
+
+		Convey("When a collection that does not exist is replaced", func() {
+			err := store.ReplaceCollection(ctx, &models.Collection{ID: "id2", Name: "Population", ETag: "etag2"}, "etag1")
+
+			Convey("Then a conflict error is returned, and the collection is not created", func() {
+				So(err, ShouldEqual, collections.ErrCollectionConflict)
+				_, err := store.GetCollectionByID(ctx, "id2", models.AnyETag)
+				So(err, ShouldEqual, collections.ErrCollectionNotFound)
+			})
+		})
+	})
+}
+
+func testGetCollections(t *testing.T, newStore NewStoreFunc) {
+
+	Convey("Given an empty store", t, func() {
+		store := newStore(t)
+
+		Convey("When the collections are listed", func() {
+			values, totalCount, err := store.GetCollections(ctx, collections.QueryParams{Limit: 10})
+
+			Convey("Then an empty list is returned", func() {
+				So(err, ShouldBeNil)
+				So(values, ShouldNotBeNil)
+				So(values, ShouldBeEmpty)
+				So(totalCount, ShouldEqual, 0)
+			})
+		})
+	})
+
+	Convey("Given a store containing collections added out of ID order", t, func() {
+		store := newStore(t)
+		addCollections(store,
+			&models.Collection{ID: "id3", Name: "collection 3", ETag: "etag3"},
+			&models.Collection{ID: "id1", Name: "collection 1", ETag: "etag1"},
+			&models.Collection{ID: "id2", Name: "collection 2", ETag: "etag2"},
+		)
+
+		Convey("When the collections are listed in ID order", func() {
+			values, totalCount, err := store.GetCollections(ctx, collections.QueryParams{Limit: 10, OrderBy: collections.OrderByID})
+
+			Convey("Then they are returned in ID order", func() {
+				So(err, ShouldBeNil)
+				So(totalCount, ShouldEqual, 3)
+				So(ids(values), ShouldResemble, []string{"id1", "id2", "id3"})
+			})
+		})
+	})
+
+	Convey("Given a store containing some collections", t, func() {
+		store := newStore(t)
+		addCollections(store,
+			&models.Collection{ID: "id1", Name: "Economy", PublishDate: &july, ETag: "etag1"},
+			&models.Collection{ID: "id2", Name: "Population", PublishDate: &may, ETag: "etag2"},
+			&models.Collection{ID: "id3", Name: "Economic indicators", ETag: "etag3"},
+			&models.Collection{ID: "id4", Name: "Labour market", PublishDate: &june, ETag: "etag4"},
+		)
+
+		Convey("When the collections are listed without an order", func() {
+			values, totalCount, err := store.GetCollections(ctx, collections.QueryParams{Limit: 10})
+
+			Convey("Then they are returned in the order they were added", func() {
+				So(err, ShouldBeNil)
+				So(totalCount, ShouldEqual, 4)
+				So(ids(values), ShouldResemble, []string{"id1", "id2", "id3", "id4"})
+			})
+		})
+
+		Convey("When the collections are listed in publish date order", func() {
+			values, totalCount, err := store.GetCollections(ctx, collections.QueryParams{Limit: 10, OrderBy: collections.OrderByPublishDate})
+
+			Convey("Then collections without a publish date come first, followed by the earliest", func() {
+				So(err, ShouldBeNil)
+				So(totalCount, ShouldEqual, 4)
+				So(ids(values), ShouldResemble, []string{"id3", "id2", "id4", "id1"})
+			})
+		})
+
+		Convey("When the collections are searched by name", func() {
+			values, totalCount, err := store.GetCollections(ctx, collections.QueryParams{Limit: 10, NameSearch: "ECONOM"})
+
+			Convey("Then the collections with names containing the search, ignoring case, are returned", func() {
+				So(err, ShouldBeNil)
+				So(totalCount, ShouldEqual, 2)
+				So(ids(values), ShouldResemble, []string{"id1", "id3"})
+			})
+		})
+
+		Convey("When the collections are searched by a name that matches none", func() {
+			values, totalCount, err := store.GetCollections(ctx, collections.QueryParams{Limit: 10, NameSearch: "trade"})
+
+			Convey("Then an empty list is returned", func() {
+				So(err, ShouldBeNil)
+				So(values, ShouldNotBeNil)
+				So(values, ShouldBeEmpty)
+				So(totalCount, ShouldEqual, 0)
+			})
+		})
+
+		Convey("When the collections are searched and ordered", func() {
+			values, totalCount, err := store.GetCollections(ctx, collections.QueryParams{Limit: 10, NameSearch: "econom", OrderBy: collections.OrderByPublishDate})
+
+			Convey("Then the matching collections are returned in order", func() {
+				So(err, ShouldBeNil)
+				So(totalCount, ShouldEqual, 2)
+				So(ids(values), ShouldResemble, []string{"id3", "id1"})
+			})
+		})
+
+		Convey("When a page of collections is requested", func() {
+			values, totalCount, err := store.GetCollections(ctx, collections.QueryParams{Offset: 1, Limit: 2, OrderBy: collections.OrderByPublishDate})
+
+			Convey("Then the collections in the page are returned, with the total count", func() {
+				So(err, ShouldBeNil)
+				So(totalCount, ShouldEqual, 4)
+				So(ids(values), ShouldResemble, []string{"id2", "id4"})
+			})
+		})
+
+		Convey("When the collections after an ID are requested", func() {
+			values, totalCount, err := store.GetCollections(ctx, collections.QueryParams{Limit: 10, OrderBy: collections.OrderByID, AfterID: "id2"})
+
+			Convey("Then only the collections with a later ID are returned, and counted", func() {
+				So(err, ShouldBeNil)
+				So(totalCount, ShouldEqual, 2)
+				So(ids(values), ShouldResemble, []string{"id3", "id4"})
+			})
+		})
+
+		Convey("When the last, partial, page of collections is requested", func() {
+			values, totalCount, err := store.GetCollections(ctx, collections.QueryParams{Offset: 3, Limit: 2})
+
+			Convey("Then the remaining collections are returned", func() {
+				So(err, ShouldBeNil)
+				So(totalCount, ShouldEqual, 4)
+				So(ids(values), ShouldResemble, []string{"id4"})
+			})
+		})
+
+		Convey("When a page beyond the last collection is requested", func() {
+			values, totalCount, err := store.GetCollections(ctx, collections.QueryParams{Offset: 10, Limit: 2})
+
+			Convey("Then an empty list is returned, with the total count", func() {
+				So(err, ShouldBeNil)
+				So(values, ShouldNotBeNil)
+				So(values, ShouldBeEmpty)
+				So(totalCount, ShouldEqual, 4)
+			})
+		})
+
+		Convey("When the collections are listed with a limit of zero", func() {
+			values, totalCount, err := store.GetCollections(ctx, collections.QueryParams{Limit: 0})
+
+			Convey("Then only the total count is returned", func() {
+				So(err, ShouldBeNil)
+				So(values, ShouldNotBeNil)
+				So(values, ShouldBeEmpty)
+				So(totalCount, ShouldEqual, 4)
+			})
+		})
+	})
+}
+
+func testGetCollectionEvents(t *testing.T, newStore NewStoreFunc) {
+
+	Convey("Given a store containing events for two collections", t, func() {
+		store := newStore(t)
+		addCollections(store,
+			&models.Collection{ID: "id1", Name: "Economy", ETag: "etag1"},
+			&models.Collection{ID: "id2", Name: "Population", ETag: "etag2"},
+		)
+		addEvents(store,
+			&models.Event{ID: "e1", CollectionID: "id1", Type: "UPDATED", Email: "a@ons.gov.uk", Date: july},
+			&models.Event{ID: "e2", CollectionID: "id1", Type: "CREATED", Email: "a@ons.gov.uk", Date: may},
+			&models.Event{ID: "e3", CollectionID: "id2", Type: "CREATED", Email: "b@ons.gov.uk", Date: june},
+			&models.Event{ID: "e4", CollectionID: "id1", Type: "APPROVED", Email: "b@ons.gov.uk", Date: june},
+		)
+
+		Convey("When the events for a collection are listed", func() {
+			values, totalCount, err := store.GetCollectionEvents(ctx, collections.EventsQueryParams{CollectionID: "id1", Limit: 10})
+
+			Convey("Then only the events for the collection are returned, in date order", func() {
+				So(err, ShouldBeNil)
+				So(totalCount, ShouldEqual, 3)
+				So(eventTypes(values), ShouldResemble, []string{"CREATED", "APPROVED", "UPDATED"})
+			})
+
+			Convey("Then the event fields are returned", func() {
+				So(err, ShouldBeNil)
+				So(values[0].Email, ShouldEqual, "a@ons.gov.uk")
+				So(values[0].CollectionID, ShouldEqual, "id1")
+				So(values[0].Date.Equal(may), ShouldBeTrue)
+			})
+		})
+
+		Convey("When a page of events is requested", func() {
+			values, totalCount, err := store.GetCollectionEvents(ctx, collections.EventsQueryParams{CollectionID: "id1", Offset: 1, Limit: 1})
+
+			Convey("Then the events in the page are returned, with the total count", func() {
+				So(err, ShouldBeNil)
+				So(totalCount, ShouldEqual, 3)
+				So(eventTypes(values), ShouldResemble, []string{"APPROVED"})
+			})
+		})
+
+		Convey("When a page beyond the last event is requested", func() {
+			values, totalCount, err := store.GetCollectionEvents(ctx, collections.EventsQueryParams{CollectionID: "id1", Offset: 5, Limit: 1})
+
+			Convey("Then an empty list is returned, with the total count", func() {
+				So(err, ShouldBeNil)
+				So(values, ShouldNotBeNil)
+				So(values, ShouldBeEmpty)
+				So(totalCount, ShouldEqual, 3)
+			})
+		})
+
+		Convey("When the events for a collection without events are listed", func() {
+			values, totalCount, err := store.GetCollectionEvents(ctx, collections.EventsQueryParams{CollectionID: "id3", Limit: 10})
+
+			Convey("Then an empty list is returned", func() {
+				So(err, ShouldBeNil)
+				So(values, ShouldNotBeNil)
+				So(values, ShouldBeEmpty)
+				So(totalCount, ShouldEqual, 0)
+			})
+		})
+	})
+}
+
+// addCollections adds copies of the collections, so that the suite's values are not changed by the store
+func addCollections(store Store, values ...*models.Collection) {
+	for _, collection := range values {
+		c := *collection
+		So(store.AddCollection(ctx, &c), ShouldBeNil)
+	}
+}
+
+func addEvents(store Store, values ...*models.Event) {
+	for _, event := range values {
+		e := *event
+		So(store.AddEvent(ctx, &e), ShouldBeNil)
+	}
+}
+
+func ids(values []models.Collection) []string {
+	result := []string{}
+	for _, value := range values {
+		result = append(result, value.ID)
+	}
+	return result
+}
+
+func eventTypes(values []models.Event) []string {
+	result := []string{}
+	for _, value := range values {
+		result = append(result, value.Type)
+	}
+	return result
+}
@@ -0,0 +1,261 @@
+package storetest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/api"
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/dp-collection-api/webhooks"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// SubscriptionStore is the subscription store under test, with the methods used by the API and by webhook deliveries
+type SubscriptionStore interface {
+	api.SubscriptionStore
+	webhooks.Store
+}
+
+// NewSubscriptionStoreFunc returns an empty subscription store, in the same way as NewStoreFunc
+type NewSubscriptionStoreFunc func(t *testing.T) SubscriptionStore
+
+// TestSubscriptionStore runs the conformance suite for subscriptions against the stores returned by newStore
+func TestSubscriptionStore(t *testing.T, newStore NewSubscriptionStoreFunc) {
+	t.Run("Subscriptions", func(t *testing.T) { testSubscriptions(t, newStore) })
+	t.Run("Deliveries", func(t *testing.T) { testDeliveries(t, newStore) })
+	t.Run("ClaimDeliveries", func(t *testing.T) { testClaimDeliveries(t, newStore) })
+}
+
+func testSubscriptions(t *testing.T, newStore NewSubscriptionStoreFunc) {
+
+	Convey("Given a store containing some subscriptions", t, func() {
+		store := newStore(t)
+		addSubscriptions(store,
+			&models.Subscription{ID: "sub1", URL: "https://a.example/hook", EventTypes: []string{models.EventPublished}, Secret: "secret1", CreatedAt: may},
+			&models.Subscription{ID: "sub2", URL: "https://b.example/hook", EventTypes: []string{}, Secret: "secret2", CreatedAt: june},
+			&models.Subscription{ID: "sub3", URL: "https://c.example/hook", EventTypes: []string{}, Secret: "secret3", CreatedAt: july},
+		)
+
+		Convey("When a subscription is retrieved", func() {
+			subscription, err := store.GetSubscriptionByID(ctx, "sub1")
+
+			Convey("Then the subscription is returned with its secret", func() {
+				So(err, ShouldBeNil)
+				So(subscription.URL, ShouldEqual, "https://a.example/hook")
+				So(subscription.EventTypes, ShouldResemble, []string{models.EventPublished})
+				So(subscription.Secret, ShouldEqual, "secret1")
+				So(subscription.CreatedAt.Equal(may), ShouldBeTrue)
+			})
+		})
+
+		Convey("When a subscription that does not exist is retrieved", func() {
+			_, err := store.GetSubscriptionByID(ctx, "sub4")
+
+			Convey("Then a not found error is returned", func() {
+				So(err, ShouldEqual, collections.ErrSubscriptionNotFound)
+			})
+		})
+
+		Convey("When a page of subscriptions is requested", func() {
+			values, totalCount, err := store.GetSubscriptions(ctx, 1, 1)
+
+			Convey("Then the subscriptions in the page are returned in the order they were created", func() {
+				So(err, ShouldBeNil)
+				So(totalCount, ShouldEqual, 3)
+				So(values, ShouldHaveLength, 1)
+				So(values[0].ID, ShouldEqual, "sub2")
+			})
+		})
+
+		Convey("When a page beyond the last subscription is requested", func() {
+			values, totalCount, err := store.GetSubscriptions(ctx, 5, 1)
+
+			Convey("Then an empty list is returned, with the total count", func() {
+				So(err, ShouldBeNil)
+				So(values, ShouldNotBeNil)
+				So(values, ShouldBeEmpty)
+				So(totalCount, ShouldEqual, 3)
+			})
+		})
+
+		Convey("When a subscription with deliveries is deleted", func() {
+			addDeliveries(store,
+				&models.Delivery{ID: "d1", SubscriptionID: "sub1", Status: models.DeliveryPending, NextAttemptAt: may, CreatedAt: may},
+				&models.Delivery{ID: "d2", SubscriptionID: "sub2", Status: models.DeliveryPending, NextAttemptAt: may, CreatedAt: may},
+			)
+			err := store.DeleteSubscription(ctx, "sub1")
+
+			Convey("Then the subscription and its deliveries are removed", func() {
+				So(err, ShouldBeNil)
+				_, err := store.GetSubscriptionByID(ctx, "sub1")
+				So(err, ShouldEqual, collections.ErrSubscriptionNotFound)
+
+				values, totalCount, err := store.GetDeliveries(ctx, collections.DeliveriesQueryParams{Limit: 10})
+				So(err, ShouldBeNil)
+				So(totalCount, ShouldEqual, 1)
+				So(values[0].ID, ShouldEqual, "d2")
+			})
+
+			Convey("Then the outcome of a delivery claimed before it was deleted is not stored", func() {
+				So(store.UpdateDelivery(ctx, &models.Delivery{ID: "d1", SubscriptionID: "sub1", Status: models.DeliveryDelivered}), ShouldBeNil)
+
+				_, totalCount, err := store.GetDeliveries(ctx, collections.DeliveriesQueryParams{SubscriptionID: "sub1", Limit: 10})
+				So(err, ShouldBeNil)
+				So(totalCount, ShouldEqual, 0)
+			})
+		})
+
+		Convey("When a subscription that does not exist is deleted", func() {
+			err := store.DeleteSubscription(ctx, "sub4")
+
+			Convey("Then a not found error is returned", func() {
+				So(err, ShouldEqual, collections.ErrSubscriptionNotFound)
+			})
+		})
+	})
+}
+
+func testDeliveries(t *testing.T, newStore NewSubscriptionStoreFunc) {
+
+	Convey("Given a store containing deliveries to some subscriptions", t, func() {
+		store := newStore(t)
+		addDeliveries(store,
+			&models.Delivery{
+				ID:             "d1",
+				SubscriptionID: "sub1",
+				Payload: models.WebhookPayload{
+					Event:      models.EventCreated,
+					Date:       may,
+					Collection: models.Collection{ID: "id1", Name: "Economy", PublishDate: &june},
+				},
+				Status:        models.DeliveryPending,
+				NextAttemptAt: may,
+				CreatedAt:     may,
+			},
+			&models.Delivery{ID: "d2", SubscriptionID: "sub1", Status: models.DeliveryDead, NextAttemptAt: june, CreatedAt: june},
+			&models.Delivery{ID: "d3", SubscriptionID: "sub2", Status: models.DeliveryDead, NextAttemptAt: july, CreatedAt: july},
+		)
+
+		Convey("When the deliveries to a subscription are listed", func() {
+			values, totalCount, err := store.GetDeliveries(ctx, collections.DeliveriesQueryParams{SubscriptionID: "sub1", Limit: 10})
+
+			Convey("Then only its deliveries are returned, most recent first", func() {
+				So(err, ShouldBeNil)
+				So(totalCount, ShouldEqual, 2)
+				So(deliveryIDs(values), ShouldResemble, []string{"d2", "d1"})
+			})
+
+			Convey("Then the payload of each delivery is returned", func() {
+				So(err, ShouldBeNil)
+				payload := values[1].Payload
+				So(payload.Event, ShouldEqual, models.EventCreated)
+				So(payload.Date.Equal(may), ShouldBeTrue)
+				So(payload.Collection.Name, ShouldEqual, "Economy")
+				So(payload.Collection.PublishDate.Equal(june), ShouldBeTrue)
+			})
+		})
+
+		Convey("When the dead deliveries to every subscription are listed", func() {
+			values, totalCount, err := store.GetDeliveries(ctx, collections.DeliveriesQueryParams{Status: models.DeliveryDead, Limit: 10})
+
+			Convey("Then the dead deliveries are returned, most recent first", func() {
+				So(err, ShouldBeNil)
+				So(totalCount, ShouldEqual, 2)
+				So(deliveryIDs(values), ShouldResemble, []string{"d3", "d2"})
+			})
+		})
+
+		Convey("When the outcome of an attempt is stored", func() {
+			attemptedAt := july
+			err := store.UpdateDelivery(ctx, &models.Delivery{
+				ID:             "d1",
+				SubscriptionID: "sub1",
+				Status:         models.DeliveryPending,
+				Attempts:       1,
+				NextAttemptAt:  july.Add(time.Minute),
+				LastAttemptAt:  &attemptedAt,
+				LastStatusCode: 500,
+				LastError:      "unexpected status code 500",
+				CreatedAt:      may,
+			})
+			So(err, ShouldBeNil)
+
+			Convey("Then the delivery is updated", func() {
+				values, _, err := store.GetDeliveries(ctx, collections.DeliveriesQueryParams{SubscriptionID: "sub1", Status: models.DeliveryPending, Limit: 10})
+				So(err, ShouldBeNil)
+				So(values, ShouldHaveLength, 1)
+				So(values[0].Attempts, ShouldEqual, 1)
+				So(values[0].LastAttemptAt.Equal(july), ShouldBeTrue)
+				So(values[0].LastStatusCode, ShouldEqual, 500)
+				So(values[0].LastError, ShouldEqual, "unexpected status code 500")
+			})
+		})
+	})
+}
+
+func testClaimDeliveries(t *testing.T, newStore NewSubscriptionStoreFunc) {
+
+	Convey("Given a store containing deliveries that are due, not yet due, and finished", t, func() {
+		store := newStore(t)
+		addDeliveries(store,
+			&models.Delivery{ID: "d1", SubscriptionID: "sub1", Status: models.DeliveryPending, NextAttemptAt: june, CreatedAt: may},
+			&models.Delivery{ID: "d2", SubscriptionID: "sub1", Status: models.DeliveryPending, NextAttemptAt: may, CreatedAt: may},
+			&models.Delivery{ID: "d3", SubscriptionID: "sub1", Status: models.DeliveryPending, NextAttemptAt: july, CreatedAt: may},
+			&models.Delivery{ID: "d4", SubscriptionID: "sub1", Status: models.DeliveryDelivered, NextAttemptAt: may, CreatedAt: may},
+			&models.Delivery{ID: "d5", SubscriptionID: "sub1", Status: models.DeliveryDead, NextAttemptAt: may, CreatedAt: may},
+		)
+		now := june.Add(time.Hour)
+
+		Convey("When the due deliveries are claimed", func() {
+			values, err := store.ClaimDeliveries(ctx, now, time.Minute, 10)
+
+			Convey("Then the pending deliveries that are due are returned, the longest overdue first", func() {
+				So(err, ShouldBeNil)
+				So(deliveryIDs(values), ShouldResemble, []string{"d2", "d1"})
+				So(values[0].NextAttemptAt.Equal(now.Add(time.Minute)), ShouldBeTrue)
+			})
+
+			Convey("Then they are not claimed again until the lease has expired", func() {
+				values, err := store.ClaimDeliveries(ctx, now, time.Minute, 10)
+				So(err, ShouldBeNil)
+				So(values, ShouldBeEmpty)
+
+				values, err = store.ClaimDeliveries(ctx, now.Add(time.Minute), time.Minute, 10)
+				So(err, ShouldBeNil)
+				So(deliveryIDs(values), ShouldHaveLength, 2)
+			})
+		})
+
+		Convey("When fewer deliveries are claimed than are due", func() {
+			values, err := store.ClaimDeliveries(ctx, now, time.Minute, 1)
+
+			Convey("Then only the longest overdue is returned", func() {
+				So(err, ShouldBeNil)
+				So(deliveryIDs(values), ShouldResemble, []string{"d2"})
+			})
+		})
+	})
+}
+
+func addSubscriptions(store SubscriptionStore, values ...*models.Subscription) {
+	for _, subscription := range values {
+		s := *subscription
+		So(store.AddSubscription(ctx, &s), ShouldBeNil)
+	}
+}
+
+func addDeliveries(store SubscriptionStore, values ...*models.Delivery) {
+	for _, delivery := range values {
+		d := *delivery
+		So(store.AddDelivery(ctx, &d), ShouldBeNil)
+	}
+}
+
+func deliveryIDs(values []models.Delivery) []string {
+	result := []string{}
+	for _, value := range values {
+		result = append(result, value.ID)
+	}
+	return result
+}
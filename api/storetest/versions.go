@@ -0,0 +1,180 @@
+package storetest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/api"
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// VersionStore is the store of collection version snapshots under test
+type VersionStore interface {
+	api.VersionStore
+}
+
+// NewVersionStoreFunc returns an empty version store, in the same way as NewStoreFunc
+type NewVersionStoreFunc func(t *testing.T) VersionStore
+
+// TestVersionStore runs the conformance suite for collection versions against the stores returned by newStore
+func TestVersionStore(t *testing.T, newStore NewVersionStoreFunc) {
+	t.Run("GetVersions", func(t *testing.T) { testGetVersions(t, newStore) })
+	t.Run("GetVersion", func(t *testing.T) { testGetVersion(t, newStore) })
+	t.Run("AddVersion", func(t *testing.T) { testAddVersion(t, newStore) })
+}
+
+func testGetVersions(t *testing.T, newStore NewVersionStoreFunc) {
+
+	Convey("Given a store containing versions of two collections, added out of order", t, func() {
+		store := newStore(t)
+		addVersions(store,
+			newVersion("id1", 2, "Economy (revised)", june),
+			newVersion("id2", 1, "Health", june),
+			newVersion("id1", 1, "Economy", may),
+			newVersion("id1", 3, "Economy (final)", july),
+		)
+
+		Convey("When the versions of a collection are retrieved", func() {
+			versions, totalCount, err := store.GetVersions(ctx, collections.VersionsQueryParams{CollectionID: "id1", Limit: 10})
+
+			Convey("Then only its versions are returned, oldest first", func() {
+				So(err, ShouldBeNil)
+				So(totalCount, ShouldEqual, 3)
+				So(versionNumbers(versions), ShouldResemble, []int{1, 2, 3})
+				So(versions[0].Collection.Name, ShouldEqual, "Economy")
+				So(versions[0].EventID, ShouldEqual, "id1-event-1")
+				So(versions[0].Date.Equal(may), ShouldBeTrue)
+			})
+		})
+
+		Convey("When a page of the versions of a collection is retrieved", func() {
+			versions, totalCount, err := store.GetVersions(ctx, collections.VersionsQueryParams{CollectionID: "id1", Offset: 1, Limit: 1})
+
+			Convey("Then the page is returned with the total number of versions", func() {
+				So(err, ShouldBeNil)
+				So(totalCount, ShouldEqual, 3)
+				So(versionNumbers(versions), ShouldResemble, []int{2})
+			})
+		})
+
+		Convey("When the versions of a collection are retrieved with a limit of zero", func() {
+			versions, totalCount, err := store.GetVersions(ctx, collections.VersionsQueryParams{CollectionID: "id1", Limit: 0})
+
+			Convey("Then only the total number of versions is returned", func() {
+				So(err, ShouldBeNil)
+				So(totalCount, ShouldEqual, 3)
+				So(versions, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the versions of a collection without versions are retrieved", func() {
+			versions, totalCount, err := store.GetVersions(ctx, collections.VersionsQueryParams{CollectionID: "id3", Limit: 10})
+
+			Convey("Then an empty list is returned", func() {
+				So(err, ShouldBeNil)
+				So(totalCount, ShouldEqual, 0)
+				So(versions, ShouldNotBeNil)
+				So(versions, ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+func testGetVersion(t *testing.T, newStore NewVersionStoreFunc) {
+
+	Convey("Given a store containing versions of a collection", t, func() {
+		store := newStore(t)
+		addVersions(store,
+			newVersion("id1", 1, "Economy", may),
+			newVersion("id1", 2, "Economy (revised)", june),
+		)
+
+		Convey("When a version is retrieved", func() {
+			version, err := store.GetVersion(ctx, "id1", 2)
+
+			Convey("Then the snapshot is returned, linked to its event", func() {
+				So(err, ShouldBeNil)
+				So(version.CollectionID, ShouldEqual, "id1")
+				So(version.Version, ShouldEqual, 2)
+				So(version.EventID, ShouldEqual, "id1-event-2")
+				So(version.EventType, ShouldEqual, models.EventUpdated)
+				So(version.Collection.Name, ShouldEqual, "Economy (revised)")
+				So(version.Collection.Version, ShouldEqual, 2)
+			})
+		})
+
+		Convey("When a version that does not exist is retrieved", func() {
+			version, err := store.GetVersion(ctx, "id1", 3)
+
+			Convey("Then a not found error is returned", func() {
+				So(err, ShouldEqual, collections.ErrVersionNotFound)
+				So(version, ShouldBeNil)
+			})
+		})
+	})
+}
+
+func testAddVersion(t *testing.T, newStore NewVersionStoreFunc) {
+
+	Convey("Given a store containing a version of a collection", t, func() {
+		store := newStore(t)
+		version := newVersion("id1", 1, "Economy", may)
+		addVersions(store, version)
+
+		Convey("When the version is modified by the caller", func() {
+			version.Collection.Name = "Changed"
+
+			Convey("Then the stored snapshot is unchanged", func() {
+				stored, err := store.GetVersion(ctx, "id1", 1)
+				So(err, ShouldBeNil)
+				So(stored.Collection.Name, ShouldEqual, "Economy")
+			})
+		})
+
+		Convey("When a second snapshot of the same version is added", func() {
+			err := store.AddVersion(ctx, newVersion("id1", 1, "Other", june))
+
+			Convey("Then an already exists error is returned, and the snapshot is unchanged", func() {
+				So(err, ShouldEqual, collections.ErrVersionAlreadyExists)
+
+				stored, err := store.GetVersion(ctx, "id1", 1)
+				So(err, ShouldBeNil)
+				So(stored.Collection.Name, ShouldEqual, "Economy")
+			})
+		})
+	})
+}
+
+func newVersion(collectionID string, number int, name string, date time.Time) *models.Version {
+	eventType := models.EventUpdated
+	if number == 1 {
+		eventType = models.EventCreated
+	}
+
+	return &models.Version{
+		ID:           models.VersionID(collectionID, number),
+		CollectionID: collectionID,
+		Version:      number,
+		EventID:      fmt.Sprintf("%s-event-%d", collectionID, number),
+		EventType:    eventType,
+		Date:         date,
+		Collection:   models.Collection{ID: collectionID, Name: name, Version: number, ETag: "etag"},
+	}
+}
+
+func addVersions(store VersionStore, values ...*models.Version) {
+	for _, version := range values {
+		So(store.AddVersion(ctx, version), ShouldBeNil)
+	}
+}
+
+func versionNumbers(values []models.Version) []int {
+	numbers := make([]int, 0, len(values))
+	for _, version := range values {
+		numbers = append(numbers, version.Version)
+	}
+	return numbers
+}
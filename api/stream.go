@@ -0,0 +1,169 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/gorilla/mux"
+)
+
+const (
+	eventStreamContentType = "text/event-stream"
+
+	// lastEventIDHeader is sent by a client that reconnects to an event stream, with the ID of the last event it read
+	lastEventIDHeader = "Last-Event-ID"
+
+	// streamHeartbeatInterval is how often a comment is sent on an idle event stream, so that proxies do not close it
+	streamHeartbeatInterval = 15 * time.Second
+
+	// streamRetry is how long a client waits before reconnecting to an event stream that has ended
+	streamRetry = time.Second
+)
+
+// SetupEventStream adds the route to stream the events of a collection as they are recorded. A stream extends the
+// server's write timeout, if it has one, each time it writes, so it stays open until the client disconnects. If the
+// server does not add the connection to the request's context with ConnContext, then the stream ends before the
+// write timeout, and the client is expected to reconnect and resume from the last event it read.
+func (api *API) SetupEventStream(broker EventBroker, writeTimeout time.Duration) {
+	api.eventBroker = broker
+	api.writeTimeout = writeTimeout
+
+	api.Router.HandleFunc("/collections/{collection_id}/events/stream", api.GetEventStreamHandler).Methods(http.MethodGet)
+}
+
+// GetEventStreamHandler streams the events of a collection as Server-Sent Events, as they are recorded. A client that
+// sends the Last-Event-ID header is first sent the events recorded after that event, so that none are missed while it
+// was disconnected, or every event of the collection if the ID is not known.
+func (api *API) GetEventStreamHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	collectionID := mux.Vars(req)["collection_id"]
+	lastEventID := req.Header.Get(lastEventIDHeader)
+	logData := log.Data{"collection_id": collectionID, "last_event_id": lastEventID}
+
+	if err := ValidateUUID(collectionID); err != nil {
+		handleError(ctx, collections.ErrInvalidID, w, req, logData)
+		return
+	}
+
+	if _, err := api.collectionStore.GetCollectionByID(ctx, collectionID, models.AnyETag); err != nil {
+		handleError(ctx, err, w, req, logData)
+		return
+	}
+
+	// subscribe before reading the stored events, so that an event recorded in between is not missed
+	events, unsubscribe := api.eventBroker.Subscribe(collectionID)
+	defer unsubscribe()
+
+	var missed []models.Event
+	if lastEventID != "" {
+		stored, err := api.allEvents(ctx, collectionID)
+		if err != nil {
+			handleError(ctx, err, w, req, logData)
+			return
+		}
+		missed = eventsAfter(stored, lastEventID)
+	}
+
+	w.Header().Set("Content-Type", eventStreamContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+
+	flush := func() {
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	var deadline <-chan time.Time
+	extend := func() {}
+	if api.writeTimeout > 0 {
+		if extendWriteDeadline(ctx, api.writeTimeout) {
+			extend = func() { extendWriteDeadline(ctx, api.writeTimeout) }
+		} else {
+			timer := time.NewTimer(api.writeTimeout * 9 / 10)
+			defer timer.Stop()
+			deadline = timer.C
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", streamRetry.Milliseconds()); err != nil {
+		return
+	}
+
+	sent := make(map[string]bool, len(missed))
+	for i := range missed {
+		extend()
+		if err := writeStreamEvent(w, &missed[i]); err != nil {
+			log.Error(ctx, "event stream stopped, failed to write event", err, logData)
+			return
+		}
+		sent[missed[i].ID] = true
+	}
+	flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-deadline:
+			return
+		case <-heartbeat.C:
+			extend()
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				// the broker has dropped the subscription, so the client reconnects and reads what it missed
+				return
+			}
+			if event.ID != "" && sent[event.ID] {
+				continue
+			}
+			extend()
+			if err := writeStreamEvent(w, &event); err != nil {
+				log.Error(ctx, "event stream stopped, failed to write event", err, logData)
+				return
+			}
+		}
+		flush()
+	}
+}
+
+// eventsAfter returns the events that follow the event with the given ID, or every event if there is no event with
+// the ID, as the events the client has read cannot be known
+func eventsAfter(events []models.Event, id string) []models.Event {
+	for i := range events {
+		if events[i].ID == id {
+			return events[i+1:]
+		}
+	}
+	return events
+}
+
+// writeStreamEvent writes an event in the Server-Sent Events format, with its ID so that the client can resume after it
+func writeStreamEvent(w http.ResponseWriter, event *models.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if event.ID != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", event.ID); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "data: %s\n\n", data)
+	return err
+}
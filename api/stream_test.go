@@ -0,0 +1,196 @@
+package api_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/api"
+	"github.com/ONSdigital/dp-collection-api/api/mock"
+	"github.com/ONSdigital/dp-collection-api/config"
+	"github.com/ONSdigital/dp-collection-api/memory"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/dp-collection-api/pagination"
+	"github.com/gorilla/mux"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetEventStream(t *testing.T) {
+
+	api.Now = func() time.Time {
+		return time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	}
+
+	Convey("Given a collection with two events, and an event stream", t, func() {
+		ids := []string{collectionID, "event-1", "event-2"}
+		api.NewID = func() (string, error) {
+			id := ids[0]
+			ids = ids[1:]
+			return id, nil
+		}
+
+		ctx := context.Background()
+		store := memory.New()
+		live := make(chan models.Event, 10)
+		broker := &mock.EventBrokerMock{
+			SubscribeFunc: func(collectionID string) (<-chan models.Event, func()) {
+				return live, func() {}
+			},
+		}
+		r := mux.NewRouter()
+		api.Setup(ctx, &config.Config{}, r, &pagination.Paginator{}, store, nil).SetupEventStream(broker, time.Minute)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/collections", strings.NewReader(`{"name":"collection 1"}`)))
+		So(w.Code, ShouldEqual, http.StatusCreated)
+		created, err := store.GetCollectionByID(ctx, collectionID, models.AnyETag)
+		So(err, ShouldBeNil)
+		So(replaceCollection(r, created, `{"name":"collection 2"}`).Code, ShouldEqual, http.StatusOK)
+
+		Convey("When a client streams the events, and an event is recorded", func() {
+			live <- models.Event{ID: "event-3", Type: models.EventStateChanged, CollectionID: collectionID, Date: api.Now()}
+			close(live)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/collections/"+collectionID+"/events/stream", nil))
+
+			Convey("Then the new event is sent, with its ID", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Content-Type"), ShouldEqual, "text/event-stream")
+				So(broker.SubscribeCalls()[0].CollectionID, ShouldEqual, collectionID)
+				So(w.Body.String(), ShouldEqual, "retry: 1000\n\n"+
+					"id: event-3\n"+
+					`data: {"type":"STATE_CHANGED","date":"2021-06-01T12:00:00Z"}`+"\n\n")
+			})
+		})
+
+		Convey("When a client resumes the stream after the first event", func() {
+			// the second event is also received from the broker, as it was recorded while the client subscribed
+			live <- models.Event{ID: "event-2", Type: models.EventUpdated, CollectionID: collectionID, Date: api.Now(), Version: 2}
+			close(live)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/collections/"+collectionID+"/events/stream", nil)
+			req.Header.Set("Last-Event-ID", "event-1")
+			r.ServeHTTP(w, req)
+
+			Convey("Then the events recorded after it are sent once", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Body.String(), ShouldEqual, "retry: 1000\n\n"+
+					"id: event-2\n"+
+					`data: {"type":"UPDATED","date":"2021-06-01T12:00:00Z","version":2}`+"\n\n")
+			})
+		})
+
+		Convey("When a client resumes the stream after an unknown event", func() {
+			close(live)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/collections/"+collectionID+"/events/stream", nil)
+			req.Header.Set("Last-Event-ID", "unknown")
+			r.ServeHTTP(w, req)
+
+			Convey("Then every event of the collection is sent", func() {
+				So(w.Body.String(), ShouldContainSubstring, "id: event-1\n")
+				So(w.Body.String(), ShouldContainSubstring, "id: event-2\n")
+			})
+		})
+
+		Convey("When a client streams the events of an invalid collection ID", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/collections/"+invalidCollectionID+"/events/stream", nil))
+
+			Convey("Then a bad request is returned, and no subscription is made", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(broker.SubscribeCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When a client streams the events of a collection that does not exist", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/collections/"+subscriptionID+"/events/stream", nil))
+
+			Convey("Then not found is returned, and no subscription is made", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+				So(broker.SubscribeCalls(), ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+func TestGetEventStream_timeout(t *testing.T) {
+
+	Convey("Given an event stream on a server with a write timeout", t, func() {
+		ctx := context.Background()
+		store := memory.New()
+		So(store.AddCollection(ctx, &models.Collection{ID: collectionID, Name: "collection 1"}), ShouldBeNil)
+		broker := &mock.EventBrokerMock{
+			SubscribeFunc: func(collectionID string) (<-chan models.Event, func()) {
+				return make(chan models.Event), func() {}
+			},
+		}
+		r := mux.NewRouter()
+		api.Setup(ctx, &config.Config{}, r, &pagination.Paginator{}, store, nil).SetupEventStream(broker, 20*time.Millisecond)
+
+		Convey("When a client streams the events, and none are recorded", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/collections/"+collectionID+"/events/stream", nil))
+
+			Convey("Then the stream ends before the write timeout, so that the client reconnects", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Body.String(), ShouldEqual, "retry: 1000\n\n")
+			})
+		})
+	})
+
+	Convey("Given an event stream on a server with a write timeout, that adds the connection to each request", t, func() {
+		ctx := context.Background()
+		store := memory.New()
+		So(store.AddCollection(ctx, &models.Collection{ID: collectionID, Name: "collection 1"}), ShouldBeNil)
+		events := make(chan models.Event, 1)
+		broker := &mock.EventBrokerMock{
+			SubscribeFunc: func(collectionID string) (<-chan models.Event, func()) {
+				return events, func() {}
+			},
+		}
+		r := mux.NewRouter()
+		api.Setup(ctx, &config.Config{}, r, &pagination.Paginator{}, store, nil).SetupEventStream(broker, 50*time.Millisecond)
+
+		server := httptest.NewUnstartedServer(r)
+		server.Config.WriteTimeout = 50 * time.Millisecond
+		server.Config.ConnContext = api.ConnContext
+		server.Start()
+		defer server.Close()
+
+		Convey("When a client streams the events, and one is recorded after the write timeout", func() {
+			resp, err := http.Get(server.URL + "/collections/" + collectionID + "/events/stream")
+			So(err, ShouldBeNil)
+			defer resp.Body.Close()
+
+			time.Sleep(200 * time.Millisecond)
+			events <- models.Event{ID: "event-1", CollectionID: collectionID, Type: models.EventUpdated}
+
+			Convey("Then the stream is still open, and the event is sent", func() {
+				So(resp.StatusCode, ShouldEqual, http.StatusOK)
+
+				reader := bufio.NewReader(resp.Body)
+				lines := []string{}
+				for len(lines) < 3 {
+					line, err := reader.ReadString('\n')
+					So(err, ShouldBeNil)
+					if line != "\n" {
+						lines = append(lines, line)
+					}
+				}
+				So(lines[0], ShouldEqual, "retry: 1000\n")
+				So(lines[1], ShouldEqual, "id: event-1\n")
+				So(lines[2], ShouldStartWith, "data: ")
+			})
+		})
+	})
+}
@@ -0,0 +1,341 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/dp-collection-api/pagination"
+	"github.com/ONSdigital/dp-collection-api/webhooks"
+	dphttp "github.com/ONSdigital/dp-net/v2/http"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/gorilla/mux"
+)
+
+// secretBytes is the number of random bytes in a subscription's secret
+const secretBytes = 32
+
+// subscriptionFields is the set of JSON fields that a subscription request body may contain
+var subscriptionFields = map[string]bool{"url": true, "event_types": true}
+
+// deliveryStatuses is the set of values accepted by the status query parameter of a delivery log
+var deliveryStatuses = map[string]bool{
+	models.DeliveryPending:   true,
+	models.DeliveryDelivered: true,
+	models.DeliveryDead:      true,
+}
+
+// SetupSubscriptions adds the routes to register webhook subscriptions, and to view their deliveries
+func (api *API) SetupSubscriptions(subscriptionStore SubscriptionStore) {
+	api.subscriptionStore = subscriptionStore
+
+	r := api.Router
+	r.HandleFunc("/subscriptions", api.PostSubscriptionHandler).Methods(http.MethodPost)
+	r.HandleFunc("/subscriptions", api.GetSubscriptionsHandler).Methods(http.MethodGet)
+	r.HandleFunc("/subscriptions/dead-letters", api.GetDeadLettersHandler).Methods(http.MethodGet)
+	r.HandleFunc("/subscriptions/{subscription_id}", api.GetSubscriptionHandler).Methods(http.MethodGet)
+	r.HandleFunc("/subscriptions/{subscription_id}", api.DeleteSubscriptionHandler).Methods(http.MethodDelete)
+	r.HandleFunc("/subscriptions/{subscription_id}/deliveries", api.GetDeliveriesHandler).Methods(http.MethodGet)
+}
+
+// PostSubscriptionHandler registers a subscription. The response is the only time that the subscription's secret is
+// returned, as it is needed to verify the signature of each delivery.
+func (api *API) PostSubscriptionHandler(w http.ResponseWriter, req *http.Request) {
+	defer dphttp.DrainBody(req)
+
+	ctx := req.Context()
+	logData := log.Data{}
+
+	subscription, err := ParseSubscription(ctx, req.Body, api.maxRequestBodyBytes, api.allowPrivateHooks)
+	if err != nil {
+		handleError(ctx, err, w, req, logData)
+		return
+	}
+
+	if subscription.ID, err = NewID(); err != nil {
+		handleError(ctx, err, w, req, logData)
+		return
+	}
+	logData["subscription_id"] = subscription.ID
+
+	if subscription.Secret, err = newSecret(); err != nil {
+		handleError(ctx, err, w, req, logData)
+		return
+	}
+	subscription.CreatedAt = Now().UTC()
+
+	if err := api.subscriptionStore.AddSubscription(ctx, subscription); err != nil {
+		handleError(ctx, err, w, req, logData)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	WriteJSONBody(ctx, subscription, w, logData)
+	log.Info(ctx, "add subscription request completed successfully", logData)
+}
+
+// GetSubscriptionsHandler returns a page of subscriptions, without their secrets
+func (api *API) GetSubscriptionsHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logData := log.Data{}
+
+	offset, limit, err := api.paginator.ReadPaginationParameters(req)
+	if err != nil {
+		handleError(ctx, err, w, req, logData)
+		return
+	}
+
+	subscriptions, totalCount, err := api.subscriptionStore.GetSubscriptions(ctx, offset, limit)
+	if err != nil {
+		handleError(ctx, err, w, req, logData)
+		return
+	}
+
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+
+	response := models.SubscriptionsResponse{
+		Items: subscriptions,
+		PaginatedResponse: pagination.PaginatedResponse{
+			Count:      len(subscriptions),
+			Offset:     offset,
+			Limit:      limit,
+			TotalCount: totalCount,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	WriteJSONBody(ctx, response, w, logData)
+}
+
+// GetSubscriptionHandler returns a subscription, without its secret
+func (api *API) GetSubscriptionHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	subscriptionID := mux.Vars(req)["subscription_id"]
+	logData := log.Data{"subscription_id": subscriptionID}
+
+	subscription, err := api.getSubscription(ctx, subscriptionID)
+	if err != nil {
+		handleError(ctx, err, w, req, logData)
+		return
+	}
+	subscription.Secret = ""
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	WriteJSONBody(ctx, subscription, w, logData)
+}
+
+// DeleteSubscriptionHandler removes a subscription, and its deliveries
+func (api *API) DeleteSubscriptionHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	subscriptionID := mux.Vars(req)["subscription_id"]
+	logData := log.Data{"subscription_id": subscriptionID}
+
+	if err := ValidateUUID(subscriptionID); err != nil {
+		handleError(ctx, collections.ErrInvalidSubscriptionID, w, req, logData)
+		return
+	}
+
+	if err := api.subscriptionStore.DeleteSubscription(ctx, subscriptionID); err != nil {
+		handleError(ctx, err, w, req, logData)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Info(ctx, "delete subscription request completed successfully", logData)
+}
+
+// GetDeliveriesHandler returns a page of a subscription's delivery log, most recent first, optionally filtered by
+// delivery status
+func (api *API) GetDeliveriesHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	subscriptionID := mux.Vars(req)["subscription_id"]
+	logData := log.Data{"subscription_id": subscriptionID}
+
+	queryParams, err := readDeliveriesQueryParams(req, api.paginator)
+	if err != nil {
+		handleError(ctx, err, w, req, logData)
+		return
+	}
+	queryParams.SubscriptionID = subscriptionID
+	logData["query_params"] = queryParams
+
+	if _, err := api.getSubscription(ctx, subscriptionID); err != nil {
+		handleError(ctx, err, w, req, logData)
+		return
+	}
+
+	api.writeDeliveries(ctx, w, req, *queryParams, logData)
+}
+
+// GetDeadLettersHandler returns a page of the deliveries to every subscription that failed every attempt, most
+// recent first
+func (api *API) GetDeadLettersHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logData := log.Data{}
+
+	offset, limit, err := api.paginator.ReadPaginationParameters(req)
+	if err != nil {
+		handleError(ctx, err, w, req, logData)
+		return
+	}
+
+	queryParams := collections.DeliveriesQueryParams{Status: models.DeliveryDead, Offset: offset, Limit: limit}
+	logData["query_params"] = queryParams
+
+	api.writeDeliveries(ctx, w, req, queryParams, logData)
+}
+
+func (api *API) writeDeliveries(ctx context.Context, w http.ResponseWriter, req *http.Request, queryParams collections.DeliveriesQueryParams, logData log.Data) {
+	deliveries, totalCount, err := api.subscriptionStore.GetDeliveries(ctx, queryParams)
+	if err != nil {
+		handleError(ctx, err, w, req, logData)
+		return
+	}
+
+	response := models.DeliveriesResponse{
+		Items: deliveries,
+		PaginatedResponse: pagination.PaginatedResponse{
+			Count:      len(deliveries),
+			Offset:     queryParams.Offset,
+			Limit:      queryParams.Limit,
+			TotalCount: totalCount,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	WriteJSONBody(ctx, response, w, logData)
+}
+
+func (api *API) getSubscription(ctx context.Context, subscriptionID string) (*models.Subscription, error) {
+	if err := ValidateUUID(subscriptionID); err != nil {
+		return nil, collections.ErrInvalidSubscriptionID
+	}
+	return api.subscriptionStore.GetSubscriptionByID(ctx, subscriptionID)
+}
+
+// ParseSubscription strictly decodes a subscription request from the given reader, returning ValidationErrors
+// containing every problem found. The body is limited to maxBytes (a value of zero or less disables the limit). The
+// URL must have a public host, unless allowPrivate is set.
+func ParseSubscription(ctx context.Context, reader io.Reader, maxBytes int64, allowPrivate bool) (*models.Subscription, error) {
+	if maxBytes > 0 {
+		reader = io.LimitReader(reader, maxBytes+1)
+	}
+
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if maxBytes > 0 && int64(len(b)) > maxBytes {
+		return nil, ErrRequestBodyTooLarge
+	}
+
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(b, &fields); err != nil {
+		log.Error(ctx, "failed to parse subscription json body", err)
+		return nil, ErrUnableToParseJSON
+	}
+
+	var errs ValidationErrors
+
+	unknownFields := make([]string, 0)
+	for name := range fields {
+		if !subscriptionFields[name] {
+			unknownFields = append(unknownFields, name)
+		}
+	}
+	sort.Strings(unknownFields)
+	for _, name := range unknownFields {
+		errs = append(errs, FieldError{Err: ErrUnknownJSONField, Field: name})
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	var subscription models.Subscription
+	if err = json.Unmarshal(b, &subscription); err != nil {
+		log.Error(ctx, "failed to decode subscription json body", err)
+		return nil, ErrUnableToParseJSON
+	}
+
+	if !isCallbackURL(subscription.URL, allowPrivate) {
+		errs = append(errs, collections.ErrInvalidCallbackURL)
+	}
+
+	for _, eventType := range subscription.EventTypes {
+		if !isLifecycleEventType(eventType) {
+			errs = append(errs, collections.ErrInvalidEventType)
+			break
+		}
+	}
+	if subscription.EventTypes == nil {
+		subscription.EventTypes = []string{}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return &subscription, nil
+}
+
+// isCallbackURL returns true if the value is an absolute http or https URL, with a public host unless private
+// networks are allowed
+func isCallbackURL(value string, allowPrivate bool) bool {
+	u, err := url.Parse(value)
+	if err != nil {
+		return false
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || len(u.Hostname()) == 0 {
+		return false
+	}
+	return allowPrivate || webhooks.IsPublicHost(u.Hostname())
+}
+
+func isLifecycleEventType(eventType string) bool {
+	for _, t := range models.LifecycleEventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func readDeliveriesQueryParams(req *http.Request, paginator Paginator) (*collections.DeliveriesQueryParams, error) {
+	offset, limit, err := paginator.ReadPaginationParameters(req)
+	if err != nil {
+		return nil, err
+	}
+
+	status := req.URL.Query().Get("status")
+	if len(status) > 0 && !deliveryStatuses[status] {
+		return nil, collections.ErrInvalidDeliveryStatus
+	}
+
+	return &collections.DeliveriesQueryParams{
+		Status: status,
+		Offset: offset,
+		Limit:  limit,
+	}, nil
+}
+
+// newSecret returns a random secret for signing deliveries
+func newSecret() (string, error) {
+	b := make([]byte, secretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
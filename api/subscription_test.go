@@ -0,0 +1,246 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/api"
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/config"
+	"github.com/ONSdigital/dp-collection-api/memory"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/dp-collection-api/pagination"
+	"github.com/gorilla/mux"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const subscriptionID = "99887766-5544-3322-1100-ffeeddccbbaa"
+
+func sendSubscriptions(r http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return w
+}
+
+func TestSetupSubscriptions(t *testing.T) {
+	Convey("Given an API", t, func() {
+		api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), &pagination.Paginator{}, memory.New(), nil)
+
+		Convey("When the subscription routes are set up", func() {
+			api.SetupSubscriptions(memory.New())
+
+			Convey("Then the subscription routes are available", func() {
+				So(hasRoute(api.Router, "/subscriptions", "POST"), ShouldBeTrue)
+				So(hasRoute(api.Router, "/subscriptions", "GET"), ShouldBeTrue)
+				So(hasRoute(api.Router, "/subscriptions/dead-letters", "GET"), ShouldBeTrue)
+				So(hasRoute(api.Router, "/subscriptions/"+subscriptionID, "GET"), ShouldBeTrue)
+				So(hasRoute(api.Router, "/subscriptions/"+subscriptionID, "DELETE"), ShouldBeTrue)
+				So(hasRoute(api.Router, "/subscriptions/"+subscriptionID+"/deliveries", "GET"), ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func TestSubscriptions(t *testing.T) {
+
+	api.NewID = func() (string, error) {
+		return subscriptionID, nil
+	}
+	api.Now = func() time.Time {
+		return time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	}
+
+	Convey("Given the subscription routes are set up", t, func() {
+		ctx := context.Background()
+		store := memory.New()
+		r := mux.NewRouter()
+		api.Setup(ctx, &config.Config{}, r, &pagination.Paginator{DefaultLimit: 10, DefaultMaxLimit: 100}, store, nil).SetupSubscriptions(store)
+
+		Convey("When a subscription is registered", func() {
+			w := sendSubscriptions(r, http.MethodPost, "/subscriptions", `{"url":"https://example.com/hook","event_types":["PUBLISHED"]}`)
+
+			var created models.Subscription
+			So(json.Unmarshal(w.Body.Bytes(), &created), ShouldBeNil)
+
+			Convey("Then it is created, and returned with its secret", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(api.ValidateUUID(created.ID), ShouldBeNil)
+				So(created.URL, ShouldEqual, "https://example.com/hook")
+				So(created.EventTypes, ShouldResemble, []string{models.EventPublished})
+				So(created.Secret, ShouldHaveLength, 64)
+				So(created.CreatedAt.Equal(api.Now()), ShouldBeTrue)
+
+				stored, err := store.GetSubscriptionByID(ctx, created.ID)
+				So(err, ShouldBeNil)
+				So(stored.Secret, ShouldEqual, created.Secret)
+			})
+
+			Convey("Then it is listed, without its secret", func() {
+				w := sendSubscriptions(r, http.MethodGet, "/subscriptions", "")
+
+				var response models.SubscriptionsResponse
+				So(json.Unmarshal(w.Body.Bytes(), &response), ShouldBeNil)
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(response.TotalCount, ShouldEqual, 1)
+				So(response.Items[0].ID, ShouldEqual, created.ID)
+				So(response.Items[0].Secret, ShouldBeEmpty)
+				So(w.Body.String(), ShouldNotContainSubstring, "secret")
+			})
+
+			Convey("Then it is returned, without its secret", func() {
+				w := sendSubscriptions(r, http.MethodGet, "/subscriptions/"+created.ID, "")
+
+				var subscription models.Subscription
+				So(json.Unmarshal(w.Body.Bytes(), &subscription), ShouldBeNil)
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(subscription.URL, ShouldEqual, "https://example.com/hook")
+				So(subscription.Secret, ShouldBeEmpty)
+			})
+
+			Convey("And it has deliveries", func() {
+				for i, status := range []string{models.DeliveryDelivered, models.DeliveryDead} {
+					So(store.AddDelivery(ctx, &models.Delivery{
+						ID:             "delivery" + string(rune('1'+i)),
+						SubscriptionID: created.ID,
+						Status:         status,
+						CreatedAt:      api.Now().Add(time.Duration(i) * time.Minute),
+					}), ShouldBeNil)
+				}
+
+				Convey("Then its delivery log is returned, most recent first", func() {
+					w := sendSubscriptions(r, http.MethodGet, "/subscriptions/"+created.ID+"/deliveries", "")
+
+					var response models.DeliveriesResponse
+					So(json.Unmarshal(w.Body.Bytes(), &response), ShouldBeNil)
+					So(w.Code, ShouldEqual, http.StatusOK)
+					So(response.TotalCount, ShouldEqual, 2)
+					So(response.Items[0].ID, ShouldEqual, "delivery2")
+				})
+
+				Convey("Then its delivery log can be filtered by status", func() {
+					w := sendSubscriptions(r, http.MethodGet, "/subscriptions/"+created.ID+"/deliveries?status=delivered", "")
+
+					var response models.DeliveriesResponse
+					So(json.Unmarshal(w.Body.Bytes(), &response), ShouldBeNil)
+					So(response.TotalCount, ShouldEqual, 1)
+					So(response.Items[0].ID, ShouldEqual, "delivery1")
+				})
+
+				Convey("Then the dead letters are returned", func() {
+					w := sendSubscriptions(r, http.MethodGet, "/subscriptions/dead-letters", "")
+
+					var response models.DeliveriesResponse
+					So(json.Unmarshal(w.Body.Bytes(), &response), ShouldBeNil)
+					So(w.Code, ShouldEqual, http.StatusOK)
+					So(response.TotalCount, ShouldEqual, 1)
+					So(response.Items[0].Status, ShouldEqual, models.DeliveryDead)
+				})
+
+				Convey("Then a bad request is returned for an unknown status", func() {
+					w := sendSubscriptions(r, http.MethodGet, "/subscriptions/"+created.ID+"/deliveries?status=failed", "")
+					So(w.Code, ShouldEqual, http.StatusBadRequest)
+					So(errorCode(w), ShouldEqual, models.ErrCodeInvalidParameter)
+				})
+			})
+
+			Convey("And it is deleted", func() {
+				w := sendSubscriptions(r, http.MethodDelete, "/subscriptions/"+created.ID, "")
+
+				Convey("Then it is removed", func() {
+					So(w.Code, ShouldEqual, http.StatusNoContent)
+
+					_, err := store.GetSubscriptionByID(ctx, created.ID)
+					So(err, ShouldEqual, collections.ErrSubscriptionNotFound)
+				})
+			})
+		})
+
+		Convey("When a subscription is registered without event types", func() {
+			w := sendSubscriptions(r, http.MethodPost, "/subscriptions", `{"url":"http://example.com:8080/hook"}`)
+
+			Convey("Then it is created for every event type", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(w.Body.String(), ShouldContainSubstring, `"event_types":[]`)
+			})
+		})
+
+		Convey("When a subscription is registered with a URL on a private network", func() {
+			for _, url := range []string{"http://localhost:8080/hook", "http://127.0.0.1/hook", "http://169.254.169.254/latest/meta-data", "https://10.0.0.1/hook", "http://[::1]/hook"} {
+				w := sendSubscriptions(r, http.MethodPost, "/subscriptions", `{"url":"`+url+`"}`)
+
+				Convey("Then a 400 status is returned for "+url, func() {
+					So(w.Code, ShouldEqual, http.StatusBadRequest)
+					So(errorResponses(w)[0].Code, ShouldEqual, models.ErrCodeInvalidCallbackURL)
+				})
+			}
+		})
+
+		Convey("When a subscription is registered with an invalid URL and event type", func() {
+			w := sendSubscriptions(r, http.MethodPost, "/subscriptions", `{"url":"ftp://example.com","event_types":["DELETED"]}`)
+
+			Convey("Then a 400 status is returned with both problems", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+
+				responses := errorResponses(w)
+				So(responses, ShouldHaveLength, 2)
+				So(responses[0].Code, ShouldEqual, models.ErrCodeInvalidCallbackURL)
+				So(responses[1].Code, ShouldEqual, models.ErrCodeInvalidEventType)
+			})
+		})
+
+		Convey("When a subscription is registered with a secret", func() {
+			w := sendSubscriptions(r, http.MethodPost, "/subscriptions", `{"url":"https://example.com/hook","secret":"chosen"}`)
+
+			Convey("Then a 400 status is returned, as the secret is always generated", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(errorCode(w), ShouldEqual, models.ErrCodeUnknownField)
+			})
+		})
+
+		Convey("When a subscription that does not exist is requested", func() {
+			w := sendSubscriptions(r, http.MethodGet, "/subscriptions/"+subscriptionID, "")
+
+			Convey("Then a 404 status is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+				So(errorCode(w), ShouldEqual, models.ErrCodeSubscriptionNotFound)
+			})
+		})
+
+		Convey("When the deliveries of a subscription that does not exist are requested", func() {
+			w := sendSubscriptions(r, http.MethodGet, "/subscriptions/"+subscriptionID+"/deliveries", "")
+
+			Convey("Then a 404 status is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+			})
+		})
+
+		Convey("When a subscription is deleted with an invalid ID", func() {
+			w := sendSubscriptions(r, http.MethodDelete, "/subscriptions/abc", "")
+
+			Convey("Then a 400 status is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(errorCode(w), ShouldEqual, models.ErrCodeInvalidID)
+			})
+		})
+	})
+
+	Convey("Given the subscription routes are set up to allow private networks", t, func() {
+		store := memory.New()
+		r := mux.NewRouter()
+		api.Setup(context.Background(), &config.Config{WebhookAllowPrivateIPs: true}, r, &pagination.Paginator{}, store, nil).SetupSubscriptions(store)
+
+		Convey("When a subscription is registered with a URL on a private network", func() {
+			w := sendSubscriptions(r, http.MethodPost, "/subscriptions", `{"url":"http://localhost:8080/hook"}`)
+
+			Convey("Then it is created", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+			})
+		})
+	})
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/dp-collection-api/pagination"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/gorilla/mux"
+)
+
+// SetupVersions stores a snapshot of each version of a collection written through the API, and adds the routes to
+// view them
+func (api *API) SetupVersions(versionStore VersionStore) {
+	api.versionStore = versionStore
+
+	r := api.Router
+	r.HandleFunc("/collections/{collection_id}/versions", api.GetVersionsHandler).Methods(http.MethodGet)
+	r.HandleFunc("/collections/{collection_id}/versions/{version}", api.GetVersionHandler).Methods(http.MethodGet)
+}
+
+// GetVersionsHandler handles HTTP requests for the versions of a collection, oldest first
+func (api *API) GetVersionsHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	collectionID := mux.Vars(req)["collection_id"]
+	logData := log.Data{"collection_id": collectionID}
+
+	offset, limit, err := api.paginator.ReadPaginationParameters(req)
+	if err != nil {
+		handleError(ctx, err, w, req, logData)
+		return
+	}
+
+	if err := ValidateUUID(collectionID); err != nil {
+		handleError(ctx, collections.ErrInvalidID, w, req, logData)
+		return
+	}
+
+	if _, err := api.collectionStore.GetCollectionByID(ctx, collectionID, models.AnyETag); err != nil {
+		handleError(ctx, err, w, req, logData)
+		return
+	}
+
+	queryParams := collections.VersionsQueryParams{
+		CollectionID: collectionID,
+		Offset:       offset,
+		Limit:        limit,
+	}
+	logData["query_params"] = queryParams
+
+	versions, totalCount, err := api.versionStore.GetVersions(ctx, queryParams)
+	if err != nil {
+		handleError(ctx, err, w, req, logData)
+		return
+	}
+
+	response := models.VersionsResponse{
+		Items: versions,
+		PaginatedResponse: pagination.PaginatedResponse{
+			Count:      len(versions),
+			Offset:     offset,
+			Limit:      limit,
+			TotalCount: totalCount,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	WriteJSONBody(ctx, response, w, logData)
+}
+
+// GetVersionHandler handles HTTP requests for a single version of a collection
+func (api *API) GetVersionHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	vars := mux.Vars(req)
+	collectionID := vars["collection_id"]
+	logData := log.Data{"collection_id": collectionID, "version": vars["version"]}
+
+	if err := ValidateUUID(collectionID); err != nil {
+		handleError(ctx, collections.ErrInvalidID, w, req, logData)
+		return
+	}
+
+	number, err := strconv.Atoi(vars["version"])
+	if err != nil || number < 1 {
+		handleError(ctx, collections.ErrInvalidVersion, w, req, logData)
+		return
+	}
+
+	if _, err := api.collectionStore.GetCollectionByID(ctx, collectionID, models.AnyETag); err != nil {
+		handleError(ctx, err, w, req, logData)
+		return
+	}
+
+	version, err := api.versionStore.GetVersion(ctx, collectionID, number)
+	if err != nil {
+		handleError(ctx, err, w, req, logData)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	WriteJSONBody(ctx, version, w, logData)
+}
@@ -0,0 +1,209 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/api"
+	"github.com/ONSdigital/dp-collection-api/api/mock"
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/config"
+	"github.com/ONSdigital/dp-collection-api/memory"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/dp-collection-api/pagination"
+	"github.com/gorilla/mux"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSetupVersions(t *testing.T) {
+	Convey("Given an API", t, func() {
+		api := api.Setup(context.Background(), &config.Config{}, mux.NewRouter(), &pagination.Paginator{}, memory.New(), nil)
+
+		Convey("When the version routes are set up", func() {
+			api.SetupVersions(memory.New())
+
+			Convey("Then the version routes are available", func() {
+				So(hasRoute(api.Router, "/collections/"+collectionID+"/versions", "GET"), ShouldBeTrue)
+				So(hasRoute(api.Router, "/collections/"+collectionID+"/versions/1", "GET"), ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func TestVersions(t *testing.T) {
+
+	api.Now = func() time.Time {
+		return time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	}
+
+	Convey("Given a collection that has been created and then updated", t, func() {
+		ids := []string{collectionID, "event-1", "event-2"}
+		api.NewID = func() (string, error) {
+			id := ids[0]
+			ids = ids[1:]
+			return id, nil
+		}
+
+		ctx := context.Background()
+		store := memory.New()
+		r := mux.NewRouter()
+		api.Setup(ctx, &config.Config{}, r, &pagination.Paginator{DefaultLimit: 20, DefaultMaxLimit: 1000}, store, nil).SetupVersions(store)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/collections", strings.NewReader(`{"name":"collection 1"}`)))
+		So(w.Code, ShouldEqual, http.StatusCreated)
+		created, err := store.GetCollectionByID(ctx, collectionID, models.AnyETag)
+		So(err, ShouldBeNil)
+		So(created.Version, ShouldEqual, 1)
+
+		w = replaceCollection(r, created, `{"name":"collection 2"}`)
+		So(w.Code, ShouldEqual, http.StatusOK)
+
+		Convey("Then the collection is at its second version", func() {
+			var response models.Collection
+			So(json.Unmarshal(w.Body.Bytes(), &response), ShouldBeNil)
+			So(response.Version, ShouldEqual, 2)
+
+			updated, err := store.GetCollectionByID(ctx, collectionID, models.AnyETag)
+			So(err, ShouldBeNil)
+			So(updated.Version, ShouldEqual, 2)
+		})
+
+		Convey("Then each event records the version it wrote", func() {
+			events, _, err := store.GetCollectionEvents(ctx, collections.EventsQueryParams{CollectionID: collectionID, Limit: 10})
+			So(err, ShouldBeNil)
+			So(events, ShouldHaveLength, 2)
+			So(events[0].Version, ShouldEqual, 1)
+			So(events[1].Version, ShouldEqual, 2)
+		})
+
+		Convey("When the versions are requested", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/collections/"+collectionID+"/versions", nil))
+
+			Convey("Then a snapshot of each version is returned, oldest first, linked to its event", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+
+				var response models.VersionsResponse
+				So(json.Unmarshal(w.Body.Bytes(), &response), ShouldBeNil)
+				So(response.TotalCount, ShouldEqual, 2)
+				So(response.Items, ShouldHaveLength, 2)
+				So(response.Items[0].Version, ShouldEqual, 1)
+				So(response.Items[0].EventID, ShouldEqual, "event-1")
+				So(response.Items[0].EventType, ShouldEqual, models.EventCreated)
+				So(response.Items[0].Collection.Name, ShouldEqual, "collection 1")
+				So(response.Items[0].Collection.ETag, ShouldEqual, created.ETag)
+				So(response.Items[1].Version, ShouldEqual, 2)
+				So(response.Items[1].EventID, ShouldEqual, "event-2")
+				So(response.Items[1].EventType, ShouldEqual, models.EventUpdated)
+				So(response.Items[1].Collection.Name, ShouldEqual, "collection 2")
+			})
+		})
+
+		Convey("When the first version is requested", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/collections/"+collectionID+"/versions/1", nil))
+
+			Convey("Then the collection is returned as it was first written", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+
+				var response models.Version
+				So(json.Unmarshal(w.Body.Bytes(), &response), ShouldBeNil)
+				So(response.CollectionID, ShouldEqual, collectionID)
+				So(response.Version, ShouldEqual, 1)
+				So(response.Collection.Name, ShouldEqual, "collection 1")
+				So(response.Collection.Version, ShouldEqual, 1)
+			})
+		})
+
+		Convey("When a version that has not been written is requested", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/collections/"+collectionID+"/versions/3", nil))
+
+			Convey("Then a version not found error is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+				So(w.Body.String(), ShouldContainSubstring, models.ErrCodeVersionNotFound)
+			})
+		})
+
+		Convey("When a version is requested with an invalid number", func() {
+			for _, version := range []string{"0", "-1", "abc"} {
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/collections/"+collectionID+"/versions/"+version, nil))
+
+				Convey("Then a bad request error is returned for "+version, func() {
+					So(w.Code, ShouldEqual, http.StatusBadRequest)
+					So(w.Body.String(), ShouldContainSubstring, collections.ErrInvalidVersion.Error())
+				})
+			}
+		})
+
+		Convey("When the versions of a collection that does not exist are requested", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/collections/00112233-4455-6677-8899-000000000000/versions", nil))
+
+			Convey("Then a collection not found error is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+				So(w.Body.String(), ShouldContainSubstring, models.ErrCodeCollectionNotFound)
+			})
+		})
+
+		Convey("When the versions are requested with an invalid collection id", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/collections/abc/versions", nil))
+
+			Convey("Then a bad request error is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+			})
+		})
+	})
+
+	Convey("Given a version store that already has a snapshot of the version being written", t, func() {
+		api.NewID = func() (string, error) {
+			return collectionID, nil
+		}
+
+		ctx := context.Background()
+		store := memory.New()
+		versionStore := &mock.VersionStoreMock{
+			AddVersionFunc: func(ctx context.Context, version *models.Version) error {
+				return collections.ErrVersionAlreadyExists
+			},
+		}
+		r := mux.NewRouter()
+		a := api.Setup(ctx, &config.Config{}, r, &pagination.Paginator{}, store, nil)
+		a.SetupVersions(versionStore)
+
+		post := func() *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/collections", strings.NewReader(`{"name":"collection 1"}`)))
+			return w
+		}
+
+		Convey("When a collection is created without an outbox", func() {
+			w := post()
+
+			Convey("Then the collection is created, as the failure to add the snapshot is only logged", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(versionStore.AddVersionCalls(), ShouldHaveLength, 1)
+				So(versionStore.AddVersionCalls()[0].Version.ID, ShouldEqual, models.VersionID(collectionID, 1))
+			})
+		})
+
+		Convey("When a collection is created with an outbox", func() {
+			a.SetOutbox(store)
+			w := post()
+
+			Convey("Then a conflict error is returned, as the snapshot is added in the same transaction as the write", func() {
+				So(w.Code, ShouldEqual, http.StatusConflict)
+				So(w.Body.String(), ShouldContainSubstring, models.ErrCodeCollectionConflict)
+			})
+		})
+	})
+}
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/dp-collection-api/zebedee"
+	dphttp "github.com/ONSdigital/dp-net/v2/http"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/gorilla/mux"
+)
+
+// zebedeePathPrefix is the path that Zebedee clients use as their Zebedee URL
+const zebedeePathPrefix = "/zebedee"
+
+// zebedeeError is the body of an unsuccessful response to a Zebedee client, in the format returned by Zebedee
+type zebedeeError struct {
+	Message    string `json:"message"`
+	StatusCode int    `json:"statusCode"`
+}
+
+// SetupZebedee adds routes under /zebedee that accept and return the JSON of Zebedee's collection endpoints, so that
+// the clients of Zebedee can be moved to this service one at a time, by changing only their Zebedee URL
+func (api *API) SetupZebedee() {
+	r := api.Router.PathPrefix(zebedeePathPrefix).Subrouter()
+	r.HandleFunc("/collections", api.GetZebedeeCollectionsHandler).Methods(http.MethodGet)
+	r.HandleFunc("/collection", api.PostZebedeeCollectionHandler).Methods(http.MethodPost)
+	r.HandleFunc("/collection/{collection_id}", api.GetZebedeeCollectionHandler).Methods(http.MethodGet)
+	r.HandleFunc("/collection/{collection_id}", api.PutZebedeeCollectionHandler).Methods(http.MethodPut)
+}
+
+// GetZebedeeCollectionsHandler returns every collection as a list of Zebedee collection descriptions. Zebedee does not
+// page its collections, so the collections are read from the store a page at a time.
+func (api *API) GetZebedeeCollectionsHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logData := log.Data{}
+
+	descriptions := []*zebedee.Collection{}
+	for offset := 0; ; {
+		page, totalCount, err := api.collectionStore.GetCollections(ctx, collections.QueryParams{Offset: offset, Limit: exportPageSize})
+		if err != nil {
+			handleZebedeeError(ctx, err, w, logData)
+			return
+		}
+		for i := range page {
+			descriptions = append(descriptions, zebedee.NewCollection(&page[i], nil))
+		}
+
+		offset += len(page)
+		if len(page) == 0 || offset >= totalCount {
+			break
+		}
+	}
+
+	writeZebedeeBody(ctx, descriptions, w, logData)
+}
+
+// GetZebedeeCollectionHandler returns a collection and its events as a Zebedee collection description
+func (api *API) GetZebedeeCollectionHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	collectionID := zebedeeCollectionID(req)
+	logData := log.Data{"collection_id": collectionID}
+
+	collection, err := api.collectionStore.GetCollectionByID(ctx, collectionID, models.AnyETag)
+	if err != nil {
+		handleZebedeeError(ctx, err, w, logData)
+		return
+	}
+
+	events, err := api.allEvents(ctx, collectionID)
+	if err != nil {
+		handleZebedeeError(ctx, err, w, logData)
+		return
+	}
+
+	writeZebedeeBody(ctx, zebedee.NewCollection(collection, events), w, logData)
+}
+
+// PostZebedeeCollectionHandler creates a collection from a Zebedee collection description, with the same validation
+// as a collection created by the collection endpoint
+func (api *API) PostZebedeeCollectionHandler(w http.ResponseWriter, req *http.Request) {
+	defer dphttp.DrainBody(req)
+
+	ctx := req.Context()
+	logData := log.Data{}
+
+	collection, err := parseZebedeeCollection(req.Body, api.maxRequestBodyBytes)
+	if err != nil {
+		handleZebedeeError(ctx, err, w, logData)
+		return
+	}
+
+	// Zebedee clients may send an ID, which is ignored as the ID is always generated
+	collection.ID = ""
+	if err := api.validateCollection(ctx, collection); err != nil {
+		handleZebedeeError(ctx, err, w, logData)
+		return
+	}
+
+	if collection.ID, err = NewID(); err != nil {
+		handleZebedeeError(ctx, err, w, logData)
+		return
+	}
+	logData["collection_id"] = collection.ID
+
+	if err := api.addCollection(ctx, collection); err != nil {
+		handleZebedeeError(ctx, err, w, logData)
+		return
+	}
+
+	writeZebedeeBody(ctx, zebedee.NewCollection(collection, nil), w, logData)
+	log.Info(ctx, "add zebedee collection request completed successfully", logData)
+}
+
+// PutZebedeeCollectionHandler updates a collection from a Zebedee collection description. Zebedee clients do not send
+// an ETag, so the collection is replaced only if it has not changed since it was read by this request.
+func (api *API) PutZebedeeCollectionHandler(w http.ResponseWriter, req *http.Request) {
+	defer dphttp.DrainBody(req)
+
+	ctx := req.Context()
+	collectionID := zebedeeCollectionID(req)
+	logData := log.Data{"collection_id": collectionID}
+
+	existing, err := api.collectionStore.GetCollectionByID(ctx, collectionID, models.AnyETag)
+	if err != nil {
+		handleZebedeeError(ctx, err, w, logData)
+		return
+	}
+
+	collection, err := parseZebedeeCollection(req.Body, api.maxRequestBodyBytes)
+	if err != nil {
+		handleZebedeeError(ctx, err, w, logData)
+		return
+	}
+
+	if existing.PublishDate != nil && collection.PublishDate == nil {
+		handleZebedeeError(ctx, zebedee.ErrUnschedule, w, logData)
+		return
+	}
+
+	collection.ID = collectionID
+	if err := api.replaceCollection(ctx, existing, collection, existing.ETag); err != nil {
+		handleZebedeeError(ctx, err, w, logData)
+		return
+	}
+
+	events, err := api.allEvents(ctx, collectionID)
+	if err != nil {
+		handleZebedeeError(ctx, err, w, logData)
+		return
+	}
+
+	writeZebedeeBody(ctx, zebedee.NewCollection(collection, events), w, logData)
+	log.Info(ctx, "put zebedee collection request completed successfully", logData)
+}
+
+// zebedeeCollectionID returns the collection ID in the path. The Zebedee ID of a collection imported from Zebedee is
+// accepted in place of its ID, so that clients holding Zebedee IDs can still find the collection.
+func zebedeeCollectionID(req *http.Request) string {
+	collectionID := mux.Vars(req)["collection_id"]
+	if ValidateUUID(collectionID) != nil {
+		return zebedee.CollectionID(collectionID)
+	}
+	return collectionID
+}
+
+// parseZebedeeCollection decodes a Zebedee collection description, and returns the collection it describes with its
+// ETag. Unlike ParseCollection, unknown fields are ignored, as Zebedee clients send fields that collections do not have.
+func parseZebedeeCollection(reader io.Reader, maxBytes int64) (*models.Collection, error) {
+	if maxBytes > 0 {
+		reader = io.LimitReader(reader, maxBytes+1)
+	}
+
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if maxBytes > 0 && int64(len(b)) > maxBytes {
+		return nil, ErrRequestBodyTooLarge
+	}
+
+	var description zebedee.Collection
+	if err := json.Unmarshal(b, &description); err != nil {
+		return nil, ErrUnableToParseJSON
+	}
+
+	collection, err := description.Model()
+	if err != nil {
+		return nil, err
+	}
+
+	// the ETag is calculated without the ID, in the same way as ParseCollection
+	collection.ID = ""
+	if collection.ETag, err = collection.Hash(nil); err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
+
+func writeZebedeeBody(ctx context.Context, v interface{}, w http.ResponseWriter, logData log.Data) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := WriteJSONBody(ctx, v, w, logData); err != nil {
+		log.Error(ctx, "failed to write zebedee response", err, logData)
+	}
+}
+
+// handleZebedeeError writes an unsuccessful response in the format returned by Zebedee, with the message of each error
+func handleZebedeeError(ctx context.Context, err error, w http.ResponseWriter, logData log.Data) {
+	errs := []error{err}
+	if validationErrs, ok := err.(ValidationErrors); ok && len(validationErrs) > 0 {
+		errs = validationErrs
+	}
+
+	messages := make([]string, 0, len(errs))
+	for _, e := range errs {
+		messages = append(messages, newErrorResponse(e).Message)
+	}
+
+	status := errorsStatus(errs)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	WriteJSONBody(ctx, zebedeeError{Message: strings.Join(messages, "; "), StatusCode: status}, w, logData)
+	log.Error(ctx, "zebedee request unsuccessful", err, logData)
+}
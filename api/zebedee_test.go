@@ -0,0 +1,202 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/api"
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/config"
+	"github.com/ONSdigital/dp-collection-api/memory"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/dp-collection-api/pagination"
+	"github.com/ONSdigital/dp-collection-api/zebedee"
+	"github.com/gorilla/mux"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type zebedeeError struct {
+	Message    string `json:"message"`
+	StatusCode int    `json:"statusCode"`
+}
+
+func sendZebedee(r http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, "/zebedee"+target, strings.NewReader(body)))
+	return w
+}
+
+func TestSetupZebedee(t *testing.T) {
+	Convey("Given an API", t, func() {
+		r := mux.NewRouter()
+		api := api.Setup(context.Background(), &config.Config{}, r, &pagination.Paginator{}, memory.New(), nil)
+
+		Convey("When the Zebedee routes are set up", func() {
+			api.SetupZebedee()
+
+			Convey("Then the Zebedee collection routes are available", func() {
+				So(hasRoute(api.Router, "/zebedee/collections", "GET"), ShouldBeTrue)
+				So(hasRoute(api.Router, "/zebedee/collection", "POST"), ShouldBeTrue)
+				So(hasRoute(api.Router, "/zebedee/collection/"+collectionID, "GET"), ShouldBeTrue)
+				So(hasRoute(api.Router, "/zebedee/collection/"+collectionID, "PUT"), ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func TestZebedeeCollections(t *testing.T) {
+
+	api.Now = func() time.Time {
+		return time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	}
+
+	Convey("Given the Zebedee routes are set up", t, func() {
+		ctx := context.Background()
+		store := memory.New()
+		r := mux.NewRouter()
+		api.Setup(ctx, &config.Config{MaxRequestBodyBytes: 1024}, r, &pagination.Paginator{}, store, nil).SetupZebedee()
+
+		Convey("When a scheduled collection is created by a Zebedee client", func() {
+			w := sendZebedee(r, http.MethodPost, "/collection",
+				`{"name":"Release 1","type":"scheduled","publishDate":"2030-01-16T09:30:00.000Z","teams":["Economy"],"approvalStatus":"NOT_STARTED"}`)
+
+			var created zebedee.Collection
+			So(json.Unmarshal(w.Body.Bytes(), &created), ShouldBeNil)
+
+			Convey("Then the collection is created, and returned as a Zebedee description", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(created.ID, ShouldNotBeEmpty)
+				So(created.Name, ShouldEqual, "Release 1")
+				So(created.Type, ShouldEqual, zebedee.TypeScheduled)
+				So(created.PublishDate, ShouldEqual, "2030-01-16T09:30:00.000Z")
+				So(created.Teams, ShouldBeEmpty)
+
+				collection, err := store.GetCollectionByID(ctx, created.ID, models.AnyETag)
+				So(err, ShouldBeNil)
+				So(collection.PublishDate.Equal(time.Date(2030, 1, 16, 9, 30, 0, 0, time.UTC)), ShouldBeTrue)
+				So(collection.ETag, ShouldNotBeEmpty)
+			})
+
+			Convey("Then it is listed", func() {
+				w := sendZebedee(r, http.MethodGet, "/collections", "")
+
+				var descriptions []zebedee.Collection
+				So(json.Unmarshal(w.Body.Bytes(), &descriptions), ShouldBeNil)
+				So(descriptions, ShouldHaveLength, 1)
+				So(descriptions[0].ID, ShouldEqual, created.ID)
+			})
+
+			Convey("Then the collection is returned with the event recording its creation", func() {
+				w := sendZebedee(r, http.MethodGet, "/collection/"+created.ID, "")
+
+				var description zebedee.Collection
+				So(json.Unmarshal(w.Body.Bytes(), &description), ShouldBeNil)
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(description.Events, ShouldResemble, []zebedee.Event{{Date: "2021-06-01T12:00:00.000Z", Type: models.EventCreated}})
+			})
+
+			Convey("And it is renamed by a Zebedee client", func() {
+				w := sendZebedee(r, http.MethodPut, "/collection/"+created.ID,
+					`{"id":"`+created.ID+`","name":"Release 2","type":"scheduled","publishDate":"2030-01-17T09:30:00.000Z"}`)
+
+				Convey("Then the collection is updated", func() {
+					So(w.Code, ShouldEqual, http.StatusOK)
+
+					collection, err := store.GetCollectionByID(ctx, created.ID, models.AnyETag)
+					So(err, ShouldBeNil)
+					So(collection.Name, ShouldEqual, "Release 2")
+					So(collection.PublishDate.Equal(time.Date(2030, 1, 17, 9, 30, 0, 0, time.UTC)), ShouldBeTrue)
+
+					var description zebedee.Collection
+					So(json.Unmarshal(w.Body.Bytes(), &description), ShouldBeNil)
+					So(description.Events, ShouldHaveLength, 2)
+					So(description.Events[1].Type, ShouldEqual, models.EventUpdated)
+				})
+			})
+
+			Convey("And it is made manual by a Zebedee client", func() {
+				w := sendZebedee(r, http.MethodPut, "/collection/"+created.ID, `{"name":"Release 1","type":"manual"}`)
+
+				Convey("Then a 400 status is returned, as the publish date cannot be removed", func() {
+					So(w.Code, ShouldEqual, http.StatusBadRequest)
+
+					var response zebedeeError
+					So(json.Unmarshal(w.Body.Bytes(), &response), ShouldBeNil)
+					So(response.Message, ShouldEqual, zebedee.ErrUnschedule.Error())
+					So(response.StatusCode, ShouldEqual, http.StatusBadRequest)
+				})
+			})
+
+			Convey("And another collection with the same name is created", func() {
+				w := sendZebedee(r, http.MethodPost, "/collection", `{"name":"Release 1","type":"manual"}`)
+
+				Convey("Then a 409 status is returned, in Zebedee's error format", func() {
+					So(w.Code, ShouldEqual, http.StatusConflict)
+
+					var response zebedeeError
+					So(json.Unmarshal(w.Body.Bytes(), &response), ShouldBeNil)
+					So(response.Message, ShouldEqual, collections.ErrCollectionNameAlreadyExists.Error())
+					So(response.StatusCode, ShouldEqual, http.StatusConflict)
+				})
+			})
+		})
+
+		Convey("When a collection imported from Zebedee is requested by its Zebedee ID", func() {
+			So(store.AddCollection(ctx, &models.Collection{ID: zebedee.CollectionID("release1-4d5c"), Name: "Imported", ETag: "etag"}), ShouldBeNil)
+			w := sendZebedee(r, http.MethodGet, "/collection/release1-4d5c", "")
+
+			Convey("Then the collection is returned as a manual collection", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+
+				var description zebedee.Collection
+				So(json.Unmarshal(w.Body.Bytes(), &description), ShouldBeNil)
+				So(description.Name, ShouldEqual, "Imported")
+				So(description.Type, ShouldEqual, zebedee.TypeManual)
+				So(description.PublishDate, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When a collection that does not exist is requested", func() {
+			w := sendZebedee(r, http.MethodGet, "/collection/"+collectionID, "")
+
+			Convey("Then a 404 status is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+			})
+		})
+
+		Convey("When a collection is created with invalid fields", func() {
+			w := sendZebedee(r, http.MethodPost, "/collection", `{"name":"","type":"scheduled","publishDate":"2020-01-16T09:30:00.000Z"}`)
+
+			Convey("Then a 400 status is returned with every problem", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+
+				var response zebedeeError
+				So(json.Unmarshal(w.Body.Bytes(), &response), ShouldBeNil)
+				So(response.Message, ShouldEqual, collections.ErrPublishDateInPast.Error()+"; "+collections.ErrCollectionNameEmpty.Error())
+			})
+		})
+
+		Convey("When a scheduled collection is created without a publish date", func() {
+			w := sendZebedee(r, http.MethodPost, "/collection", `{"name":"Release 1","type":"scheduled"}`)
+
+			Convey("Then a 400 status is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(w.Body.String(), ShouldContainSubstring, zebedee.ErrPublishDateRequired.Error())
+			})
+		})
+
+		Convey("When a collection is created with a body that is not JSON", func() {
+			w := sendZebedee(r, http.MethodPost, "/collection", `{"name":`)
+
+			Convey("Then a 400 status is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+			})
+		})
+	})
+}
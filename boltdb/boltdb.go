@@ -0,0 +1,610 @@
+package boltdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	bolt "go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const openTimeout = 5 * time.Second
+
+var (
+	collectionsBucket   = []byte("collections")
+	eventsBucket        = []byte("events")
+	versionsBucket      = []byte("versions")
+	idempotencyBucket   = []byte("idempotency")
+	subscriptionsBucket = []byte("subscriptions")
+	deliveriesBucket    = []byte("deliveries")
+)
+
+// Store is a data store of collections, events, versions, idempotency records and webhook subscriptions held in an embedded
+// bbolt database file, with the same behaviour as the MongoDB store. It is intended for small environments and offline demos.
+type Store struct {
+	db *bolt.DB
+}
+
+// storedCollection is the document held for each collection. The sequence records the order that collections were
+// added in, as collections are keyed by ID.
+type storedCollection struct {
+	Sequence   uint64            `bson:"sequence"`
+	Collection models.Collection `bson:"collection"`
+}
+
+// storedSubscription is the document held for each subscription, with the order that subscriptions were added in
+type storedSubscription struct {
+	Sequence     uint64              `bson:"sequence"`
+	Subscription models.Subscription `bson:"subscription"`
+}
+
+// Open opens the database file at the given path, creating it if it does not exist
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{collectionsBucket, eventsBucket, versionsBucket, idempotencyBucket, subscriptionsBucket, deliveriesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the database file
+func (s *Store) Close(ctx context.Context) error {
+	return s.db.Close()
+}
+
+// Checker is called by the health check library to check that the database can be read
+func (s *Store) Checker(ctx context.Context, state *healthcheck.CheckState) error {
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(collectionsBucket) == nil {
+			return errors.New("collections bucket not found")
+		}
+		return nil
+	})
+	if err != nil {
+		return state.Update(healthcheck.StatusCritical, err.Error(), 0)
+	}
+	return state.Update(healthcheck.StatusOK, "bolt database is healthy", 0)
+}
+
+// GetCollections retrieves the collections matching the name search, in the requested order
+func (s *Store) GetCollections(ctx context.Context, queryParams collections.QueryParams) ([]models.Collection, int, error) {
+	values, err := s.allCollections()
+	if err != nil {
+		return nil, 0, err
+	}
+	return collections.SelectCollections(values, queryParams)
+}
+
+// GetCollectionByName retrieves a single collection by name
+func (s *Store) GetCollectionByName(ctx context.Context, name string) (*models.Collection, error) {
+	values, err := s.allCollections()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, collection := range values {
+		if collection.Name == name {
+			return &collection, nil
+		}
+	}
+	return nil, collections.ErrCollectionNotFound
+}
+
+// GetCollectionByID retrieves a single collection by ID. If the eTag selector does not match, then
+// collections.ErrCollectionConflict is returned.
+func (s *Store) GetCollectionByID(ctx context.Context, id string, eTagSelector string) (*models.Collection, error) {
+	var stored *storedCollection
+
+	err := s.db.View(func(tx *bolt.Tx) (err error) {
+		stored, err = getCollection(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return nil, collections.ErrCollectionNotFound
+	}
+	if eTagSelector != models.AnyETag && eTagSelector != stored.Collection.ETag {
+		return nil, collections.ErrCollectionConflict
+	}
+
+	return &stored.Collection, nil
+}
+
+// AddCollection adds or updates a collection
+func (s *Store) AddCollection(ctx context.Context, collection *models.Collection) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		now := time.Now()
+
+		stored, err := getCollection(tx, collection.ID)
+		if err != nil {
+			return err
+		}
+		if stored == nil {
+			sequence, err := tx.Bucket(collectionsBucket).NextSequence()
+			if err != nil {
+				return err
+			}
+			stored = &storedCollection{
+				Sequence:   sequence,
+				Collection: models.Collection{ID: collection.ID, CreatedAt: now},
+			}
+		}
+
+		stored.Collection.Set(collection)
+		stored.Collection.LastUpdated = now
+		return putCollection(tx, stored)
+	})
+}
+
+// ReplaceCollection replaces an existing collection. If there is no collection with the ID and eTag,
+// then collections.ErrCollectionConflict is returned. The check and the update are made in a single
+// transaction, so a concurrent update cannot be overwritten.
+func (s *Store) ReplaceCollection(ctx context.Context, collection *models.Collection, eTagSelector string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		stored, err := getCollection(tx, collection.ID)
+		if err != nil {
+			return err
+		}
+		if stored == nil || stored.Collection.ETag != eTagSelector {
+			return collections.ErrCollectionConflict
+		}
+
+		stored.Collection.Set(collection)
+		stored.Collection.LastUpdated = time.Now()
+		return putCollection(tx, stored)
+	})
+}
+
+// GetCollectionEvents retrieves the events for a collection, in date order
+func (s *Store) GetCollectionEvents(ctx context.Context, queryParams collections.EventsQueryParams) ([]models.Event, int, error) {
+	var values []models.Event
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(eventsBucket).ForEach(func(k, v []byte) error {
+			var event models.Event
+			if err := bson.Unmarshal(v, &event); err != nil {
+				return err
+			}
+			values = append(values, event)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	values, totalCount := collections.SelectEvents(values, queryParams)
+	return values, totalCount, nil
+}
+
+// AddEvent adds an event to a collection
+func (s *Store) AddEvent(ctx context.Context, event *models.Event) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(eventsBucket)
+
+		// events are keyed by sequence, so that they are held in the order they were added
+		sequence, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		b, err := bson.Marshal(event)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(sequenceKey(sequence), b)
+	})
+}
+
+// GetVersions retrieves a page of the versions of a collection, oldest first
+func (s *Store) GetVersions(ctx context.Context, queryParams collections.VersionsQueryParams) ([]models.Version, int, error) {
+	var values []models.Version
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		// versions are keyed by collection ID and version number, so only the collection's versions are read
+		c := tx.Bucket(versionsBucket).Cursor()
+		prefix := []byte(queryParams.CollectionID + ":")
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var version models.Version
+			if err := bson.Unmarshal(v, &version); err != nil {
+				return err
+			}
+			values = append(values, version)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	values, totalCount := collections.SelectVersions(values, queryParams)
+	return values, totalCount, nil
+}
+
+// GetVersion retrieves a single version of a collection
+func (s *Store) GetVersion(ctx context.Context, collectionID string, version int) (*models.Version, error) {
+	var result models.Version
+	var found bool
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(versionsBucket).Get([]byte(models.VersionID(collectionID, version)))
+		if v == nil {
+			return nil
+		}
+		found = true
+		return bson.Unmarshal(v, &result)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, collections.ErrVersionNotFound
+	}
+
+	return &result, nil
+}
+
+// AddVersion adds a snapshot of a version of a collection. If the version already has a snapshot, then
+// collections.ErrVersionAlreadyExists is returned.
+func (s *Store) AddVersion(ctx context.Context, version *models.Version) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(versionsBucket)
+		if bucket.Get([]byte(version.ID)) != nil {
+			return collections.ErrVersionAlreadyExists
+		}
+
+		b, err := bson.Marshal(version)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(version.ID), b)
+	})
+}
+
+// GetCollectionStats counts the collections in each state at the given time, and those due to be published
+// within the upcoming window
+func (s *Store) GetCollectionStats(ctx context.Context, now time.Time, upcomingWindow time.Duration) (*models.CollectionStats, error) {
+	values, err := s.allCollections()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.CollectionStats{}
+	for i := range values {
+		stats.Add(&values[i], now, upcomingWindow)
+	}
+	return stats, nil
+}
+
+// AddIdempotencyRecord adds a record for a new idempotency key. An expired record for the same key is replaced,
+// but if an unexpired record exists then collections.ErrIdempotencyKeyExists is returned.
+func (s *Store) AddIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		existing, err := getIdempotencyRecord(tx, record.Key)
+		if err != nil {
+			return err
+		}
+		if existing != nil && existing.ExpiresAt.After(time.Now()) {
+			return collections.ErrIdempotencyKeyExists
+		}
+		return putIdempotencyRecord(tx, record)
+	})
+}
+
+// GetIdempotencyRecord retrieves the unexpired record for an idempotency key
+func (s *Store) GetIdempotencyRecord(ctx context.Context, key string) (*models.IdempotencyRecord, error) {
+	var record *models.IdempotencyRecord
+
+	err := s.db.View(func(tx *bolt.Tx) (err error) {
+		record, err = getIdempotencyRecord(tx, key)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || !record.ExpiresAt.After(time.Now()) {
+		return nil, collections.ErrIdempotencyRecordNotFound
+	}
+	return record, nil
+}
+
+// UpdateIdempotencyRecord stores the response for an idempotency key
+func (s *Store) UpdateIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		existing, err := getIdempotencyRecord(tx, record.Key)
+		if err != nil || existing == nil {
+			return err
+		}
+		return putIdempotencyRecord(tx, record)
+	})
+}
+
+// DeleteIdempotencyRecord removes the record for an idempotency key, so that the key can be used again
+func (s *Store) DeleteIdempotencyRecord(ctx context.Context, key string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(idempotencyBucket).Delete([]byte(key))
+	})
+}
+
+// GetSubscriptions retrieves a page of subscriptions, in the order they were added
+func (s *Store) GetSubscriptions(ctx context.Context, offset, limit int) ([]models.Subscription, int, error) {
+	var stored []storedSubscription
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(subscriptionsBucket).ForEach(func(k, v []byte) error {
+			var subscription storedSubscription
+			if err := bson.Unmarshal(v, &subscription); err != nil {
+				return err
+			}
+			stored = append(stored, subscription)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sort.Slice(stored, func(i, j int) bool {
+		return stored[i].Sequence < stored[j].Sequence
+	})
+
+	values := make([]models.Subscription, 0, len(stored))
+	for _, subscription := range stored {
+		values = append(values, subscription.Subscription)
+	}
+
+	values, totalCount := collections.SelectSubscriptions(values, offset, limit)
+	return values, totalCount, nil
+}
+
+// GetSubscriptionByID retrieves a single subscription by ID
+func (s *Store) GetSubscriptionByID(ctx context.Context, id string) (*models.Subscription, error) {
+	var stored storedSubscription
+	var found bool
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(subscriptionsBucket).Get([]byte(id))
+		if v == nil {
+			return nil
+		}
+		found = true
+		return bson.Unmarshal(v, &stored)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, collections.ErrSubscriptionNotFound
+	}
+
+	return &stored.Subscription, nil
+}
+
+// AddSubscription adds a subscription
+func (s *Store) AddSubscription(ctx context.Context, subscription *models.Subscription) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(subscriptionsBucket)
+
+		sequence, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		b, err := bson.Marshal(storedSubscription{Sequence: sequence, Subscription: *subscription})
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(subscription.ID), b)
+	})
+}
+
+// DeleteSubscription removes a subscription and its deliveries. If there is no subscription with the ID, then
+// collections.ErrSubscriptionNotFound is returned.
+func (s *Store) DeleteSubscription(ctx context.Context, id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(subscriptionsBucket)
+		if bucket.Get([]byte(id)) == nil {
+			return collections.ErrSubscriptionNotFound
+		}
+		if err := bucket.Delete([]byte(id)); err != nil {
+			return err
+		}
+
+		deliveries, err := allDeliveries(tx)
+		if err != nil {
+			return err
+		}
+		for _, delivery := range deliveries {
+			if delivery.SubscriptionID == id {
+				if err := tx.Bucket(deliveriesBucket).Delete([]byte(delivery.ID)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// GetDeliveries retrieves the deliveries matching the query parameters, most recent first
+func (s *Store) GetDeliveries(ctx context.Context, queryParams collections.DeliveriesQueryParams) ([]models.Delivery, int, error) {
+	var values []models.Delivery
+
+	err := s.db.View(func(tx *bolt.Tx) (err error) {
+		values, err = allDeliveries(tx)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	values, totalCount := collections.SelectDeliveries(values, queryParams)
+	return values, totalCount, nil
+}
+
+// AddDelivery adds a delivery
+func (s *Store) AddDelivery(ctx context.Context, delivery *models.Delivery) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putDelivery(tx, delivery)
+	})
+}
+
+// UpdateDelivery stores the outcome of an attempt to make a delivery. A delivery that has been removed, along with
+// its subscription, is not added again.
+func (s *Store) UpdateDelivery(ctx context.Context, delivery *models.Delivery) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(deliveriesBucket).Get([]byte(delivery.ID)) == nil {
+			return nil
+		}
+		return putDelivery(tx, delivery)
+	})
+}
+
+// ClaimDeliveries returns up to limit pending deliveries that are due at the given time, the longest overdue first,
+// and postpones their next attempt by the lease, so that they are not claimed again while they are being made. The
+// deliveries are selected and postponed in a single transaction.
+func (s *Store) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Delivery, error) {
+	var due []models.Delivery
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		values, err := allDeliveries(tx)
+		if err != nil {
+			return err
+		}
+
+		due = collections.SelectDueDeliveries(values, now, limit)
+		for i := range due {
+			due[i].NextAttemptAt = now.Add(lease)
+			if err := putDelivery(tx, &due[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return due, nil
+}
+
+// allCollections returns every collection, in the order they were added
+func (s *Store) allCollections() ([]models.Collection, error) {
+	var stored []storedCollection
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(collectionsBucket).ForEach(func(k, v []byte) error {
+			var collection storedCollection
+			if err := bson.Unmarshal(v, &collection); err != nil {
+				return err
+			}
+			stored = append(stored, collection)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(stored, func(i, j int) bool {
+		return stored[i].Sequence < stored[j].Sequence
+	})
+
+	values := make([]models.Collection, 0, len(stored))
+	for _, collection := range stored {
+		values = append(values, collection.Collection)
+	}
+	return values, nil
+}
+
+func getCollection(tx *bolt.Tx, id string) (*storedCollection, error) {
+	v := tx.Bucket(collectionsBucket).Get([]byte(id))
+	if v == nil {
+		return nil, nil
+	}
+
+	var stored storedCollection
+	if err := bson.Unmarshal(v, &stored); err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+func putCollection(tx *bolt.Tx, stored *storedCollection) error {
+	b, err := bson.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(collectionsBucket).Put([]byte(stored.Collection.ID), b)
+}
+
+func getIdempotencyRecord(tx *bolt.Tx, key string) (*models.IdempotencyRecord, error) {
+	v := tx.Bucket(idempotencyBucket).Get([]byte(key))
+	if v == nil {
+		return nil, nil
+	}
+
+	var record models.IdempotencyRecord
+	if err := bson.Unmarshal(v, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func putIdempotencyRecord(tx *bolt.Tx, record *models.IdempotencyRecord) error {
+	b, err := bson.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(idempotencyBucket).Put([]byte(record.Key), b)
+}
+
+func allDeliveries(tx *bolt.Tx) ([]models.Delivery, error) {
+	var values []models.Delivery
+
+	err := tx.Bucket(deliveriesBucket).ForEach(func(k, v []byte) error {
+		var delivery models.Delivery
+		if err := bson.Unmarshal(v, &delivery); err != nil {
+			return err
+		}
+		values = append(values, delivery)
+		return nil
+	})
+	return values, err
+}
+
+func putDelivery(tx *bolt.Tx, delivery *models.Delivery) error {
+	b, err := bson.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(deliveriesBucket).Put([]byte(delivery.ID), b)
+}
+
+func sequenceKey(sequence uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, sequence)
+	return key
+}
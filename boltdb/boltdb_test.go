@@ -0,0 +1,122 @@
+package boltdb_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/boltdb"
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var ctx = context.Background()
+
+func TestStore(t *testing.T) {
+
+	Convey("Given a bolt store containing some collections", t, func() {
+		path := filepath.Join(t.TempDir(), "collections.db")
+		store, err := boltdb.Open(path)
+		So(err, ShouldBeNil)
+
+		publishDate := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+		for _, id := range []string{"c", "a", "b"} {
+			So(store.AddCollection(ctx, &models.Collection{ID: id, Name: "collection " + id, PublishDate: &publishDate, ETag: "etag-" + id}), ShouldBeNil)
+		}
+		So(store.AddEvent(ctx, &models.Event{ID: "1", CollectionID: "a", Type: "CREATED", Date: publishDate}), ShouldBeNil)
+
+		Convey("When the store is closed and reopened", func() {
+			So(store.Close(ctx), ShouldBeNil)
+			store, err = boltdb.Open(path)
+			So(err, ShouldBeNil)
+
+			Convey("Then the collections are still there, in the order they were added", func() {
+				values, total, err := store.GetCollections(ctx, collections.QueryParams{Limit: 10})
+				So(err, ShouldBeNil)
+				So(total, ShouldEqual, 3)
+				So(values[0].ID, ShouldEqual, "c")
+				So(values[1].ID, ShouldEqual, "a")
+				So(values[2].ID, ShouldEqual, "b")
+				So(*values[0].PublishDate, ShouldEqual, publishDate)
+				So(values[0].CreatedAt.IsZero(), ShouldBeFalse)
+			})
+
+			Convey("Then the events are still there", func() {
+				values, total, err := store.GetCollectionEvents(ctx, collections.EventsQueryParams{CollectionID: "a", Limit: 10})
+				So(err, ShouldBeNil)
+				So(total, ShouldEqual, 1)
+				So(values[0].Type, ShouldEqual, "CREATED")
+			})
+		})
+
+		Convey("When a collection is replaced with the current eTag", func() {
+			err := store.ReplaceCollection(ctx, &models.Collection{ID: "a", Name: "renamed", ETag: "etag-new"}, "etag-a")
+			So(err, ShouldBeNil)
+
+			Convey("Then the collection is updated", func() {
+				collection, err := store.GetCollectionByID(ctx, "a", "etag-new")
+				So(err, ShouldBeNil)
+				So(collection.Name, ShouldEqual, "renamed")
+				So(*collection.PublishDate, ShouldEqual, publishDate)
+			})
+
+			Convey("Then replacing it again with the previous eTag is rejected", func() {
+				err := store.ReplaceCollection(ctx, &models.Collection{ID: "a", Name: "lost update"}, "etag-a")
+				So(err, ShouldEqual, collections.ErrCollectionConflict)
+			})
+		})
+
+		Convey("When a collection that does not exist is retrieved", func() {
+			_, err := store.GetCollectionByID(ctx, "d", models.AnyETag)
+
+			Convey("Then a not found error is returned", func() {
+				So(err, ShouldEqual, collections.ErrCollectionNotFound)
+			})
+		})
+
+		Convey("When the health of the store is checked", func() {
+			state := healthcheck.NewCheckState("Bolt DB")
+			err := store.Checker(ctx, state)
+
+			Convey("Then the store is healthy", func() {
+				So(err, ShouldBeNil)
+				So(state.Status(), ShouldEqual, healthcheck.StatusOK)
+			})
+		})
+
+		Reset(func() {
+			store.Close(ctx)
+		})
+	})
+}
+
+func TestStore_idempotency(t *testing.T) {
+
+	Convey("Given a bolt store containing an unexpired idempotency record", t, func() {
+		store, err := boltdb.Open(filepath.Join(t.TempDir(), "collections.db"))
+		So(err, ShouldBeNil)
+		defer store.Close(ctx)
+
+		record := &models.IdempotencyRecord{Key: "key", RequestHash: "hash", ExpiresAt: time.Now().Add(time.Hour)}
+		So(store.AddIdempotencyRecord(ctx, record), ShouldBeNil)
+
+		Convey("Then a record with the same key cannot be added", func() {
+			err := store.AddIdempotencyRecord(ctx, &models.IdempotencyRecord{Key: "key", ExpiresAt: time.Now().Add(time.Hour)})
+			So(err, ShouldEqual, collections.ErrIdempotencyKeyExists)
+		})
+
+		Convey("Then the response can be stored and retrieved", func() {
+			record.StatusCode = 201
+			record.Body = []byte(`{"id":"a"}`)
+			So(store.UpdateIdempotencyRecord(ctx, record), ShouldBeNil)
+
+			stored, err := store.GetIdempotencyRecord(ctx, "key")
+			So(err, ShouldBeNil)
+			So(stored.StatusCode, ShouldEqual, 201)
+			So(stored.Body, ShouldResemble, record.Body)
+		})
+	})
+}
@@ -0,0 +1,30 @@
+package boltdb_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ONSdigital/dp-collection-api/api/storetest"
+	"github.com/ONSdigital/dp-collection-api/boltdb"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.TestCollectionStore(t, func(t *testing.T) storetest.Store {
+		return openStore(t)
+	})
+	storetest.TestSubscriptionStore(t, func(t *testing.T) storetest.SubscriptionStore {
+		return openStore(t)
+	})
+	storetest.TestVersionStore(t, func(t *testing.T) storetest.VersionStore {
+		return openStore(t)
+	})
+}
+
+func openStore(t *testing.T) *boltdb.Store {
+	store, err := boltdb.Open(filepath.Join(t.TempDir(), "collections.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close(ctx) })
+	return store
+}
@@ -0,0 +1,234 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/api"
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// CollectionStore wraps an api.CollectionStore, caching the collections read by ID and by name. Entries expire after
+// the TTL, and the least recently used entry is evicted once the cache is full. A write removes the collection from
+// this cache, and is passed to the notifier to remove it from any others. A write made in a transaction is only
+// visible once it commits, so the store is also an api.EventListener, and removes the collection again once the API
+// reports that the write has been committed.
+//
+// Only collections that were found are cached. A name that is not found is always looked up in the store, so that a
+// collection added by another instance is not missed when checking that a new name is unique.
+type CollectionStore struct {
+	store      api.CollectionStore
+	notifier   Notifier
+	ttl        time.Duration
+	maxEntries int
+
+	mutex      sync.Mutex
+	entries    map[string]*entry
+	names      map[string]string
+	recent     *list.List
+	generation uint64
+}
+
+// entry is a cached collection, and its position in the list of recently used entries
+type entry struct {
+	collection models.Collection
+	expiresAt  time.Time
+	element    *list.Element
+}
+
+// NewCollectionStore returns a caching decorator for the given collection store. A maxEntries of zero or less
+// leaves the number of entries unbounded.
+func NewCollectionStore(store api.CollectionStore, notifier Notifier, ttl time.Duration, maxEntries int) *CollectionStore {
+	s := &CollectionStore{
+		store:      store,
+		notifier:   notifier,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    map[string]*entry{},
+		names:      map[string]string{},
+		recent:     list.New(),
+	}
+	notifier.Subscribe(s.remove)
+	return s
+}
+
+// GetCollections reads from the wrapped store, as lists are not cached
+func (s *CollectionStore) GetCollections(ctx context.Context, queryParams collections.QueryParams) ([]models.Collection, int, error) {
+	return s.store.GetCollections(ctx, queryParams)
+}
+
+// GetCollectionEvents reads from the wrapped store, as events are not cached
+func (s *CollectionStore) GetCollectionEvents(ctx context.Context, queryParams collections.EventsQueryParams) ([]models.Event, int, error) {
+	return s.store.GetCollectionEvents(ctx, queryParams)
+}
+
+// AddEvent adds the event to the wrapped store, as events are not cached
+func (s *CollectionStore) AddEvent(ctx context.Context, event *models.Event) error {
+	return s.store.AddEvent(ctx, event)
+}
+
+// GetCollectionByID returns the cached collection if its ETag matches the selector. Otherwise the collection is read
+// from the wrapped store, so that a stale entry never causes a conflict.
+func (s *CollectionStore) GetCollectionByID(ctx context.Context, id string, eTagSelector string) (*models.Collection, error) {
+	if collection, ok := s.get(id); ok && (eTagSelector == models.AnyETag || eTagSelector == collection.ETag) {
+		return collection, nil
+	}
+
+	generation := s.currentGeneration()
+	collection, err := s.store.GetCollectionByID(ctx, id, eTagSelector)
+	if err != nil {
+		return nil, err
+	}
+	s.put(collection, generation)
+	return collection, nil
+}
+
+// GetCollectionByName returns the cached collection with the name, or reads it from the wrapped store
+func (s *CollectionStore) GetCollectionByName(ctx context.Context, name string) (*models.Collection, error) {
+	if collection, ok := s.getByName(name); ok {
+		return collection, nil
+	}
+
+	generation := s.currentGeneration()
+	collection, err := s.store.GetCollectionByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	s.put(collection, generation)
+	return collection, nil
+}
+
+// AddCollection adds the collection to the wrapped store, and invalidates any cached copy
+func (s *CollectionStore) AddCollection(ctx context.Context, collection *models.Collection) error {
+	err := s.store.AddCollection(ctx, collection)
+	s.invalidate(ctx, collection.ID)
+	return err
+}
+
+// ReplaceCollection replaces the collection in the wrapped store, and invalidates any cached copy. The cached copy is
+// invalidated even if the replacement fails, as a conflict shows that it may be stale.
+func (s *CollectionStore) ReplaceCollection(ctx context.Context, collection *models.Collection, eTagSelector string) error {
+	err := s.store.ReplaceCollection(ctx, collection, eTagSelector)
+	s.invalidate(ctx, collection.ID)
+	return err
+}
+
+// HandleCollectionEvent invalidates the collection once a write to it has been committed, so that a copy read from
+// the wrapped store before the commit is not served until the TTL expires
+func (s *CollectionStore) HandleCollectionEvent(ctx context.Context, event *models.Event, collection *models.Collection) error {
+	s.invalidate(ctx, collection.ID)
+	return nil
+}
+
+// invalidate removes the collection from this cache, and notifies the other caches. A failure to notify is logged
+// rather than returned, as the write itself has been made and other caches will expire the entry after the TTL.
+func (s *CollectionStore) invalidate(ctx context.Context, id string) {
+	s.remove(id)
+	if err := s.notifier.Notify(ctx, id); err != nil {
+		log.Error(ctx, "failed to notify caches of a collection change", err, log.Data{"collection_id": id})
+	}
+}
+
+func (s *CollectionStore) get(id string) (*models.Collection, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	e, ok := s.entries[id]
+	if !ok {
+		return nil, false
+	}
+	if !time.Now().Before(e.expiresAt) {
+		s.removeEntry(id, e)
+		return nil, false
+	}
+
+	s.recent.MoveToFront(e.element)
+	collection := copyCollection(&e.collection)
+	return &collection, true
+}
+
+func (s *CollectionStore) getByName(name string) (*models.Collection, bool) {
+	s.mutex.Lock()
+	id, ok := s.names[name]
+	s.mutex.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	collection, ok := s.get(id)
+	if !ok || collection.Name != name {
+		return nil, false
+	}
+	return collection, true
+}
+
+// put caches the collection, unless a collection has been invalidated since the given generation. That collection
+// may be the one being cached, in which case the copy read from the store could already be stale.
+func (s *CollectionStore) put(collection *models.Collection, generation uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if generation != s.generation {
+		return
+	}
+
+	if e, ok := s.entries[collection.ID]; ok {
+		s.removeEntry(collection.ID, e)
+	}
+
+	e := &entry{
+		collection: copyCollection(collection),
+		expiresAt:  time.Now().Add(s.ttl),
+		element:    s.recent.PushFront(collection.ID),
+	}
+	s.entries[collection.ID] = e
+	s.names[collection.Name] = collection.ID
+
+	for s.maxEntries > 0 && s.recent.Len() > s.maxEntries {
+		oldest := s.recent.Back().Value.(string)
+		s.removeEntry(oldest, s.entries[oldest])
+	}
+}
+
+// remove is called for each change, made by this instance or another
+func (s *CollectionStore) remove(id string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.generation++
+	if e, ok := s.entries[id]; ok {
+		s.removeEntry(id, e)
+	}
+}
+
+func (s *CollectionStore) removeEntry(id string, e *entry) {
+	s.recent.Remove(e.element)
+	delete(s.entries, id)
+	if s.names[e.collection.Name] == id {
+		delete(s.names, e.collection.Name)
+	}
+}
+
+func (s *CollectionStore) currentGeneration() uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.generation
+}
+
+func copyCollection(collection *models.Collection) models.Collection {
+	c := *collection
+	if collection.PublishDate != nil {
+		publishDate := *collection.PublishDate
+		c.PublishDate = &publishDate
+	}
+	if collection.PublishResult != nil {
+		publishResult := *collection.PublishResult
+		c.PublishResult = &publishResult
+	}
+	return c
+}
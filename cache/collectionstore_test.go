@@ -0,0 +1,308 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/api/mock"
+	"github.com/ONSdigital/dp-collection-api/cache"
+	cacheMock "github.com/ONSdigital/dp-collection-api/cache/mock"
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var ctx = context.Background()
+
+func newStoreMock() *mock.CollectionStoreMock {
+	return &mock.CollectionStoreMock{
+		GetCollectionByIDFunc: func(ctx context.Context, id string, eTagSelector string) (*models.Collection, error) {
+			if id == "missing" {
+				return nil, collections.ErrCollectionNotFound
+			}
+			return &models.Collection{ID: id, Name: "name-" + id, ETag: "etag-" + id}, nil
+		},
+		GetCollectionByNameFunc: func(ctx context.Context, name string) (*models.Collection, error) {
+			if name == "missing" {
+				return nil, collections.ErrCollectionNotFound
+			}
+			return &models.Collection{ID: "id-" + name, Name: name, ETag: "etag"}, nil
+		},
+		AddCollectionFunc: func(ctx context.Context, collection *models.Collection) error {
+			return nil
+		},
+		ReplaceCollectionFunc: func(ctx context.Context, collection *models.Collection, eTagSelector string) error {
+			return collections.ErrCollectionConflict
+		},
+	}
+}
+
+func TestCollectionStore_GetCollectionByID(t *testing.T) {
+
+	Convey("Given a cached collection store", t, func() {
+		store := newStoreMock()
+		cached := cache.NewCollectionStore(store, cache.NewLocalNotifier(), time.Minute, 10)
+
+		Convey("When a collection is read twice", func() {
+			first, err := cached.GetCollectionByID(ctx, "123", models.AnyETag)
+			So(err, ShouldBeNil)
+			second, err := cached.GetCollectionByID(ctx, "123", models.AnyETag)
+			So(err, ShouldBeNil)
+
+			Convey("Then the wrapped store is only read once", func() {
+				So(store.GetCollectionByIDCalls(), ShouldHaveLength, 1)
+				So(second, ShouldResemble, first)
+			})
+
+			Convey("Then changes to a returned collection are not cached", func() {
+				second.Name = "changed"
+				third, err := cached.GetCollectionByID(ctx, "123", models.AnyETag)
+				So(err, ShouldBeNil)
+				So(third.Name, ShouldEqual, "name-123")
+			})
+
+			Convey("Then the collection is also cached by name", func() {
+				collection, err := cached.GetCollectionByName(ctx, "name-123")
+				So(err, ShouldBeNil)
+				So(collection.ID, ShouldEqual, "123")
+				So(store.GetCollectionByNameCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When a cached collection is read with a matching eTag", func() {
+			cached.GetCollectionByID(ctx, "123", models.AnyETag)
+			collection, err := cached.GetCollectionByID(ctx, "123", "etag-123")
+
+			Convey("Then the cached collection is returned", func() {
+				So(err, ShouldBeNil)
+				So(collection.ID, ShouldEqual, "123")
+				So(store.GetCollectionByIDCalls(), ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When a cached collection is read with a different eTag", func() {
+			cached.GetCollectionByID(ctx, "123", models.AnyETag)
+			cached.GetCollectionByID(ctx, "123", "other")
+
+			Convey("Then the wrapped store is read, so that it decides whether there is a conflict", func() {
+				So(store.GetCollectionByIDCalls(), ShouldHaveLength, 2)
+				So(store.GetCollectionByIDCalls()[1].ETagSelector, ShouldEqual, "other")
+			})
+		})
+
+		Convey("When a collection that does not exist is read twice", func() {
+			_, err := cached.GetCollectionByID(ctx, "missing", models.AnyETag)
+			So(err, ShouldEqual, collections.ErrCollectionNotFound)
+			_, err = cached.GetCollectionByID(ctx, "missing", models.AnyETag)
+			So(err, ShouldEqual, collections.ErrCollectionNotFound)
+
+			Convey("Then the wrapped store is read each time", func() {
+				So(store.GetCollectionByIDCalls(), ShouldHaveLength, 2)
+			})
+		})
+	})
+
+	Convey("Given a cached collection store with a short TTL", t, func() {
+		store := newStoreMock()
+		cached := cache.NewCollectionStore(store, cache.NewLocalNotifier(), time.Millisecond, 10)
+
+		Convey("When a collection is read again after the TTL", func() {
+			cached.GetCollectionByID(ctx, "123", models.AnyETag)
+			time.Sleep(5 * time.Millisecond)
+			cached.GetCollectionByID(ctx, "123", models.AnyETag)
+
+			Convey("Then the wrapped store is read again", func() {
+				So(store.GetCollectionByIDCalls(), ShouldHaveLength, 2)
+			})
+		})
+	})
+
+	Convey("Given a cached collection store with room for two entries", t, func() {
+		store := newStoreMock()
+		cached := cache.NewCollectionStore(store, cache.NewLocalNotifier(), time.Minute, 2)
+
+		Convey("When a third collection is cached", func() {
+			cached.GetCollectionByID(ctx, "1", models.AnyETag)
+			cached.GetCollectionByID(ctx, "2", models.AnyETag)
+			cached.GetCollectionByID(ctx, "1", models.AnyETag)
+			cached.GetCollectionByID(ctx, "3", models.AnyETag)
+
+			Convey("Then the least recently used collection is evicted", func() {
+				cached.GetCollectionByID(ctx, "1", models.AnyETag)
+				cached.GetCollectionByID(ctx, "3", models.AnyETag)
+				So(store.GetCollectionByIDCalls(), ShouldHaveLength, 3)
+
+				cached.GetCollectionByID(ctx, "2", models.AnyETag)
+				So(store.GetCollectionByIDCalls(), ShouldHaveLength, 4)
+			})
+		})
+	})
+}
+
+func TestCollectionStore_publishResult(t *testing.T) {
+
+	Convey("Given a cached collection store, and a collection with a publish result", t, func() {
+		store := newStoreMock()
+		store.GetCollectionByIDFunc = func(ctx context.Context, id string, eTagSelector string) (*models.Collection, error) {
+			return &models.Collection{ID: id, ETag: "etag-" + id, PublishResult: &models.PublishResult{Status: models.PublishCompleted}}, nil
+		}
+		cached := cache.NewCollectionStore(store, cache.NewLocalNotifier(), time.Minute, 10)
+
+		Convey("When the publish result of a returned collection is changed", func() {
+			first, err := cached.GetCollectionByID(ctx, "123", models.AnyETag)
+			So(err, ShouldBeNil)
+			first.PublishResult.Status = "changed"
+
+			Convey("Then the cached collection keeps its publish result", func() {
+				second, err := cached.GetCollectionByID(ctx, "123", models.AnyETag)
+				So(err, ShouldBeNil)
+				So(store.GetCollectionByIDCalls(), ShouldHaveLength, 1)
+				So(second.PublishResult.Status, ShouldEqual, models.PublishCompleted)
+			})
+		})
+	})
+}
+
+func TestCollectionStore_GetCollectionByName(t *testing.T) {
+
+	Convey("Given a cached collection store", t, func() {
+		store := newStoreMock()
+		cached := cache.NewCollectionStore(store, cache.NewLocalNotifier(), time.Minute, 10)
+
+		Convey("When a collection is read by name twice", func() {
+			cached.GetCollectionByName(ctx, "abc")
+			collection, err := cached.GetCollectionByName(ctx, "abc")
+
+			Convey("Then the wrapped store is only read once", func() {
+				So(err, ShouldBeNil)
+				So(collection.ID, ShouldEqual, "id-abc")
+				So(store.GetCollectionByNameCalls(), ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When a name that is not found is read twice", func() {
+			cached.GetCollectionByName(ctx, "missing")
+			_, err := cached.GetCollectionByName(ctx, "missing")
+
+			Convey("Then the wrapped store is read each time", func() {
+				So(err, ShouldEqual, collections.ErrCollectionNotFound)
+				So(store.GetCollectionByNameCalls(), ShouldHaveLength, 2)
+			})
+		})
+	})
+}
+
+func TestCollectionStore_invalidation(t *testing.T) {
+
+	Convey("Given two cached collection stores sharing a notifier", t, func() {
+		store := newStoreMock()
+		notifier := cache.NewLocalNotifier()
+		cached := cache.NewCollectionStore(store, notifier, time.Minute, 10)
+		other := cache.NewCollectionStore(store, notifier, time.Minute, 10)
+
+		cached.GetCollectionByID(ctx, "123", models.AnyETag)
+		other.GetCollectionByID(ctx, "123", models.AnyETag)
+		So(store.GetCollectionByIDCalls(), ShouldHaveLength, 2)
+
+		Convey("When a collection is added through one store", func() {
+			err := cached.AddCollection(ctx, &models.Collection{ID: "123"})
+			So(err, ShouldBeNil)
+
+			Convey("Then both stores read the collection again", func() {
+				cached.GetCollectionByID(ctx, "123", models.AnyETag)
+				other.GetCollectionByID(ctx, "123", models.AnyETag)
+				So(store.GetCollectionByIDCalls(), ShouldHaveLength, 4)
+			})
+
+			Convey("Then the cached name is also invalidated", func() {
+				cached.GetCollectionByName(ctx, "name-123")
+				So(store.GetCollectionByNameCalls(), ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When a replacement fails with a conflict", func() {
+			err := other.ReplaceCollection(ctx, &models.Collection{ID: "123"}, "etag-123")
+
+			Convey("Then the error is returned", func() {
+				So(err, ShouldEqual, collections.ErrCollectionConflict)
+			})
+
+			Convey("Then the possibly stale collection is invalidated", func() {
+				cached.GetCollectionByID(ctx, "123", models.AnyETag)
+				So(store.GetCollectionByIDCalls(), ShouldHaveLength, 3)
+			})
+		})
+
+		Convey("When a collection is re-cached before its write commits, and the commit is reported", func() {
+			cached.AddCollection(ctx, &models.Collection{ID: "123"})
+			cached.GetCollectionByID(ctx, "123", models.AnyETag)
+			other.GetCollectionByID(ctx, "123", models.AnyETag)
+			So(store.GetCollectionByIDCalls(), ShouldHaveLength, 4)
+
+			err := cached.HandleCollectionEvent(ctx, &models.Event{Type: models.EventCreated}, &models.Collection{ID: "123"})
+			So(err, ShouldBeNil)
+
+			Convey("Then both stores read the committed collection again", func() {
+				cached.GetCollectionByID(ctx, "123", models.AnyETag)
+				other.GetCollectionByID(ctx, "123", models.AnyETag)
+				So(store.GetCollectionByIDCalls(), ShouldHaveLength, 6)
+			})
+		})
+
+		Convey("When a different collection is invalidated", func() {
+			notifier.Notify(ctx, "456")
+
+			Convey("Then the cached collection is still used", func() {
+				cached.GetCollectionByID(ctx, "123", models.AnyETag)
+				So(store.GetCollectionByIDCalls(), ShouldHaveLength, 2)
+			})
+		})
+	})
+
+	Convey("Given a collection is changed while it is being read from the wrapped store", t, func() {
+		notifier := cache.NewLocalNotifier()
+		store := newStoreMock()
+		store.GetCollectionByIDFunc = func(ctx context.Context, id string, eTagSelector string) (*models.Collection, error) {
+			notifier.Notify(ctx, id)
+			return &models.Collection{ID: id}, nil
+		}
+		cached := cache.NewCollectionStore(store, notifier, time.Minute, 10)
+
+		Convey("When the collection is read twice", func() {
+			cached.GetCollectionByID(ctx, "123", models.AnyETag)
+			cached.GetCollectionByID(ctx, "123", models.AnyETag)
+
+			Convey("Then the possibly stale result of the first read is not cached", func() {
+				So(store.GetCollectionByIDCalls(), ShouldHaveLength, 2)
+			})
+		})
+	})
+
+	Convey("Given a notifier that fails", t, func() {
+		store := newStoreMock()
+		notifier := &cacheMock.NotifierMock{
+			SubscribeFunc: func(invalidate func(id string)) {},
+			NotifyFunc: func(ctx context.Context, id string) error {
+				return errors.New("notifier is broken")
+			},
+		}
+		cached := cache.NewCollectionStore(store, notifier, time.Minute, 10)
+		cached.GetCollectionByID(ctx, "123", models.AnyETag)
+
+		Convey("When a collection is added", func() {
+			err := cached.AddCollection(ctx, &models.Collection{ID: "123"})
+
+			Convey("Then the write succeeds and the local entry is still invalidated", func() {
+				So(err, ShouldBeNil)
+				So(notifier.NotifyCalls(), ShouldHaveLength, 1)
+				So(notifier.NotifyCalls()[0].ID, ShouldEqual, "123")
+
+				cached.GetCollectionByID(ctx, "123", models.AnyETag)
+				So(store.GetCollectionByIDCalls(), ShouldHaveLength, 2)
+			})
+		})
+	})
+}
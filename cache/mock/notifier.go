@@ -0,0 +1,170 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/ONSdigital/dp-collection-api/cache"
+	"sync"
+)
+
+// Ensure, that NotifierMock does implement cache.Notifier.
+// If this is not the case, regenerate this file with moq.
+var _ cache.Notifier = &NotifierMock{}
+
+// NotifierMock is a mock implementation of cache.Notifier.
+//
+//	func TestSomethingThatUsesNotifier(t *testing.T) {
+//
+//		// make and configure a mocked cache.Notifier
+//		mockedNotifier := &NotifierMock{
+//			CloseFunc: func(ctx context.Context) error {
+//				panic("mock out the Close method")
+//			},
+//			NotifyFunc: func(ctx context.Context, id string) error {
+//				panic("mock out the Notify method")
+//			},
+//			SubscribeFunc: func(invalidate func(id string))  {
+//				panic("mock out the Subscribe method")
+//			},
+//		}
+//
+//		// use mockedNotifier in code that requires cache.Notifier
+//		// and then make assertions.
+//
+//	}
+type NotifierMock struct {
+	// CloseFunc mocks the Close method.
+	CloseFunc func(ctx context.Context) error
+
+	// NotifyFunc mocks the Notify method.
+	NotifyFunc func(ctx context.Context, id string) error
+
+	// SubscribeFunc mocks the Subscribe method.
+	SubscribeFunc func(invalidate func(id string))
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Close holds details about calls to the Close method.
+		Close []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// Notify holds details about calls to the Notify method.
+		Notify []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// Subscribe holds details about calls to the Subscribe method.
+		Subscribe []struct {
+			// Invalidate is the invalidate argument value.
+			Invalidate func(id string)
+		}
+	}
+	lockClose     sync.RWMutex
+	lockNotify    sync.RWMutex
+	lockSubscribe sync.RWMutex
+}
+
+// Close calls CloseFunc.
+func (mock *NotifierMock) Close(ctx context.Context) error {
+	if mock.CloseFunc == nil {
+		panic("NotifierMock.CloseFunc: method is nil but Notifier.Close was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockClose.Lock()
+	mock.calls.Close = append(mock.calls.Close, callInfo)
+	mock.lockClose.Unlock()
+	return mock.CloseFunc(ctx)
+}
+
+// CloseCalls gets all the calls that were made to Close.
+// Check the length with:
+//
+//	len(mockedNotifier.CloseCalls())
+func (mock *NotifierMock) CloseCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockClose.RLock()
+	calls = mock.calls.Close
+	mock.lockClose.RUnlock()
+	return calls
+}
+
+// Notify calls NotifyFunc.
+func (mock *NotifierMock) Notify(ctx context.Context, id string) error {
+	if mock.NotifyFunc == nil {
+		panic("NotifierMock.NotifyFunc: method is nil but Notifier.Notify was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockNotify.Lock()
+	mock.calls.Notify = append(mock.calls.Notify, callInfo)
+	mock.lockNotify.Unlock()
+	return mock.NotifyFunc(ctx, id)
+}
+
+// NotifyCalls gets all the calls that were made to Notify.
+// Check the length with:
+//
+//	len(mockedNotifier.NotifyCalls())
+func (mock *NotifierMock) NotifyCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockNotify.RLock()
+	calls = mock.calls.Notify
+	mock.lockNotify.RUnlock()
+	return calls
+}
+
+// Subscribe calls SubscribeFunc.
+func (mock *NotifierMock) Subscribe(invalidate func(id string)) {
+	if mock.SubscribeFunc == nil {
+		panic("NotifierMock.SubscribeFunc: method is nil but Notifier.Subscribe was just called")
+	}
+	callInfo := struct {
+		Invalidate func(id string)
+	}{
+		Invalidate: invalidate,
+	}
+	mock.lockSubscribe.Lock()
+	mock.calls.Subscribe = append(mock.calls.Subscribe, callInfo)
+	mock.lockSubscribe.Unlock()
+	mock.SubscribeFunc(invalidate)
+}
+
+// SubscribeCalls gets all the calls that were made to Subscribe.
+// Check the length with:
+//
+//	len(mockedNotifier.SubscribeCalls())
+func (mock *NotifierMock) SubscribeCalls() []struct {
+	Invalidate func(id string)
+} {
+	var calls []struct {
+		Invalidate func(id string)
+	}
+	mock.lockSubscribe.RLock()
+	calls = mock.calls.Subscribe
+	mock.lockSubscribe.RUnlock()
+	return calls
+}
@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"context"
+	"sync"
+)
+
+//go:generate moq -out mock/notifier.go -pkg mock . Notifier
+
+// Notifier tells every cache that a collection has changed, so that it is read from the store again. An
+// implementation may reach caches in other instances, for example by watching the datastore for changes.
+type Notifier interface {
+	Notify(ctx context.Context, id string) error
+	Subscribe(invalidate func(id string))
+	Close(ctx context.Context) error
+}
+
+// LocalNotifier broadcasts changes to the caches subscribed in this instance only. It is suitable for a single
+// instance, or where the cache TTL is an acceptable bound on how stale other instances can be.
+type LocalNotifier struct {
+	mutex       sync.RWMutex
+	subscribers []func(id string)
+}
+
+// NewLocalNotifier returns a notifier with no subscribers
+func NewLocalNotifier() *LocalNotifier {
+	return &LocalNotifier{}
+}
+
+// Notify calls every subscriber with the ID of the changed collection
+func (n *LocalNotifier) Notify(ctx context.Context, id string) error {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+
+	for _, invalidate := range n.subscribers {
+		invalidate(id)
+	}
+	return nil
+}
+
+// Subscribe adds a function to be called with the ID of each changed collection
+func (n *LocalNotifier) Subscribe(invalidate func(id string)) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.subscribers = append(n.subscribers, invalidate)
+}
+
+// Close does nothing, as there is nothing to release
+func (n *LocalNotifier) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,245 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+)
+
+const (
+	ifMatchHeader        = "If-Match"
+	eTagHeader           = "ETag"
+	idempotencyKeyHeader = "Idempotency-Key"
+	jsonContentType      = "application/json"
+	ndjsonContentType    = "application/x-ndjson"
+)
+
+// HTTPClient defines the required methods from the HTTP client
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client is a client for the collection API
+type Client struct {
+	url        string
+	httpClient HTTPClient
+}
+
+// QueryParams are the optional parameters for listing collections. Zero values are left out of the request, so that
+// the API defaults are used.
+type QueryParams struct {
+	Offset  int
+	Limit   int
+	OrderBy string
+	Name    string
+}
+
+// collectionRequest is the body sent to create or update a collection, containing only the fields a client can set
+type collectionRequest struct {
+	Name        string     `json:"name,omitempty"`
+	PublishDate *time.Time `json:"publish_date,omitempty"`
+}
+
+// New returns a client for the collection API at the given URL, using the default HTTP client
+func New(collectionAPIURL string) *Client {
+	return NewWithHTTPClient(collectionAPIURL, http.DefaultClient)
+}
+
+// NewWithHTTPClient returns a client for the collection API at the given URL, using the given HTTP client
+func NewWithHTTPClient(collectionAPIURL string, httpClient HTTPClient) *Client {
+	return &Client{
+		url:        strings.TrimRight(collectionAPIURL, "/"),
+		httpClient: httpClient,
+	}
+}
+
+// GetCollections returns a page of collections
+func (c *Client) GetCollections(ctx context.Context, queryParams QueryParams) (*models.CollectionsResponse, error) {
+	query := pageQuery(queryParams.Offset, queryParams.Limit)
+	if len(queryParams.OrderBy) > 0 {
+		query.Set("order_by", queryParams.OrderBy)
+	}
+	if len(queryParams.Name) > 0 {
+		query.Set("name", queryParams.Name)
+	}
+
+	var response models.CollectionsResponse
+	if _, err := c.do(ctx, http.MethodGet, "/collections?"+query.Encode(), nil, nil, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetCollection returns a single collection, with the ETag needed to update it
+func (c *Client) GetCollection(ctx context.Context, id string) (*models.Collection, error) {
+	var collection models.Collection
+	header, err := c.do(ctx, http.MethodGet, collectionPath(id), nil, nil, &collection)
+	if err != nil {
+		return nil, err
+	}
+
+	setETag(&collection, header)
+	return &collection, nil
+}
+
+// CreateCollection creates a collection with the name and publish date of the given collection, and returns the
+// created collection with its ID and ETag
+func (c *Client) CreateCollection(ctx context.Context, collection *models.Collection) (*models.Collection, error) {
+	return c.CreateCollectionWithIdempotencyKey(ctx, collection, "")
+}
+
+// CreateCollectionWithIdempotencyKey creates a collection in the same way as CreateCollection. The API processes a
+// key only once, so the request can be safely retried with the same key, e.g. after a timeout.
+func (c *Client) CreateCollectionWithIdempotencyKey(ctx context.Context, collection *models.Collection, idempotencyKey string) (*models.Collection, error) {
+	header := http.Header{}
+	if len(idempotencyKey) > 0 {
+		header.Set(idempotencyKeyHeader, idempotencyKey)
+	}
+
+	var created models.Collection
+	responseHeader, err := c.do(ctx, http.MethodPost, "/collections", header, newCollectionRequest(collection), &created)
+	if err != nil {
+		return nil, err
+	}
+
+	setETag(&created, responseHeader)
+	return &created, nil
+}
+
+// UpdateCollection updates a collection with the name and publish date of the given collection. The collection's
+// ETag is sent as the If-Match header, so the update is rejected with collections.ErrCollectionConflict if the
+// collection has changed since it was read. The updated collection is returned with its new ETag.
+func (c *Client) UpdateCollection(ctx context.Context, collection *models.Collection) (*models.Collection, error) {
+	header := http.Header{}
+	if len(collection.ETag) > 0 {
+		header.Set(ifMatchHeader, collection.ETag)
+	}
+
+	var updated models.Collection
+	responseHeader, err := c.do(ctx, http.MethodPut, collectionPath(collection.ID), header, newCollectionRequest(collection), &updated)
+	if err != nil {
+		return nil, err
+	}
+
+	setETag(&updated, responseHeader)
+	return &updated, nil
+}
+
+// GetEvents returns a page of the events for a collection
+func (c *Client) GetEvents(ctx context.Context, id string, offset, limit int) (*models.EventsResponse, error) {
+	path := collectionPath(id) + "/events?" + pageQuery(offset, limit).Encode()
+
+	var response models.EventsResponse
+	if _, err := c.do(ctx, http.MethodGet, path, nil, nil, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Import imports collections in the export format, with a policy for collections that already exist, and returns the
+// report of the result of each collection. The import is sent as a single request, so a collection that is not
+// imported is in the report rather than returned as an error.
+func (c *Client) Import(ctx context.Context, items []models.ExportedCollection, onConflict collections.ConflictPolicy) (*models.ImportReport, error) {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	for i := range items {
+		if err := encoder.Encode(items[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	path := "/collections/import"
+	if len(onConflict) > 0 {
+		path += "?" + url.Values{"on_conflict": []string{string(onConflict)}}.Encode()
+	}
+
+	var report models.ImportReport
+	if _, err := c.send(ctx, http.MethodPost, path, nil, ndjsonContentType, &b, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// do sends a request with an optional JSON body, and decodes a successful JSON response into the result. An
+// unsuccessful response is returned as an *Error.
+func (c *Client) do(ctx context.Context, method, path string, header http.Header, body interface{}, result interface{}) (http.Header, error) {
+	if body == nil {
+		return c.send(ctx, method, path, header, "", nil, result)
+	}
+
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return c.send(ctx, method, path, header, jsonContentType, bytes.NewReader(b), result)
+}
+
+// send sends a request with an optional body of the given content type, and decodes a successful JSON response in
+// the same way as do
+func (c *Client) send(ctx context.Context, method, path string, header http.Header, contentType string, body io.Reader, result interface{}) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path, body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", jsonContentType)
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, newError(resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return nil, fmt.Errorf("failed to decode response from %s %s: %w", method, path, err)
+	}
+	return resp.Header, nil
+}
+
+func newCollectionRequest(collection *models.Collection) *collectionRequest {
+	return &collectionRequest{
+		Name:        collection.Name,
+		PublishDate: collection.PublishDate,
+	}
+}
+
+func collectionPath(id string) string {
+	return "/collections/" + url.PathEscape(id)
+}
+
+func pageQuery(offset, limit int) url.Values {
+	query := url.Values{}
+	if offset > 0 {
+		query.Set("offset", strconv.Itoa(offset))
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	return query
+}
+
+// setETag sets the collection's ETag from the response header, which is the value to send back in an If-Match header
+func setETag(collection *models.Collection, header http.Header) {
+	if eTag := header.Get(eTagHeader); len(eTag) > 0 {
+		collection.ETag = eTag
+	}
+}
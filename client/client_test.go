@@ -0,0 +1,315 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/api"
+	"github.com/ONSdigital/dp-collection-api/client"
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/config"
+	"github.com/ONSdigital/dp-collection-api/memory"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/dp-collection-api/pagination"
+	"github.com/gorilla/mux"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var ctx = context.Background()
+
+// newAPI starts the API with an in-memory store, returning a client for it and the store
+func newAPI(t *testing.T) (*client.Client, *memory.Store) {
+	store := memory.New()
+	cfg := &config.Config{MaxRequestBodyBytes: 1024 * 1024, IdempotencyKeyTTL: time.Hour}
+
+	r := mux.NewRouter()
+	api.Setup(ctx, cfg, r, pagination.NewPaginator(20, 0, 1000), store, store)
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+
+	return client.New(server.URL), store
+}
+
+func TestClient_collections(t *testing.T) {
+
+	Convey("Given a client for the collection API", t, func() {
+		c, _ := newAPI(t)
+		publishDate := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+		Convey("When a collection is created", func() {
+			created, err := c.CreateCollection(ctx, &models.Collection{Name: "collection 1", PublishDate: &publishDate})
+
+			Convey("Then the created collection is returned with its ID and ETag", func() {
+				So(err, ShouldBeNil)
+				So(created.ID, ShouldNotBeEmpty)
+				So(created.ETag, ShouldNotBeEmpty)
+				So(created.Name, ShouldEqual, "collection 1")
+				So(created.PublishDate.Equal(publishDate), ShouldBeTrue)
+			})
+
+			Convey("Then it can be read by ID", func() {
+				collection, err := c.GetCollection(ctx, created.ID)
+				So(err, ShouldBeNil)
+				So(collection.Name, ShouldEqual, "collection 1")
+				So(collection.ETag, ShouldEqual, created.ETag)
+			})
+
+			Convey("Then it can be updated using the ETag that was read", func() {
+				collection, err := c.GetCollection(ctx, created.ID)
+				So(err, ShouldBeNil)
+
+				collection.Name = "renamed"
+				updated, err := c.UpdateCollection(ctx, collection)
+				So(err, ShouldBeNil)
+				So(updated.Name, ShouldEqual, "renamed")
+				So(updated.ETag, ShouldNotEqual, created.ETag)
+
+				Convey("And an update using the old ETag is rejected as a conflict", func() {
+					_, err := c.UpdateCollection(ctx, collection)
+					So(errors.Is(err, collections.ErrCollectionConflict), ShouldBeTrue)
+
+					var clientErr *client.Error
+					So(errors.As(err, &clientErr), ShouldBeTrue)
+					So(clientErr.StatusCode, ShouldEqual, 409)
+				})
+
+				Convey("And an update using the new ETag succeeds", func() {
+					updated.Name = "renamed again"
+					_, err := c.UpdateCollection(ctx, updated)
+					So(err, ShouldBeNil)
+				})
+			})
+
+			Convey("Then an update without an ETag is rejected", func() {
+				_, err := c.UpdateCollection(ctx, &models.Collection{ID: created.ID, Name: "renamed"})
+				So(errors.Is(err, collections.ErrNoIfMatchHeader), ShouldBeTrue)
+			})
+
+			Convey("Then another collection with the same name is rejected", func() {
+				_, err := c.CreateCollection(ctx, &models.Collection{Name: "collection 1"})
+				So(errors.Is(err, collections.ErrCollectionNameAlreadyExists), ShouldBeTrue)
+			})
+		})
+
+		Convey("When a collection is created with an idempotency key twice", func() {
+			first, err := c.CreateCollectionWithIdempotencyKey(ctx, &models.Collection{Name: "collection 1"}, "key")
+			So(err, ShouldBeNil)
+			second, err := c.CreateCollectionWithIdempotencyKey(ctx, &models.Collection{Name: "collection 1"}, "key")
+
+			Convey("Then the same collection is returned", func() {
+				So(err, ShouldBeNil)
+				So(second.ID, ShouldEqual, first.ID)
+			})
+		})
+
+		Convey("When an invalid collection is created", func() {
+			_, err := c.CreateCollection(ctx, &models.Collection{})
+
+			Convey("Then each error in the response can be checked for", func() {
+				So(errors.Is(err, collections.ErrCollectionNameEmpty), ShouldBeTrue)
+				So(errors.Is(err, collections.ErrCollectionNotFound), ShouldBeFalse)
+				So(err.Error(), ShouldContainSubstring, "400")
+			})
+		})
+
+		Convey("When a collection is created with a body larger than the maximum", func() {
+			_, err := c.CreateCollection(ctx, &models.Collection{Name: strings.Repeat("a", 1024*1024)})
+
+			Convey("Then collections.ErrRequestBodyTooLarge is returned", func() {
+				So(errors.Is(err, collections.ErrRequestBodyTooLarge), ShouldBeTrue)
+			})
+		})
+
+		Convey("When collections are imported with an invalid conflict policy", func() {
+			_, err := c.Import(ctx, nil, "bogus")
+
+			Convey("Then collections.ErrInvalidConflictPolicy is returned", func() {
+				So(errors.Is(err, collections.ErrInvalidConflictPolicy), ShouldBeTrue)
+			})
+		})
+
+		Convey("When a collection that does not exist is read", func() {
+			_, err := c.GetCollection(ctx, "00112233-4455-6677-8899-aabbccddeeff")
+
+			Convey("Then collections.ErrCollectionNotFound is returned", func() {
+				So(errors.Is(err, collections.ErrCollectionNotFound), ShouldBeTrue)
+			})
+		})
+
+		Convey("When a collection is read with an invalid ID", func() {
+			_, err := c.GetCollection(ctx, "abc")
+
+			Convey("Then collections.ErrInvalidID is returned", func() {
+				So(errors.Is(err, collections.ErrInvalidID), ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func TestClient_listCollections(t *testing.T) {
+
+	Convey("Given the API has five collections", t, func() {
+		c, _ := newAPI(t)
+		for i := 1; i <= 5; i++ {
+			_, err := c.CreateCollection(ctx, &models.Collection{Name: fmt.Sprintf("collection %d", i)})
+			So(err, ShouldBeNil)
+		}
+
+		Convey("When a page of collections is requested", func() {
+			response, err := c.GetCollections(ctx, client.QueryParams{Offset: 1, Limit: 2})
+
+			Convey("Then the page is returned", func() {
+				So(err, ShouldBeNil)
+				So(response.Items, ShouldHaveLength, 2)
+				So(response.Items[0].Name, ShouldEqual, "collection 2")
+				So(response.TotalCount, ShouldEqual, 5)
+			})
+		})
+
+		Convey("When collections are searched by name", func() {
+			response, err := c.GetCollections(ctx, client.QueryParams{Name: "collection 3"})
+
+			Convey("Then the matching collections are returned", func() {
+				So(err, ShouldBeNil)
+				So(response.Items, ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When collections are requested with an invalid order", func() {
+			_, err := c.GetCollections(ctx, client.QueryParams{OrderBy: "unknown"})
+
+			Convey("Then collections.ErrInvalidOrderBy is returned", func() {
+				So(errors.Is(err, collections.ErrInvalidOrderBy), ShouldBeTrue)
+			})
+		})
+
+		Convey("When the collections are iterated two at a time", func() {
+			it := c.IterateCollections(client.QueryParams{Limit: 2})
+
+			var names []string
+			for it.Next(ctx) {
+				names = append(names, it.Collection().Name)
+			}
+
+			Convey("Then every collection is read in order", func() {
+				So(it.Err(), ShouldBeNil)
+				So(names, ShouldResemble, []string{
+					"collection 1", "collection 2", "collection 3", "collection 4", "collection 5",
+				})
+			})
+		})
+
+		Convey("When the collections are iterated from an offset", func() {
+			it := c.IterateCollections(client.QueryParams{Offset: 3, Limit: 10})
+
+			var names []string
+			for it.Next(ctx) {
+				names = append(names, it.Collection().Name)
+			}
+
+			Convey("Then the collections after the offset are read", func() {
+				So(it.Err(), ShouldBeNil)
+				So(names, ShouldResemble, []string{"collection 4", "collection 5"})
+			})
+		})
+
+		Convey("When the collections are iterated with a limit over the maximum", func() {
+			it := c.IterateCollections(client.QueryParams{Limit: 10000})
+
+			Convey("Then the iteration stops with the error", func() {
+				So(it.Next(ctx), ShouldBeFalse)
+				So(errors.Is(it.Err(), pagination.ErrLimitOverMax), ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func TestClient_events(t *testing.T) {
+
+	Convey("Given a collection with the event recording its creation, and three more events", t, func() {
+		c, store := newAPI(t)
+		collection, err := c.CreateCollection(ctx, &models.Collection{Name: "collection 1"})
+		So(err, ShouldBeNil)
+
+		date := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+		for i := 0; i < 3; i++ {
+			err := store.AddEvent(ctx, &models.Event{
+				CollectionID: collection.ID,
+				Type:         fmt.Sprintf("event %d", i),
+				Date:         date.Add(time.Duration(i) * time.Hour),
+			})
+			So(err, ShouldBeNil)
+		}
+
+		Convey("When a page of events is requested", func() {
+			response, err := c.GetEvents(ctx, collection.ID, 0, 2)
+
+			Convey("Then the page is returned", func() {
+				So(err, ShouldBeNil)
+				So(response.Items, ShouldHaveLength, 2)
+				So(response.TotalCount, ShouldEqual, 4)
+			})
+		})
+
+		Convey("When the events are iterated", func() {
+			it := c.IterateEvents(collection.ID, 2)
+
+			var types []string
+			for it.Next(ctx) {
+				types = append(types, it.Event().Type)
+			}
+
+			Convey("Then every event is read", func() {
+				So(it.Err(), ShouldBeNil)
+				So(types, ShouldHaveLength, 4)
+			})
+		})
+
+		Convey("When the events of a collection that does not exist are requested", func() {
+			_, err := c.GetEvents(ctx, "00112233-4455-6677-8899-aabbccddeeff", 0, 0)
+
+			Convey("Then collections.ErrCollectionNotFound is returned", func() {
+				So(errors.Is(err, collections.ErrCollectionNotFound), ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func TestClient_import(t *testing.T) {
+
+	Convey("Given a collection has been imported", t, func() {
+		c, store := newAPI(t)
+		items := []models.ExportedCollection{{
+			Collection: models.Collection{ID: "00000000-0000-0000-0000-000000000001", Name: "collection 1"},
+			Events:     []models.Event{{Type: "CREATED", Email: "a@b.c", Date: time.Date(2021, 6, 1, 9, 0, 0, 0, time.UTC)}},
+		}}
+
+		report, err := c.Import(ctx, items, collections.ConflictPolicySkip)
+		So(err, ShouldBeNil)
+
+		Convey("Then it is created with its events, and the event recording the import", func() {
+			So(report.Created, ShouldEqual, 1)
+
+			events, _, err := store.GetCollectionEvents(ctx, collections.EventsQueryParams{CollectionID: items[0].ID, Limit: 10})
+			So(err, ShouldBeNil)
+			So(events, ShouldHaveLength, 2)
+		})
+
+		Convey("When it is imported again", func() {
+			report, err := c.Import(ctx, items, collections.ConflictPolicySkip)
+
+			Convey("Then it is skipped", func() {
+				So(err, ShouldBeNil)
+				So(report.Skipped, ShouldEqual, 1)
+				So(report.Results[0].Errors[0].Code, ShouldEqual, models.ErrCodeCollectionAlreadyExists)
+			})
+		})
+	})
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/dp-collection-api/pagination"
+)
+
+// errorCodes maps the codes in an error response back to the errors that the API returned them for, so that callers
+// can check for them with errors.Is
+var errorCodes = map[string]error{
+	models.ErrCodeInvalidLimit:                pagination.ErrInvalidLimitParameter,
+	models.ErrCodeInvalidOffset:               pagination.ErrInvalidOffsetParameter,
+	models.ErrCodeLimitOverMax:                pagination.ErrLimitOverMax,
+	models.ErrCodeInvalidOrderBy:              collections.ErrInvalidOrderBy,
+	models.ErrCodeNameSearchTooLong:           collections.ErrNameSearchTooLong,
+	models.ErrCodeCollectionNameEmpty:         collections.ErrCollectionNameEmpty,
+	models.ErrCodeCollectionNameAlreadyExists: collections.ErrCollectionNameAlreadyExists,
+	models.ErrCodePublishDateInPast:           collections.ErrPublishDateInPast,
+	models.ErrCodeInvalidID:                   collections.ErrInvalidID,
+	models.ErrCodeCollectionIDMismatch:        collections.ErrCollectionIDMismatch,
+	models.ErrCodeCollectionNotFound:          collections.ErrCollectionNotFound,
+	models.ErrCodeCollectionConflict:          collections.ErrCollectionConflict,
+	models.ErrCodeCollectionAlreadyExists:     collections.ErrCollectionAlreadyExists,
+	models.ErrCodeIfMatchHeaderRequired:       collections.ErrNoIfMatchHeader,
+	models.ErrCodeIdempotencyKeyTooLong:       collections.ErrIdempotencyKeyTooLong,
+	models.ErrCodeIdempotencyKeyReused:        collections.ErrIdempotencyKeyReused,
+	models.ErrCodeIdempotencyKeyInProgress:    collections.ErrIdempotencyKeyInProgress,
+	models.ErrCodeInvalidJSON:                 collections.ErrUnableToParseJSON,
+	models.ErrCodeUnknownField:                collections.ErrUnknownJSONField,
+	models.ErrCodeRequestBodyTooLarge:         collections.ErrRequestBodyTooLarge,
+	models.ErrCodeUnsupportedContentType:      collections.ErrUnsupportedContentType,
+}
+
+// fieldErrorCodes maps the codes that the API returns for more than one error back to those errors, by the field
+// in the error response
+var fieldErrorCodes = map[string]map[string]error{
+	models.ErrCodeReadOnlyField: {
+		"id":             collections.ErrCollectionIDReadOnly,
+		"e_tag":          collections.ErrETagReadOnly,
+		"last_updated":   collections.ErrLastUpdatedReadOnly,
+		"publish_result": collections.ErrPublishResultReadOnly,
+		"version":        collections.ErrVersionReadOnly,
+	},
+	models.ErrCodeInvalidParameter: {
+		"on_conflict": collections.ErrInvalidConflictPolicy,
+		"events":      collections.ErrInvalidEventsParameter,
+		"version":     collections.ErrInvalidVersion,
+		"status":      collections.ErrInvalidDeliveryStatus,
+	},
+}
+
+// Error is returned for an unsuccessful response from the API. The errors in the response that are known can be
+// checked for with errors.Is, e.g. errors.Is(err, collections.ErrCollectionNotFound). The errors that the API
+// returns without a known code, such as an invalid_parameter for a field that fails validation against the API
+// specification, are only available through Errors.
+type Error struct {
+	StatusCode int
+	Errors     []models.ErrorResponse
+}
+
+func (e *Error) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, errorResponse := range e.Errors {
+		messages = append(messages, errorResponse.Message)
+	}
+	if len(messages) == 0 {
+		return fmt.Sprintf("collection API returned %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("collection API returned %d: %s", e.StatusCode, strings.Join(messages, "; "))
+}
+
+// Is returns true if any of the errors in the response is the target error
+func (e *Error) Is(target error) bool {
+	for _, errorResponse := range e.Errors {
+		if err, ok := errorCodes[errorResponse.Code]; ok && err == target {
+			return true
+		}
+		if err, ok := fieldErrorCodes[errorResponse.Code][errorResponse.Field]; ok && err == target {
+			return true
+		}
+	}
+	return false
+}
+
+// newError reads the errors from an unsuccessful response. A body that is not an errors response, e.g. from a
+// proxy, leaves the errors empty.
+func newError(resp *http.Response) *Error {
+	e := &Error{StatusCode: resp.StatusCode}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return e
+	}
+
+	var errorsResponse models.ErrorsResponse
+	if err := json.Unmarshal(b, &errorsResponse); err == nil {
+		e.Errors = errorsResponse.Errors
+	}
+	return e
+}
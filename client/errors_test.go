@@ -0,0 +1,42 @@
+package client_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ONSdigital/dp-collection-api/client"
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestError_Is(t *testing.T) {
+
+	Convey("Given an error response with a read-only field error and an unknown field error", t, func() {
+		err := &client.Error{StatusCode: 400, Errors: []models.ErrorResponse{
+			{Code: models.ErrCodeReadOnlyField, Field: "e_tag"},
+			{Code: models.ErrCodeUnknownField, Field: "colour"},
+		}}
+
+		Convey("Then the read-only field error is matched by its field", func() {
+			So(errors.Is(err, collections.ErrETagReadOnly), ShouldBeTrue)
+			So(errors.Is(err, collections.ErrCollectionIDReadOnly), ShouldBeFalse)
+		})
+
+		Convey("Then the unknown field error is matched", func() {
+			So(errors.Is(err, collections.ErrUnknownJSONField), ShouldBeTrue)
+		})
+	})
+
+	Convey("Given an error response with an invalid parameter error for a field with no known error", t, func() {
+		err := &client.Error{StatusCode: 400, Errors: []models.ErrorResponse{
+			{Code: models.ErrCodeInvalidParameter, Field: "limit"},
+		}}
+
+		Convey("Then no parameter error is matched", func() {
+			So(errors.Is(err, collections.ErrInvalidConflictPolicy), ShouldBeFalse)
+			So(errors.Is(err, collections.ErrInvalidVersion), ShouldBeFalse)
+		})
+	})
+}
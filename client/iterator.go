@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+
+	"github.com/ONSdigital/dp-collection-api/models"
+)
+
+// page tracks the position of an iterator through a paged list
+type page struct {
+	offset int
+	limit  int
+	index  int
+	size   int
+	done   bool
+	err    error
+}
+
+// next returns true if there is another item on the current page, or calls fetch to get the next page. Fetch
+// returns the number of items on the page and the total count of items.
+func (p *page) next(ctx context.Context, fetch func(ctx context.Context, offset, limit int) (int, int, error)) bool {
+	if p.err != nil {
+		return false
+	}
+	if p.index+1 < p.size {
+		p.index++
+		return true
+	}
+	if p.done {
+		return false
+	}
+
+	count, totalCount, err := fetch(ctx, p.offset, p.limit)
+	if err != nil {
+		p.err = err
+		return false
+	}
+
+	p.offset += count
+	p.index = 0
+	p.size = count
+	p.done = count == 0 || p.offset >= totalCount
+	return count > 0
+}
+
+// CollectionIterator reads every collection matching the query, a page at a time
+type CollectionIterator struct {
+	client      *Client
+	queryParams QueryParams
+	items       []models.Collection
+	page        page
+}
+
+// IterateCollections returns an iterator over the collections matching the query. The query's offset is where the
+// iterator starts, and its limit is the size of each page.
+//
+//	it := c.IterateCollections(client.QueryParams{Limit: 100})
+//	for it.Next(ctx) {
+//		collection := it.Collection()
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+func (c *Client) IterateCollections(queryParams QueryParams) *CollectionIterator {
+	return &CollectionIterator{
+		client:      c,
+		queryParams: queryParams,
+		page:        page{offset: queryParams.Offset, limit: queryParams.Limit},
+	}
+}
+
+// Next advances to the next collection, fetching the next page if needed. It returns false when there are no more
+// collections, or if a page could not be fetched.
+func (it *CollectionIterator) Next(ctx context.Context) bool {
+	return it.page.next(ctx, func(ctx context.Context, offset, limit int) (int, int, error) {
+		queryParams := it.queryParams
+		queryParams.Offset = offset
+		queryParams.Limit = limit
+
+		response, err := it.client.GetCollections(ctx, queryParams)
+		if err != nil {
+			return 0, 0, err
+		}
+		it.items = response.Items
+		return len(response.Items), response.TotalCount, nil
+	})
+}
+
+// Collection returns the current collection
+func (it *CollectionIterator) Collection() models.Collection {
+	return it.items[it.page.index]
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *CollectionIterator) Err() error {
+	return it.page.err
+}
+
+// EventIterator reads every event for a collection, a page at a time
+type EventIterator struct {
+	client *Client
+	id     string
+	items  []models.Event
+	page   page
+}
+
+// IterateEvents returns an iterator over the events for a collection, fetching pages of the given size. A limit of
+// zero uses the API's default page size.
+func (c *Client) IterateEvents(id string, limit int) *EventIterator {
+	return &EventIterator{
+		client: c,
+		id:     id,
+		page:   page{limit: limit},
+	}
+}
+
+// Next advances to the next event, fetching the next page if needed. It returns false when there are no more
+// events, or if a page could not be fetched.
+func (it *EventIterator) Next(ctx context.Context) bool {
+	return it.page.next(ctx, func(ctx context.Context, offset, limit int) (int, int, error) {
+		response, err := it.client.GetEvents(ctx, it.id, offset, limit)
+		if err != nil {
+			return 0, 0, err
+		}
+		it.items = response.Items
+		return len(response.Items), response.TotalCount, nil
+	})
+}
+
+// Event returns the current event
+func (it *EventIterator) Event() models.Event {
+	return it.items[it.page.index]
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *EventIterator) Err() error {
+	return it.page.err
+}
@@ -0,0 +1,369 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/client"
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/dp-collection-api/zebedee"
+)
+
+const (
+	defaultURL          = "http://localhost:26000"
+	defaultPollInterval = 5 * time.Second
+)
+
+var (
+	errUsage = errors.New("usage: collectionctl list|get|create|update|state|events|zebedee [flags] [arguments]")
+
+	// errUnschedule is returned when asked to unschedule a collection, as the API cannot remove a publish date
+	errUnschedule = errors.New("a collection cannot be unscheduled, as the API does not remove a publish date")
+)
+
+type command func(ctx context.Context, args []string, w io.Writer, getenv func(string) string) error
+
+var commands = map[string]command{
+	"list":    list,
+	"get":     get,
+	"create":  create,
+	"update":  update,
+	"state":   state,
+	"events":  events,
+	"zebedee": importZebedee,
+}
+
+// options are the flags accepted by every command
+type options struct {
+	url    string
+	token  string
+	output string
+}
+
+func newFlagSet(name string, getenv func(string) string) (*flag.FlagSet, *options) {
+	o := &options{}
+
+	url := getenv("COLLECTION_API_URL")
+	if len(url) == 0 {
+		url = defaultURL
+	}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&o.url, "url", url, "the base URL of the collection API (COLLECTION_API_URL)")
+	fs.StringVar(&o.token, "token", getenv("SERVICE_AUTH_TOKEN"), "the token sent as the Authorization header (SERVICE_AUTH_TOKEN)")
+	fs.StringVar(&o.output, "output", outputTable, "the output format: table, json or csv")
+	return fs, o
+}
+
+func (o *options) client() *client.Client {
+	return client.NewWithHTTPClient(o.url, &authClient{token: o.token, httpClient: http.DefaultClient})
+}
+
+// authClient adds the token to each request
+type authClient struct {
+	token      string
+	httpClient client.HTTPClient
+}
+
+func (c *authClient) Do(req *http.Request) (*http.Response, error) {
+	if len(c.token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	return c.httpClient.Do(req)
+}
+
+// parse parses the command's flags, and checks that the expected number of arguments remain
+func parse(fs *flag.FlagSet, args []string, nArgs int, usage string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != nArgs {
+		return fmt.Errorf("usage: collectionctl %s %s", fs.Name(), usage)
+	}
+	return nil
+}
+
+func list(ctx context.Context, args []string, w io.Writer, getenv func(string) string) error {
+	fs, o := newFlagSet("list", getenv)
+	name := fs.String("name", "", "only list collections with a name containing this text")
+	orderBy := fs.String("order-by", "", "the order of the collections: publish_date, or the order they were created in")
+	offset := fs.Int("offset", 0, "the number of collections to skip")
+	limit := fs.Int("limit", 0, "the number of collections to list, or the page size with -all. Defaults to the API's page size")
+	all := fs.Bool("all", false, "list every collection, reading a page at a time")
+	if err := parse(fs, args, 0, "[flags]"); err != nil {
+		return err
+	}
+
+	p, err := newPrinter(o.output, w)
+	if err != nil {
+		return err
+	}
+
+	c := o.client()
+	queryParams := client.QueryParams{Offset: *offset, Limit: *limit, OrderBy: *orderBy, Name: *name}
+
+	if !*all {
+		response, err := c.GetCollections(ctx, queryParams)
+		if err != nil {
+			return err
+		}
+		return p.printCollections(response.Items)
+	}
+
+	var items []models.Collection
+	it := c.IterateCollections(queryParams)
+	for it.Next(ctx) {
+		items = append(items, it.Collection())
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+	return p.printCollections(items)
+}
+
+func get(ctx context.Context, args []string, w io.Writer, getenv func(string) string) error {
+	fs, o := newFlagSet("get", getenv)
+	if err := parse(fs, args, 1, "[flags] ID"); err != nil {
+		return err
+	}
+
+	p, err := newPrinter(o.output, w)
+	if err != nil {
+		return err
+	}
+
+	collection, err := o.client().GetCollection(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return p.printCollections([]models.Collection{*collection})
+}
+
+func create(ctx context.Context, args []string, w io.Writer, getenv func(string) string) error {
+	fs, o := newFlagSet("create", getenv)
+	name := fs.String("name", "", "the name of the collection")
+	publishDate := fs.String("publish-date", "", "the time to publish the collection, in RFC 3339 format")
+	idempotencyKey := fs.String("idempotency-key", "", "a key that makes it safe to retry the command")
+	if err := parse(fs, args, 0, "-name NAME [flags]"); err != nil {
+		return err
+	}
+
+	p, err := newPrinter(o.output, w)
+	if err != nil {
+		return err
+	}
+
+	collection := &models.Collection{Name: *name}
+	if collection.PublishDate, err = parseTime(*publishDate); err != nil {
+		return err
+	}
+
+	created, err := o.client().CreateCollectionWithIdempotencyKey(ctx, collection, *idempotencyKey)
+	if err != nil {
+		return err
+	}
+	return p.printCollections([]models.Collection{*created})
+}
+
+func update(ctx context.Context, args []string, w io.Writer, getenv func(string) string) error {
+	fs, o := newFlagSet("update", getenv)
+	name := fs.String("name", "", "the new name of the collection")
+	publishDate := fs.String("publish-date", "", "the new time to publish the collection, in RFC 3339 format")
+	if err := parse(fs, args, 1, "[flags] ID"); err != nil {
+		return err
+	}
+
+	p, err := newPrinter(o.output, w)
+	if err != nil {
+		return err
+	}
+
+	date, err := parseTime(*publishDate)
+	if err != nil {
+		return err
+	}
+
+	return updateCollection(ctx, o.client(), p, fs.Arg(0), func(collection *models.Collection) {
+		if len(*name) > 0 {
+			collection.Name = *name
+		}
+		if date != nil {
+			collection.PublishDate = date
+		}
+	})
+}
+
+func state(ctx context.Context, args []string, w io.Writer, getenv func(string) string) error {
+	fs, o := newFlagSet("state", getenv)
+	publishDate := fs.String("publish-date", "", "the time to publish a scheduled collection, in RFC 3339 format")
+	if err := parse(fs, args, 2, "[flags] ID scheduled|published"); err != nil {
+		return err
+	}
+
+	p, err := newPrinter(o.output, w)
+	if err != nil {
+		return err
+	}
+
+	var date *time.Time
+	switch fs.Arg(1) {
+	case models.StateScheduled:
+		if date, err = parseTime(*publishDate); err != nil {
+			return err
+		}
+		if date == nil || !date.After(time.Now()) {
+			return errors.New("a scheduled collection needs a -publish-date in the future")
+		}
+	case models.StatePublished:
+		now := time.Now().UTC()
+		date = &now
+	case models.StateUnscheduled:
+		return errUnschedule
+	default:
+		return fmt.Errorf("unknown state %q, expected scheduled or published", fs.Arg(1))
+	}
+
+	return updateCollection(ctx, o.client(), p, fs.Arg(0), func(collection *models.Collection) {
+		collection.PublishDate = date
+	})
+}
+
+// updateCollection reads the collection, changes it, and writes it back using the ETag that was read, so that a
+// concurrent change is not overwritten
+func updateCollection(ctx context.Context, c *client.Client, p *printer, id string, change func(*models.Collection)) error {
+	collection, err := c.GetCollection(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	change(collection)
+
+	updated, err := c.UpdateCollection(ctx, collection)
+	if err != nil {
+		return err
+	}
+	return p.printCollections([]models.Collection{*updated})
+}
+
+func events(ctx context.Context, args []string, w io.Writer, getenv func(string) string) error {
+	fs, o := newFlagSet("events", getenv)
+	follow := fs.Bool("follow", false, "keep polling for new events until interrupted")
+	interval := fs.Duration("interval", defaultPollInterval, "how often to poll for new events with -follow")
+	limit := fs.Int("limit", 0, "the page size. Defaults to the API's page size")
+	if err := parse(fs, args, 1, "[flags] ID"); err != nil {
+		return err
+	}
+
+	p, err := newPrinter(o.output, w)
+	if err != nil {
+		return err
+	}
+
+	c := o.client()
+	offset := 0
+
+	for {
+		for {
+			response, err := c.GetEvents(ctx, fs.Arg(0), offset, *limit)
+			if err != nil {
+				if *follow && ctx.Err() != nil {
+					// interrupted while following
+					return nil
+				}
+				return err
+			}
+			if len(response.Items) > 0 {
+				if err := p.printEvents(response.Items); err != nil {
+					return err
+				}
+			}
+
+			offset += len(response.Items)
+			if len(response.Items) == 0 || offset >= response.TotalCount {
+				break
+			}
+		}
+
+		if !*follow {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(*interval):
+		}
+	}
+}
+
+// importZebedee imports the collections in a Zebedee collections directory. Anything that could not be mapped is
+// written first, followed by the result of each collection, and an error is returned if any collection failed.
+func importZebedee(ctx context.Context, args []string, w io.Writer, getenv func(string) string) error {
+	fs, o := newFlagSet("zebedee", getenv)
+	onConflict := fs.String("on-conflict", "", "what to do with a collection that already exists: skip, overwrite or fail. Defaults to fail")
+	dryRun := fs.Bool("dry-run", false, "write the collections that would be imported, without importing them")
+	if err := parse(fs, args, 1, "[flags] DIRECTORY"); err != nil {
+		return err
+	}
+
+	policy, err := collections.ParseConflictPolicy(*onConflict)
+	if err != nil {
+		return err
+	}
+
+	// each section is written by its own printer, so that it has its own header
+	issues, err := newPrinter(o.output, w)
+	if err != nil {
+		return err
+	}
+	results, _ := newPrinter(o.output, w)
+
+	result, err := zebedee.ReadDir(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if len(result.Issues) > 0 {
+		if err := issues.printIssues(result.Issues); err != nil {
+			return err
+		}
+	}
+
+	if *dryRun {
+		items := make([]models.Collection, 0, len(result.Collections))
+		for i := range result.Collections {
+			items = append(items, result.Collections[i].Collection)
+		}
+		return results.printCollections(items)
+	}
+
+	report, err := o.client().Import(ctx, result.Collections, policy)
+	if err != nil {
+		return err
+	}
+	if err := results.printImportResults(report.Results); err != nil {
+		return err
+	}
+
+	if report.Failed > 0 {
+		return fmt.Errorf("%d of %d collections failed to import", report.Failed, len(result.Collections))
+	}
+	return nil
+}
+
+func parseTime(value string) (*time.Time, error) {
+	if len(value) == 0 {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time %q, expected RFC 3339 format e.g. 2021-06-01T09:30:00Z", value)
+	}
+	return &t, nil
+}
@@ -0,0 +1,45 @@
+// Command collectionctl is a command-line client for the collection API.
+//
+//	collectionctl list [-name NAME] [-order-by publish_date] [-offset N] [-limit N] [-all]
+//	collectionctl get ID
+//	collectionctl create -name NAME [-publish-date TIME] [-idempotency-key KEY]
+//	collectionctl update [-name NAME] [-publish-date TIME] ID
+//	collectionctl state [-publish-date TIME] ID scheduled|published
+//	collectionctl events [-follow] [-interval DURATION] ID
+//	collectionctl zebedee [-on-conflict skip|overwrite|fail] [-dry-run] DIRECTORY
+//
+// Every command accepts -url, -token and -output flags. The URL and token default to the COLLECTION_API_URL and
+// SERVICE_AUTH_TOKEN environment variables. Output is a table, JSON or CSV.
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdout, os.Getenv); err != nil {
+		fmt.Fprintln(os.Stderr, "collectionctl:", err)
+		os.Exit(1)
+	}
+}
+
+// run runs the command named by the first argument, writing its output to w
+func run(ctx context.Context, args []string, w io.Writer, getenv func(string) string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	command, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q: %w", args[0], errUsage)
+	}
+
+	return command(ctx, args[1:], w, getenv)
+}
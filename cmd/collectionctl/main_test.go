@@ -0,0 +1,306 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/api"
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/config"
+	"github.com/ONSdigital/dp-collection-api/memory"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/dp-collection-api/pagination"
+	"github.com/gorilla/mux"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var ctx = context.Background()
+
+// newAPI starts the API with an in-memory store, returning an environment that points collectionctl at it and the
+// authorization header of the last request
+func newAPI(t *testing.T) (func(string) string, *memory.Store, *string) {
+	store := memory.New()
+	cfg := &config.Config{MaxRequestBodyBytes: 1024 * 1024, IdempotencyKeyTTL: time.Hour}
+
+	var authorization string
+	r := mux.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			authorization = req.Header.Get("Authorization")
+			next.ServeHTTP(w, req)
+		})
+	})
+	api.Setup(ctx, cfg, r, pagination.NewPaginator(20, 0, 1000), store, store)
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+
+	env := map[string]string{
+		"COLLECTION_API_URL": server.URL,
+		"SERVICE_AUTH_TOKEN": "secret",
+	}
+	return func(name string) string { return env[name] }, store, &authorization
+}
+
+func runCommand(getenv func(string) string, args ...string) (string, error) {
+	var b bytes.Buffer
+	err := run(ctx, args, &b, getenv)
+	return b.String(), err
+}
+
+// createCollection runs the create command, returning the created collection
+func createCollection(getenv func(string) string, args ...string) models.Collection {
+	output, err := runCommand(getenv, append([]string{"create", "-output", "json"}, args...)...)
+	So(err, ShouldBeNil)
+
+	var collection models.Collection
+	So(json.Unmarshal([]byte(output), &collection), ShouldBeNil)
+	return collection
+}
+
+func TestCollectionctl(t *testing.T) {
+
+	Convey("Given collectionctl is configured from the environment", t, func() {
+		getenv, _, authorization := newAPI(t)
+
+		Convey("When a collection is created", func() {
+			created := createCollection(getenv, "-name", "collection 1")
+
+			Convey("Then the token from the environment is sent", func() {
+				So(*authorization, ShouldEqual, "Bearer secret")
+			})
+
+			Convey("Then it is listed as a table", func() {
+				output, err := runCommand(getenv, "list")
+				So(err, ShouldBeNil)
+
+				lines := strings.Split(strings.TrimSpace(output), "\n")
+				So(lines, ShouldHaveLength, 2)
+				So(lines[0], ShouldStartWith, "ID")
+				So(lines[1], ShouldContainSubstring, created.ID)
+				So(lines[1], ShouldContainSubstring, "collection 1")
+				So(lines[1], ShouldContainSubstring, models.StateUnscheduled)
+			})
+
+			Convey("Then it can be read as CSV", func() {
+				output, err := runCommand(getenv, "get", "-output", "csv", created.ID)
+				So(err, ShouldBeNil)
+				So(output, ShouldEqual, "ID,NAME,PUBLISH DATE,STATE,ETAG\n"+
+					created.ID+",collection 1,,unscheduled,"+created.ETag+"\n")
+			})
+
+			Convey("Then it can be renamed", func() {
+				output, err := runCommand(getenv, "update", "-output", "json", "-name", "renamed", created.ID)
+				So(err, ShouldBeNil)
+
+				var updated models.Collection
+				So(json.Unmarshal([]byte(output), &updated), ShouldBeNil)
+				So(updated.Name, ShouldEqual, "renamed")
+				So(updated.ETag, ShouldNotEqual, created.ETag)
+			})
+
+			Convey("Then it can be scheduled", func() {
+				publishDate := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+				output, err := runCommand(getenv, "state", "-publish-date", publishDate, created.ID, "scheduled")
+				So(err, ShouldBeNil)
+				So(output, ShouldContainSubstring, publishDate)
+				So(output, ShouldContainSubstring, models.StateScheduled)
+			})
+
+			Convey("Then it can be published", func() {
+				output, err := runCommand(getenv, "state", created.ID, "published")
+				So(err, ShouldBeNil)
+				So(output, ShouldContainSubstring, models.StatePublished)
+			})
+
+			Convey("Then it cannot be scheduled without a publish date", func() {
+				_, err := runCommand(getenv, "state", created.ID, "scheduled")
+				So(err, ShouldNotBeNil)
+			})
+
+			Convey("Then it cannot be unscheduled", func() {
+				_, err := runCommand(getenv, "state", created.ID, "unscheduled")
+				So(err, ShouldEqual, errUnschedule)
+			})
+		})
+
+		Convey("When a collection is created with a name that is already used", func() {
+			createCollection(getenv, "-name", "collection 1")
+			_, err := runCommand(getenv, "create", "-name", "collection 1")
+
+			Convey("Then the API error is returned", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "a collection with this name already exists")
+			})
+		})
+
+		Convey("When every collection is listed a page at a time", func() {
+			for _, name := range []string{"a", "b", "c"} {
+				createCollection(getenv, "-name", name)
+			}
+			output, err := runCommand(getenv, "list", "-all", "-limit", "2", "-output", "csv")
+
+			Convey("Then every collection is written", func() {
+				So(err, ShouldBeNil)
+				So(strings.Split(strings.TrimSpace(output), "\n"), ShouldHaveLength, 4)
+			})
+		})
+
+		Convey("When the URL and token are given as flags", func() {
+			_, err := runCommand(getenv, "list", "-url", "http://localhost:1", "-token", "other")
+
+			Convey("Then the flags are used instead of the environment", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "localhost:1")
+			})
+		})
+
+		Convey("When an unknown output format is given", func() {
+			_, err := runCommand(getenv, "list", "-output", "xml")
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When an unknown command is given", func() {
+			_, err := runCommand(getenv, "delete")
+
+			Convey("Then the usage is returned", func() {
+				So(err.Error(), ShouldContainSubstring, "usage")
+			})
+		})
+	})
+}
+
+func TestCollectionctl_events(t *testing.T) {
+
+	date := time.Date(2021, 6, 1, 9, 0, 0, 0, time.UTC)
+	api.Now = func() time.Time { return date }
+	t.Cleanup(func() { api.Now = time.Now })
+
+	Convey("Given a collection with the event recording its creation, and another event", t, func() {
+		getenv, store, _ := newAPI(t)
+		collection := createCollection(getenv, "-name", "collection 1")
+
+		err := store.AddEvent(ctx, &models.Event{CollectionID: collection.ID, Type: "UPDATED", Email: "a@b.c", Date: date.Add(time.Hour)})
+		So(err, ShouldBeNil)
+
+		Convey("When the events are listed one page at a time", func() {
+			output, err := runCommand(getenv, "events", "-limit", "1", "-output", "csv", collection.ID)
+
+			Convey("Then every event is written under one header", func() {
+				So(err, ShouldBeNil)
+				So(output, ShouldEqual, "DATE,TYPE,EMAIL\n"+
+					"2021-06-01T09:00:00Z,CREATED,\n"+
+					"2021-06-01T10:00:00Z,UPDATED,a@b.c\n")
+			})
+		})
+
+		Convey("When the events are followed", func() {
+			followCtx, cancel := context.WithCancel(ctx)
+			var b bytes.Buffer
+			done := make(chan error)
+			go func() {
+				done <- run(followCtx, []string{"events", "-follow", "-interval", "10ms", "-output", "json", collection.ID}, &b, getenv)
+			}()
+
+			time.Sleep(50 * time.Millisecond)
+			err := store.AddEvent(ctx, &models.Event{CollectionID: collection.ID, Type: "PUBLISHED", Date: date.Add(2 * time.Hour)})
+			So(err, ShouldBeNil)
+			time.Sleep(50 * time.Millisecond)
+			cancel()
+
+			Convey("Then new events are written until interrupted", func() {
+				So(<-done, ShouldBeNil)
+				lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+				So(lines, ShouldHaveLength, 3)
+				So(lines[2], ShouldContainSubstring, "PUBLISHED")
+			})
+		})
+	})
+}
+
+func TestCollectionctl_zebedee(t *testing.T) {
+
+	Convey("Given a Zebedee collections directory", t, func() {
+		getenv, store, _ := newAPI(t)
+
+		dir := t.TempDir()
+		files := map[string]string{
+			"release1.json": `{"id":"release1-4d5c","name":"Release 1","type":"scheduled","publishDate":"2100-01-16T09:30:00.000Z",` +
+				`"events":[{"date":"2021-06-01T09:00:00.000Z","type":"CREATED","email":"a@b.c"}]}`,
+			"release2.json": `{"id":"release2-8e9f","name":"Release 2","type":"manual","teams":["Economy"]}`,
+		}
+		for name, content := range files {
+			So(os.WriteFile(filepath.Join(dir, name), []byte(content), 0600), ShouldBeNil)
+		}
+
+		Convey("When it is imported", func() {
+			output, err := runCommand(getenv, "zebedee", "-output", "csv", dir)
+
+			Convey("Then the issues are written, followed by the result of each collection", func() {
+				So(err, ShouldBeNil)
+				lines := strings.Split(strings.TrimSpace(output), "\n")
+				So(lines, ShouldHaveLength, 5)
+				So(lines[0], ShouldEqual, "FILE,NAME,FIELD,ISSUE")
+				So(lines[1], ShouldStartWith, "release2.json,Release 2,teams,")
+				So(lines[2], ShouldEqual, "ID,NAME,RESULT,ERRORS")
+				So(lines[3], ShouldEndWith, ",Release 1,created,")
+				So(lines[4], ShouldEndWith, ",Release 2,created,")
+			})
+
+			Convey("Then the collections and their events are stored", func() {
+				items, totalCount, err := store.GetCollections(ctx, collections.QueryParams{Limit: 10})
+				So(err, ShouldBeNil)
+				So(totalCount, ShouldEqual, 2)
+
+				events, _, err := store.GetCollectionEvents(ctx, collections.EventsQueryParams{CollectionID: items[0].ID, Limit: 10})
+				So(err, ShouldBeNil)
+				So(events, ShouldHaveLength, 2)
+				So(events[1].Type, ShouldEqual, models.EventCreated)
+			})
+
+			Convey("And it is imported again, skipping existing collections", func() {
+				output, err := runCommand(getenv, "zebedee", "-on-conflict", "skip", "-output", "csv", dir)
+
+				Convey("Then each collection is skipped", func() {
+					So(err, ShouldBeNil)
+					So(output, ShouldContainSubstring, ",Release 1,skipped,")
+					So(output, ShouldContainSubstring, ",Release 2,skipped,")
+				})
+			})
+
+			Convey("And it is imported again, failing on existing collections", func() {
+				_, err := runCommand(getenv, "zebedee", "-output", "csv", dir)
+
+				Convey("Then an error is returned", func() {
+					So(err, ShouldNotBeNil)
+					So(err.Error(), ShouldContainSubstring, "1 of 2 collections failed to import")
+				})
+			})
+		})
+
+		Convey("When it is imported as a dry run", func() {
+			output, err := runCommand(getenv, "zebedee", "-dry-run", "-output", "csv", dir)
+
+			Convey("Then the collections are written, but not imported", func() {
+				So(err, ShouldBeNil)
+				So(output, ShouldContainSubstring, ",Release 1,2100-01-16T09:30:00Z,scheduled,")
+
+				_, totalCount, err := store.GetCollections(ctx, collections.QueryParams{Limit: 10})
+				So(err, ShouldBeNil)
+				So(totalCount, ShouldEqual, 0)
+			})
+		})
+	})
+}
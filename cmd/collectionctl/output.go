@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/dp-collection-api/zebedee"
+)
+
+// Output formats
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputCSV   = "csv"
+)
+
+var (
+	collectionColumns = []string{"ID", "NAME", "PUBLISH DATE", "STATE", "ETAG"}
+	eventColumns      = []string{"DATE", "TYPE", "EMAIL"}
+	issueColumns      = []string{"FILE", "NAME", "FIELD", "ISSUE"}
+	importColumns     = []string{"ID", "NAME", "RESULT", "ERRORS"}
+)
+
+// printer writes items in the output format. JSON is written as one object per line, and the table and CSV headers
+// are written once, so that the output of a command that prints more than once, e.g. events -follow, can be read
+// as a single stream.
+type printer struct {
+	format      string
+	w           io.Writer
+	wroteHeader bool
+}
+
+func newPrinter(format string, w io.Writer) (*printer, error) {
+	switch format {
+	case outputTable, outputJSON, outputCSV:
+		return &printer{format: format, w: w}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q, expected table, json or csv", format)
+	}
+}
+
+func (p *printer) printCollections(items []models.Collection) error {
+	now := time.Now()
+	rows := make([][]string, 0, len(items))
+	values := make([]interface{}, 0, len(items))
+
+	for i := range items {
+		collection := items[i]
+		rows = append(rows, []string{
+			collection.ID,
+			collection.Name,
+			formatTime(collection.PublishDate),
+			collection.State(now),
+			collection.ETag,
+		})
+		values = append(values, collection)
+	}
+
+	return p.print(collectionColumns, rows, values)
+}
+
+func (p *printer) printEvents(items []models.Event) error {
+	rows := make([][]string, 0, len(items))
+	values := make([]interface{}, 0, len(items))
+
+	for i := range items {
+		event := items[i]
+		rows = append(rows, []string{formatTime(&event.Date), event.Type, event.Email})
+		values = append(values, event)
+	}
+
+	return p.print(eventColumns, rows, values)
+}
+
+func (p *printer) printIssues(items []zebedee.Issue) error {
+	rows := make([][]string, 0, len(items))
+	values := make([]interface{}, 0, len(items))
+
+	for _, issue := range items {
+		rows = append(rows, []string{issue.File, issue.Name, issue.Field, issue.Message})
+		values = append(values, issue)
+	}
+
+	return p.print(issueColumns, rows, values)
+}
+
+func (p *printer) printImportResults(items []models.ImportResult) error {
+	rows := make([][]string, 0, len(items))
+	values := make([]interface{}, 0, len(items))
+
+	for _, result := range items {
+		messages := make([]string, 0, len(result.Errors))
+		for _, e := range result.Errors {
+			messages = append(messages, e.Message)
+		}
+		rows = append(rows, []string{result.ID, result.Name, result.Result, strings.Join(messages, "; ")})
+		values = append(values, result)
+	}
+
+	return p.print(importColumns, rows, values)
+}
+
+func (p *printer) print(columns []string, rows [][]string, values []interface{}) error {
+	switch p.format {
+	case outputJSON:
+		encoder := json.NewEncoder(p.w)
+		for _, value := range values {
+			if err := encoder.Encode(value); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case outputCSV:
+		writer := csv.NewWriter(p.w)
+		if !p.wroteHeader {
+			if err := writer.Write(columns); err != nil {
+				return err
+			}
+			p.wroteHeader = true
+		}
+		return writer.WriteAll(rows)
+
+	default:
+		writer := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+		if !p.wroteHeader {
+			fmt.Fprintln(writer, strings.Join(columns, "\t"))
+			p.wroteHeader = true
+		}
+		for _, row := range rows {
+			fmt.Fprintln(writer, strings.Join(row, "\t"))
+		}
+		return writer.Flush()
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
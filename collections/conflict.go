@@ -0,0 +1,28 @@
+package collections
+
+// ConflictPolicy is what an import does with a collection that has the ID or name of an existing collection
+type ConflictPolicy string
+
+const (
+	// ConflictPolicySkip leaves the existing collection unchanged, and carries on with the import
+	ConflictPolicySkip ConflictPolicy = "skip"
+
+	// ConflictPolicyOverwrite replaces the existing collection with the same ID. A collection with the name of a
+	// different existing collection is never overwritten, as names must be unique.
+	ConflictPolicyOverwrite ConflictPolicy = "overwrite"
+
+	// ConflictPolicyFail stops the import at the first collection that cannot be imported
+	ConflictPolicyFail ConflictPolicy = "fail"
+)
+
+// ParseConflictPolicy parses the given string as a conflict policy. If it is empty, then ConflictPolicyFail is used.
+func ParseConflictPolicy(input string) (ConflictPolicy, error) {
+	switch policy := ConflictPolicy(input); policy {
+	case "":
+		return ConflictPolicyFail, nil
+	case ConflictPolicySkip, ConflictPolicyOverwrite, ConflictPolicyFail:
+		return policy, nil
+	default:
+		return "", ErrInvalidConflictPolicy
+	}
+}
@@ -0,0 +1,29 @@
+package collections
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseConflictPolicy(t *testing.T) {
+
+	Convey("ParseConflictPolicy returns fail for an empty value", t, func() {
+		policy, err := ParseConflictPolicy("")
+		So(policy, ShouldEqual, ConflictPolicyFail)
+		So(err, ShouldBeNil)
+	})
+
+	Convey("ParseConflictPolicy parses valid values", t, func() {
+		for _, expected := range []ConflictPolicy{ConflictPolicySkip, ConflictPolicyOverwrite, ConflictPolicyFail} {
+			policy, err := ParseConflictPolicy(string(expected))
+			So(policy, ShouldEqual, expected)
+			So(err, ShouldBeNil)
+		}
+	})
+
+	Convey("ParseConflictPolicy returns an error for an unrecognised value", t, func() {
+		_, err := ParseConflictPolicy("replace")
+		So(err, ShouldEqual, ErrInvalidConflictPolicy)
+	})
+}
@@ -17,6 +17,10 @@ const (
 
 	// OrderByPublishDate is used to order results by publish date
 	OrderByPublishDate
+
+	// OrderByID is used to order results by ID, to page through them with QueryParams.AfterID. It cannot be requested
+	// in the query string.
+	OrderByID
 )
 
 // supportedOrderBy defines the supported order by values for ordering collection results.
@@ -27,7 +31,7 @@ var supportedOrderBy = map[string]OrderBy{
 
 // String returns a string representation of the OrderBy instance
 func (ob OrderBy) String() string {
-	return []string{"default", "publish_date"}[ob]
+	return []string{"default", "publish_date", "id"}[ob]
 }
 
 // ParseOrderBy parses the given string as an orderBy value
@@ -11,6 +11,9 @@ var ErrCollectionNameAlreadyExists = errors.New("a collection with this name alr
 // ErrCollectionNameEmpty is the error used when an empty collection name is provided
 var ErrCollectionNameEmpty = errors.New("the collection name field must be specified")
 
+// ErrPublishDateInPast is the error used when a collection publish date is not in the future
+var ErrPublishDateInPast = errors.New("the collection publish date must be in the future")
+
 // ErrCollectionIDEmpty is the error used when an empty collection ID is provided
 var ErrCollectionIDEmpty = errors.New("the collection id field must be specified")
 
@@ -29,12 +32,92 @@ var ErrCollectionConflict = errors.New("out of date collection resource")
 // ErrNoIfMatchHeader is the error used when an If-Match is required but not provided
 var ErrNoIfMatchHeader = errors.New("required If-Match header not provided")
 
+// ErrCollectionIDMismatch is the error used when the ID in a request body does not match the ID in the URL
+var ErrCollectionIDMismatch = errors.New("the collection id in the request body does not match the id in the URL")
+
+// ErrCollectionIDReadOnly is the error used when a collection ID is provided in a request to create a collection
+var ErrCollectionIDReadOnly = errors.New("the collection id field is read-only and cannot be provided")
+
+// ErrETagReadOnly is the error used when an e_tag value is provided in a request body
+var ErrETagReadOnly = errors.New("the e_tag field is read-only and cannot be provided")
+
+// ErrLastUpdatedReadOnly is the error used when a last_updated value is provided in a request body
+var ErrLastUpdatedReadOnly = errors.New("the last_updated field is read-only and cannot be provided")
+
+// ErrPublishResultReadOnly is the error used when a publish_result value is provided in a request body
+var ErrPublishResultReadOnly = errors.New("the publish_result field is read-only and cannot be provided")
+
+// ErrIdempotencyKeyTooLong is the error used when an Idempotency-Key header value is larger than the maximum allowed
+var ErrIdempotencyKeyTooLong = errors.New("the Idempotency-Key header is >255 chars")
+
+// ErrIdempotencyKeyReused is the error used when an Idempotency-Key is reused with a different request
+var ErrIdempotencyKeyReused = errors.New("the Idempotency-Key has already been used for a different request")
+
+// ErrIdempotencyKeyInProgress is the error used when a request with the same Idempotency-Key is still being processed
+var ErrIdempotencyKeyInProgress = errors.New("a request with this Idempotency-Key is already being processed")
+
+// ErrIdempotencyKeyExists is the error used when an unexpired record already exists for an Idempotency-Key
+var ErrIdempotencyKeyExists = errors.New("idempotency key already exists")
+
+// ErrIdempotencyRecordNotFound is the error used when no unexpired record exists for an Idempotency-Key
+var ErrIdempotencyRecordNotFound = errors.New("idempotency record not found")
+
+// ErrCollectionAlreadyExists is the error used when an imported collection has the ID of an existing collection
+var ErrCollectionAlreadyExists = errors.New("a collection with this id already exists")
+
+// ErrInvalidConflictPolicy is the error used when an import conflict policy is not one of the supported policies
+var ErrInvalidConflictPolicy = errors.New("invalid on_conflict query parameter, expected skip, overwrite or fail")
+
+// ErrInvalidEventsParameter is the error used when the events query parameter of an export is not a boolean
+var ErrInvalidEventsParameter = errors.New("invalid events query parameter, expected true or false")
+
+// ErrSubscriptionNotFound is the error used when a particular subscription is not found
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
+// ErrInvalidSubscriptionID is the error used when an invalid subscription ID format is used
+var ErrInvalidSubscriptionID = errors.New("subscription id must be valid UUID")
+
+// ErrInvalidCallbackURL is the error used when a subscription URL is not an absolute http or https URL, or its host
+// is on a private network
+var ErrInvalidCallbackURL = errors.New("the url field must be an absolute http or https URL of a public host")
+
+// ErrInvalidEventType is the error used when a subscription is for an event type that is not recorded
+var ErrInvalidEventType = errors.New("the event_types field contains an unknown event type")
+
+// ErrInvalidDeliveryStatus is the error used when the status query parameter of a delivery log is not a delivery state
+var ErrInvalidDeliveryStatus = errors.New("invalid status query parameter, expected pending, delivered or dead")
+
+// ErrVersionNotFound is the error used when a version of a collection cannot be found
+var ErrVersionNotFound = errors.New("collection version not found")
+
+// ErrInvalidVersion is the error used when a collection version number in a URL is not a positive integer
+var ErrInvalidVersion = errors.New("collection version must be a positive integer")
+
+// ErrVersionAlreadyExists is the error used when a snapshot is added for a version of a collection that already has one
+var ErrVersionAlreadyExists = errors.New("a snapshot of this collection version already exists")
+
+// ErrVersionReadOnly is the error used when the client provides the read-only version field
+var ErrVersionReadOnly = errors.New("the version field is read-only and cannot be provided")
+
+// ErrUnableToParseJSON is the error used when a request body is not valid JSON
+var ErrUnableToParseJSON = errors.New("failed to parse json body")
+
+// ErrUnknownJSONField is the error used when a request body contains a field that is not part of the resource
+var ErrUnknownJSONField = errors.New("json body contains an unknown field")
+
+// ErrRequestBodyTooLarge is the error used when a request body is larger than the maximum allowed
+var ErrRequestBodyTooLarge = errors.New("request body is larger than the maximum allowed")
+
+// ErrUnsupportedContentType is the error used when a request body has a content type that is not supported
+var ErrUnsupportedContentType = errors.New("request content type is not supported")
+
 // QueryParams represents the query parameters that can be sent to get collections
 type QueryParams struct {
 	Offset     int
 	Limit      int
 	OrderBy    OrderBy
 	NameSearch string
+	AfterID    string // only matches the collections with an ID after it, when ordered by ID
 }
 
 // EventsQueryParams represents the parameters to query a collection's events
@@ -44,6 +127,22 @@ type EventsQueryParams struct {
 	Limit        int
 }
 
+// VersionsQueryParams represents the parameters to query the versions of a collection
+type VersionsQueryParams struct {
+	CollectionID string
+	Offset       int
+	Limit        int
+}
+
+// DeliveriesQueryParams represents the parameters to query the deliveries of webhook events. An empty subscription ID
+// or status matches every delivery.
+type DeliveriesQueryParams struct {
+	SubscriptionID string
+	Status         string
+	Offset         int
+	Limit          int
+}
+
 // ValidateNameSearchInput returns an error if the given input is not valid as a name search term
 func ValidateNameSearchInput(input string) error {
 	if len(input) > 64 {
@@ -0,0 +1,174 @@
+package collections
+
+import (
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/models"
+)
+
+// SelectCollections returns the page of collections that match the query parameters, and the total number of matches,
+// in the same way as the MongoDB query. It is used by the datastores that query collections held in memory, so the
+// collections must be given in the order they were added.
+func SelectCollections(values []models.Collection, queryParams QueryParams) ([]models.Collection, int, error) {
+	var nameSearch *regexp.Regexp
+	if len(queryParams.NameSearch) > 0 {
+		var err error
+		nameSearch, err = regexp.Compile("(?i)" + queryParams.NameSearch)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	var matches []models.Collection
+	for _, collection := range values {
+		if len(queryParams.AfterID) > 0 && collection.ID <= queryParams.AfterID {
+			continue
+		}
+		if nameSearch == nil || nameSearch.MatchString(collection.Name) {
+			matches = append(matches, collection)
+		}
+	}
+
+	switch queryParams.OrderBy {
+	case OrderByID:
+		sort.SliceStable(matches, func(i, j int) bool {
+			return matches[i].ID < matches[j].ID
+		})
+	case OrderByPublishDate:
+		// collections without a publish date come first, as they do in MongoDB
+		sort.SliceStable(matches, func(i, j int) bool {
+			a, b := matches[i].PublishDate, matches[j].PublishDate
+			if a == nil || b == nil {
+				return a == nil && b != nil
+			}
+			return a.Before(*b)
+		})
+	}
+
+	page := []models.Collection{}
+	if queryParams.Limit > 0 {
+		start, end := pageRange(len(matches), queryParams.Offset, queryParams.Limit)
+		page = append(page, matches[start:end]...)
+	}
+
+	return page, len(matches), nil
+}
+
+// SelectEvents returns the page of events for the collection in the query parameters, in date order, and the total
+// number of events for the collection
+func SelectEvents(values []models.Event, queryParams EventsQueryParams) ([]models.Event, int) {
+	var matches []models.Event
+	for _, event := range values {
+		if event.CollectionID == queryParams.CollectionID {
+			matches = append(matches, event)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Date.Before(matches[j].Date)
+	})
+
+	page := []models.Event{}
+	if queryParams.Limit > 0 {
+		start, end := pageRange(len(matches), queryParams.Offset, queryParams.Limit)
+		page = append(page, matches[start:end]...)
+	}
+
+	return page, len(matches)
+}
+
+// SelectVersions returns the page of versions of the collection in the query parameters, oldest first, and the total
+// number of versions of the collection
+func SelectVersions(values []models.Version, queryParams VersionsQueryParams) ([]models.Version, int) {
+	var matches []models.Version
+	for _, version := range values {
+		if version.CollectionID == queryParams.CollectionID {
+			matches = append(matches, version)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Version < matches[j].Version
+	})
+
+	page := []models.Version{}
+	if queryParams.Limit > 0 {
+		start, end := pageRange(len(matches), queryParams.Offset, queryParams.Limit)
+		page = append(page, matches[start:end]...)
+	}
+
+	return page, len(matches)
+}
+
+// SelectSubscriptions returns the page of subscriptions given by the offset and limit, and the total number of
+// subscriptions. The subscriptions must be given in the order they were added.
+func SelectSubscriptions(values []models.Subscription, offset, limit int) ([]models.Subscription, int) {
+	page := []models.Subscription{}
+	if limit > 0 {
+		start, end := pageRange(len(values), offset, limit)
+		page = append(page, values[start:end]...)
+	}
+
+	return page, len(values)
+}
+
+// SelectDeliveries returns the page of deliveries that match the query parameters, most recent first, and the total
+// number of matches
+func SelectDeliveries(values []models.Delivery, queryParams DeliveriesQueryParams) ([]models.Delivery, int) {
+	var matches []models.Delivery
+	for _, delivery := range values {
+		if len(queryParams.SubscriptionID) > 0 && delivery.SubscriptionID != queryParams.SubscriptionID {
+			continue
+		}
+		if len(queryParams.Status) > 0 && delivery.Status != queryParams.Status {
+			continue
+		}
+		matches = append(matches, delivery)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].CreatedAt.After(matches[j].CreatedAt)
+	})
+
+	page := []models.Delivery{}
+	if queryParams.Limit > 0 {
+		start, end := pageRange(len(matches), queryParams.Offset, queryParams.Limit)
+		page = append(page, matches[start:end]...)
+	}
+
+	return page, len(matches)
+}
+
+// SelectDueDeliveries returns up to limit pending deliveries that are due to be attempted at the given time, the
+// longest overdue first
+func SelectDueDeliveries(values []models.Delivery, now time.Time, limit int) []models.Delivery {
+	var due []models.Delivery
+	for _, delivery := range values {
+		if delivery.Status == models.DeliveryPending && !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+	})
+
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due
+}
+
+// pageRange returns the start and end indexes of the page of items given by the offset and limit
+func pageRange(total, offset, limit int) (start, end int) {
+	start, end = offset, offset+limit
+	if start > total {
+		start = total
+	}
+	if end > total {
+		end = total
+	}
+	return start, end
+}
@@ -8,24 +8,75 @@ import (
 // Config represents service configuration for dp-collection-api
 type Config struct {
 	BindAddr                   string        `envconfig:"BIND_ADDR"`
+	HTTPWriteTimeout           time.Duration `envconfig:"HTTP_WRITE_TIMEOUT"`
 	GracefulShutdownTimeout    time.Duration `envconfig:"GRACEFUL_SHUTDOWN_TIMEOUT"`
+	ReadinessDrainDelay        time.Duration `envconfig:"READINESS_DRAIN_DELAY"`
 	HealthCheckInterval        time.Duration `envconfig:"HEALTHCHECK_INTERVAL"`
 	HealthCheckCriticalTimeout time.Duration `envconfig:"HEALTHCHECK_CRITICAL_TIMEOUT"`
 	DefaultMaxLimit            int           `envconfig:"DEFAULT_MAXIMUM_LIMIT"`
 	DefaultLimit               int           `envconfig:"DEFAULT_LIMIT"`
 	DefaultOffset              int           `envconfig:"DEFAULT_OFFSET"`
+	MaxRequestBodyBytes        int64         `envconfig:"MAX_REQUEST_BODY_BYTES"`
+	IdempotencyKeyTTL          time.Duration `envconfig:"IDEMPOTENCY_KEY_TTL"`
+	EnableRequestValidation    bool          `envconfig:"ENABLE_REQUEST_VALIDATION"`
+	UpcomingPublishWindow      time.Duration `envconfig:"METRICS_UPCOMING_PUBLISH_WINDOW"`
+	OTExporter                 string        `envconfig:"OTEL_EXPORTER"`
+	OTExporterFilePath         string        `envconfig:"OTEL_EXPORTER_FILE_PATH"`
+	OTExporterOTLPEndpoint     string        `envconfig:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	OTExporterOTLPInsecure     bool          `envconfig:"OTEL_EXPORTER_OTLP_INSECURE"`
+	OTServiceName              string        `envconfig:"OTEL_SERVICE_NAME"`
+	Datastore                  string        `envconfig:"DATASTORE"`
+	BoltPath                   string        `envconfig:"BOLT_PATH"`
+	CacheEnabled               bool          `envconfig:"CACHE_ENABLED"`
+	CacheTTL                   time.Duration `envconfig:"CACHE_TTL"`
+	CacheMaxEntries            int           `envconfig:"CACHE_MAX_ENTRIES"`
+	CacheNotifier              string        `envconfig:"CACHE_NOTIFIER"`
+	ZebedeeFacadeEnabled       bool          `envconfig:"ZEBEDEE_FACADE_ENABLED"`
+	WebhooksEnabled            bool          `envconfig:"WEBHOOKS_ENABLED"`
+	WebhookMaxAttempts         int           `envconfig:"WEBHOOK_MAX_ATTEMPTS"`
+	WebhookInitialBackoff      time.Duration `envconfig:"WEBHOOK_INITIAL_BACKOFF"`
+	WebhookMaxBackoff          time.Duration `envconfig:"WEBHOOK_MAX_BACKOFF"`
+	WebhookPollInterval        time.Duration `envconfig:"WEBHOOK_POLL_INTERVAL"`
+	WebhookTimeout             time.Duration `envconfig:"WEBHOOK_TIMEOUT"`
+	WebhookAllowPrivateIPs     bool          `envconfig:"WEBHOOK_ALLOW_PRIVATE_IPS"`
+	EventStreamSource          string        `envconfig:"EVENT_STREAM_SOURCE"`
+	KafkaConfig                KafkaConfig
 	MongoConfig                MongoConfig
 }
 
+// KafkaConfig contains the config required to publish collection events to Kafka, and consume publish results
+type KafkaConfig struct {
+	Enabled               bool          `envconfig:"KAFKA_ENABLED"`
+	Brokers               []string      `envconfig:"KAFKA_ADDR"`
+	Version               string        `envconfig:"KAFKA_VERSION"`
+	SecProtocol           string        `envconfig:"KAFKA_SEC_PROTO"`
+	CollectionEventsTopic string        `envconfig:"KAFKA_COLLECTION_EVENTS_TOPIC"`
+	OutboxPollInterval    time.Duration `envconfig:"KAFKA_OUTBOX_POLL_INTERVAL"`
+	ConsumerGroup         string        `envconfig:"KAFKA_CONSUMER_GROUP"`
+	PublishCompletedTopic string        `envconfig:"KAFKA_PUBLISH_COMPLETED_TOPIC"`
+	PublishFailedTopic    string        `envconfig:"KAFKA_PUBLISH_FAILED_TOPIC"`
+}
+
 // MongoConfig contains the config required to connect to MongoDB.
 type MongoConfig struct {
-	BindAddr              string `envconfig:"MONGODB_BIND_ADDR"           json:"-"` // This line contains sensitive data and the json:"-" tells the json marshaller to skip serialising it.
-	CollectionsDatabase   string `envconfig:"MONGODB_COLLECTIONS_DATABASE"`
-	CollectionsCollection string `envconfig:"MONGODB_COLLECTIONS_COLLECTION"`
-	EventsCollection      string `envconfig:"MONGODB_EVENTS_COLLECTION"`
-	Username              string `envconfig:"MONGODB_USERNAME"    json:"-"`
-	Password              string `envconfig:"MONGODB_PASSWORD"    json:"-"`
-	IsSSL                 bool   `envconfig:"MONGODB_IS_SSL"`
+	BindAddr                string `envconfig:"MONGODB_BIND_ADDR"           json:"-"` // This line contains sensitive data and the json:"-" tells the json marshaller to skip serialising it.
+	CollectionsDatabase     string `envconfig:"MONGODB_COLLECTIONS_DATABASE"`
+	CollectionsCollection   string `envconfig:"MONGODB_COLLECTIONS_COLLECTION"`
+	EventsCollection        string `envconfig:"MONGODB_EVENTS_COLLECTION"`
+	VersionsCollection      string `envconfig:"MONGODB_VERSIONS_COLLECTION"`
+	IdempotencyCollection   string `envconfig:"MONGODB_IDEMPOTENCY_COLLECTION"`
+	SubscriptionsCollection string `envconfig:"MONGODB_SUBSCRIPTIONS_COLLECTION"`
+	DeliveriesCollection    string `envconfig:"MONGODB_DELIVERIES_COLLECTION"`
+	OutboxCollection        string `envconfig:"MONGODB_OUTBOX_COLLECTION"`
+	MigrationsCollection    string `envconfig:"MONGODB_MIGRATIONS_COLLECTION"`
+	Username                string `envconfig:"MONGODB_USERNAME"    json:"-"`
+	Password                string `envconfig:"MONGODB_PASSWORD"    json:"-"`
+	IsSSL                   bool   `envconfig:"MONGODB_IS_SSL"`
+	VerifyIndexesOnly       bool   `envconfig:"MONGODB_VERIFY_INDEXES_ONLY"`
+	MigrateOnStartup        bool   `envconfig:"MONGODB_MIGRATE_ON_STARTUP"`
+	WriteConcernMajority    bool   `envconfig:"MONGODB_WRITE_CONCERN_MAJORITY"`
+	StrongReadConcern       bool   `envconfig:"MONGODB_STRONG_READ_CONCERN"`
+	ListReadPreference      string `envconfig:"MONGODB_LIST_READ_PREFERENCE"`
 }
 
 var cfg *Config
@@ -39,20 +90,68 @@ func Get() (*Config, error) {
 
 	cfg = &Config{
 		BindAddr:                   "localhost:26000",
+		HTTPWriteTimeout:           10 * time.Second,
 		GracefulShutdownTimeout:    5 * time.Second,
+		ReadinessDrainDelay:        2 * time.Second,
 		HealthCheckInterval:        30 * time.Second,
 		HealthCheckCriticalTimeout: 90 * time.Second,
 		DefaultMaxLimit:            1000,
 		DefaultLimit:               20,
 		DefaultOffset:              0,
+		MaxRequestBodyBytes:        1024 * 1024,
+		IdempotencyKeyTTL:          24 * time.Hour,
+		EnableRequestValidation:    false,
+		UpcomingPublishWindow:      24 * time.Hour,
+		OTExporter:                 "none",
+		OTExporterFilePath:         "traces.json",
+		OTExporterOTLPEndpoint:     "localhost:4318",
+		OTExporterOTLPInsecure:     true,
+		OTServiceName:              "dp-collection-api",
+		Datastore:                  "mongodb",
+		BoltPath:                   "collections.db",
+		CacheEnabled:               false,
+		CacheTTL:                   30 * time.Second,
+		CacheMaxEntries:            1000,
+		CacheNotifier:              "local",
+		ZebedeeFacadeEnabled:       false,
+		WebhooksEnabled:            false,
+		WebhookMaxAttempts:         8,
+		WebhookInitialBackoff:      10 * time.Second,
+		WebhookMaxBackoff:          time.Hour,
+		WebhookPollInterval:        5 * time.Second,
+		WebhookTimeout:             10 * time.Second,
+		WebhookAllowPrivateIPs:     false,
+		EventStreamSource:          "local",
+		KafkaConfig: KafkaConfig{
+			Enabled:               false,
+			Brokers:               []string{"localhost:9092"},
+			Version:               "1.0.2",
+			SecProtocol:           "",
+			CollectionEventsTopic: "collection-events",
+			OutboxPollInterval:    time.Second,
+			ConsumerGroup:         "dp-collection-api",
+			PublishCompletedTopic: "publish-completed",
+			PublishFailedTopic:    "publish-failed",
+		},
 		MongoConfig: MongoConfig{
-			BindAddr:              "localhost:27017",
-			CollectionsDatabase:   "collections",
-			CollectionsCollection: "collections",
-			EventsCollection:      "events",
-			Username:              "",
-			Password:              "",
-			IsSSL:                 false,
+			BindAddr:                "localhost:27017",
+			CollectionsDatabase:     "collections",
+			CollectionsCollection:   "collections",
+			EventsCollection:        "events",
+			VersionsCollection:      "versions",
+			IdempotencyCollection:   "idempotency_keys",
+			SubscriptionsCollection: "subscriptions",
+			DeliveriesCollection:    "webhook_deliveries",
+			OutboxCollection:        "outbox",
+			MigrationsCollection:    "migrations",
+			Username:                "",
+			Password:                "",
+			IsSSL:                   false,
+			VerifyIndexesOnly:       false,
+			MigrateOnStartup:        true,
+			WriteConcernMajority:    true,
+			StrongReadConcern:       true,
+			ListReadPreference:      "",
 		},
 	}
 
@@ -25,20 +25,67 @@ func TestConfig(t *testing.T) {
 				So(err, ShouldBeNil)
 				So(configuration, ShouldResemble, &Config{
 					BindAddr:                   "localhost:26000",
+					HTTPWriteTimeout:           10 * time.Second,
 					GracefulShutdownTimeout:    5 * time.Second,
+					ReadinessDrainDelay:        2 * time.Second,
 					HealthCheckInterval:        30 * time.Second,
 					HealthCheckCriticalTimeout: 90 * time.Second,
 					DefaultMaxLimit:            1000,
 					DefaultLimit:               20,
 					DefaultOffset:              0,
+					MaxRequestBodyBytes:        1024 * 1024,
+					IdempotencyKeyTTL:          24 * time.Hour,
+					EnableRequestValidation:    false,
+					UpcomingPublishWindow:      24 * time.Hour,
+					OTExporter:                 "none",
+					OTExporterFilePath:         "traces.json",
+					OTExporterOTLPEndpoint:     "localhost:4318",
+					OTExporterOTLPInsecure:     true,
+					OTServiceName:              "dp-collection-api",
+					Datastore:                  "mongodb",
+					BoltPath:                   "collections.db",
+					CacheEnabled:               false,
+					CacheTTL:                   30 * time.Second,
+					CacheMaxEntries:            1000,
+					CacheNotifier:              "local",
+					ZebedeeFacadeEnabled:       false,
+					WebhooksEnabled:            false,
+					WebhookMaxAttempts:         8,
+					WebhookInitialBackoff:      10 * time.Second,
+					WebhookMaxBackoff:          time.Hour,
+					WebhookPollInterval:        5 * time.Second,
+					WebhookTimeout:             10 * time.Second,
+					EventStreamSource:          "local",
+					KafkaConfig: KafkaConfig{
+						Enabled:               false,
+						Brokers:               []string{"localhost:9092"},
+						Version:               "1.0.2",
+						SecProtocol:           "",
+						CollectionEventsTopic: "collection-events",
+						OutboxPollInterval:    time.Second,
+						ConsumerGroup:         "dp-collection-api",
+						PublishCompletedTopic: "publish-completed",
+						PublishFailedTopic:    "publish-failed",
+					},
 					MongoConfig: MongoConfig{
-						BindAddr:              "localhost:27017",
-						CollectionsDatabase:   "collections",
-						CollectionsCollection: "collections",
-						EventsCollection:      "events",
-						Username:              "",
-						Password:              "",
-						IsSSL:                 false,
+						BindAddr:                "localhost:27017",
+						CollectionsDatabase:     "collections",
+						CollectionsCollection:   "collections",
+						EventsCollection:        "events",
+						VersionsCollection:      "versions",
+						IdempotencyCollection:   "idempotency_keys",
+						SubscriptionsCollection: "subscriptions",
+						DeliveriesCollection:    "webhook_deliveries",
+						OutboxCollection:        "outbox",
+						MigrationsCollection:    "migrations",
+						Username:                "",
+						Password:                "",
+						IsSSL:                   false,
+						VerifyIndexesOnly:       false,
+						MigrateOnStartup:        true,
+						WriteConcernMajority:    true,
+						StrongReadConcern:       true,
+						ListReadPreference:      "",
 					},
 				})
 			})
@@ -103,7 +103,7 @@ Feature: Get Collections
         And I should receive the following JSON response:
             """
             {
-                "errors":[ {"message":  "invalid order_by"}]
+                "errors":[ {"code": "invalid_order_by", "message":  "invalid order_by", "field": "order_by"}]
             }
             """
 
@@ -189,7 +189,7 @@ Feature: Get Collections
         And I should receive the following JSON response:
             """
             {
-                "errors":[ {"message":  "name search text is >64 chars"}]
+                "errors":[ {"code": "name_search_too_long", "message":  "name search text is >64 chars", "field": "name"}]
             }
             """
 
@@ -254,6 +254,6 @@ Feature: Get Collections
         And I should receive the following JSON response:
         """
         {
-            "errors":[ {"message":  "collection not found"}]
+            "errors":[ {"code": "collection_not_found", "message":  "collection not found"}]
         }
         """
@@ -5,7 +5,7 @@ Feature: Post Collection
             """
             {
                 "name": "Coronavirus key indicators",
-                "publish_date": "2020-05-05T14:58:29.317Z"
+                "publish_date": "2099-05-05T14:58:29.317Z"
             }
             """
         Then the HTTP status code should be "201"
@@ -25,7 +25,44 @@ Feature: Post Collection
             """
             {
                 "name": "Coronavirus key indicators",
+                "publish_date": "2099-05-05T14:58:29.317Z"
+            }
+            """
+        Then the HTTP status code should be "409"
+    Scenario: POST /collections with an unknown field
+        Given there are no collections
+        When I POST "/collections"
+            """
+            {
+                "name": "Coronavirus key indicators",
+                "publish_date": "2099-05-05T14:58:29.317Z",
+                "colour": "blue"
+            }
+            """
+        Then the HTTP status code should be "400"
+        And I should receive the following JSON response:
+            """
+            {
+                "errors":[ {"code": "unknown_field", "message": "json body contains an unknown field", "field": "colour"}]
+            }
+            """
+
+    Scenario: POST /collections with an empty name and a publish date in the past
+        Given there are no collections
+        When I POST "/collections"
+            """
+            {
+                "name": "",
                 "publish_date": "2020-05-05T14:58:29.317Z"
             }
             """
-        Then the HTTP status code should be "409"
\ No newline at end of file
+        Then the HTTP status code should be "400"
+        And I should receive the following JSON response:
+            """
+            {
+                "errors":[
+                    {"code": "publish_date_in_past", "message": "the collection publish date must be in the future", "field": "publish_date"},
+                    {"code": "collection_name_empty", "message": "the collection name field must be specified", "field": "name"}
+                ]
+            }
+            """
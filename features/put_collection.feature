@@ -46,7 +46,7 @@ Feature: Put Collection
     And I should receive the following JSON response:
         """
         {
-            "errors":[ {"message":  "out of date collection resource"}]
+            "errors":[ {"code": "collection_conflict", "message":  "out of date collection resource"}]
         }
         """
 
@@ -67,6 +67,6 @@ Feature: Put Collection
     And I should receive the following JSON response:
         """
         {
-            "errors":[ {"message":  "collection not found"}]
+            "errors":[ {"code": "collection_not_found", "message":  "collection not found"}]
         }
         """
\ No newline at end of file
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/ONSdigital/dp-collection-api/api"
 	"github.com/ONSdigital/dp-collection-api/config"
 	"github.com/ONSdigital/dp-collection-api/mongo"
 	"github.com/ONSdigital/dp-collection-api/service"
@@ -78,7 +79,7 @@ func (c *CollectionComponent) InitialiseService() (http.Handler, error) {
 
 	service.GetHTTPServer = c.GetHTTPServer
 	service.GetHealthCheck = c.GetHealthCheck
-	service.GetMongoDB = func(ctx context.Context, cfg config.MongoConfig) (service.MongoDB, error) {
+	service.GetDatastore = func(ctx context.Context, cfg *config.Config) (service.MongoDB, error) {
 		return c.mongoClient, nil
 	}
 
@@ -100,8 +101,10 @@ func (c *CollectionComponent) GetHealthCheck(version healthcheck.VersionInfo, cr
 	}
 }
 
-func (c *CollectionComponent) GetHTTPServer(bindAddr string, router http.Handler) service.HTTPServer {
+func (c *CollectionComponent) GetHTTPServer(bindAddr string, writeTimeout time.Duration, router http.Handler) service.HTTPServer {
 	c.httpServer.Addr = bindAddr
 	c.httpServer.Handler = router
+	c.httpServer.WriteTimeout = writeTimeout
+	c.httpServer.ConnContext = api.ConnContext
 	return c.httpServer
 }
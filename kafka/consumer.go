@@ -0,0 +1,291 @@
+package kafka
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/dp-collection-api/schema"
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/Shopify/sarama"
+	"github.com/linkedin/goavro/v2"
+)
+
+// retryInterval is the time waited before connecting again, or handling a message again, after a failure
+const retryInterval = 5 * time.Second
+
+// Consumer consumes messages from Kafka in the background once started
+type Consumer interface {
+	Start(ctx context.Context)
+	Checker(ctx context.Context, state *healthcheck.CheckState) error
+	Close(ctx context.Context) error
+}
+
+// PublishResultHandler stores the outcome of publishing a collection
+type PublishResultHandler interface {
+	SetPublishResult(ctx context.Context, collectionID string, result *models.PublishResult) error
+}
+
+// ConsumerConfig defines the brokers, consumer group and topics that a consumer reads messages from
+type ConsumerConfig struct {
+	Brokers               []string
+	Version               string
+	TLS                   bool
+	Group                 string
+	PublishCompletedTopic string
+	PublishFailedTopic    string
+}
+
+// SaramaConsumer reads the publish-completed and publish-failed messages sent by the publishing services, and stores
+// the publish result of each collection. Each message is handled until it succeeds before its offset is committed, so
+// a message may be handled more than once, which the handler tolerates. Like SaramaProducer, it connects to the
+// brokers in the background, so that the service can start while Kafka is unavailable.
+type SaramaConsumer struct {
+	cfg     ConsumerConfig
+	config  *sarama.Config
+	handler PublishResultHandler
+	mutex   sync.Mutex
+	client  sarama.Client
+	group   sarama.ConsumerGroup
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewConsumer returns a consumer for the config, which passes the publish results it reads to the handler. An error
+// is returned only if the config is not valid.
+func NewConsumer(cfg ConsumerConfig, handler PublishResultHandler) (*SaramaConsumer, error) {
+	config, err := newConfig(cfg.Version, cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	// a new consumer group starts from the oldest message, so that results sent before it first started are applied
+	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &SaramaConsumer{
+		cfg:     cfg,
+		config:  config,
+		handler: handler,
+	}, nil
+}
+
+// Start starts consuming messages, until Close is called
+func (c *SaramaConsumer) Start(ctx context.Context) {
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.done = make(chan struct{})
+	topics := []string{c.cfg.PublishCompletedTopic, c.cfg.PublishFailedTopic}
+
+	go func() {
+		defer close(c.done)
+
+		for {
+			// Consume returns when the group is rebalanced, so it is called again to join the new session
+			_, group, err := c.connect()
+			if err == nil {
+				err = group.Consume(ctx, topics, c)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				log.Error(ctx, "failed to consume publish results", err)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(retryInterval):
+				}
+			}
+		}
+	}()
+}
+
+// Checker reports the consumer as healthy if the metadata of its topics can be read from the brokers. Publish results
+// are kept in Kafka while the consumer is not connected, so an unhealthy consumer is reported as a warning.
+func (c *SaramaConsumer) Checker(ctx context.Context, state *healthcheck.CheckState) error {
+	client, _, err := c.connect()
+	if err == nil {
+		err = client.RefreshMetadata(c.cfg.PublishCompletedTopic, c.cfg.PublishFailedTopic)
+	}
+	if err != nil {
+		return state.Update(healthcheck.StatusWarning, "kafka consumer is not connected: "+err.Error(), 0)
+	}
+	return state.Update(healthcheck.StatusOK, "kafka consumer is healthy", 0)
+}
+
+// Close stops consuming messages, waiting for any message being handled to finish, and closes the connections to the
+// brokers. A message interrupted by Close is handled again when the consumer next starts.
+func (c *SaramaConsumer) Close(ctx context.Context) error {
+	if c.cancel != nil {
+		c.cancel()
+
+		select {
+		case <-c.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.group == nil {
+		return nil
+	}
+
+	// a consumer group created from a client does not close the client
+	err := c.group.Close()
+	if clientErr := c.client.Close(); err == nil {
+		err = clientErr
+	}
+	c.group, c.client = nil, nil
+	return err
+}
+
+// Setup is called by sarama at the start of each consumer group session
+func (c *SaramaConsumer) Setup(session sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+// Cleanup is called by sarama at the end of each consumer group session
+func (c *SaramaConsumer) Cleanup(session sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+// ConsumeClaim handles each message of a partition claimed by the session, and marks it as consumed once it has been
+// handled. A message that cannot be handled is retried until it succeeds or the session ends, so that the results
+// of a collection are not skipped.
+func (c *SaramaConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	ctx := session.Context()
+
+	for message := range claim.Messages() {
+		for {
+			err := c.HandleMessage(ctx, message.Topic, message.Value)
+			if err == nil {
+				break
+			}
+
+			log.Error(ctx, "failed to handle publish result, and will retry", err, log.Data{"topic": message.Topic, "offset": message.Offset})
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(retryInterval):
+			}
+		}
+		session.MarkMessage(message, "")
+	}
+	return nil
+}
+
+// HandleMessage passes the publish result in a message to the handler. A message that can never be handled, because
+// it does not match its schema or is about a collection that does not exist, is logged and skipped rather than
+// returned as an error, so that it does not stop the messages after it.
+func (c *SaramaConsumer) HandleMessage(ctx context.Context, topic string, value []byte) error {
+	logData := log.Data{"topic": topic}
+
+	var collectionID string
+	var result *models.PublishResult
+	var err error
+
+	switch topic {
+	case c.cfg.PublishCompletedTopic:
+		collectionID, result, err = UnmarshalPublishCompleted(value)
+	case c.cfg.PublishFailedTopic:
+		collectionID, result, err = UnmarshalPublishFailed(value)
+	default:
+		log.Warn(ctx, "message from an unknown topic skipped", logData)
+		return nil
+	}
+	if err != nil {
+		log.Error(ctx, "publish result does not match its schema, and is skipped", err, logData)
+		return nil
+	}
+
+	logData["collection_id"] = collectionID
+	logData["message_id"] = result.MessageID
+
+	err = c.handler.SetPublishResult(ctx, collectionID, result)
+	if err == collections.ErrCollectionNotFound {
+		log.Warn(ctx, "publish result for a collection that does not exist skipped", logData)
+		return nil
+	}
+	return err
+}
+
+// connect returns the client and consumer group, connecting to the brokers if they are not connected
+func (c *SaramaConsumer) connect() (sarama.Client, sarama.ConsumerGroup, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.group != nil {
+		return c.client, c.group, nil
+	}
+
+	client, err := sarama.NewClient(c.cfg.Brokers, c.config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	group, err := sarama.NewConsumerGroupFromClient(c.cfg.Group, client)
+	if err != nil {
+		client.Close()
+		return nil, nil, err
+	}
+
+	c.client, c.group = client, group
+	return client, group, nil
+}
+
+// UnmarshalPublishCompleted decodes an Avro encoded publish-completed message, returning the ID of the collection that
+// was published and its publish result
+func UnmarshalPublishCompleted(b []byte) (string, *models.PublishResult, error) {
+	fields, err := unmarshalRecord(schema.PublishCompleted, b)
+	if err != nil {
+		return "", nil, err
+	}
+
+	collectionID, result := newPublishResult(fields, models.PublishCompleted)
+	return collectionID, result, nil
+}
+
+// UnmarshalPublishFailed decodes an Avro encoded publish-failed message, returning the ID of the collection that
+// could not be published and its publish result
+func UnmarshalPublishFailed(b []byte) (string, *models.PublishResult, error) {
+	fields, err := unmarshalRecord(schema.PublishFailed, b)
+	if err != nil {
+		return "", nil, err
+	}
+
+	collectionID, result := newPublishResult(fields, models.PublishFailed)
+	result.Error, _ = fields["error"].(string)
+	return collectionID, result, nil
+}
+
+func unmarshalRecord(codec *goavro.Codec, b []byte) (map[string]interface{}, error) {
+	native, _, err := codec.NativeFromBinary(b)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, ok := native.(map[string]interface{})
+	if !ok {
+		return nil, ErrInvalidMessage
+	}
+	return fields, nil
+}
+
+func newPublishResult(fields map[string]interface{}, status string) (string, *models.PublishResult) {
+	result := &models.PublishResult{Status: status}
+	result.MessageID, _ = fields["id"].(string)
+	result.Date, _ = fields["date"].(time.Time)
+	result.Date = result.Date.UTC()
+
+	collectionID, _ := fields["collection_id"].(string)
+	return collectionID, result
+}
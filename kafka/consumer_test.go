@@ -0,0 +1,125 @@
+package kafka_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/kafka"
+	"github.com/ONSdigital/dp-collection-api/kafka/mock"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/dp-collection-api/schema"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const (
+	publishCompletedTopic = "publish-completed"
+	publishFailedTopic    = "publish-failed"
+)
+
+func TestConsumer_HandleMessage(t *testing.T) {
+
+	ctx := context.Background()
+	publishedAt := time.Date(2021, 6, 2, 9, 30, 5, 0, time.UTC)
+
+	Convey("Given a consumer of publish results", t, func() {
+		handler := &mock.PublishResultHandlerMock{
+			SetPublishResultFunc: func(ctx context.Context, collectionID string, result *models.PublishResult) error {
+				return nil
+			},
+		}
+		consumer, err := kafka.NewConsumer(kafka.ConsumerConfig{
+			Brokers:               []string{"localhost:9092"},
+			Version:               "1.0.2",
+			Group:                 "dp-collection-api",
+			PublishCompletedTopic: publishCompletedTopic,
+			PublishFailedTopic:    publishFailedTopic,
+		}, handler)
+		So(err, ShouldBeNil)
+
+		Convey("When a publish-completed message is handled", func() {
+			value, err := schema.PublishCompleted.BinaryFromNative(nil, map[string]interface{}{
+				"id":            "message-1",
+				"collection_id": collectionID,
+				"date":          publishedAt,
+			})
+			So(err, ShouldBeNil)
+			err = consumer.HandleMessage(ctx, publishCompletedTopic, value)
+
+			Convey("Then a completed result is passed to the handler", func() {
+				So(err, ShouldBeNil)
+				So(handler.SetPublishResultCalls(), ShouldHaveLength, 1)
+				call := handler.SetPublishResultCalls()[0]
+				So(call.CollectionID, ShouldEqual, collectionID)
+				So(call.Result.Status, ShouldEqual, models.PublishCompleted)
+				So(call.Result.Date.Equal(publishedAt), ShouldBeTrue)
+				So(call.Result.MessageID, ShouldEqual, "message-1")
+			})
+		})
+
+		Convey("When a publish-failed message is handled", func() {
+			value, err := schema.PublishFailed.BinaryFromNative(nil, map[string]interface{}{
+				"id":            "message-1",
+				"collection_id": collectionID,
+				"date":          publishedAt,
+				"error":         "timed out",
+			})
+			So(err, ShouldBeNil)
+			err = consumer.HandleMessage(ctx, publishFailedTopic, value)
+
+			Convey("Then a failed result with its error is passed to the handler", func() {
+				So(err, ShouldBeNil)
+				So(handler.SetPublishResultCalls(), ShouldHaveLength, 1)
+				call := handler.SetPublishResultCalls()[0]
+				So(call.Result.Status, ShouldEqual, models.PublishFailed)
+				So(call.Result.Error, ShouldEqual, "timed out")
+			})
+		})
+
+		Convey("When a message that does not match its schema is handled", func() {
+			err := consumer.HandleMessage(ctx, publishCompletedTopic, []byte("not avro"))
+
+			Convey("Then it is skipped", func() {
+				So(err, ShouldBeNil)
+				So(handler.SetPublishResultCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When a message is for a collection that does not exist", func() {
+			handler.SetPublishResultFunc = func(ctx context.Context, collectionID string, result *models.PublishResult) error {
+				return collections.ErrCollectionNotFound
+			}
+			value, err := schema.PublishCompleted.BinaryFromNative(nil, map[string]interface{}{
+				"id":            "message-1",
+				"collection_id": collectionID,
+				"date":          publishedAt,
+			})
+			So(err, ShouldBeNil)
+			err = consumer.HandleMessage(ctx, publishCompletedTopic, value)
+
+			Convey("Then it is skipped", func() {
+				So(err, ShouldBeNil)
+			})
+		})
+
+		Convey("When the handler cannot store the result", func() {
+			handler.SetPublishResultFunc = func(ctx context.Context, collectionID string, result *models.PublishResult) error {
+				return errors.New("datastore is unavailable")
+			}
+			value, err := schema.PublishCompleted.BinaryFromNative(nil, map[string]interface{}{
+				"id":            "message-1",
+				"collection_id": collectionID,
+				"date":          publishedAt,
+			})
+			So(err, ShouldBeNil)
+			err = consumer.HandleMessage(ctx, publishCompletedTopic, value)
+
+			Convey("Then the error is returned, so that the message is retried", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
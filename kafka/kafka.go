@@ -0,0 +1,110 @@
+// Package kafka publishes collection events to Kafka. Each event is stored in an outbox in the same transaction as the
+// write that produced it, and a relay publishes the messages in the outbox, so that an event is published if and only
+// if its write is committed. A message may be published more than once, and consumers should use its id to ignore
+// repeats. A consumer reads the results of publishing collections from Kafka, and stores them with the collections.
+package kafka
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/dp-collection-api/schema"
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	"github.com/linkedin/goavro/v2"
+)
+
+//go:generate moq -out mock/producer.go -pkg mock . Producer
+//go:generate moq -out mock/outboxstore.go -pkg mock . OutboxStore
+//go:generate moq -out mock/consumer.go -pkg mock . Consumer
+//go:generate moq -out mock/publishresulthandler.go -pkg mock . PublishResultHandler
+
+// publishDateBranch is the name of the non-null branch of the publish date union
+const publishDateBranch = "long.timestamp-millis"
+
+// ErrInvalidMessage is returned when a message does not match its schema
+var ErrInvalidMessage = errors.New("the message does not match its schema")
+
+// Producer sends messages to a Kafka topic
+type Producer interface {
+	Send(ctx context.Context, key string, value []byte) error
+	Checker(ctx context.Context, state *healthcheck.CheckState) error
+	Close(ctx context.Context) error
+}
+
+// CollectionEvent is the message produced for each collection event, as defined by schema.CollectionEvent
+type CollectionEvent struct {
+	ID           string
+	Type         string
+	Date         time.Time
+	CollectionID string
+	Name         string
+	State        string
+	PublishDate  *time.Time
+	ETag         string
+}
+
+// NewCollectionEvent returns the message for an event in the outbox, with the state of the collection at the time of
+// the event
+func NewCollectionEvent(message *models.OutboxMessage) *CollectionEvent {
+	return &CollectionEvent{
+		ID:           message.ID,
+		Type:         message.Event.Type,
+		Date:         message.Event.Date,
+		CollectionID: message.Collection.ID,
+		Name:         message.Collection.Name,
+		State:        message.Collection.State(message.Event.Date),
+		PublishDate:  message.Collection.PublishDate,
+		ETag:         message.Collection.ETag,
+	}
+}
+
+// Marshal returns the Avro encoding of the event
+func (e *CollectionEvent) Marshal() ([]byte, error) {
+	var publishDate interface{}
+	if e.PublishDate != nil {
+		publishDate = goavro.Union(publishDateBranch, *e.PublishDate)
+	}
+
+	return schema.CollectionEvent.BinaryFromNative(nil, map[string]interface{}{
+		"id":            e.ID,
+		"type":          e.Type,
+		"date":          e.Date,
+		"collection_id": e.CollectionID,
+		"name":          e.Name,
+		"state":         e.State,
+		"publish_date":  publishDate,
+		"e_tag":         e.ETag,
+	})
+}
+
+// UnmarshalCollectionEvent decodes an Avro encoded collection event
+func UnmarshalCollectionEvent(b []byte) (*CollectionEvent, error) {
+	native, _, err := schema.CollectionEvent.NativeFromBinary(b)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, ok := native.(map[string]interface{})
+	if !ok {
+		return nil, ErrInvalidMessage
+	}
+
+	e := &CollectionEvent{}
+	e.ID, _ = fields["id"].(string)
+	e.Type, _ = fields["type"].(string)
+	e.Date, _ = fields["date"].(time.Time)
+	e.CollectionID, _ = fields["collection_id"].(string)
+	e.Name, _ = fields["name"].(string)
+	e.State, _ = fields["state"].(string)
+	e.ETag, _ = fields["e_tag"].(string)
+
+	if union, ok := fields["publish_date"].(map[string]interface{}); ok {
+		if publishDate, ok := union[publishDateBranch].(time.Time); ok {
+			e.PublishDate = &publishDate
+		}
+	}
+
+	return e, nil
+}
@@ -0,0 +1,134 @@
+package kafka_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/kafka"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	"github.com/Shopify/sarama"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const (
+	collectionID = "93a5e4b7-0a2e-4a8e-9f5c-4c9f1f4b1a2b"
+	topic        = "collection-events"
+)
+
+func TestCollectionEvent(t *testing.T) {
+
+	eventDate := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	publishDate := time.Date(2030, 1, 16, 9, 30, 0, 0, time.UTC)
+
+	Convey("Given an outbox message for a scheduled collection", t, func() {
+		message := &models.OutboxMessage{
+			ID:         "message-1",
+			Event:      models.Event{Type: models.EventCreated, Date: eventDate, CollectionID: collectionID},
+			Collection: models.Collection{ID: collectionID, Name: "Release 1", PublishDate: &publishDate, ETag: "etag"},
+		}
+
+		Convey("When its collection event is marshalled and unmarshalled", func() {
+			b, err := kafka.NewCollectionEvent(message).Marshal()
+			So(err, ShouldBeNil)
+
+			event, err := kafka.UnmarshalCollectionEvent(b)
+			So(err, ShouldBeNil)
+
+			Convey("Then the event has the collection as it was at the time of the event", func() {
+				So(event.ID, ShouldEqual, "message-1")
+				So(event.Type, ShouldEqual, models.EventCreated)
+				So(event.Date.Equal(eventDate), ShouldBeTrue)
+				So(event.CollectionID, ShouldEqual, collectionID)
+				So(event.Name, ShouldEqual, "Release 1")
+				So(event.State, ShouldEqual, models.StateScheduled)
+				So(event.PublishDate.Equal(publishDate), ShouldBeTrue)
+				So(event.ETag, ShouldEqual, "etag")
+			})
+		})
+
+		Convey("When the collection has no publish date", func() {
+			message.Collection.PublishDate = nil
+			b, err := kafka.NewCollectionEvent(message).Marshal()
+			So(err, ShouldBeNil)
+
+			event, err := kafka.UnmarshalCollectionEvent(b)
+			So(err, ShouldBeNil)
+
+			Convey("Then the event has no publish date", func() {
+				So(event.PublishDate, ShouldBeNil)
+			})
+		})
+	})
+
+	Convey("Given a message that is not a collection event", t, func() {
+		_, err := kafka.UnmarshalCollectionEvent([]byte{0xff})
+
+		Convey("Then an error is returned", func() {
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestSaramaProducer(t *testing.T) {
+
+	Convey("Given a broker that leads the topic", t, func() {
+		broker := sarama.NewMockBroker(t, 1)
+		defer broker.Close()
+
+		broker.SetHandlerByMap(map[string]sarama.MockResponse{
+			"MetadataRequest": sarama.NewMockMetadataResponse(t).
+				SetBroker(broker.Addr(), broker.BrokerID()).
+				SetLeader(topic, 0, broker.BrokerID()),
+			"ProduceRequest": sarama.NewMockProduceResponse(t).SetVersion(3),
+		})
+
+		producer, err := kafka.NewProducer(kafka.ProducerConfig{Brokers: []string{broker.Addr()}, Version: "1.0.2", Topic: topic})
+		So(err, ShouldBeNil)
+
+		Convey("When a message is sent", func() {
+			err := producer.Send(context.Background(), collectionID, []byte("event"))
+
+			Convey("Then it is acknowledged", func() {
+				So(err, ShouldBeNil)
+			})
+		})
+
+		Convey("When the producer is checked", func() {
+			state := healthcheck.NewCheckState("Kafka producer")
+			So(producer.Checker(context.Background(), state), ShouldBeNil)
+
+			Convey("Then it is healthy", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusOK)
+			})
+		})
+
+		Reset(func() {
+			So(producer.Close(context.Background()), ShouldBeNil)
+		})
+	})
+
+	Convey("Given no broker is available", t, func() {
+		producer, err := kafka.NewProducer(kafka.ProducerConfig{Brokers: []string{"127.0.0.1:1"}, Topic: topic})
+		So(err, ShouldBeNil)
+
+		Convey("When the producer is checked", func() {
+			state := healthcheck.NewCheckState("Kafka producer")
+			So(producer.Checker(context.Background(), state), ShouldBeNil)
+
+			Convey("Then it is reported as a warning, as messages are kept in the outbox", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusWarning)
+			})
+		})
+	})
+
+	Convey("Given a Kafka version that is not valid", t, func() {
+		_, err := kafka.NewProducer(kafka.ProducerConfig{Version: "latest", Topic: topic})
+
+		Convey("Then an error is returned", func() {
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,146 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/ONSdigital/dp-collection-api/kafka"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"sync"
+	"time"
+)
+
+// Ensure, that OutboxStoreMock does implement kafka.OutboxStore.
+// If this is not the case, regenerate this file with moq.
+var _ kafka.OutboxStore = &OutboxStoreMock{}
+
+// OutboxStoreMock is a mock implementation of kafka.OutboxStore.
+//
+//	func TestSomethingThatUsesOutboxStore(t *testing.T) {
+//
+//		// make and configure a mocked kafka.OutboxStore
+//		mockedOutboxStore := &OutboxStoreMock{
+//			ClaimOutboxMessagesFunc: func(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.OutboxMessage, error) {
+//				panic("mock out the ClaimOutboxMessages method")
+//			},
+//			DeleteOutboxMessageFunc: func(ctx context.Context, id string) error {
+//				panic("mock out the DeleteOutboxMessage method")
+//			},
+//		}
+//
+//		// use mockedOutboxStore in code that requires kafka.OutboxStore
+//		// and then make assertions.
+//
+//	}
+type OutboxStoreMock struct {
+	// ClaimOutboxMessagesFunc mocks the ClaimOutboxMessages method.
+	ClaimOutboxMessagesFunc func(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.OutboxMessage, error)
+
+	// DeleteOutboxMessageFunc mocks the DeleteOutboxMessage method.
+	DeleteOutboxMessageFunc func(ctx context.Context, id string) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ClaimOutboxMessages holds details about calls to the ClaimOutboxMessages method.
+		ClaimOutboxMessages []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Now is the now argument value.
+			Now time.Time
+			// Lease is the lease argument value.
+			Lease time.Duration
+			// Limit is the limit argument value.
+			Limit int
+		}
+		// DeleteOutboxMessage holds details about calls to the DeleteOutboxMessage method.
+		DeleteOutboxMessage []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+	}
+	lockClaimOutboxMessages sync.RWMutex
+	lockDeleteOutboxMessage sync.RWMutex
+}
+
+// ClaimOutboxMessages calls ClaimOutboxMessagesFunc.
+func (mock *OutboxStoreMock) ClaimOutboxMessages(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.OutboxMessage, error) {
+	if mock.ClaimOutboxMessagesFunc == nil {
+		panic("OutboxStoreMock.ClaimOutboxMessagesFunc: method is nil but OutboxStore.ClaimOutboxMessages was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Now   time.Time
+		Lease time.Duration
+		Limit int
+	}{
+		Ctx:   ctx,
+		Now:   now,
+		Lease: lease,
+		Limit: limit,
+	}
+	mock.lockClaimOutboxMessages.Lock()
+	mock.calls.ClaimOutboxMessages = append(mock.calls.ClaimOutboxMessages, callInfo)
+	mock.lockClaimOutboxMessages.Unlock()
+	return mock.ClaimOutboxMessagesFunc(ctx, now, lease, limit)
+}
+
+// ClaimOutboxMessagesCalls gets all the calls that were made to ClaimOutboxMessages.
+// Check the length with:
+//
+//	len(mockedOutboxStore.ClaimOutboxMessagesCalls())
+func (mock *OutboxStoreMock) ClaimOutboxMessagesCalls() []struct {
+	Ctx   context.Context
+	Now   time.Time
+	Lease time.Duration
+	Limit int
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Now   time.Time
+		Lease time.Duration
+		Limit int
+	}
+	mock.lockClaimOutboxMessages.RLock()
+	calls = mock.calls.ClaimOutboxMessages
+	mock.lockClaimOutboxMessages.RUnlock()
+	return calls
+}
+
+// DeleteOutboxMessage calls DeleteOutboxMessageFunc.
+func (mock *OutboxStoreMock) DeleteOutboxMessage(ctx context.Context, id string) error {
+	if mock.DeleteOutboxMessageFunc == nil {
+		panic("OutboxStoreMock.DeleteOutboxMessageFunc: method is nil but OutboxStore.DeleteOutboxMessage was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockDeleteOutboxMessage.Lock()
+	mock.calls.DeleteOutboxMessage = append(mock.calls.DeleteOutboxMessage, callInfo)
+	mock.lockDeleteOutboxMessage.Unlock()
+	return mock.DeleteOutboxMessageFunc(ctx, id)
+}
+
+// DeleteOutboxMessageCalls gets all the calls that were made to DeleteOutboxMessage.
+// Check the length with:
+//
+//	len(mockedOutboxStore.DeleteOutboxMessageCalls())
+func (mock *OutboxStoreMock) DeleteOutboxMessageCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockDeleteOutboxMessage.RLock()
+	calls = mock.calls.DeleteOutboxMessage
+	mock.lockDeleteOutboxMessage.RUnlock()
+	return calls
+}
@@ -0,0 +1,183 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/ONSdigital/dp-collection-api/kafka"
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	"sync"
+)
+
+// Ensure, that ProducerMock does implement kafka.Producer.
+// If this is not the case, regenerate this file with moq.
+var _ kafka.Producer = &ProducerMock{}
+
+// ProducerMock is a mock implementation of kafka.Producer.
+//
+//	func TestSomethingThatUsesProducer(t *testing.T) {
+//
+//		// make and configure a mocked kafka.Producer
+//		mockedProducer := &ProducerMock{
+//			CheckerFunc: func(ctx context.Context, state *healthcheck.CheckState) error {
+//				panic("mock out the Checker method")
+//			},
+//			CloseFunc: func(ctx context.Context) error {
+//				panic("mock out the Close method")
+//			},
+//			SendFunc: func(ctx context.Context, key string, value []byte) error {
+//				panic("mock out the Send method")
+//			},
+//		}
+//
+//		// use mockedProducer in code that requires kafka.Producer
+//		// and then make assertions.
+//
+//	}
+type ProducerMock struct {
+	// CheckerFunc mocks the Checker method.
+	CheckerFunc func(ctx context.Context, state *healthcheck.CheckState) error
+
+	// CloseFunc mocks the Close method.
+	CloseFunc func(ctx context.Context) error
+
+	// SendFunc mocks the Send method.
+	SendFunc func(ctx context.Context, key string, value []byte) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Checker holds details about calls to the Checker method.
+		Checker []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// State is the state argument value.
+			State *healthcheck.CheckState
+		}
+		// Close holds details about calls to the Close method.
+		Close []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// Send holds details about calls to the Send method.
+		Send []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Key is the key argument value.
+			Key string
+			// Value is the value argument value.
+			Value []byte
+		}
+	}
+	lockChecker sync.RWMutex
+	lockClose   sync.RWMutex
+	lockSend    sync.RWMutex
+}
+
+// Checker calls CheckerFunc.
+func (mock *ProducerMock) Checker(ctx context.Context, state *healthcheck.CheckState) error {
+	if mock.CheckerFunc == nil {
+		panic("ProducerMock.CheckerFunc: method is nil but Producer.Checker was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		State *healthcheck.CheckState
+	}{
+		Ctx:   ctx,
+		State: state,
+	}
+	mock.lockChecker.Lock()
+	mock.calls.Checker = append(mock.calls.Checker, callInfo)
+	mock.lockChecker.Unlock()
+	return mock.CheckerFunc(ctx, state)
+}
+
+// CheckerCalls gets all the calls that were made to Checker.
+// Check the length with:
+//
+//	len(mockedProducer.CheckerCalls())
+func (mock *ProducerMock) CheckerCalls() []struct {
+	Ctx   context.Context
+	State *healthcheck.CheckState
+} {
+	var calls []struct {
+		Ctx   context.Context
+		State *healthcheck.CheckState
+	}
+	mock.lockChecker.RLock()
+	calls = mock.calls.Checker
+	mock.lockChecker.RUnlock()
+	return calls
+}
+
+// Close calls CloseFunc.
+func (mock *ProducerMock) Close(ctx context.Context) error {
+	if mock.CloseFunc == nil {
+		panic("ProducerMock.CloseFunc: method is nil but Producer.Close was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockClose.Lock()
+	mock.calls.Close = append(mock.calls.Close, callInfo)
+	mock.lockClose.Unlock()
+	return mock.CloseFunc(ctx)
+}
+
+// CloseCalls gets all the calls that were made to Close.
+// Check the length with:
+//
+//	len(mockedProducer.CloseCalls())
+func (mock *ProducerMock) CloseCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockClose.RLock()
+	calls = mock.calls.Close
+	mock.lockClose.RUnlock()
+	return calls
+}
+
+// Send calls SendFunc.
+func (mock *ProducerMock) Send(ctx context.Context, key string, value []byte) error {
+	if mock.SendFunc == nil {
+		panic("ProducerMock.SendFunc: method is nil but Producer.Send was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Key   string
+		Value []byte
+	}{
+		Ctx:   ctx,
+		Key:   key,
+		Value: value,
+	}
+	mock.lockSend.Lock()
+	mock.calls.Send = append(mock.calls.Send, callInfo)
+	mock.lockSend.Unlock()
+	return mock.SendFunc(ctx, key, value)
+}
+
+// SendCalls gets all the calls that were made to Send.
+// Check the length with:
+//
+//	len(mockedProducer.SendCalls())
+func (mock *ProducerMock) SendCalls() []struct {
+	Ctx   context.Context
+	Key   string
+	Value []byte
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Key   string
+		Value []byte
+	}
+	mock.lockSend.RLock()
+	calls = mock.calls.Send
+	mock.lockSend.RUnlock()
+	return calls
+}
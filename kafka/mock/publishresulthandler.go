@@ -0,0 +1,89 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/ONSdigital/dp-collection-api/kafka"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"sync"
+)
+
+// Ensure, that PublishResultHandlerMock does implement kafka.PublishResultHandler.
+// If this is not the case, regenerate this file with moq.
+var _ kafka.PublishResultHandler = &PublishResultHandlerMock{}
+
+// PublishResultHandlerMock is a mock implementation of kafka.PublishResultHandler.
+//
+//	func TestSomethingThatUsesPublishResultHandler(t *testing.T) {
+//
+//		// make and configure a mocked kafka.PublishResultHandler
+//		mockedPublishResultHandler := &PublishResultHandlerMock{
+//			SetPublishResultFunc: func(ctx context.Context, collectionID string, result *models.PublishResult) error {
+//				panic("mock out the SetPublishResult method")
+//			},
+//		}
+//
+//		// use mockedPublishResultHandler in code that requires kafka.PublishResultHandler
+//		// and then make assertions.
+//
+//	}
+type PublishResultHandlerMock struct {
+	// SetPublishResultFunc mocks the SetPublishResult method.
+	SetPublishResultFunc func(ctx context.Context, collectionID string, result *models.PublishResult) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// SetPublishResult holds details about calls to the SetPublishResult method.
+		SetPublishResult []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// CollectionID is the collectionID argument value.
+			CollectionID string
+			// Result is the result argument value.
+			Result *models.PublishResult
+		}
+	}
+	lockSetPublishResult sync.RWMutex
+}
+
+// SetPublishResult calls SetPublishResultFunc.
+func (mock *PublishResultHandlerMock) SetPublishResult(ctx context.Context, collectionID string, result *models.PublishResult) error {
+	if mock.SetPublishResultFunc == nil {
+		panic("PublishResultHandlerMock.SetPublishResultFunc: method is nil but PublishResultHandler.SetPublishResult was just called")
+	}
+	callInfo := struct {
+		Ctx          context.Context
+		CollectionID string
+		Result       *models.PublishResult
+	}{
+		Ctx:          ctx,
+		CollectionID: collectionID,
+		Result:       result,
+	}
+	mock.lockSetPublishResult.Lock()
+	mock.calls.SetPublishResult = append(mock.calls.SetPublishResult, callInfo)
+	mock.lockSetPublishResult.Unlock()
+	return mock.SetPublishResultFunc(ctx, collectionID, result)
+}
+
+// SetPublishResultCalls gets all the calls that were made to SetPublishResult.
+// Check the length with:
+//
+//	len(mockedPublishResultHandler.SetPublishResultCalls())
+func (mock *PublishResultHandlerMock) SetPublishResultCalls() []struct {
+	Ctx          context.Context
+	CollectionID string
+	Result       *models.PublishResult
+} {
+	var calls []struct {
+		Ctx          context.Context
+		CollectionID string
+		Result       *models.PublishResult
+	}
+	mock.lockSetPublishResult.RLock()
+	calls = mock.calls.SetPublishResult
+	mock.lockSetPublishResult.RUnlock()
+	return calls
+}
@@ -0,0 +1,141 @@
+package kafka
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	"github.com/Shopify/sarama"
+)
+
+// ProducerConfig defines the brokers and topic that a producer sends messages to
+type ProducerConfig struct {
+	Brokers []string
+	Version string
+	TLS     bool
+	Topic   string
+}
+
+// SaramaProducer sends messages to a topic, waiting for every in-sync replica to acknowledge each message. It connects
+// to the brokers when it is first used, and again after a failed connection, so that the service can start while
+// Kafka is unavailable.
+type SaramaProducer struct {
+	topic    string
+	config   *sarama.Config
+	brokers  []string
+	mutex    sync.Mutex
+	client   sarama.Client
+	producer sarama.SyncProducer
+}
+
+// NewProducer returns a producer for the config. An error is returned only if the config is not valid.
+func NewProducer(cfg ProducerConfig) (*SaramaProducer, error) {
+	config, err := newConfig(cfg.Version, cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Return.Successes = true
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &SaramaProducer{
+		topic:   cfg.Topic,
+		config:  config,
+		brokers: cfg.Brokers,
+	}, nil
+}
+
+// Send sends a message to the topic, returning once it has been acknowledged
+func (p *SaramaProducer) Send(ctx context.Context, key string, value []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	producer, _, err := p.connect()
+	if err != nil {
+		return err
+	}
+
+	_, _, err = producer.SendMessage(&sarama.ProducerMessage{
+		Topic: p.topic,
+		Key:   sarama.StringEncoder(key),
+		Value: sarama.ByteEncoder(value),
+	})
+	return err
+}
+
+// Checker reports the producer as healthy if the metadata of its topic can be read from the brokers. Messages are
+// kept in the outbox while Kafka is unavailable, so an unhealthy producer is reported as a warning.
+func (p *SaramaProducer) Checker(ctx context.Context, state *healthcheck.CheckState) error {
+	_, client, err := p.connect()
+	if err == nil {
+		err = client.RefreshMetadata(p.topic)
+	}
+	if err != nil {
+		return state.Update(healthcheck.StatusWarning, "kafka producer is not connected: "+err.Error(), 0)
+	}
+	return state.Update(healthcheck.StatusOK, "kafka producer is healthy", 0)
+}
+
+// Close closes the producer, and its connections to the brokers
+func (p *SaramaProducer) Close(ctx context.Context) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.producer == nil {
+		return nil
+	}
+
+	// a producer created from a client does not close the client
+	err := p.producer.Close()
+	if clientErr := p.client.Close(); err == nil {
+		err = clientErr
+	}
+	p.producer, p.client = nil, nil
+	return err
+}
+
+// connect returns the producer and its client, connecting to the brokers if they are not connected
+func (p *SaramaProducer) connect() (sarama.SyncProducer, sarama.Client, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.producer != nil {
+		return p.producer, p.client, nil
+	}
+
+	client, err := sarama.NewClient(p.brokers, p.config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	producer, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		client.Close()
+		return nil, nil, err
+	}
+
+	p.producer, p.client = producer, client
+	return producer, client, nil
+}
+
+// newConfig returns the sarama config shared by producers and consumers, for the version of the brokers
+func newConfig(version string, tls bool) (*sarama.Config, error) {
+	config := sarama.NewConfig()
+
+	if len(version) > 0 {
+		v, err := sarama.ParseKafkaVersion(version)
+		if err != nil {
+			return nil, err
+		}
+		config.Version = v
+	}
+
+	config.Net.TLS.Enable = tls
+	config.Metadata.Full = false
+	return config, nil
+}
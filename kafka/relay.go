@@ -0,0 +1,145 @@
+package kafka
+
+import (
+	"context"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+const (
+	// claimLimit is the number of outbox messages claimed at a time
+	claimLimit = 50
+
+	// claimLease is the time a claimed message is kept from other relays, after which a message claimed by a relay
+	// that stopped is published again
+	claimLease = time.Minute
+)
+
+// Now returns the current time, and can be replaced in tests
+var Now = time.Now
+
+// OutboxStore defines the required methods from the data store of outbox messages
+type OutboxStore interface {
+	ClaimOutboxMessages(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.OutboxMessage, error)
+	DeleteOutboxMessage(ctx context.Context, id string) error
+}
+
+// Relay publishes the messages in the outbox, in the order they were stored, removing each one once it has been
+// acknowledged. Each instance of the service runs a relay, and the relays claim different messages from the outbox,
+// so messages are only published in order while a single relay is publishing them.
+type Relay struct {
+	store        OutboxStore
+	producer     Producer
+	pollInterval time.Duration
+	wake         chan struct{}
+	cancel       context.CancelFunc
+	done         chan struct{}
+}
+
+// NewRelay returns a relay that publishes the messages in the store with the producer, checking the store for messages
+// at each poll interval
+func NewRelay(store OutboxStore, producer Producer, pollInterval time.Duration) *Relay {
+	return &Relay{
+		store:        store,
+		producer:     producer,
+		pollInterval: pollInterval,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// HandleCollectionEvent wakes the worker, so that the message stored with the event is published straight away. The
+// message itself is read from the outbox, as it was stored by the write.
+func (r *Relay) HandleCollectionEvent(ctx context.Context, event *models.Event, collection *models.Collection) error {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Start starts the worker that publishes the messages in the outbox, until Close is called
+func (r *Relay) Start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.done = make(chan struct{})
+
+	go func() {
+		defer close(r.done)
+
+		ticker := time.NewTicker(r.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := r.PublishPending(ctx); err != nil && ctx.Err() == nil {
+				log.Error(ctx, "failed to publish outbox messages", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-r.wake:
+			}
+		}
+	}()
+}
+
+// Close stops the worker, waiting for any message being published to be acknowledged. A message interrupted by Close
+// is published again once its claim has expired.
+func (r *Relay) Close(ctx context.Context) error {
+	if r.cancel == nil {
+		return nil
+	}
+	r.cancel()
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// PublishPending publishes every message in the outbox, claiming them from the store a few at a time so that other
+// instances do not publish them too. Publishing stops at the first message that cannot be published, so that this
+// relay does not publish the later messages it has claimed ahead of it. Another relay may still publish a later
+// message for the same collection while the claim on this one expires, so the order is not guaranteed.
+func (r *Relay) PublishPending(ctx context.Context) error {
+	for {
+		messages, err := r.store.ClaimOutboxMessages(ctx, Now().UTC(), claimLease, claimLimit)
+		if err != nil {
+			return err
+		}
+
+		for i := range messages {
+			if err := r.publish(ctx, &messages[i]); err != nil {
+				return err
+			}
+		}
+
+		if len(messages) < claimLimit {
+			return nil
+		}
+	}
+}
+
+// publish sends a message, keyed by its collection ID so that the events of a collection go to the same partition,
+// and removes it from the outbox once it has been acknowledged
+func (r *Relay) publish(ctx context.Context, message *models.OutboxMessage) error {
+	logData := log.Data{"message_id": message.ID, "collection_id": message.Collection.ID, "event_type": message.Event.Type}
+
+	value, err := NewCollectionEvent(message).Marshal()
+	if err != nil {
+		// the message can never be published, so it is logged and removed rather than blocking the outbox
+		log.Error(ctx, "outbox message does not match the collection event schema, and is discarded", err, logData)
+		return r.store.DeleteOutboxMessage(ctx, message.ID)
+	}
+
+	if err := r.producer.Send(ctx, message.Collection.ID, value); err != nil {
+		return err
+	}
+	log.Info(ctx, "collection event published", logData)
+
+	return r.store.DeleteOutboxMessage(ctx, message.ID)
+}
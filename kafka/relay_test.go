@@ -0,0 +1,130 @@
+package kafka_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/kafka"
+	"github.com/ONSdigital/dp-collection-api/kafka/mock"
+	"github.com/ONSdigital/dp-collection-api/memory"
+	"github.com/ONSdigital/dp-collection-api/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func addOutboxMessages(store *memory.Store, ids ...string) {
+	for _, id := range ids {
+		So(store.AddOutboxMessage(context.Background(), &models.OutboxMessage{
+			ID:         id,
+			Event:      models.Event{Type: models.EventUpdated, Date: kafka.Now(), CollectionID: collectionID},
+			Collection: models.Collection{ID: collectionID, Name: "collection " + id},
+		}), ShouldBeNil)
+	}
+}
+
+// sentIDs returns the ID of each event sent by the producer, in the order they were sent
+func sentIDs(producer *mock.ProducerMock) []string {
+	ids := []string{}
+	for _, call := range producer.SendCalls() {
+		event, err := kafka.UnmarshalCollectionEvent(call.Value)
+		So(err, ShouldBeNil)
+		So(call.Key, ShouldEqual, collectionID)
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func TestRelay(t *testing.T) {
+
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	kafka.Now = func() time.Time { return now }
+	defer func() { kafka.Now = time.Now }()
+
+	Convey("Given an outbox with messages", t, func() {
+		ctx := context.Background()
+		store := memory.New()
+		addOutboxMessages(store, "1", "2", "3")
+
+		Convey("When the messages are published", func() {
+			producer := &mock.ProducerMock{
+				SendFunc: func(ctx context.Context, key string, value []byte) error { return nil },
+			}
+			err := kafka.NewRelay(store, producer, time.Second).PublishPending(ctx)
+
+			Convey("Then each message is sent in order, keyed by its collection ID, and removed from the outbox", func() {
+				So(err, ShouldBeNil)
+				So(sentIDs(producer), ShouldResemble, []string{"1", "2", "3"})
+
+				remaining, err := store.ClaimOutboxMessages(ctx, now.Add(time.Hour), time.Minute, 10)
+				So(err, ShouldBeNil)
+				So(remaining, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When a message cannot be sent", func() {
+			calls := 0
+			producer := &mock.ProducerMock{
+				SendFunc: func(ctx context.Context, key string, value []byte) error {
+					calls++
+					if calls == 2 {
+						return errors.New("kafka is unavailable")
+					}
+					return nil
+				},
+			}
+			relay := kafka.NewRelay(store, producer, time.Second)
+			err := relay.PublishPending(ctx)
+
+			Convey("Then publishing stops, so that the later messages are not sent first", func() {
+				So(err, ShouldNotBeNil)
+				So(sentIDs(producer), ShouldResemble, []string{"1", "2"})
+			})
+
+			Convey("Then the unsent messages are published again once their claim expires", func() {
+				now = now.Add(2 * time.Minute)
+				defer func() { now = now.Add(-2 * time.Minute) }()
+
+				So(relay.PublishPending(ctx), ShouldBeNil)
+				So(sentIDs(producer), ShouldResemble, []string{"1", "2", "2", "3"})
+			})
+		})
+	})
+
+	Convey("Given a relay that has been started", t, func() {
+		ctx := context.Background()
+		store := memory.New()
+		sent := make(chan string, 10)
+		producer := &mock.ProducerMock{
+			SendFunc: func(ctx context.Context, key string, value []byte) error {
+				event, err := kafka.UnmarshalCollectionEvent(value)
+				if err != nil {
+					return err
+				}
+				sent <- event.ID
+				return nil
+			},
+		}
+		relay := kafka.NewRelay(store, producer, time.Hour)
+		relay.Start(ctx)
+
+		Convey("When a message is added, and the relay is notified of its event", func() {
+			addOutboxMessages(store, "1")
+			So(relay.HandleCollectionEvent(ctx, &models.Event{}, &models.Collection{}), ShouldBeNil)
+
+			Convey("Then the message is published without waiting for the poll interval", func() {
+				select {
+				case id := <-sent:
+					So(id, ShouldEqual, "1")
+				case <-time.After(5 * time.Second):
+					So("the message was not published", ShouldBeEmpty)
+				}
+			})
+		})
+
+		Reset(func() {
+			So(relay.Close(ctx), ShouldBeNil)
+		})
+	})
+}
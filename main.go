@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"os"
 	"os/signal"
 
@@ -22,9 +23,23 @@ var (
 	Version string
 )
 
+var (
+	migrate = flag.Bool("migrate", false, "apply any pending database migrations, then exit without starting the service")
+	dryRun  = flag.Bool("dry-run", false, "with -migrate, report the pending migrations without applying them")
+)
+
 func main() {
 	log.Namespace = serviceName
 	ctx := context.Background()
+	flag.Parse()
+
+	if *migrate {
+		if err := runMigrations(ctx); err != nil {
+			log.Fatal(ctx, "migrations failed", err)
+			os.Exit(1)
+		}
+		return
+	}
 
 	if err := run(ctx); err != nil {
 		log.Fatal(ctx, "fatal runtime error", err)
@@ -68,3 +83,12 @@ func run(ctx context.Context) error {
 	}
 	return service.Close(ctx)
 }
+
+func runMigrations(ctx context.Context) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return errors.Wrap(err, "error getting configuration")
+	}
+
+	return service.Migrate(ctx, cfg, *dryRun)
+}
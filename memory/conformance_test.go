@@ -0,0 +1,23 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/ONSdigital/dp-collection-api/api/storetest"
+	"github.com/ONSdigital/dp-collection-api/memory"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.TestCollectionStore(t, func(t *testing.T) storetest.Store {
+		return memory.New()
+	})
+	storetest.TestSubscriptionStore(t, func(t *testing.T) storetest.SubscriptionStore {
+		return memory.New()
+	})
+	storetest.TestVersionStore(t, func(t *testing.T) storetest.VersionStore {
+		return memory.New()
+	})
+	storetest.TestOutboxStore(t, func(t *testing.T) storetest.OutboxStore {
+		return memory.New()
+	})
+}
@@ -0,0 +1,571 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+)
+
+// Store is an in-memory data store of collections, events, versions, idempotency records, webhook subscriptions and outbox messages, with the
+// same behaviour as the MongoDB store. It is intended for local development and tests, and its contents are lost when the service stops.
+type Store struct {
+	mutex         sync.RWMutex
+	collections   []*models.Collection
+	events        []*models.Event
+	versions      []*models.Version
+	idempotency   map[string]*models.IdempotencyRecord
+	subscriptions []*models.Subscription
+	deliveries    []*models.Delivery
+	outbox        []*models.OutboxMessage
+}
+
+// New returns an empty in-memory store
+func New() *Store {
+	return &Store{
+		idempotency: map[string]*models.IdempotencyRecord{},
+	}
+}
+
+// Close does nothing, as there is no connection to close
+func (s *Store) Close(ctx context.Context) error {
+	return nil
+}
+
+// Checker always reports the store as healthy
+func (s *Store) Checker(ctx context.Context, state *healthcheck.CheckState) error {
+	return state.Update(healthcheck.StatusOK, "in-memory store is healthy", 0)
+}
+
+// GetCollections retrieves the collections matching the name search, in the requested order
+func (s *Store) GetCollections(ctx context.Context, queryParams collections.QueryParams) ([]models.Collection, int, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	values := make([]models.Collection, 0, len(s.collections))
+	for _, collection := range s.collections {
+		values = append(values, copyCollection(collection))
+	}
+
+	return collections.SelectCollections(values, queryParams)
+}
+
+// GetCollectionByName retrieves a single collection by name
+func (s *Store) GetCollectionByName(ctx context.Context, name string) (*models.Collection, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, collection := range s.collections {
+		if collection.Name == name {
+			result := copyCollection(collection)
+			return &result, nil
+		}
+	}
+	return nil, collections.ErrCollectionNotFound
+}
+
+// GetCollectionByID retrieves a single collection by ID. If the eTag selector does not match, then
+// collections.ErrCollectionConflict is returned.
+func (s *Store) GetCollectionByID(ctx context.Context, id string, eTagSelector string) (*models.Collection, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	collection := s.findCollection(id)
+	if collection == nil {
+		return nil, collections.ErrCollectionNotFound
+	}
+	if eTagSelector != models.AnyETag && eTagSelector != collection.ETag {
+		return nil, collections.ErrCollectionConflict
+	}
+
+	result := copyCollection(collection)
+	return &result, nil
+}
+
+// AddCollection adds or updates a collection
+func (s *Store) AddCollection(ctx context.Context, collection *models.Collection) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+
+	existing := s.findCollection(collection.ID)
+	if existing == nil {
+		existing = &models.Collection{ID: collection.ID, CreatedAt: now}
+		s.collections = append(s.collections, existing)
+		added := existing
+		onRollback(ctx, func() { s.collections = removeCollection(s.collections, added) })
+	} else {
+		s.restoreOnRollback(ctx, existing)
+	}
+
+	existing.Set(collection)
+	existing.LastUpdated = now
+	return nil
+}
+
+// ReplaceCollection replaces an existing collection. If there is no collection with the ID and eTag,
+// then collections.ErrCollectionConflict is returned.
+func (s *Store) ReplaceCollection(ctx context.Context, collection *models.Collection, eTagSelector string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	existing := s.findCollection(collection.ID)
+	if existing == nil || existing.ETag != eTagSelector {
+		return collections.ErrCollectionConflict
+	}
+
+	s.restoreOnRollback(ctx, existing)
+	existing.Set(collection)
+	existing.LastUpdated = time.Now()
+	return nil
+}
+
+// GetCollectionEvents retrieves the events for a collection, in date order
+func (s *Store) GetCollectionEvents(ctx context.Context, queryParams collections.EventsQueryParams) ([]models.Event, int, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	values := make([]models.Event, 0, len(s.events))
+	for _, event := range s.events {
+		values = append(values, *event)
+	}
+
+	values, totalCount := collections.SelectEvents(values, queryParams)
+	return values, totalCount, nil
+}
+
+// AddEvent adds an event to a collection
+func (s *Store) AddEvent(ctx context.Context, event *models.Event) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	e := *event
+	s.events = append(s.events, &e)
+	onRollback(ctx, func() { s.events = removeEvent(s.events, &e) })
+	return nil
+}
+
+// GetVersions retrieves a page of the versions of a collection, oldest first
+func (s *Store) GetVersions(ctx context.Context, queryParams collections.VersionsQueryParams) ([]models.Version, int, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	values := make([]models.Version, 0, len(s.versions))
+	for _, version := range s.versions {
+		values = append(values, copyVersion(version))
+	}
+
+	values, totalCount := collections.SelectVersions(values, queryParams)
+	return values, totalCount, nil
+}
+
+// GetVersion retrieves a single version of a collection
+func (s *Store) GetVersion(ctx context.Context, collectionID string, version int) (*models.Version, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	id := models.VersionID(collectionID, version)
+	for _, v := range s.versions {
+		if v.ID == id {
+			result := copyVersion(v)
+			return &result, nil
+		}
+	}
+	return nil, collections.ErrVersionNotFound
+}
+
+// AddVersion adds a snapshot of a version of a collection. If the version already has a snapshot, then
+// collections.ErrVersionAlreadyExists is returned.
+func (s *Store) AddVersion(ctx context.Context, version *models.Version) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, v := range s.versions {
+		if v.ID == version.ID {
+			return collections.ErrVersionAlreadyExists
+		}
+	}
+
+	result := copyVersion(version)
+	s.versions = append(s.versions, &result)
+	onRollback(ctx, func() { s.versions = removeVersion(s.versions, &result) })
+	return nil
+}
+
+// GetCollectionStats counts the collections in each state at the given time, and those due to be published
+// within the upcoming window
+func (s *Store) GetCollectionStats(ctx context.Context, now time.Time, upcomingWindow time.Duration) (*models.CollectionStats, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	stats := &models.CollectionStats{}
+	for _, collection := range s.collections {
+		stats.Add(collection, now, upcomingWindow)
+	}
+
+	return stats, nil
+}
+
+// AddIdempotencyRecord adds a record for a new idempotency key. An expired record for the same key is replaced,
+// but if an unexpired record exists then collections.ErrIdempotencyKeyExists is returned.
+func (s *Store) AddIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if existing, ok := s.idempotency[record.Key]; ok && existing.ExpiresAt.After(time.Now()) {
+		return collections.ErrIdempotencyKeyExists
+	}
+
+	s.idempotency[record.Key] = copyIdempotencyRecord(record)
+	return nil
+}
+
+// GetIdempotencyRecord retrieves the unexpired record for an idempotency key
+func (s *Store) GetIdempotencyRecord(ctx context.Context, key string) (*models.IdempotencyRecord, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	record, ok := s.idempotency[key]
+	if !ok || !record.ExpiresAt.After(time.Now()) {
+		return nil, collections.ErrIdempotencyRecordNotFound
+	}
+	return copyIdempotencyRecord(record), nil
+}
+
+// UpdateIdempotencyRecord stores the response for an idempotency key
+func (s *Store) UpdateIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.idempotency[record.Key]; ok {
+		s.idempotency[record.Key] = copyIdempotencyRecord(record)
+	}
+	return nil
+}
+
+// DeleteIdempotencyRecord removes the record for an idempotency key, so that the key can be used again
+func (s *Store) DeleteIdempotencyRecord(ctx context.Context, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.idempotency, key)
+	return nil
+}
+
+// GetSubscriptions retrieves a page of subscriptions, in the order they were added
+func (s *Store) GetSubscriptions(ctx context.Context, offset, limit int) ([]models.Subscription, int, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	values := make([]models.Subscription, 0, len(s.subscriptions))
+	for _, subscription := range s.subscriptions {
+		values = append(values, copySubscription(subscription))
+	}
+
+	values, totalCount := collections.SelectSubscriptions(values, offset, limit)
+	return values, totalCount, nil
+}
+
+// GetSubscriptionByID retrieves a single subscription by ID
+func (s *Store) GetSubscriptionByID(ctx context.Context, id string) (*models.Subscription, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, subscription := range s.subscriptions {
+		if subscription.ID == id {
+			result := copySubscription(subscription)
+			return &result, nil
+		}
+	}
+	return nil, collections.ErrSubscriptionNotFound
+}
+
+// AddSubscription adds a subscription
+func (s *Store) AddSubscription(ctx context.Context, subscription *models.Subscription) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result := copySubscription(subscription)
+	s.subscriptions = append(s.subscriptions, &result)
+	return nil
+}
+
+// DeleteSubscription removes a subscription and its deliveries. If there is no subscription with the ID, then
+// collections.ErrSubscriptionNotFound is returned.
+func (s *Store) DeleteSubscription(ctx context.Context, id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, subscription := range s.subscriptions {
+		if subscription.ID != id {
+			continue
+		}
+		s.subscriptions = append(s.subscriptions[:i], s.subscriptions[i+1:]...)
+
+		deliveries := s.deliveries[:0]
+		for _, delivery := range s.deliveries {
+			if delivery.SubscriptionID != id {
+				deliveries = append(deliveries, delivery)
+			}
+		}
+		s.deliveries = deliveries
+		return nil
+	}
+	return collections.ErrSubscriptionNotFound
+}
+
+// GetDeliveries retrieves the deliveries matching the query parameters, most recent first
+func (s *Store) GetDeliveries(ctx context.Context, queryParams collections.DeliveriesQueryParams) ([]models.Delivery, int, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	values, totalCount := collections.SelectDeliveries(s.allDeliveries(), queryParams)
+	return values, totalCount, nil
+}
+
+// AddDelivery adds a delivery
+func (s *Store) AddDelivery(ctx context.Context, delivery *models.Delivery) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result := copyDelivery(delivery)
+	s.deliveries = append(s.deliveries, &result)
+	onRollback(ctx, func() { s.deliveries = removeDelivery(s.deliveries, &result) })
+	return nil
+}
+
+// UpdateDelivery stores the outcome of an attempt to make a delivery. A delivery that has been removed, along with
+// its subscription, is not added again.
+func (s *Store) UpdateDelivery(ctx context.Context, delivery *models.Delivery) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, existing := range s.deliveries {
+		if existing.ID == delivery.ID {
+			result := copyDelivery(delivery)
+			s.deliveries[i] = &result
+			return nil
+		}
+	}
+	return nil
+}
+
+// ClaimDeliveries returns up to limit pending deliveries that are due at the given time, the longest overdue first,
+// and postpones their next attempt by the lease, so that they are not claimed again while they are being made
+func (s *Store) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Delivery, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	due := collections.SelectDueDeliveries(s.allDeliveries(), now, limit)
+	for i := range due {
+		due[i].NextAttemptAt = now.Add(lease)
+		for _, existing := range s.deliveries {
+			if existing.ID == due[i].ID {
+				existing.NextAttemptAt = due[i].NextAttemptAt
+			}
+		}
+	}
+	return due, nil
+}
+
+// WithTransaction calls fn, and rolls back the collections, events, versions, deliveries and outbox messages it added
+// or changed if it fails. Unlike the MongoDB store, its writes are seen by other readers before it returns.
+func (s *Store) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx := &transaction{}
+	if err := fn(context.WithValue(ctx, transactionKey{}, tx)); err != nil {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		for i := len(tx.undo) - 1; i >= 0; i-- {
+			tx.undo[i]()
+		}
+		return err
+	}
+	return nil
+}
+
+// AddOutboxMessage adds a message to the outbox
+func (s *Store) AddOutboxMessage(ctx context.Context, message *models.OutboxMessage) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	m := copyOutboxMessage(message)
+	s.outbox = append(s.outbox, &m)
+	onRollback(ctx, func() { s.outbox = removeOutboxMessage(s.outbox, &m) })
+	return nil
+}
+
+// ClaimOutboxMessages returns up to limit messages that are not claimed at the given time, in the order they were
+// added, and claims them for the lease
+func (s *Store) ClaimOutboxMessages(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.OutboxMessage, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	values := []models.OutboxMessage{}
+	for _, message := range s.outbox {
+		if len(values) >= limit {
+			break
+		}
+		if message.ClaimedUntil.After(now) {
+			continue
+		}
+		message.ClaimedUntil = now.Add(lease)
+		values = append(values, copyOutboxMessage(message))
+	}
+	return values, nil
+}
+
+// DeleteOutboxMessage removes a message from the outbox. Removing a message that does not exist is not an error, as
+// the message may have been published by another relay.
+func (s *Store) DeleteOutboxMessage(ctx context.Context, id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, message := range s.outbox {
+		if message.ID == id {
+			s.outbox = append(s.outbox[:i], s.outbox[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// transaction records how to undo the writes made in a call to WithTransaction
+type transaction struct {
+	undo []func()
+}
+
+type transactionKey struct{}
+
+// onRollback registers undo to be called if the transaction in the context fails. Outside of a transaction it does
+// nothing. The caller must hold the mutex.
+func onRollback(ctx context.Context, undo func()) {
+	if tx, ok := ctx.Value(transactionKey{}).(*transaction); ok {
+		tx.undo = append(tx.undo, undo)
+	}
+}
+
+// restoreOnRollback registers the current value of a collection to be restored if the transaction in the context
+// fails. The caller must hold the mutex.
+func (s *Store) restoreOnRollback(ctx context.Context, existing *models.Collection) {
+	previous := copyCollection(existing)
+	onRollback(ctx, func() { *existing = previous })
+}
+
+// allDeliveries returns copies of every delivery. The caller must hold the mutex.
+func (s *Store) allDeliveries() []models.Delivery {
+	values := make([]models.Delivery, 0, len(s.deliveries))
+	for _, delivery := range s.deliveries {
+		values = append(values, copyDelivery(delivery))
+	}
+	return values
+}
+
+func (s *Store) findCollection(id string) *models.Collection {
+	for _, collection := range s.collections {
+		if collection.ID == id {
+			return collection
+		}
+	}
+	return nil
+}
+
+func removeCollection(values []*models.Collection, value *models.Collection) []*models.Collection {
+	for i := range values {
+		if values[i] == value {
+			return append(values[:i], values[i+1:]...)
+		}
+	}
+	return values
+}
+
+func removeEvent(values []*models.Event, value *models.Event) []*models.Event {
+	for i := range values {
+		if values[i] == value {
+			return append(values[:i], values[i+1:]...)
+		}
+	}
+	return values
+}
+
+func removeVersion(values []*models.Version, value *models.Version) []*models.Version {
+	for i := range values {
+		if values[i] == value {
+			return append(values[:i], values[i+1:]...)
+		}
+	}
+	return values
+}
+
+func removeDelivery(values []*models.Delivery, value *models.Delivery) []*models.Delivery {
+	for i := range values {
+		if values[i] == value {
+			return append(values[:i], values[i+1:]...)
+		}
+	}
+	return values
+}
+
+func removeOutboxMessage(values []*models.OutboxMessage, value *models.OutboxMessage) []*models.OutboxMessage {
+	for i := range values {
+		if values[i] == value {
+			return append(values[:i], values[i+1:]...)
+		}
+	}
+	return values
+}
+
+func copyCollection(collection *models.Collection) models.Collection {
+	c := *collection
+	if collection.PublishDate != nil {
+		publishDate := *collection.PublishDate
+		c.PublishDate = &publishDate
+	}
+	if collection.PublishResult != nil {
+		publishResult := *collection.PublishResult
+		c.PublishResult = &publishResult
+	}
+	return c
+}
+
+func copyVersion(version *models.Version) models.Version {
+	v := *version
+	v.Collection = copyCollection(&version.Collection)
+	return v
+}
+
+func copyIdempotencyRecord(record *models.IdempotencyRecord) *models.IdempotencyRecord {
+	r := *record
+	r.Header = make(map[string][]string, len(record.Header))
+	for k, v := range record.Header {
+		r.Header[k] = append([]string(nil), v...)
+	}
+	r.Body = append([]byte(nil), record.Body...)
+	return &r
+}
+
+func copySubscription(subscription *models.Subscription) models.Subscription {
+	c := *subscription
+	c.EventTypes = append(make([]string, 0, len(subscription.EventTypes)), subscription.EventTypes...)
+	return c
+}
+
+func copyDelivery(delivery *models.Delivery) models.Delivery {
+	d := *delivery
+	d.Payload.Collection = copyCollection(&delivery.Payload.Collection)
+	if delivery.LastAttemptAt != nil {
+		lastAttemptAt := *delivery.LastAttemptAt
+		d.LastAttemptAt = &lastAttemptAt
+	}
+	return d
+}
+
+func copyOutboxMessage(message *models.OutboxMessage) models.OutboxMessage {
+	m := *message
+	m.Collection = copyCollection(&message.Collection)
+	return m
+}
@@ -0,0 +1,213 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/memory"
+	"github.com/ONSdigital/dp-collection-api/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var ctx = context.Background()
+
+func TestStore_collections(t *testing.T) {
+
+	Convey("Given a store containing some collections", t, func() {
+		store := memory.New()
+
+		later := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+		earlier := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+
+		So(store.AddCollection(ctx, &models.Collection{ID: "1", Name: "Economy", PublishDate: &later, ETag: "etag1"}), ShouldBeNil)
+		So(store.AddCollection(ctx, &models.Collection{ID: "2", Name: "Population", PublishDate: &earlier, ETag: "etag2"}), ShouldBeNil)
+		So(store.AddCollection(ctx, &models.Collection{ID: "3", Name: "Economic indicators", ETag: "etag3"}), ShouldBeNil)
+
+		Convey("When the collections are listed with no order", func() {
+			values, total, err := store.GetCollections(ctx, collections.QueryParams{Limit: 10})
+
+			Convey("Then they are returned in the order they were added", func() {
+				So(err, ShouldBeNil)
+				So(total, ShouldEqual, 3)
+				So(ids(values), ShouldResemble, []string{"1", "2", "3"})
+			})
+		})
+
+		Convey("When the collections are listed by publish date", func() {
+			values, _, err := store.GetCollections(ctx, collections.QueryParams{Limit: 10, OrderBy: collections.OrderByPublishDate})
+
+			Convey("Then collections without a publish date come first", func() {
+				So(err, ShouldBeNil)
+				So(ids(values), ShouldResemble, []string{"3", "2", "1"})
+			})
+		})
+
+		Convey("When the collections are searched by name", func() {
+			values, total, err := store.GetCollections(ctx, collections.QueryParams{Limit: 10, NameSearch: "econ"})
+
+			Convey("Then the case insensitive matches are returned", func() {
+				So(err, ShouldBeNil)
+				So(total, ShouldEqual, 2)
+				So(ids(values), ShouldResemble, []string{"1", "3"})
+			})
+		})
+
+		Convey("When a page of collections is requested", func() {
+			values, total, err := store.GetCollections(ctx, collections.QueryParams{Offset: 2, Limit: 2})
+
+			Convey("Then only the collections in the page are returned, with the total count", func() {
+				So(err, ShouldBeNil)
+				So(total, ShouldEqual, 3)
+				So(ids(values), ShouldResemble, []string{"3"})
+			})
+		})
+
+		Convey("When a collection is retrieved by name", func() {
+			collection, err := store.GetCollectionByName(ctx, "Population")
+
+			Convey("Then the collection is returned", func() {
+				So(err, ShouldBeNil)
+				So(collection.ID, ShouldEqual, "2")
+			})
+		})
+
+		Convey("When a collection is retrieved with an eTag that does not match", func() {
+			_, err := store.GetCollectionByID(ctx, "1", "etag2")
+
+			Convey("Then a conflict error is returned", func() {
+				So(err, ShouldEqual, collections.ErrCollectionConflict)
+			})
+		})
+
+		Convey("When a collection that does not exist is retrieved", func() {
+			_, err := store.GetCollectionByID(ctx, "4", models.AnyETag)
+
+			Convey("Then a not found error is returned", func() {
+				So(err, ShouldEqual, collections.ErrCollectionNotFound)
+			})
+		})
+
+		Convey("When a collection is replaced with the current eTag", func() {
+			err := store.ReplaceCollection(ctx, &models.Collection{ID: "1", Name: "Economy 2021", ETag: "etag4"}, "etag1")
+
+			Convey("Then the collection is updated, keeping the fields that were not provided", func() {
+				So(err, ShouldBeNil)
+				collection, err := store.GetCollectionByID(ctx, "1", models.AnyETag)
+				So(err, ShouldBeNil)
+				So(collection.Name, ShouldEqual, "Economy 2021")
+				So(collection.ETag, ShouldEqual, "etag4")
+				So(*collection.PublishDate, ShouldEqual, later)
+			})
+		})
+
+		Convey("When a collection is replaced with an out of date eTag", func() {
+			err := store.ReplaceCollection(ctx, &models.Collection{ID: "1", Name: "Economy 2021"}, "etag2")
+
+			Convey("Then a conflict error is returned", func() {
+				So(err, ShouldEqual, collections.ErrCollectionConflict)
+			})
+		})
+
+		Convey("When a returned collection is modified", func() {
+			collection, err := store.GetCollectionByID(ctx, "1", models.AnyETag)
+			So(err, ShouldBeNil)
+			collection.Name = "changed"
+			*collection.PublishDate = earlier
+
+			Convey("Then the stored collection is unchanged", func() {
+				stored, err := store.GetCollectionByID(ctx, "1", models.AnyETag)
+				So(err, ShouldBeNil)
+				So(stored.Name, ShouldEqual, "Economy")
+				So(*stored.PublishDate, ShouldEqual, later)
+			})
+		})
+
+		Convey("When the collection stats are requested", func() {
+			stats, err := store.GetCollectionStats(ctx, earlier.Add(time.Hour), 60*24*time.Hour)
+
+			Convey("Then the collections are counted by state", func() {
+				So(err, ShouldBeNil)
+				So(*stats, ShouldResemble, models.CollectionStats{Unscheduled: 1, Scheduled: 1, Published: 1, UpcomingPublishes: 1})
+			})
+		})
+	})
+}
+
+func TestStore_events(t *testing.T) {
+
+	Convey("Given a store containing events for two collections", t, func() {
+		store := memory.New()
+
+		for i, date := range []time.Time{
+			time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC),
+		} {
+			So(store.AddEvent(ctx, &models.Event{ID: string(rune('a' + i)), CollectionID: "1", Date: date}), ShouldBeNil)
+		}
+		So(store.AddEvent(ctx, &models.Event{ID: "d", CollectionID: "2"}), ShouldBeNil)
+
+		Convey("When the events for a collection are requested", func() {
+			values, total, err := store.GetCollectionEvents(ctx, collections.EventsQueryParams{CollectionID: "1", Limit: 2})
+
+			Convey("Then a page of its events are returned in date order", func() {
+				So(err, ShouldBeNil)
+				So(total, ShouldEqual, 3)
+				So(len(values), ShouldEqual, 2)
+				So(values[0].ID, ShouldEqual, "b")
+				So(values[1].ID, ShouldEqual, "c")
+			})
+		})
+	})
+}
+
+func TestStore_idempotency(t *testing.T) {
+
+	Convey("Given a store containing an unexpired idempotency record", t, func() {
+		store := memory.New()
+		record := &models.IdempotencyRecord{Key: "key", ExpiresAt: time.Now().Add(time.Hour)}
+		So(store.AddIdempotencyRecord(ctx, record), ShouldBeNil)
+
+		Convey("When a record with the same key is added", func() {
+			err := store.AddIdempotencyRecord(ctx, &models.IdempotencyRecord{Key: "key", ExpiresAt: time.Now().Add(time.Hour)})
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldEqual, collections.ErrIdempotencyKeyExists)
+			})
+		})
+
+		Convey("When the record is deleted", func() {
+			So(store.DeleteIdempotencyRecord(ctx, "key"), ShouldBeNil)
+
+			Convey("Then it is no longer found", func() {
+				_, err := store.GetIdempotencyRecord(ctx, "key")
+				So(err, ShouldEqual, collections.ErrIdempotencyRecordNotFound)
+			})
+		})
+	})
+
+	Convey("Given a store containing an expired idempotency record", t, func() {
+		store := memory.New()
+		So(store.AddIdempotencyRecord(ctx, &models.IdempotencyRecord{Key: "key", ExpiresAt: time.Now().Add(-time.Hour)}), ShouldBeNil)
+
+		Convey("Then it is not found", func() {
+			_, err := store.GetIdempotencyRecord(ctx, "key")
+			So(err, ShouldEqual, collections.ErrIdempotencyRecordNotFound)
+		})
+
+		Convey("Then a new record can be added with the same key", func() {
+			err := store.AddIdempotencyRecord(ctx, &models.IdempotencyRecord{Key: "key", ExpiresAt: time.Now().Add(time.Hour)})
+			So(err, ShouldBeNil)
+		})
+	})
+}
+
+func ids(values []models.Collection) []string {
+	var result []string
+	for _, value := range values {
+		result = append(result, value.ID)
+	}
+	return result
+}
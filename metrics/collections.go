@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// collectionStatsTimeout limits how long a scrape of the metrics endpoint waits for the collection store
+const collectionStatsTimeout = 5 * time.Second
+
+// collectionsCollector reports gauges for the state of collections, read from the store each time metrics are scraped
+type collectionsCollector struct {
+	store           CollectionStatsStore
+	upcomingWindow  time.Duration
+	collectionsDesc *prometheus.Desc
+	upcomingDesc    *prometheus.Desc
+}
+
+// RegisterCollectionStats adds gauges for the number of collections in each state, and the number of scheduled
+// publishes within the upcoming window
+func (m *Metrics) RegisterCollectionStats(store CollectionStatsStore, upcomingWindow time.Duration) error {
+	return m.registry.Register(&collectionsCollector{
+		store:          store,
+		upcomingWindow: upcomingWindow,
+		collectionsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "collections"),
+			"The number of collections, by state",
+			[]string{"state"}, nil,
+		),
+		upcomingDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "upcoming_scheduled_publishes"),
+			"The number of collections scheduled to be published within the upcoming window",
+			nil, prometheus.Labels{"window": upcomingWindow.String()},
+		),
+	})
+}
+
+// Describe implements prometheus.Collector
+func (c *collectionsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.collectionsDesc
+	ch <- c.upcomingDesc
+}
+
+// Collect implements prometheus.Collector. If the store cannot be read, the gauges are left out of the scrape.
+func (c *collectionsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectionStatsTimeout)
+	defer cancel()
+
+	stats, err := c.store.GetCollectionStats(ctx, time.Now(), c.upcomingWindow)
+	if err != nil {
+		log.Error(ctx, "failed to get collection stats for metrics", err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.collectionsDesc, prometheus.GaugeValue, float64(stats.Unscheduled), models.StateUnscheduled)
+	ch <- prometheus.MustNewConstMetric(c.collectionsDesc, prometheus.GaugeValue, float64(stats.Scheduled), models.StateScheduled)
+	ch <- prometheus.MustNewConstMetric(c.collectionsDesc, prometheus.GaugeValue, float64(stats.Published), models.StatePublished)
+	ch <- prometheus.MustNewConstMetric(c.upcomingDesc, prometheus.GaugeValue, float64(stats.UpcomingPublishes))
+}
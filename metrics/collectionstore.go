@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/api"
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+)
+
+// expectedErrors are returned by the collection store as part of normal operation, so are not counted as failures
+var expectedErrors = map[error]bool{
+	collections.ErrCollectionNotFound: true,
+	collections.ErrCollectionConflict: true,
+}
+
+// CollectionStore wraps an api.CollectionStore, recording the duration and failures of each operation
+type CollectionStore struct {
+	store   api.CollectionStore
+	metrics *Metrics
+}
+
+// NewCollectionStore returns an instrumented decorator for the given collection store
+func NewCollectionStore(store api.CollectionStore, m *Metrics) *CollectionStore {
+	return &CollectionStore{
+		store:   store,
+		metrics: m,
+	}
+}
+
+func (s *CollectionStore) observe(operation string, start time.Time, err error) {
+	s.metrics.datastoreOperationTime.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil && !expectedErrors[err] {
+		s.metrics.datastoreOperationErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+// GetCollections records the duration and failure of the wrapped store's GetCollections
+func (s *CollectionStore) GetCollections(ctx context.Context, queryParams collections.QueryParams) ([]models.Collection, int, error) {
+	start := time.Now()
+	values, totalCount, err := s.store.GetCollections(ctx, queryParams)
+	s.observe("GetCollections", start, err)
+	return values, totalCount, err
+}
+
+// AddCollection records the duration and failure of the wrapped store's AddCollection
+func (s *CollectionStore) AddCollection(ctx context.Context, collection *models.Collection) error {
+	start := time.Now()
+	err := s.store.AddCollection(ctx, collection)
+	s.observe("AddCollection", start, err)
+	return err
+}
+
+// ReplaceCollection records the duration and failure of the wrapped store's ReplaceCollection
+func (s *CollectionStore) ReplaceCollection(ctx context.Context, collection *models.Collection, eTagSelector string) error {
+	start := time.Now()
+	err := s.store.ReplaceCollection(ctx, collection, eTagSelector)
+	s.observe("ReplaceCollection", start, err)
+	return err
+}
+
+// GetCollectionByID records the duration and failure of the wrapped store's GetCollectionByID
+func (s *CollectionStore) GetCollectionByID(ctx context.Context, id string, eTagSelector string) (*models.Collection, error) {
+	start := time.Now()
+	collection, err := s.store.GetCollectionByID(ctx, id, eTagSelector)
+	s.observe("GetCollectionByID", start, err)
+	return collection, err
+}
+
+// GetCollectionByName records the duration and failure of the wrapped store's GetCollectionByName
+func (s *CollectionStore) GetCollectionByName(ctx context.Context, name string) (*models.Collection, error) {
+	start := time.Now()
+	collection, err := s.store.GetCollectionByName(ctx, name)
+	s.observe("GetCollectionByName", start, err)
+	return collection, err
+}
+
+// GetCollectionEvents records the duration and failure of the wrapped store's GetCollectionEvents
+func (s *CollectionStore) GetCollectionEvents(ctx context.Context, queryParams collections.EventsQueryParams) ([]models.Event, int, error) {
+	start := time.Now()
+	events, totalCount, err := s.store.GetCollectionEvents(ctx, queryParams)
+	s.observe("GetCollectionEvents", start, err)
+	return events, totalCount, err
+}
+
+// AddEvent records the duration and failure of the wrapped store's AddEvent
+func (s *CollectionStore) AddEvent(ctx context.Context, event *models.Event) error {
+	start := time.Now()
+	err := s.store.AddEvent(ctx, event)
+	s.observe("AddEvent", start, err)
+	return err
+}
@@ -0,0 +1,77 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ONSdigital/dp-collection-api/api/mock"
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/metrics"
+	"github.com/ONSdigital/dp-collection-api/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCollectionStore(t *testing.T) {
+
+	Convey("Given an instrumented collection store", t, func() {
+		m := metrics.New()
+		store := &mock.CollectionStoreMock{
+			GetCollectionByIDFunc: func(ctx context.Context, id string, eTagSelector string) (*models.Collection, error) {
+				return &models.Collection{ID: id}, nil
+			},
+			GetCollectionByNameFunc: func(ctx context.Context, name string) (*models.Collection, error) {
+				return nil, collections.ErrCollectionNotFound
+			},
+			AddCollectionFunc: func(ctx context.Context, collection *models.Collection) error {
+				return errors.New("db is broken")
+			},
+		}
+		instrumented := metrics.NewCollectionStore(store, m)
+
+		Convey("When a store operation succeeds", func() {
+			collection, err := instrumented.GetCollectionByID(context.Background(), "123", models.AnyETag)
+
+			Convey("Then the result of the wrapped store is returned", func() {
+				So(err, ShouldBeNil)
+				So(collection.ID, ShouldEqual, "123")
+				So(len(store.GetCollectionByIDCalls()), ShouldEqual, 1)
+			})
+
+			Convey("Then the duration of the operation is observed", func() {
+				So(scrape(m), ShouldContainSubstring,
+					`dp_collection_api_datastore_operation_duration_seconds_count{operation="GetCollectionByID"} 1`)
+			})
+
+			Convey("Then no error is counted", func() {
+				So(scrape(m), ShouldNotContainSubstring, `dp_collection_api_datastore_operation_errors_total{operation="GetCollectionByID"}`)
+			})
+		})
+
+		Convey("When a store operation fails", func() {
+			err := instrumented.AddCollection(context.Background(), &models.Collection{})
+
+			Convey("Then the error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+
+			Convey("Then the error is counted", func() {
+				So(scrape(m), ShouldContainSubstring,
+					`dp_collection_api_datastore_operation_errors_total{operation="AddCollection"} 1`)
+			})
+		})
+
+		Convey("When a store operation returns an expected error", func() {
+			_, err := instrumented.GetCollectionByName(context.Background(), "name")
+
+			Convey("Then the error is returned", func() {
+				So(err, ShouldEqual, collections.ErrCollectionNotFound)
+			})
+
+			Convey("Then the error is not counted as a failure", func() {
+				So(scrape(m), ShouldNotContainSubstring, `dp_collection_api_datastore_operation_errors_total{operation="GetCollectionByName"}`)
+			})
+		})
+	})
+}
@@ -0,0 +1,15 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/models"
+)
+
+//go:generate moq -out mock/collectionstatsstore.go -pkg mock . CollectionStatsStore
+
+// CollectionStatsStore defines the required methods to report on the state of collections
+type CollectionStatsStore interface {
+	GetCollectionStats(ctx context.Context, now time.Time, upcomingWindow time.Duration) (*models.CollectionStats, error)
+}
@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "dp_collection_api"
+
+// streamedRoutes are the routes whose responses are streamed for as long as there is data to send or the client
+// stays connected. Their durations are not observed, as they would skew the request duration histogram.
+var streamedRoutes = map[string]bool{
+	"/collections/export":                        true,
+	"/collections/{collection_id}/events/stream": true,
+}
+
+// Metrics holds the Prometheus collectors for the service, in a registry of its own
+type Metrics struct {
+	registry                 *prometheus.Registry
+	httpRequests             *prometheus.CounterVec
+	httpRequestDuration      *prometheus.HistogramVec
+	datastoreOperationTime   *prometheus.HistogramVec
+	datastoreOperationErrors *prometheus.CounterVec
+}
+
+// New creates and registers the service's metrics, along with the standard Go runtime and process metrics
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "The number of HTTP requests handled, by route, method and status",
+		}, []string{"route", "method", "status"}),
+		httpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "The time taken to handle HTTP requests, other than streamed responses, by route, method and status",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		datastoreOperationTime: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "datastore_operation_duration_seconds",
+			Help:      "The time taken by collection store operations, by operation",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation"}),
+		datastoreOperationErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "datastore_operation_errors_total",
+			Help:      "The number of collection store operations that failed, by operation",
+		}, []string{"operation"}),
+	}
+
+	m.registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpRequestDuration,
+		m.datastoreOperationTime,
+		m.datastoreOperationErrors,
+	)
+
+	return m
+}
+
+// Handler returns the handler for the metrics endpoint
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware records the count and duration of requests, other than the duration of streamed responses. It must be
+// added to the router with Use, so that requests are labelled with the template of the matched route rather than
+// the request path.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+
+		route := "unknown"
+		if current := mux.CurrentRoute(req); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(recorder, req)
+
+		labels := prometheus.Labels{
+			"route":  route,
+			"method": req.Method,
+			"status": strconv.Itoa(recorder.statusCode),
+		}
+		m.httpRequests.With(labels).Inc()
+		if !streamedRoutes[route] {
+			m.httpRequestDuration.With(labels).Observe(time.Since(start).Seconds())
+		}
+	})
+}
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(statusCode int) {
+	if !r.wroteHeader {
+		r.statusCode = statusCode
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush sends any buffered response to the client, if the underlying writer supports it, so that streamed responses
+// are not held back by the recorder
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		r.wroteHeader = true
+		flusher.Flush()
+	}
+}
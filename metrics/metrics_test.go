@@ -0,0 +1,121 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/metrics"
+	"github.com/ONSdigital/dp-collection-api/metrics/mock"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/gorilla/mux"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func scrape(m *metrics.Metrics) string {
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	return w.Body.String()
+}
+
+func TestMiddleware(t *testing.T) {
+
+	Convey("Given a router instrumented with the metrics middleware", t, func() {
+		m := metrics.New()
+		r := mux.NewRouter()
+		r.Use(m.Middleware)
+		r.HandleFunc("/collections/{collection_id}", func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}).Methods(http.MethodGet)
+
+		Convey("When a request is handled", func() {
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/collections/123", nil))
+
+			Convey("Then the request is counted against the route template and status", func() {
+				So(scrape(m), ShouldContainSubstring,
+					`dp_collection_api_http_requests_total{method="GET",route="/collections/{collection_id}",status="404"} 1`)
+			})
+
+			Convey("Then the request duration is observed", func() {
+				So(scrape(m), ShouldContainSubstring,
+					`dp_collection_api_http_request_duration_seconds_count{method="GET",route="/collections/{collection_id}",status="404"} 1`)
+			})
+		})
+
+		Convey("When a request to the event stream is handled", func() {
+			r.HandleFunc("/collections/{collection_id}/events/stream", func(w http.ResponseWriter, req *http.Request) {
+				w.Write([]byte("data: {}\n\n"))
+			})
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/collections/123/events/stream", nil))
+
+			Convey("Then the request is counted", func() {
+				So(scrape(m), ShouldContainSubstring,
+					`dp_collection_api_http_requests_total{method="GET",route="/collections/{collection_id}/events/stream",status="200"} 1`)
+			})
+
+			Convey("Then the duration of the streamed response is not observed", func() {
+				So(scrape(m), ShouldNotContainSubstring, "dp_collection_api_http_request_duration_seconds_count")
+			})
+		})
+
+		Convey("When a handler does not write a status", func() {
+			r.HandleFunc("/ok", func(w http.ResponseWriter, req *http.Request) {
+				w.Write([]byte("ok"))
+			})
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ok", nil))
+
+			Convey("Then the request is counted with a 200 status", func() {
+				So(scrape(m), ShouldContainSubstring,
+					`dp_collection_api_http_requests_total{method="GET",route="/ok",status="200"} 1`)
+			})
+		})
+	})
+}
+
+func TestCollectionStats(t *testing.T) {
+
+	Convey("Given metrics with collection stats registered", t, func() {
+		m := metrics.New()
+		store := &mock.CollectionStatsStoreMock{
+			GetCollectionStatsFunc: func(ctx context.Context, now time.Time, upcomingWindow time.Duration) (*models.CollectionStats, error) {
+				return &models.CollectionStats{Unscheduled: 1, Scheduled: 2, Published: 3, UpcomingPublishes: 4}, nil
+			},
+		}
+		So(m.RegisterCollectionStats(store, time.Hour), ShouldBeNil)
+
+		Convey("When the metrics are scraped", func() {
+			body := scrape(m)
+
+			Convey("Then the store is called with the upcoming window", func() {
+				So(len(store.GetCollectionStatsCalls()), ShouldEqual, 1)
+				So(store.GetCollectionStatsCalls()[0].UpcomingWindow, ShouldEqual, time.Hour)
+			})
+
+			Convey("Then the number of collections in each state is reported", func() {
+				So(body, ShouldContainSubstring, `dp_collection_api_collections{state="unscheduled"} 1`)
+				So(body, ShouldContainSubstring, `dp_collection_api_collections{state="scheduled"} 2`)
+				So(body, ShouldContainSubstring, `dp_collection_api_collections{state="published"} 3`)
+			})
+
+			Convey("Then the number of upcoming scheduled publishes is reported", func() {
+				So(body, ShouldContainSubstring, `dp_collection_api_upcoming_scheduled_publishes{window="1h0m0s"} 4`)
+			})
+		})
+
+		Convey("When the store returns an error", func() {
+			store.GetCollectionStatsFunc = func(ctx context.Context, now time.Time, upcomingWindow time.Duration) (*models.CollectionStats, error) {
+				return nil, errors.New("db is broken")
+			}
+			body := scrape(m)
+
+			Convey("Then the other metrics are still reported", func() {
+				So(body, ShouldContainSubstring, "go_goroutines")
+				So(body, ShouldNotContainSubstring, "dp_collection_api_collections")
+			})
+		})
+	})
+}
@@ -0,0 +1,90 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/ONSdigital/dp-collection-api/metrics"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"sync"
+	"time"
+)
+
+// Ensure, that CollectionStatsStoreMock does implement metrics.CollectionStatsStore.
+// If this is not the case, regenerate this file with moq.
+var _ metrics.CollectionStatsStore = &CollectionStatsStoreMock{}
+
+// CollectionStatsStoreMock is a mock implementation of metrics.CollectionStatsStore.
+//
+//	func TestSomethingThatUsesCollectionStatsStore(t *testing.T) {
+//
+//		// make and configure a mocked metrics.CollectionStatsStore
+//		mockedCollectionStatsStore := &CollectionStatsStoreMock{
+//			GetCollectionStatsFunc: func(ctx context.Context, now time.Time, upcomingWindow time.Duration) (*models.CollectionStats, error) {
+//				panic("mock out the GetCollectionStats method")
+//			},
+//		}
+//
+//		// use mockedCollectionStatsStore in code that requires metrics.CollectionStatsStore
+//		// and then make assertions.
+//
+//	}
+type CollectionStatsStoreMock struct {
+	// GetCollectionStatsFunc mocks the GetCollectionStats method.
+	GetCollectionStatsFunc func(ctx context.Context, now time.Time, upcomingWindow time.Duration) (*models.CollectionStats, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// GetCollectionStats holds details about calls to the GetCollectionStats method.
+		GetCollectionStats []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Now is the now argument value.
+			Now time.Time
+			// UpcomingWindow is the upcomingWindow argument value.
+			UpcomingWindow time.Duration
+		}
+	}
+	lockGetCollectionStats sync.RWMutex
+}
+
+// GetCollectionStats calls GetCollectionStatsFunc.
+func (mock *CollectionStatsStoreMock) GetCollectionStats(ctx context.Context, now time.Time, upcomingWindow time.Duration) (*models.CollectionStats, error) {
+	if mock.GetCollectionStatsFunc == nil {
+		panic("CollectionStatsStoreMock.GetCollectionStatsFunc: method is nil but CollectionStatsStore.GetCollectionStats was just called")
+	}
+	callInfo := struct {
+		Ctx            context.Context
+		Now            time.Time
+		UpcomingWindow time.Duration
+	}{
+		Ctx:            ctx,
+		Now:            now,
+		UpcomingWindow: upcomingWindow,
+	}
+	mock.lockGetCollectionStats.Lock()
+	mock.calls.GetCollectionStats = append(mock.calls.GetCollectionStats, callInfo)
+	mock.lockGetCollectionStats.Unlock()
+	return mock.GetCollectionStatsFunc(ctx, now, upcomingWindow)
+}
+
+// GetCollectionStatsCalls gets all the calls that were made to GetCollectionStats.
+// Check the length with:
+//
+//	len(mockedCollectionStatsStore.GetCollectionStatsCalls())
+func (mock *CollectionStatsStoreMock) GetCollectionStatsCalls() []struct {
+	Ctx            context.Context
+	Now            time.Time
+	UpcomingWindow time.Duration
+} {
+	var calls []struct {
+		Ctx            context.Context
+		Now            time.Time
+		UpcomingWindow time.Duration
+	}
+	mock.lockGetCollectionStats.RLock()
+	calls = mock.calls.GetCollectionStats
+	mock.lockGetCollectionStats.RUnlock()
+	return calls
+}
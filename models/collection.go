@@ -13,11 +13,14 @@ const AnyETag = "*"
 
 // Collection represents information related to a single collection
 type Collection struct {
-	ID          string     `bson:"_id,omitempty"          json:"id,omitempty"`
-	Name        string     `bson:"name,omitempty"         json:"name,omitempty"`
-	PublishDate *time.Time `bson:"publish_date,omitempty" json:"publish_date,omitempty"`
-	LastUpdated time.Time  `bson:"last_updated,omitempty" json:"-"`
-	ETag        string     `bson:"e_tag"                  json:"e_tag,omitempty"`
+	ID            string         `bson:"_id,omitempty"            json:"id,omitempty"`
+	Name          string         `bson:"name,omitempty"           json:"name,omitempty"`
+	PublishDate   *time.Time     `bson:"publish_date,omitempty"   json:"publish_date,omitempty"`
+	PublishResult *PublishResult `bson:"publish_result,omitempty" json:"publish_result,omitempty"`
+	Version       int            `bson:"version,omitempty"        json:"version,omitempty"`
+	LastUpdated   time.Time      `bson:"last_updated,omitempty"   json:"-"`
+	CreatedAt     time.Time      `bson:"created_at,omitempty"     json:"-"`
+	ETag          string         `bson:"e_tag"                    json:"e_tag,omitempty"`
 }
 
 // CollectionsResponse represents a paginated list of collections
@@ -57,3 +60,65 @@ func (c *Collection) NewETagForUpdate(update *Collection) (eTag string, err erro
 	}
 	return c.Hash(b)
 }
+
+// Set updates the collection with the non-empty fields of the update, in the same way as a MongoDB $set of the
+// update document. The ETag is always set, and the read-only ID and dates are not changed.
+func (c *Collection) Set(update *Collection) {
+	if len(update.Name) > 0 {
+		c.Name = update.Name
+	}
+	if update.PublishDate != nil {
+		publishDate := *update.PublishDate
+		c.PublishDate = &publishDate
+	}
+	if update.PublishResult != nil {
+		publishResult := *update.PublishResult
+		c.PublishResult = &publishResult
+	}
+	if update.Version > 0 {
+		c.Version = update.Version
+	}
+	c.ETag = update.ETag
+}
+
+// Collection states. The state of a collection is derived from its publish date.
+const (
+	StateUnscheduled = "unscheduled"
+	StateScheduled   = "scheduled"
+	StatePublished   = "published"
+)
+
+// State returns the state of the collection at the given time
+func (c *Collection) State(now time.Time) string {
+	switch {
+	case c.PublishDate == nil:
+		return StateUnscheduled
+	case c.PublishDate.After(now):
+		return StateScheduled
+	default:
+		return StatePublished
+	}
+}
+
+// CollectionStats represents the number of collections in each state, and the number due to be published soon
+type CollectionStats struct {
+	Unscheduled       int
+	Scheduled         int
+	Published         int
+	UpcomingPublishes int
+}
+
+// Add counts the collection in the stats, at the given time
+func (s *CollectionStats) Add(c *Collection, now time.Time, upcomingWindow time.Duration) {
+	switch c.State(now) {
+	case StateUnscheduled:
+		s.Unscheduled++
+	case StateScheduled:
+		s.Scheduled++
+		if !c.PublishDate.After(now.Add(upcomingWindow)) {
+			s.UpcomingPublishes++
+		}
+	case StatePublished:
+		s.Published++
+	}
+}
@@ -100,3 +100,66 @@ func TestGetNewETagForUpdate(t *testing.T) {
 		})
 	})
 }
+
+func TestCollectionState(t *testing.T) {
+
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	Convey("Given a collection without a publish date", t, func() {
+		collection := Collection{}
+
+		Convey("Then it is unscheduled", func() {
+			So(collection.State(now), ShouldEqual, StateUnscheduled)
+		})
+	})
+
+	Convey("Given a collection with a publish date in the future", t, func() {
+		collection := Collection{PublishDate: &future}
+
+		Convey("Then it is scheduled", func() {
+			So(collection.State(now), ShouldEqual, StateScheduled)
+		})
+	})
+
+	Convey("Given a collection with a publish date in the past", t, func() {
+		collection := Collection{PublishDate: &past}
+
+		Convey("Then it is published", func() {
+			So(collection.State(now), ShouldEqual, StatePublished)
+		})
+	})
+}
+
+func TestPublishResultSupersedes(t *testing.T) {
+
+	earlier := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Minute)
+
+	Convey("Given a collection without a publish result", t, func() {
+		Convey("Then any result supersedes it", func() {
+			result := &PublishResult{Status: PublishFailed, Date: earlier, MessageID: "1"}
+			So(result.Supersedes(nil), ShouldBeTrue)
+		})
+	})
+
+	Convey("Given a collection with a publish result", t, func() {
+		current := &PublishResult{Status: PublishFailed, Date: earlier, MessageID: "1"}
+
+		Convey("Then a later result supersedes it", func() {
+			result := &PublishResult{Status: PublishCompleted, Date: later, MessageID: "2"}
+			So(result.Supersedes(current), ShouldBeTrue)
+		})
+
+		Convey("Then an earlier result does not supersede it", func() {
+			result := &PublishResult{Status: PublishCompleted, Date: earlier.Add(-time.Minute), MessageID: "2"}
+			So(result.Supersedes(current), ShouldBeFalse)
+		})
+
+		Convey("Then the same message received again does not supersede it", func() {
+			result := &PublishResult{Status: PublishFailed, Date: earlier, MessageID: "1"}
+			So(result.Supersedes(current), ShouldBeFalse)
+		})
+	})
+}
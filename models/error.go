@@ -1,11 +1,59 @@
 package models
 
+// Error codes are stable, machine-readable values identifying the type of an error in a response.
+// Clients should use these rather than the message, which may change.
+const (
+	ErrCodeInternalError               = "internal_error"
+	ErrCodeInvalidJSON                 = "invalid_json"
+	ErrCodeUnknownField                = "unknown_field"
+	ErrCodeReadOnlyField               = "read_only_field"
+	ErrCodeRequestBodyTooLarge         = "request_body_too_large"
+	ErrCodeInvalidLimit                = "invalid_limit"
+	ErrCodeInvalidOffset               = "invalid_offset"
+	ErrCodeLimitOverMax                = "limit_over_max"
+	ErrCodeInvalidOrderBy              = "invalid_order_by"
+	ErrCodeNameSearchTooLong           = "name_search_too_long"
+	ErrCodeCollectionNameEmpty         = "collection_name_empty"
+	ErrCodeCollectionNameAlreadyExists = "collection_name_already_exists"
+	ErrCodePublishDateInPast           = "publish_date_in_past"
+	ErrCodeInvalidID                   = "invalid_id"
+	ErrCodeCollectionIDMismatch        = "collection_id_mismatch"
+	ErrCodeCollectionNotFound          = "collection_not_found"
+	ErrCodeCollectionConflict          = "collection_conflict"
+	ErrCodeCollectionAlreadyExists     = "collection_already_exists"
+	ErrCodeIfMatchHeaderRequired       = "if_match_header_required"
+	ErrCodeIdempotencyKeyTooLong       = "idempotency_key_too_long"
+	ErrCodeIdempotencyKeyReused        = "idempotency_key_reused"
+	ErrCodeIdempotencyKeyInProgress    = "idempotency_key_in_progress"
+	ErrCodeSubscriptionNotFound        = "subscription_not_found"
+	ErrCodeVersionNotFound             = "version_not_found"
+	ErrCodeInvalidCallbackURL          = "invalid_callback_url"
+	ErrCodeInvalidEventType            = "invalid_event_type"
+	ErrCodeInvalidParameter            = "invalid_parameter"
+	ErrCodeInvalidRequestBody          = "invalid_request_body"
+	ErrCodeUnsupportedContentType      = "unsupported_content_type"
+)
+
 // ErrorsResponse represents a slice of errors in a JSON response body
 type ErrorsResponse struct {
 	Errors []ErrorResponse `json:"errors"`
 }
 
-// ErrorResponse represents a single error in a JSON response body
+// ErrorResponse represents a single error in a JSON response body. The field value is only set when the
+// error relates to a specific field of the request, e.g. a body field, query parameter or header.
 type ErrorResponse struct {
+	Code    string `json:"code"`
 	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+}
+
+// ProblemDetails represents an RFC 7807 problem details document. The errors found when processing the request
+// are included as an extension member, in the same format as an ErrorsResponse.
+type ProblemDetails struct {
+	Type     string          `json:"type"`
+	Title    string          `json:"title"`
+	Status   int             `json:"status"`
+	Detail   string          `json:"detail,omitempty"`
+	Instance string          `json:"instance,omitempty"`
+	Errors   []ErrorResponse `json:"errors,omitempty"`
 }
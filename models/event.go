@@ -12,8 +12,26 @@ type Event struct {
 	Email        string    `bson:"email,omitempty" json:"email,omitempty"`
 	Date         time.Time `bson:"date,omitempty"  json:"date,omitempty"`
 	CollectionID string    `bson:"collection_id,omitempty"   json:"-"`
+	Version      int       `bson:"version,omitempty" json:"version,omitempty"`
 }
 
+// Types of the lifecycle events recorded when a collection is written through the API
+const (
+	EventCreated      = "CREATED"
+	EventUpdated      = "UPDATED"
+	EventStateChanged = "STATE_CHANGED"
+	EventPublished    = "PUBLISHED"
+)
+
+// Types of the events recorded when the publishing services report the outcome of publishing a collection
+const (
+	EventPublishCompleted = "PUBLISH_COMPLETED"
+	EventPublishFailed    = "PUBLISH_FAILED"
+)
+
+// LifecycleEventTypes are the types of every lifecycle event, in the order they are documented
+var LifecycleEventTypes = []string{EventCreated, EventUpdated, EventStateChanged, EventPublished, EventPublishCompleted, EventPublishFailed}
+
 // EventsResponse represents a paginated list of collection events
 type EventsResponse struct {
 	Items []Event `json:"items"`
@@ -0,0 +1,54 @@
+package models
+
+// ExportedCollection is a single line of an NDJSON export of collections, and of the body of an import. Events are
+// only included when they were requested.
+type ExportedCollection struct {
+	Collection
+	Events []Event `json:"events,omitempty"`
+}
+
+// Import results, for each line of an import
+const (
+	ImportCreated   = "created"
+	ImportUpdated   = "updated"
+	ImportUnchanged = "unchanged"
+	ImportSkipped   = "skipped"
+	ImportFailed    = "failed"
+)
+
+// ImportReport represents the result of an import, with a result for each line
+type ImportReport struct {
+	Created   int            `json:"created"`
+	Updated   int            `json:"updated"`
+	Unchanged int            `json:"unchanged"`
+	Skipped   int            `json:"skipped"`
+	Failed    int            `json:"failed"`
+	Stopped   bool           `json:"stopped"`
+	Results   []ImportResult `json:"results"`
+}
+
+// ImportResult represents the result of importing a single line. The errors explain why a line was skipped or failed.
+type ImportResult struct {
+	Line   int             `json:"line"`
+	ID     string          `json:"id,omitempty"`
+	Name   string          `json:"name,omitempty"`
+	Result string          `json:"result"`
+	Errors []ErrorResponse `json:"errors,omitempty"`
+}
+
+// Add adds the result of a line to the report
+func (r *ImportReport) Add(result ImportResult) {
+	switch result.Result {
+	case ImportCreated:
+		r.Created++
+	case ImportUpdated:
+		r.Updated++
+	case ImportUnchanged:
+		r.Unchanged++
+	case ImportSkipped:
+		r.Skipped++
+	case ImportFailed:
+		r.Failed++
+	}
+	r.Results = append(r.Results, result)
+}
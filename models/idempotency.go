@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// IdempotencyRecord represents a request made with an Idempotency-Key header, and the response that was returned for it.
+// A record with no status code is still being processed.
+type IdempotencyRecord struct {
+	Key         string              `bson:"_id"`
+	RequestHash string              `bson:"request_hash"`
+	StatusCode  int                 `bson:"status_code,omitempty"`
+	Header      map[string][]string `bson:"header,omitempty"`
+	Body        []byte              `bson:"body,omitempty"`
+	CreatedAt   time.Time           `bson:"created_at"`
+	ExpiresAt   time.Time           `bson:"expires_at"`
+}
+
+// IsComplete returns true if a response has been stored for the request
+func (r *IdempotencyRecord) IsComplete() bool {
+	return r.StatusCode != 0
+}
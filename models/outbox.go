@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// OutboxMessage is a collection event waiting to be published, stored in the same transaction as the write that
+// produced it. The collection is as it was written.
+type OutboxMessage struct {
+	ID           string     `bson:"_id"           json:"id"`
+	Event        Event      `bson:"event"         json:"event"`
+	Collection   Collection `bson:"collection"    json:"collection"`
+	CreatedAt    time.Time  `bson:"created_at"    json:"created_at"`
+	ClaimedUntil time.Time  `bson:"claimed_until" json:"claimed_until"`
+}
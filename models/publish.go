@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Outcomes of publishing a collection, reported by the publishing services
+const (
+	PublishCompleted = "completed"
+	PublishFailed    = "failed"
+)
+
+// PublishResult is the latest outcome of publishing a collection, as reported by the publishing services. The message
+// ID is the ID of the message that reported it, so that the same message is not applied twice.
+type PublishResult struct {
+	Status    string    `bson:"status"          json:"status"`
+	Date      time.Time `bson:"date"            json:"date"`
+	Error     string    `bson:"error,omitempty" json:"error,omitempty"`
+	MessageID string    `bson:"message_id"      json:"-"`
+}
+
+// Supersedes reports whether the result replaces the current result of a collection. Results are reported
+// asynchronously, and may be received more than once or out of order, so a result only replaces an earlier one, and
+// the result that has already been stored is never applied again.
+func (r *PublishResult) Supersedes(current *PublishResult) bool {
+	if current == nil {
+		return true
+	}
+	if r.MessageID == current.MessageID {
+		return false
+	}
+	return r.Date.After(current.Date)
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/pagination"
+)
+
+// Subscription represents a webhook registered to receive collection lifecycle events. A subscription without
+// event types receives every event. The secret is used to sign deliveries, and is only returned when the
+// subscription is created.
+type Subscription struct {
+	ID         string    `bson:"_id"         json:"id"`
+	URL        string    `bson:"url"         json:"url"`
+	EventTypes []string  `bson:"event_types" json:"event_types"`
+	Secret     string    `bson:"secret"      json:"secret,omitempty"`
+	CreatedAt  time.Time `bson:"created_at"  json:"created_at"`
+}
+
+// SubscriptionsResponse represents a paginated list of subscriptions
+type SubscriptionsResponse struct {
+	Items []Subscription `json:"items"`
+	pagination.PaginatedResponse
+}
+
+// Matches returns true if the subscription receives events of the given type
+func (s *Subscription) Matches(eventType string) bool {
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range s.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery states. A pending delivery is waiting for its first attempt or a retry, and a dead delivery has failed
+// every attempt and will not be retried.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// Delivery represents the delivery of an event to a subscription, and the outcome of its latest attempt
+type Delivery struct {
+	ID             string         `bson:"_id"                        json:"id"`
+	SubscriptionID string         `bson:"subscription_id"            json:"subscription_id"`
+	Payload        WebhookPayload `bson:"payload"                    json:"payload"`
+	Status         string         `bson:"status"                     json:"status"`
+	Attempts       int            `bson:"attempts"                   json:"attempts"`
+	NextAttemptAt  time.Time      `bson:"next_attempt_at"            json:"next_attempt_at"`
+	LastAttemptAt  *time.Time     `bson:"last_attempt_at,omitempty"  json:"last_attempt_at,omitempty"`
+	LastStatusCode int            `bson:"last_status_code,omitempty" json:"last_status_code,omitempty"`
+	LastError      string         `bson:"last_error,omitempty"       json:"last_error,omitempty"`
+	CreatedAt      time.Time      `bson:"created_at"                 json:"created_at"`
+}
+
+// DeliveriesResponse represents a paginated list of deliveries
+type DeliveriesResponse struct {
+	Items []Delivery `json:"items"`
+	pagination.PaginatedResponse
+}
+
+// WebhookPayload is the body sent to a subscription's URL for an event, with the collection as it was written
+type WebhookPayload struct {
+	Event      string     `bson:"event"      json:"event"`
+	Date       time.Time  `bson:"date"       json:"date"`
+	Collection Collection `bson:"collection" json:"collection"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/pagination"
+)
+
+// Version represents an immutable snapshot of a collection, as it was written by the request that recorded the event.
+// Versions of a collection are numbered from 1, in the order they were written.
+type Version struct {
+	ID           string     `bson:"_id"           json:"-"`
+	CollectionID string     `bson:"collection_id" json:"collection_id"`
+	Version      int        `bson:"version"       json:"version"`
+	EventID      string     `bson:"event_id"      json:"event_id"`
+	EventType    string     `bson:"event_type"    json:"event_type"`
+	Date         time.Time  `bson:"date"          json:"date"`
+	Collection   Collection `bson:"collection"    json:"collection"`
+}
+
+// VersionsResponse represents a paginated list of collection versions
+type VersionsResponse struct {
+	Items []Version `json:"items"`
+	pagination.PaginatedResponse
+}
+
+// VersionID returns the ID of a version of a collection. A collection can only have one snapshot for each version
+// number, so the ID is derived from both.
+func VersionID(collectionID string, version int) string {
+	return fmt.Sprintf("%s:%d", collectionID, version)
+}
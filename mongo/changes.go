@@ -0,0 +1,226 @@
+package mongo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/log.go/v2/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// changeStreamRetryDelay is how long to wait before reopening a change stream that has failed
+const changeStreamRetryDelay = 5 * time.Second
+
+// CollectionChanges reports every change to the collections collection, made by any instance, using a MongoDB
+// change stream. Change streams require a replica set or sharded cluster.
+type CollectionChanges struct {
+	mutex       sync.RWMutex
+	subscribers []func(id string)
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+// collectionChange is the part of a change event that identifies the changed collection
+type collectionChange struct {
+	DocumentKey struct {
+		ID string `bson:"_id"`
+	} `bson:"documentKey"`
+}
+
+// WatchCollections opens a change stream on the collections collection, and calls the subscribers with the ID of
+// each collection that is changed until Close is called
+func (m *Mongo) WatchCollections(ctx context.Context) (*CollectionChanges, error) {
+	stream, err := m.watchCollections(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	watchCtx, cancel := context.WithCancel(context.Background())
+	c := &CollectionChanges{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go watchChanges(watchCtx, "collection", stream, m.watchCollections, c.handle, c.done)
+
+	return c, nil
+}
+
+func (m *Mongo) watchCollections(ctx context.Context, resumeToken bson.Raw) (*mongo.ChangeStream, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"operationType": bson.M{"$in": bson.A{"insert", "update", "replace", "delete"}}}}},
+	}
+
+	streamOptions := options.ChangeStream()
+	if resumeToken != nil {
+		streamOptions.SetResumeAfter(resumeToken)
+	}
+
+	return m.client.Database(m.Database).Collection(m.CollectionsCollection).Watch(ctx, pipeline, streamOptions)
+}
+
+func (c *CollectionChanges) handle(stream *mongo.ChangeStream) error {
+	var change collectionChange
+	if err := stream.Decode(&change); err != nil {
+		return err
+	}
+	c.publish(change.DocumentKey.ID)
+	return nil
+}
+
+func (c *CollectionChanges) publish(id string) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	for _, invalidate := range c.subscribers {
+		invalidate(id)
+	}
+}
+
+// Notify does nothing, as the change stream already reports the changes made by this instance
+func (c *CollectionChanges) Notify(ctx context.Context, id string) error {
+	return nil
+}
+
+// Subscribe adds a function to be called with the ID of each changed collection
+func (c *CollectionChanges) Subscribe(invalidate func(id string)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.subscribers = append(c.subscribers, invalidate)
+}
+
+// Close stops watching for changes
+func (c *CollectionChanges) Close(ctx context.Context) error {
+	c.cancel()
+
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// EventChanges reports every event added to the events collection, by any instance, using a MongoDB change stream.
+// Change streams require a replica set or sharded cluster.
+type EventChanges struct {
+	mutex       sync.RWMutex
+	subscribers []func(event *models.Event)
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+// eventChange is the part of a change event that holds the added event
+type eventChange struct {
+	FullDocument models.Event `bson:"fullDocument"`
+}
+
+// WatchEvents opens a change stream on the events collection, and calls the subscribers with each event that is added
+// until Close is called
+func (m *Mongo) WatchEvents(ctx context.Context) (*EventChanges, error) {
+	stream, err := m.watchEvents(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	watchCtx, cancel := context.WithCancel(context.Background())
+	c := &EventChanges{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go watchChanges(watchCtx, "event", stream, m.watchEvents, c.handle, c.done)
+
+	return c, nil
+}
+
+func (m *Mongo) watchEvents(ctx context.Context, resumeToken bson.Raw) (*mongo.ChangeStream, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"operationType": "insert"}}},
+	}
+
+	streamOptions := options.ChangeStream()
+	if resumeToken != nil {
+		streamOptions.SetResumeAfter(resumeToken)
+	}
+
+	return m.client.Database(m.Database).Collection(m.EventsCollection).Watch(ctx, pipeline, streamOptions)
+}
+
+func (c *EventChanges) handle(stream *mongo.ChangeStream) error {
+	var change eventChange
+	if err := stream.Decode(&change); err != nil {
+		return err
+	}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	for _, publish := range c.subscribers {
+		publish(&change.FullDocument)
+	}
+	return nil
+}
+
+// HandleCollectionEvent does nothing, as the change stream already reports the events recorded by this instance
+func (c *EventChanges) HandleCollectionEvent(ctx context.Context, event *models.Event, collection *models.Collection) error {
+	return nil
+}
+
+// Subscribe adds a function to be called with each added event
+func (c *EventChanges) Subscribe(publish func(event *models.Event)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.subscribers = append(c.subscribers, publish)
+}
+
+// Close stops watching for events
+func (c *EventChanges) Close(ctx context.Context) error {
+	c.cancel()
+
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// watchChanges reads a change stream, passing each change to handle, until the context is cancelled, and then closes
+// done. If the stream fails, it is reopened from the last change that was read, so that no change is missed.
+func watchChanges(ctx context.Context, name string, stream *mongo.ChangeStream, reopen func(ctx context.Context, resumeToken bson.Raw) (*mongo.ChangeStream, error), handle func(stream *mongo.ChangeStream) error, done chan struct{}) {
+	defer close(done)
+	logData := log.Data{"change_stream": name}
+
+	for {
+		for stream.Next(ctx) {
+			if err := handle(stream); err != nil {
+				log.Error(ctx, "failed to decode change", err, logData)
+			}
+		}
+
+		resumeToken := stream.ResumeToken()
+		if err := stream.Err(); err != nil && ctx.Err() == nil {
+			log.Error(ctx, "change stream failed", err, logData)
+		}
+		stream.Close(context.Background())
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(changeStreamRetryDelay):
+			}
+
+			var err error
+			if stream, err = reopen(ctx, resumeToken); err == nil {
+				break
+			}
+			log.Error(ctx, "failed to reopen change stream", err, logData)
+		}
+	}
+}
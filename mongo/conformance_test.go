@@ -0,0 +1,64 @@
+package mongo_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/api/storetest"
+	"github.com/ONSdigital/dp-collection-api/mongo"
+)
+
+// TestConformance runs the datastore conformance suite against a local MongoDB, given by MONGODB_TEST_BIND_ADDR,
+// e.g. MONGODB_TEST_BIND_ADDR=localhost:27017 go test ./mongo/... The outbox suite uses transactions, so MongoDB must
+// be running as a replica set.
+func TestConformance(t *testing.T) {
+	bindAddr := os.Getenv("MONGODB_TEST_BIND_ADDR")
+	if len(bindAddr) == 0 {
+		t.Skip("MONGODB_TEST_BIND_ADDR is required to run the conformance suite against MongoDB")
+	}
+
+	storetest.TestCollectionStore(t, func(t *testing.T) storetest.Store {
+		return newStore(t, bindAddr)
+	})
+	storetest.TestSubscriptionStore(t, func(t *testing.T) storetest.SubscriptionStore {
+		return newStore(t, bindAddr)
+	})
+	storetest.TestVersionStore(t, func(t *testing.T) storetest.VersionStore {
+		return newStore(t, bindAddr)
+	})
+	storetest.TestOutboxStore(t, func(t *testing.T) storetest.OutboxStore {
+		return newStore(t, bindAddr)
+	})
+}
+
+// newStore returns a store using a new database, which is dropped when the test ends
+func newStore(t *testing.T, bindAddr string) *mongo.Mongo {
+	m := &mongo.Mongo{
+		URI:                     bindAddr,
+		Database:                fmt.Sprintf("conformance_%d", time.Now().UnixNano()),
+		CollectionsCollection:   "collections",
+		EventsCollection:        "events",
+		VersionsCollection:      "versions",
+		IdempotencyCollection:   "idempotency_keys",
+		SubscriptionsCollection: "subscriptions",
+		DeliveriesCollection:    "webhook_deliveries",
+		OutboxCollection:        "outbox",
+		MigrationsCollection:    "migrations",
+	}
+	if err := m.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		ctx := context.Background()
+		if err := m.Connection.DropDatabase(ctx); err != nil {
+			t.Error(err)
+		}
+		m.Close(ctx)
+	})
+
+	return m
+}
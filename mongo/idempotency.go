@@ -0,0 +1,90 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+	dpMongoDriver "github.com/ONSdigital/dp-mongodb/v3/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AddIdempotencyRecord adds a record for a new idempotency key. An expired record for the same key is replaced,
+// but if an unexpired record exists then collections.ErrIdempotencyKeyExists is returned.
+func (m *Mongo) AddIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) (err error) {
+	ctx, span := m.startSpan(ctx, "AddIdempotencyRecord", m.IdempotencyCollection)
+	defer func() { endSpan(span, err) }()
+
+	// the selector only matches an expired record, so for an unexpired record the upsert attempts
+	// an insert with the same _id, which is rejected by the unique _id index
+	selector := bson.M{
+		"_id":        record.Key,
+		"expires_at": bson.M{"$lte": time.Now()},
+	}
+
+	update := bson.M{
+		"$set": record,
+	}
+
+	_, err = m.Connection.C(m.IdempotencyCollection).Upsert(ctx, selector, update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return collections.ErrIdempotencyKeyExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetIdempotencyRecord retrieves the unexpired record for an idempotency key
+func (m *Mongo) GetIdempotencyRecord(ctx context.Context, key string) (result *models.IdempotencyRecord, err error) {
+	ctx, span := m.startSpan(ctx, "GetIdempotencyRecord", m.IdempotencyCollection)
+	defer func() { endSpan(span, err) }()
+
+	query := bson.M{
+		"_id":        key,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}
+	result = &models.IdempotencyRecord{}
+
+	err = m.Connection.
+		C(m.IdempotencyCollection).
+		FindOne(ctx, query, result)
+	if err != nil {
+		if dpMongoDriver.IsErrNoDocumentFound(err) {
+			return nil, collections.ErrIdempotencyRecordNotFound
+		}
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// UpdateIdempotencyRecord stores the response for an idempotency key
+func (m *Mongo) UpdateIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) (err error) {
+	ctx, span := m.startSpan(ctx, "UpdateIdempotencyRecord", m.IdempotencyCollection)
+	defer func() { endSpan(span, err) }()
+
+	update := bson.M{
+		"$set": record,
+	}
+
+	_, err = m.Connection.C(m.IdempotencyCollection).UpdateById(ctx, record.Key, update)
+	return err
+}
+
+// DeleteIdempotencyRecord removes the record for an idempotency key, so that the key can be used again
+func (m *Mongo) DeleteIdempotencyRecord(ctx context.Context, key string) (err error) {
+	ctx, span := m.startSpan(ctx, "DeleteIdempotencyRecord", m.IdempotencyCollection)
+	defer func() { endSpan(span, err) }()
+
+	_, err = m.Connection.C(m.IdempotencyCollection).DeleteById(ctx, key)
+	if err != nil && !dpMongoDriver.IsErrNoDocumentFound(err) {
+		return err
+	}
+
+	return nil
+}
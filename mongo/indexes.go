@@ -0,0 +1,175 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	"github.com/ONSdigital/log.go/v2/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Index describes an index that the service's queries rely on
+type Index struct {
+	Collection string
+	Keys       bson.D
+
+	// ExpireAfterSeconds makes this a TTL index, removing documents once the indexed date is this many seconds old
+	ExpireAfterSeconds *int32
+}
+
+// String returns the collection and name of the index, using the name that MongoDB gives an index by default
+func (i Index) String() string {
+	parts := make([]string, 0, len(i.Keys))
+	for _, key := range i.Keys {
+		parts = append(parts, fmt.Sprintf("%s_%v", key.Key, key.Value))
+	}
+	return i.Collection + "." + strings.Join(parts, "_")
+}
+
+// Indexes returns the specification of every index required by the service
+func (m *Mongo) Indexes() []Index {
+	expireOnDate := int32(0)
+
+	return []Index{
+		// collection name lookups, for the name uniqueness check and search
+		{Collection: m.CollectionsCollection, Keys: bson.D{{Key: "name", Value: 1}}},
+		// ordering by publish date, and the collection state counts
+		{Collection: m.CollectionsCollection, Keys: bson.D{{Key: "publish_date", Value: 1}}},
+		// the events for a collection, in date order
+		{Collection: m.EventsCollection, Keys: bson.D{{Key: "collection_id", Value: 1}, {Key: "date", Value: 1}}},
+		// the versions of a collection, oldest first
+		{Collection: m.VersionsCollection, Keys: bson.D{{Key: "collection_id", Value: 1}, {Key: "version", Value: 1}}},
+		// removes idempotency records once they have expired
+		{Collection: m.IdempotencyCollection, Keys: bson.D{{Key: "expires_at", Value: 1}}, ExpireAfterSeconds: &expireOnDate},
+		// the delivery log of a subscription, most recent first
+		{Collection: m.DeliveriesCollection, Keys: bson.D{{Key: "subscription_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		// claiming the deliveries that are due
+		{Collection: m.DeliveriesCollection, Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+		// the dead letters, most recent first
+		{Collection: m.DeliveriesCollection, Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}}},
+		// claiming the outbox messages that are not claimed, in the order they were added
+		{Collection: m.OutboxCollection, Keys: bson.D{{Key: "created_at", Value: 1}, {Key: "claimed_until", Value: 1}}},
+	}
+}
+
+// EnsureIndexes creates any of the required indexes that do not exist. If VerifyIndexesOnly is set, missing
+// indexes are logged rather than created, and are reported by the health check.
+func (m *Mongo) EnsureIndexes(ctx context.Context) error {
+	missing, err := m.MissingIndexes(ctx)
+	if err != nil {
+		return err
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if m.VerifyIndexesOnly {
+		log.Warn(ctx, "required mongo indexes are missing", log.Data{"missing_indexes": indexNames(missing)})
+		return nil
+	}
+
+	for _, index := range missing {
+		model := mongo.IndexModel{
+			Keys:    index.Keys,
+			Options: options.Index().SetBackground(true),
+		}
+		if index.ExpireAfterSeconds != nil {
+			model.Options.SetExpireAfterSeconds(*index.ExpireAfterSeconds)
+		}
+
+		if _, err := m.client.Database(m.Database).Collection(index.Collection).Indexes().CreateOne(ctx, model); err != nil {
+			return fmt.Errorf("failed to create index %s: %w", index, err)
+		}
+		log.Info(ctx, "created mongo index", log.Data{"index": index.String()})
+	}
+
+	return nil
+}
+
+// MissingIndexes returns the required indexes that do not exist, or that exist with different options
+func (m *Mongo) MissingIndexes(ctx context.Context) ([]Index, error) {
+	existing := map[string][]*mongo.IndexSpecification{}
+	var missing []Index
+
+	for _, index := range m.Indexes() {
+		specs, ok := existing[index.Collection]
+		if !ok {
+			var err error
+			specs, err = m.client.Database(m.Database).Collection(index.Collection).Indexes().ListSpecifications(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list indexes for %s: %w", index.Collection, err)
+			}
+			existing[index.Collection] = specs
+		}
+
+		found, err := hasIndex(specs, index)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			missing = append(missing, index)
+		}
+	}
+
+	return missing, nil
+}
+
+// checkIndexes downgrades an otherwise healthy check state to a warning if any required index is missing, as queries
+// still work without them but are slower
+func (m *Mongo) checkIndexes(ctx context.Context, state *healthcheck.CheckState) error {
+	missing, err := m.MissingIndexes(ctx)
+	if err != nil {
+		return state.Update(healthcheck.StatusCritical, err.Error(), 0)
+	}
+	if len(missing) > 0 {
+		msg := "required indexes are missing: " + strings.Join(indexNames(missing), ", ")
+		return state.Update(healthcheck.StatusWarning, msg, 0)
+	}
+	return nil
+}
+
+// hasIndex reports whether one of the existing index specifications has the same keys and expiry as the index.
+// Indexes are matched on their keys rather than their name, so that an equivalent index created by hand is accepted.
+func hasIndex(specs []*mongo.IndexSpecification, index Index) (bool, error) {
+	for _, spec := range specs {
+		var keys bson.D
+		if err := bson.Unmarshal(spec.KeysDocument, &keys); err != nil {
+			return false, err
+		}
+		if sameKeys(keys, index.Keys) && sameExpiry(spec.ExpireAfterSeconds, index.ExpireAfterSeconds) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func sameKeys(a, b bson.D) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Key != b[i].Key || fmt.Sprint(a[i].Value) != fmt.Sprint(b[i].Value) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameExpiry(a, b *int32) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func indexNames(indexes []Index) []string {
+	names := make([]string, 0, len(indexes))
+	for _, index := range indexes {
+		names = append(names, index.String())
+	}
+	return names
+}
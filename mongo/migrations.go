@@ -0,0 +1,285 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/gofrs/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	migrationLockID  = "lock"
+	migrationLockTTL = 10 * time.Minute
+
+	// migrationLockRenewal is how often the lock is renewed while migrations run, so that it only expires if the
+	// instance holding it stops
+	migrationLockRenewal = migrationLockTTL / 3
+)
+
+// ErrMigrationsLocked is returned when another instance of the service is already running migrations
+var ErrMigrationsLocked = errors.New("migrations are already being run by another instance")
+
+// ErrMigrationsLockLost is returned when the migrations lock expired while migrations were running, so another
+// instance may have started running them too
+var ErrMigrationsLockLost = errors.New("the migrations lock was lost while running migrations")
+
+// Migration is a versioned change to the existing documents, for example to backfill a new field
+type Migration struct {
+	Version     int
+	Description string
+
+	// Run applies the migration and returns the number of documents changed. For a dry run, it returns the number of
+	// documents that would be changed without changing them.
+	Run func(ctx context.Context, dryRun bool) (int, error)
+}
+
+// MigrationResult is the outcome of running a single migration
+type MigrationResult struct {
+	Version     int
+	Description string
+	Documents   int
+	DryRun      bool
+}
+
+// migrationRecord is stored in the migrations collection for each migration that has been applied
+type migrationRecord struct {
+	Version     int       `bson:"_id"`
+	Description string    `bson:"description"`
+	Documents   int       `bson:"documents"`
+	AppliedAt   time.Time `bson:"applied_at"`
+}
+
+// Migrations returns every migration in version order. A migration must not be changed or removed once it has been
+// released, as it is only ever applied once to each database.
+func (m *Mongo) Migrations() []Migration {
+	return []Migration{
+		{
+			Version:     1,
+			Description: "backfill last_updated on collections",
+			Run:         m.backfillLastUpdated,
+		},
+		{
+			Version:     2,
+			Description: "backfill created_at on collections from last_updated",
+			Run:         m.backfillCreatedAt,
+		},
+	}
+}
+
+// Migrate runs any migrations that have not yet been applied, in version order, recording each one as it is applied.
+// Migrations are run under a lock, so that only one instance of the service runs them at a time, which is renewed until
+// they finish. If the lock is lost, then the migration being run is cancelled. A dry run reports the pending migrations
+// and the number of documents each would change, without taking the lock or changing anything.
+func (m *Mongo) Migrate(ctx context.Context, dryRun bool) (results []MigrationResult, err error) {
+	if !dryRun {
+		owner, err := m.lockMigrations(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		lockCtx := ctx
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+
+		var lost error
+		renewing := m.renewMigrationsLock(ctx, owner, func(err error) {
+			lost = err
+			cancel()
+		})
+
+		defer func() {
+			cancel()
+			<-renewing
+			if lost != nil {
+				err = lost
+				return
+			}
+			m.unlockMigrations(lockCtx, owner)
+		}()
+	}
+
+	applied, err := m.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, migration := range m.Migrations() {
+		if applied[migration.Version] {
+			continue
+		}
+
+		logData := log.Data{"version": migration.Version, "description": migration.Description, "dry_run": dryRun}
+
+		documents, err := migration.Run(ctx, dryRun)
+		if err != nil {
+			return results, fmt.Errorf("migration %d failed: %w", migration.Version, err)
+		}
+		logData["documents"] = documents
+
+		if !dryRun {
+			record := migrationRecord{
+				Version:     migration.Version,
+				Description: migration.Description,
+				Documents:   documents,
+				AppliedAt:   time.Now(),
+			}
+			if _, err := m.Connection.C(m.MigrationsCollection).Insert(ctx, record); err != nil {
+				return results, fmt.Errorf("failed to record migration %d: %w", migration.Version, err)
+			}
+		}
+
+		if dryRun {
+			log.Info(ctx, "would run migration", logData)
+		} else {
+			log.Info(ctx, "ran migration", logData)
+		}
+		results = append(results, MigrationResult{
+			Version:     migration.Version,
+			Description: migration.Description,
+			Documents:   documents,
+			DryRun:      dryRun,
+		})
+	}
+
+	return results, nil
+}
+
+// appliedMigrations returns the versions of the migrations that have already been applied
+func (m *Mongo) appliedMigrations(ctx context.Context) (map[int]bool, error) {
+	var records []migrationRecord
+
+	query := bson.M{"applied_at": bson.M{"$exists": true}}
+	if err := m.Connection.C(m.MigrationsCollection).Find(query).IterAll(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]bool, len(records))
+	for _, record := range records {
+		applied[record.Version] = true
+	}
+	return applied, nil
+}
+
+// lockMigrations takes the migrations lock, and returns the owner ID that it is held with. The lock expires, so that
+// migrations are not blocked forever by an instance that stopped while holding it.
+func (m *Mongo) lockMigrations(ctx context.Context) (string, error) {
+	owner, err := uuid.NewV4()
+	if err != nil {
+		return "", err
+	}
+
+	// the selector only matches an expired lock, so while the lock is held the upsert attempts an insert with the
+	// same _id, which is rejected by the unique _id index
+	selector := bson.M{
+		"_id":        migrationLockID,
+		"expires_at": bson.M{"$lte": time.Now()},
+	}
+
+	update := bson.M{
+		"$set": bson.M{"owner": owner.String(), "expires_at": time.Now().Add(migrationLockTTL)},
+	}
+
+	if _, err := m.Connection.C(m.MigrationsCollection).Upsert(ctx, selector, update); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return "", ErrMigrationsLocked
+		}
+		return "", err
+	}
+	return owner.String(), nil
+}
+
+// renewMigrationsLock extends the migrations lock held by the owner every migrationLockRenewal, until ctx is done. If
+// the lock is no longer held by the owner, then lost is called and renewal stops. A failure to renew the lock is
+// retried, as the lock lasts for several renewals. The returned channel is closed once renewal has stopped.
+func (m *Mongo) renewMigrationsLock(ctx context.Context, owner string, lost func(error)) <-chan struct{} {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(migrationLockRenewal)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			selector := bson.M{"_id": migrationLockID, "owner": owner}
+			update := bson.M{"$set": bson.M{"expires_at": time.Now().Add(migrationLockTTL)}}
+
+			result, err := m.Connection.C(m.MigrationsCollection).Update(ctx, selector, update)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				log.Error(ctx, "failed to renew the migrations lock", err)
+				continue
+			}
+			if result.MatchedCount == 0 {
+				log.Error(ctx, "migrations lock lost, stopping migrations", ErrMigrationsLockLost)
+				lost(ErrMigrationsLockLost)
+				return
+			}
+		}
+	}()
+
+	return done
+}
+
+// unlockMigrations releases the migrations lock, if it is still held by the owner
+func (m *Mongo) unlockMigrations(ctx context.Context, owner string) {
+	selector := bson.M{"_id": migrationLockID, "owner": owner}
+	if _, err := m.Connection.C(m.MigrationsCollection).Delete(ctx, selector); err != nil {
+		log.Error(ctx, "failed to release the migrations lock", err)
+	}
+}
+
+// backfillLastUpdated sets last_updated to the current time on collections that do not have it
+func (m *Mongo) backfillLastUpdated(ctx context.Context, dryRun bool) (int, error) {
+	query := bson.M{"last_updated": bson.M{"$exists": false}}
+
+	if dryRun {
+		return m.Connection.C(m.CollectionsCollection).Find(query).Count(ctx)
+	}
+
+	update := bson.M{"$set": bson.M{"last_updated": time.Now()}}
+	result, err := m.client.Database(m.Database).Collection(m.CollectionsCollection).UpdateMany(ctx, query, update)
+	if err != nil {
+		return 0, err
+	}
+	return int(result.ModifiedCount), nil
+}
+
+// backfillCreatedAt sets created_at on collections that do not have it. Until created_at was added, last_updated was
+// only set when a collection was inserted, so it is the creation time.
+func (m *Mongo) backfillCreatedAt(ctx context.Context, dryRun bool) (int, error) {
+	query := bson.M{"created_at": bson.M{"$exists": false}}
+
+	if dryRun {
+		return m.Connection.C(m.CollectionsCollection).Find(query).Count(ctx)
+	}
+
+	var docs []struct {
+		ID          string    `bson:"_id"`
+		LastUpdated time.Time `bson:"last_updated"`
+	}
+	if err := m.Connection.C(m.CollectionsCollection).Find(query).Select(bson.M{"last_updated": 1}).IterAll(ctx, &docs); err != nil {
+		return 0, err
+	}
+
+	for _, doc := range docs {
+		update := bson.M{"$set": bson.M{"created_at": doc.LastUpdated}}
+		if _, err := m.Connection.C(m.CollectionsCollection).UpdateById(ctx, doc.ID, update); err != nil {
+			return 0, err
+		}
+	}
+	return len(docs), nil
+}
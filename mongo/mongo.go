@@ -3,6 +3,7 @@ package mongo
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/ONSdigital/dp-collection-api/collections"
@@ -13,6 +14,11 @@ import (
 	"github.com/ONSdigital/log.go/v2/log"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 )
 
 const (
@@ -22,15 +28,27 @@ const (
 
 // Mongo represents a simplistic MongoDB configuration.
 type Mongo struct {
-	healthClient          *dpMongoHealth.CheckMongoClient
-	Database              string
-	CollectionsCollection string
-	EventsCollection      string
-	Connection            *dpMongoDriver.MongoConnection
-	Username              string
-	Password              string
-	URI                   string
-	IsSSL                 bool
+	client                  *mongo.Client
+	healthClient            *dpMongoHealth.CheckMongoClient
+	Database                string
+	CollectionsCollection   string
+	EventsCollection        string
+	VersionsCollection      string
+	IdempotencyCollection   string
+	SubscriptionsCollection string
+	DeliveriesCollection    string
+	OutboxCollection        string
+	MigrationsCollection    string
+	Connection              *dpMongoDriver.MongoConnection
+	Username                string
+	Password                string
+	URI                     string
+	IsSSL                   bool
+	VerifyIndexesOnly       bool
+	WriteConcernMajority    bool
+	StrongReadConcern       bool
+	ListReadPreference      string
+	listReadPref            *readpref.ReadPref
 }
 
 func (m *Mongo) getConnectionConfig() *dpMongoDriver.MongoConnectionConfig {
@@ -42,24 +60,33 @@ func (m *Mongo) getConnectionConfig() *dpMongoDriver.MongoConnectionConfig {
 		ClusterEndpoint:               m.URI,
 		Database:                      m.Database,
 		Collection:                    m.CollectionsCollection,
-		IsWriteConcernMajorityEnabled: false,
-		IsStrongReadConcernEnabled:    false,
+		IsWriteConcernMajorityEnabled: m.WriteConcernMajority,
+		IsStrongReadConcernEnabled:    m.StrongReadConcern,
 		TLSConnectionConfig: dpMongoDriver.TLSConnectionConfig{
 			IsSSL: m.IsSSL,
 		},
 	}
 }
 
-// Init creates a new mongoConnection with a strong consistency and a write mode of "majority".
+// Init creates a new mongoConnection, with majority writes and strong consistency if configured.
 func (m *Mongo) Init() error {
 	if m.Connection != nil {
 		return errors.New("datastore connection already exists")
 	}
 
-	mongoConnection, err := dpMongoDriver.Open(m.getConnectionConfig())
+	listReadPref, err := parseReadPreference(m.ListReadPreference)
 	if err != nil {
 		return err
 	}
+	m.listReadPref = listReadPref
+
+	client, err := m.connect()
+	if err != nil {
+		return err
+	}
+	m.client = client
+
+	mongoConnection := dpMongoDriver.NewMongoConnection(client, m.Database, m.CollectionsCollection)
 	m.Connection = mongoConnection
 	databaseCollectionBuilder := make(map[dpMongoHealth.Database][]dpMongoHealth.Collection)
 	databaseCollectionBuilder[(dpMongoHealth.Database)(m.Database)] = []dpMongoHealth.Collection{(dpMongoHealth.Collection)(m.CollectionsCollection)}
@@ -70,6 +97,80 @@ func (m *Mongo) Init() error {
 	return nil
 }
 
+// connect opens a client with the same options as dpMongoDriver.Open. The client is kept, alongside the
+// connection wrapping it, for the operations that the wrapper does not provide, such as managing indexes.
+func (m *Mongo) connect() (*mongo.Client, error) {
+	cfg := m.getConnectionConfig()
+
+	tlsConfig, err := cfg.GetTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	uri, err := cfg.GetConnectionURI()
+	if err != nil {
+		return nil, err
+	}
+
+	clientOptions := options.Client().
+		ApplyURI(uri).
+		SetTLSConfig(tlsConfig).
+		SetReadPreference(readpref.SecondaryPreferred()).
+		SetRetryWrites(false)
+
+	if cfg.IsStrongReadConcernEnabled {
+		// majority reads from the primary, so that a read always reflects the latest acknowledged write
+		clientOptions.SetReadConcern(readconcern.Majority())
+		clientOptions.SetReadPreference(readpref.Primary())
+	}
+	if cfg.IsWriteConcernMajorityEnabled {
+		clientOptions.SetWriteConcern(writeconcern.New(writeconcern.WMajority()))
+	}
+
+	client, err := mongo.NewClient(clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeoutInSeconds*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		return nil, fmt.Errorf("failed to connect to cluster: %w", err)
+	}
+
+	// force a connection to verify the connection string
+	if err := client.Ping(ctx, nil); err != nil {
+		return nil, fmt.Errorf("failed to ping cluster: %w", err)
+	}
+
+	return client, nil
+}
+
+// parseReadPreference returns the read preference for a mode name such as "primary" or "secondaryPreferred".
+// If no mode is given then nil is returned, so that the client's read preference is used.
+func parseReadPreference(mode string) (*readpref.ReadPref, error) {
+	if len(mode) == 0 {
+		return nil, nil
+	}
+
+	readPrefMode, err := readpref.ModeFromString(mode)
+	if err != nil {
+		return nil, err
+	}
+	return readpref.New(readPrefMode)
+}
+
+// listCollection returns the named collection, reading with the read preference configured for list queries
+func (m *Mongo) listCollection(name string) *dpMongoDriver.Collection {
+	if m.listReadPref == nil {
+		return m.Connection.C(name)
+	}
+
+	collectionOptions := options.Collection().SetReadPreference(m.listReadPref)
+	return dpMongoDriver.NewCollection(m.client.Database(m.Database).Collection(name, collectionOptions))
+}
+
 // Close closes the mongo session and returns any error
 func (m *Mongo) Close(ctx context.Context) error {
 	if m.Connection == nil {
@@ -80,35 +181,47 @@ func (m *Mongo) Close(ctx context.Context) error {
 
 // Checker is called by the health check library to check the health state of this mongoDB instance
 func (m *Mongo) Checker(ctx context.Context, state *healthcheck.CheckState) error {
-	return m.healthClient.Checker(ctx, state)
+	if err := m.healthClient.Checker(ctx, state); err != nil {
+		return err
+	}
+	if state.Status() != healthcheck.StatusOK {
+		return nil
+	}
+	return m.checkIndexes(ctx, state)
 }
 
 // GetCollections retrieves all collection documents
-func (m *Mongo) GetCollections(ctx context.Context, queryParams collections.QueryParams) ([]models.Collection, int, error) {
+func (m *Mongo) GetCollections(ctx context.Context, queryParams collections.QueryParams) (values []models.Collection, totalCount int, err error) {
+	ctx, span := m.startSpan(ctx, "GetCollections", m.CollectionsCollection)
+	defer func() { endSpan(span, err) }()
 
 	var q *dpMongoDriver.Find
 	query := bson.D{}
 
 	if len(queryParams.NameSearch) > 0 {
-		query = bson.D{{"name", primitive.Regex{Pattern: queryParams.NameSearch, Options: "i"}}}
+		query = append(query, bson.E{Key: "name", Value: primitive.Regex{Pattern: queryParams.NameSearch, Options: "i"}})
+	}
+	if len(queryParams.AfterID) > 0 {
+		query = append(query, bson.E{Key: "_id", Value: bson.M{"$gt": queryParams.AfterID}})
 	}
 
-	q = m.Connection.
-		C(m.CollectionsCollection).
+	q = m.listCollection(m.CollectionsCollection).
 		Find(query)
 
 	switch queryParams.OrderBy {
+	case collections.OrderByID:
+		q.Sort(bson.D{{Key: "_id", Value: 1}})
 	case collections.OrderByPublishDate:
-		q.Sort(bson.D{{"publish_date", 1}})
+		q.Sort(bson.D{{Key: "publish_date", Value: 1}})
 	}
 
-	totalCount, err := q.Count(ctx)
+	totalCount, err = q.Count(ctx)
 	if err != nil {
 		log.Error(ctx, "error getting count of collections from mongo db", err)
 		return nil, totalCount, err
 	}
 
-	values := []models.Collection{}
+	values = []models.Collection{}
 
 	if queryParams.Limit > 0 {
 		err = q.Skip(queryParams.Offset).Limit(queryParams.Limit).IterAll(ctx, &values)
@@ -121,12 +234,14 @@ func (m *Mongo) GetCollections(ctx context.Context, queryParams collections.Quer
 }
 
 // GetCollectionByName retrieves a single collection by name
-func (m *Mongo) GetCollectionByName(ctx context.Context, name string) (*models.Collection, error) {
+func (m *Mongo) GetCollectionByName(ctx context.Context, name string) (result *models.Collection, err error) {
+	ctx, span := m.startSpan(ctx, "GetCollectionByName", m.CollectionsCollection)
+	defer func() { endSpan(span, err) }()
 
-	query := bson.D{{"name", name}}
-	result := &models.Collection{}
+	query := bson.D{{Key: "name", Value: name}}
+	result = &models.Collection{}
 
-	err := m.Connection.
+	err = m.Connection.
 		C(m.CollectionsCollection).
 		FindOne(ctx, query, result)
 	if err != nil {
@@ -140,12 +255,14 @@ func (m *Mongo) GetCollectionByName(ctx context.Context, name string) (*models.C
 }
 
 // GetCollectionByID retrieves a single collection by ID
-func (m *Mongo) GetCollectionByID(ctx context.Context, id string, eTagSelector string) (*models.Collection, error) {
+func (m *Mongo) GetCollectionByID(ctx context.Context, id string, eTagSelector string) (result *models.Collection, err error) {
+	ctx, span := m.startSpan(ctx, "GetCollectionByID", m.CollectionsCollection)
+	defer func() { endSpan(span, err) }()
 
-	query := bson.D{{"_id", id}}
-	result := &models.Collection{}
+	query := bson.D{{Key: "_id", Value: id}}
+	result = &models.Collection{}
 
-	err := m.Connection.
+	err = m.Connection.
 		C(m.CollectionsCollection).
 		FindOne(ctx, query, result)
 	if err != nil {
@@ -165,32 +282,42 @@ func (m *Mongo) GetCollectionByID(ctx context.Context, id string, eTagSelector s
 }
 
 // AddCollection adds or updates a collection
-func (m *Mongo) AddCollection(ctx context.Context, collection *models.Collection) error {
+func (m *Mongo) AddCollection(ctx context.Context, collection *models.Collection) (err error) {
+	ctx, span := m.startSpan(ctx, "AddCollection", m.CollectionsCollection)
+	defer func() { endSpan(span, err) }()
+
+	now := time.Now()
+	updated := *collection
+	updated.LastUpdated = now
+	updated.CreatedAt = time.Time{} // only set on insert
+
 	update := bson.M{
-		"$set": collection,
+		"$set": updated,
 		"$setOnInsert": bson.M{
-			"last_updated": time.Now(),
+			"created_at": now,
 		},
 	}
 
-	_, err := m.Connection.C(m.CollectionsCollection).UpsertById(ctx, collection.ID, update)
+	_, err = m.Connection.C(m.CollectionsCollection).UpsertById(ctx, collection.ID, update)
 
 	return err
 }
 
 // ReplaceCollection replaces an existing collection
-func (m *Mongo) ReplaceCollection(ctx context.Context, collection *models.Collection, eTagSelector string) error {
+func (m *Mongo) ReplaceCollection(ctx context.Context, collection *models.Collection, eTagSelector string) (err error) {
+	ctx, span := m.startSpan(ctx, "ReplaceCollection", m.CollectionsCollection)
+	defer func() { endSpan(span, err) }()
 
 	selector := bson.M{
 		"_id":   collection.ID,
 		"e_tag": eTagSelector,
 	}
 
+	updated := *collection
+	updated.LastUpdated = time.Now()
+
 	update := bson.M{
-		"$set": collection,
-		"$setOnInsert": bson.M{
-			"last_updated": time.Now(),
-		},
+		"$set": updated,
 	}
 
 	result, err := m.Connection.C(m.CollectionsCollection).Update(ctx, selector, update)
@@ -205,24 +332,25 @@ func (m *Mongo) ReplaceCollection(ctx context.Context, collection *models.Collec
 }
 
 // GetCollectionEvents retrieves all events for a collection
-func (m *Mongo) GetCollectionEvents(ctx context.Context, queryParams collections.EventsQueryParams) ([]models.Event, int, error) {
+func (m *Mongo) GetCollectionEvents(ctx context.Context, queryParams collections.EventsQueryParams) (values []models.Event, totalCount int, err error) {
+	ctx, span := m.startSpan(ctx, "GetCollectionEvents", m.EventsCollection)
+	defer func() { endSpan(span, err) }()
 
 	var q *dpMongoDriver.Find
 
-	query := bson.D{{"collection_id", queryParams.CollectionID}}
+	query := bson.D{{Key: "collection_id", Value: queryParams.CollectionID}}
 
-	q = m.Connection.
-		C(m.EventsCollection).
+	q = m.listCollection(m.EventsCollection).
 		Find(query).
-		Sort(bson.D{{"date", 1}})
+		Sort(bson.D{{Key: "date", Value: 1}})
 
-	totalCount, err := q.Count(ctx)
+	totalCount, err = q.Count(ctx)
 	if err != nil {
 		log.Error(ctx, "error getting count of collection events from mongo db", err)
 		return nil, totalCount, err
 	}
 
-	values := []models.Event{}
+	values = []models.Event{}
 
 	if queryParams.Limit > 0 {
 		err = q.Skip(queryParams.Offset).Limit(queryParams.Limit).IterAll(ctx, &values)
@@ -233,3 +361,12 @@ func (m *Mongo) GetCollectionEvents(ctx context.Context, queryParams collections
 
 	return values, totalCount, nil
 }
+
+// AddEvent adds an event to a collection
+func (m *Mongo) AddEvent(ctx context.Context, event *models.Event) (err error) {
+	ctx, span := m.startSpan(ctx, "AddEvent", m.EventsCollection)
+	defer func() { endSpan(span, err) }()
+
+	_, err = m.Connection.C(m.EventsCollection).Insert(ctx, event)
+	return err
+}
@@ -0,0 +1,88 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// WithTransaction calls fn in a transaction, which is committed if fn succeeds and aborted otherwise. The store's
+// methods called with the context passed to fn are part of the transaction. MongoDB only supports transactions on a
+// replica set or sharded cluster.
+func (m *Mongo) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	ctx, span := m.startSpan(ctx, "WithTransaction", m.OutboxCollection)
+	defer func() { endSpan(span, err) }()
+
+	session, err := m.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	// reads in a transaction must be from the primary
+	transactionOptions := options.Transaction().SetReadPreference(readpref.Primary())
+
+	_, err = session.WithTransaction(ctx, func(sessionCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessionCtx)
+	}, transactionOptions)
+	return err
+}
+
+// AddOutboxMessage adds a message to the outbox
+func (m *Mongo) AddOutboxMessage(ctx context.Context, message *models.OutboxMessage) (err error) {
+	ctx, span := m.startSpan(ctx, "AddOutboxMessage", m.OutboxCollection)
+	defer func() { endSpan(span, err) }()
+
+	_, err = m.Connection.C(m.OutboxCollection).Insert(ctx, message)
+	return err
+}
+
+// ClaimOutboxMessages returns up to limit messages that are not claimed at the given time, in the order they were
+// added, and claims them for the lease, so that they are not published by another relay while they are being
+// published. Each message is claimed with a single atomic update, so that two relays never claim the same message.
+func (m *Mongo) ClaimOutboxMessages(ctx context.Context, now time.Time, lease time.Duration, limit int) (values []models.OutboxMessage, err error) {
+	ctx, span := m.startSpan(ctx, "ClaimOutboxMessages", m.OutboxCollection)
+	defer func() { endSpan(span, err) }()
+
+	filter := bson.M{
+		"claimed_until": bson.M{"$lte": now},
+	}
+	update := bson.M{
+		"$set": bson.M{"claimed_until": now.Add(lease)},
+	}
+	findOptions := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	collection := m.client.Database(m.Database).Collection(m.OutboxCollection)
+
+	values = []models.OutboxMessage{}
+	for len(values) < limit {
+		var message models.OutboxMessage
+		err = collection.FindOneAndUpdate(ctx, filter, update, findOptions).Decode(&message)
+		if err == mongo.ErrNoDocuments {
+			return values, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, message)
+	}
+
+	return values, nil
+}
+
+// DeleteOutboxMessage removes a message from the outbox. Removing a message that does not exist is not an error, as
+// the message may have been published by another relay.
+func (m *Mongo) DeleteOutboxMessage(ctx context.Context, id string) (err error) {
+	ctx, span := m.startSpan(ctx, "DeleteOutboxMessage", m.OutboxCollection)
+	defer func() { endSpan(span, err) }()
+
+	_, err = m.Connection.C(m.OutboxCollection).DeleteById(ctx, id)
+	return err
+}
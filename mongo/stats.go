@@ -0,0 +1,35 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// GetCollectionStats counts the collections in each state at the given time, and those due to be published
+// within the upcoming window
+func (m *Mongo) GetCollectionStats(ctx context.Context, now time.Time, upcomingWindow time.Duration) (stats *models.CollectionStats, err error) {
+	ctx, span := m.startSpan(ctx, "GetCollectionStats", m.CollectionsCollection)
+	defer func() { endSpan(span, err) }()
+
+	stats = &models.CollectionStats{}
+
+	queries := map[*int]bson.M{
+		&stats.Unscheduled:       {"publish_date": nil},
+		&stats.Scheduled:         {"publish_date": bson.M{"$gt": now}},
+		&stats.Published:         {"publish_date": bson.M{"$lte": now}},
+		&stats.UpcomingPublishes: {"publish_date": bson.M{"$gt": now, "$lte": now.Add(upcomingWindow)}},
+	}
+
+	for count, query := range queries {
+		n, err := m.Connection.C(m.CollectionsCollection).Find(query).Count(ctx)
+		if err != nil {
+			return nil, err
+		}
+		*count = n
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,184 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+	dpMongoDriver "github.com/ONSdigital/dp-mongodb/v3/mongodb"
+	"github.com/ONSdigital/log.go/v2/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetSubscriptions retrieves a page of subscriptions, in the order they were created
+func (m *Mongo) GetSubscriptions(ctx context.Context, offset, limit int) (values []models.Subscription, totalCount int, err error) {
+	ctx, span := m.startSpan(ctx, "GetSubscriptions", m.SubscriptionsCollection)
+	defer func() { endSpan(span, err) }()
+
+	q := m.Connection.
+		C(m.SubscriptionsCollection).
+		Find(bson.D{}).
+		Sort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+
+	totalCount, err = q.Count(ctx)
+	if err != nil {
+		log.Error(ctx, "error getting count of subscriptions from mongo db", err)
+		return nil, totalCount, err
+	}
+
+	values = []models.Subscription{}
+
+	if limit > 0 {
+		err = q.Skip(offset).Limit(limit).IterAll(ctx, &values)
+		if err != nil {
+			return nil, totalCount, err
+		}
+	}
+
+	return values, totalCount, nil
+}
+
+// GetSubscriptionByID retrieves a single subscription by ID
+func (m *Mongo) GetSubscriptionByID(ctx context.Context, id string) (result *models.Subscription, err error) {
+	ctx, span := m.startSpan(ctx, "GetSubscriptionByID", m.SubscriptionsCollection)
+	defer func() { endSpan(span, err) }()
+
+	result = &models.Subscription{}
+
+	err = m.Connection.
+		C(m.SubscriptionsCollection).
+		FindOne(ctx, bson.D{{Key: "_id", Value: id}}, result)
+	if err != nil {
+		if dpMongoDriver.IsErrNoDocumentFound(err) {
+			return nil, collections.ErrSubscriptionNotFound
+		}
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// AddSubscription adds a subscription
+func (m *Mongo) AddSubscription(ctx context.Context, subscription *models.Subscription) (err error) {
+	ctx, span := m.startSpan(ctx, "AddSubscription", m.SubscriptionsCollection)
+	defer func() { endSpan(span, err) }()
+
+	_, err = m.Connection.C(m.SubscriptionsCollection).Insert(ctx, subscription)
+	return err
+}
+
+// DeleteSubscription removes a subscription and its deliveries. If there is no subscription with the ID, then
+// collections.ErrSubscriptionNotFound is returned.
+func (m *Mongo) DeleteSubscription(ctx context.Context, id string) (err error) {
+	ctx, span := m.startSpan(ctx, "DeleteSubscription", m.SubscriptionsCollection)
+	defer func() { endSpan(span, err) }()
+
+	result, err := m.Connection.C(m.SubscriptionsCollection).DeleteById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return collections.ErrSubscriptionNotFound
+	}
+
+	// a delivery claimed before the subscription was removed is not added again when its outcome is stored
+	_, err = m.Connection.C(m.DeliveriesCollection).DeleteMany(ctx, bson.D{{Key: "subscription_id", Value: id}})
+	return err
+}
+
+// GetDeliveries retrieves the deliveries matching the query parameters, most recent first
+func (m *Mongo) GetDeliveries(ctx context.Context, queryParams collections.DeliveriesQueryParams) (values []models.Delivery, totalCount int, err error) {
+	ctx, span := m.startSpan(ctx, "GetDeliveries", m.DeliveriesCollection)
+	defer func() { endSpan(span, err) }()
+
+	query := bson.D{}
+	if len(queryParams.SubscriptionID) > 0 {
+		query = append(query, bson.E{Key: "subscription_id", Value: queryParams.SubscriptionID})
+	}
+	if len(queryParams.Status) > 0 {
+		query = append(query, bson.E{Key: "status", Value: queryParams.Status})
+	}
+
+	q := m.listCollection(m.DeliveriesCollection).
+		Find(query).
+		Sort(bson.D{{Key: "created_at", Value: -1}})
+
+	totalCount, err = q.Count(ctx)
+	if err != nil {
+		log.Error(ctx, "error getting count of deliveries from mongo db", err)
+		return nil, totalCount, err
+	}
+
+	values = []models.Delivery{}
+
+	if queryParams.Limit > 0 {
+		err = q.Skip(queryParams.Offset).Limit(queryParams.Limit).IterAll(ctx, &values)
+		if err != nil {
+			return nil, totalCount, err
+		}
+	}
+
+	return values, totalCount, nil
+}
+
+// AddDelivery adds a delivery
+func (m *Mongo) AddDelivery(ctx context.Context, delivery *models.Delivery) (err error) {
+	ctx, span := m.startSpan(ctx, "AddDelivery", m.DeliveriesCollection)
+	defer func() { endSpan(span, err) }()
+
+	_, err = m.Connection.C(m.DeliveriesCollection).Insert(ctx, delivery)
+	return err
+}
+
+// UpdateDelivery stores the outcome of an attempt to make a delivery. A delivery that has been removed, along with
+// its subscription, is not added again.
+func (m *Mongo) UpdateDelivery(ctx context.Context, delivery *models.Delivery) (err error) {
+	ctx, span := m.startSpan(ctx, "UpdateDelivery", m.DeliveriesCollection)
+	defer func() { endSpan(span, err) }()
+
+	update := bson.M{
+		"$set": delivery,
+	}
+
+	_, err = m.Connection.C(m.DeliveriesCollection).UpdateById(ctx, delivery.ID, update)
+	return err
+}
+
+// ClaimDeliveries returns up to limit pending deliveries that are due at the given time, the longest overdue first,
+// and postpones their next attempt by the lease, so that they are not claimed again while they are being made. Each
+// delivery is claimed with a single atomic update, so that two instances never claim the same delivery.
+func (m *Mongo) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) (values []models.Delivery, err error) {
+	ctx, span := m.startSpan(ctx, "ClaimDeliveries", m.DeliveriesCollection)
+	defer func() { endSpan(span, err) }()
+
+	filter := bson.M{
+		"status":          models.DeliveryPending,
+		"next_attempt_at": bson.M{"$lte": now},
+	}
+	update := bson.M{
+		"$set": bson.M{"next_attempt_at": now.Add(lease)},
+	}
+	findOptions := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	collection := m.client.Database(m.Database).Collection(m.DeliveriesCollection)
+
+	values = []models.Delivery{}
+	for len(values) < limit {
+		var delivery models.Delivery
+		err = collection.FindOneAndUpdate(ctx, filter, update, findOptions).Decode(&delivery)
+		if err == mongo.ErrNoDocuments {
+			return values, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, delivery)
+	}
+
+	return values, nil
+}
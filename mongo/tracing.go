@@ -0,0 +1,46 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/ONSdigital/dp-collection-api/mongo")
+
+// expectedErrors are returned as part of normal operation, e.g. by the name uniqueness check, so do not mark a span as failed
+var expectedErrors = map[error]bool{
+	collections.ErrCollectionNotFound:        true,
+	collections.ErrCollectionConflict:        true,
+	collections.ErrIdempotencyKeyExists:      true,
+	collections.ErrIdempotencyRecordNotFound: true,
+	collections.ErrSubscriptionNotFound:      true,
+}
+
+// startSpan starts a child span of the request for a single Mongo method
+func (m *Mongo) startSpan(ctx context.Context, operation, collection string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "mongo."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemMongoDB,
+			semconv.DBNameKey.String(m.Database),
+			semconv.DBMongoDBCollectionKey.String(collection),
+			semconv.DBOperationKey.String(operation),
+		),
+	)
+}
+
+// endSpan records the outcome of a Mongo method on its span, and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		if !expectedErrors[err] {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
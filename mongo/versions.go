@@ -0,0 +1,78 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+	dpMongoDriver "github.com/ONSdigital/dp-mongodb/v3/mongodb"
+	"github.com/ONSdigital/log.go/v2/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetVersions retrieves a page of the versions of a collection, oldest first
+func (m *Mongo) GetVersions(ctx context.Context, queryParams collections.VersionsQueryParams) (values []models.Version, totalCount int, err error) {
+	ctx, span := m.startSpan(ctx, "GetVersions", m.VersionsCollection)
+	defer func() { endSpan(span, err) }()
+
+	q := m.listCollection(m.VersionsCollection).
+		Find(bson.D{{Key: "collection_id", Value: queryParams.CollectionID}}).
+		Sort(bson.D{{Key: "version", Value: 1}})
+
+	totalCount, err = q.Count(ctx)
+	if err != nil {
+		log.Error(ctx, "error getting count of collection versions from mongo db", err)
+		return nil, totalCount, err
+	}
+
+	values = []models.Version{}
+
+	if queryParams.Limit > 0 {
+		err = q.Skip(queryParams.Offset).Limit(queryParams.Limit).IterAll(ctx, &values)
+		if err != nil {
+			return nil, totalCount, err
+		}
+	}
+
+	return values, totalCount, nil
+}
+
+// GetVersion retrieves a single version of a collection
+func (m *Mongo) GetVersion(ctx context.Context, collectionID string, version int) (result *models.Version, err error) {
+	ctx, span := m.startSpan(ctx, "GetVersion", m.VersionsCollection)
+	defer func() { endSpan(span, err) }()
+
+	result = &models.Version{}
+
+	err = m.Connection.
+		C(m.VersionsCollection).
+		FindOne(ctx, bson.D{{Key: "_id", Value: models.VersionID(collectionID, version)}}, result)
+	if err != nil {
+		if dpMongoDriver.IsErrNoDocumentFound(err) {
+			return nil, collections.ErrVersionNotFound
+		}
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// AddVersion adds a snapshot of a version of a collection. If the version already has a snapshot, then
+// collections.ErrVersionAlreadyExists is returned.
+func (m *Mongo) AddVersion(ctx context.Context, version *models.Version) (err error) {
+	ctx, span := m.startSpan(ctx, "AddVersion", m.VersionsCollection)
+	defer func() { endSpan(span, err) }()
+
+	// the ID is derived from the collection ID and version number, so a second snapshot of the same version is
+	// rejected by the unique _id index
+	_, err = m.Connection.C(m.VersionsCollection).Insert(ctx, version)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return collections.ErrVersionAlreadyExists
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+// Package schema contains the Avro schemas of the Kafka messages that the service produces and consumes. Each schema
+// is kept in its own .avsc file, so that it can be shared with the services at the other end of the topic.
+package schema
+
+import (
+	_ "embed"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+//go:embed collection-event.avsc
+var collectionEvent string
+
+//go:embed publish-completed.avsc
+var publishCompleted string
+
+//go:embed publish-failed.avsc
+var publishFailed string
+
+// CollectionEvent is the codec of the message produced for each collection event
+var CollectionEvent = mustCodec(collectionEvent)
+
+// PublishCompleted is the codec of the message consumed when a collection has been published
+var PublishCompleted = mustCodec(publishCompleted)
+
+// PublishFailed is the codec of the message consumed when a collection could not be published
+var PublishFailed = mustCodec(publishFailed)
+
+// mustCodec returns the codec of a schema, and panics if the schema is not valid, as the schemas are part of the build
+func mustCodec(schema string) *goavro.Codec {
+	codec, err := goavro.NewCodec(schema)
+	if err != nil {
+		panic(err)
+	}
+	return codec
+}
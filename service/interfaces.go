@@ -3,6 +3,9 @@ package service
 import (
 	"context"
 	"github.com/ONSdigital/dp-collection-api/api"
+	"github.com/ONSdigital/dp-collection-api/kafka"
+	"github.com/ONSdigital/dp-collection-api/metrics"
+	"github.com/ONSdigital/dp-collection-api/webhooks"
 	"net/http"
 
 	"github.com/ONSdigital/dp-healthcheck/healthcheck"
@@ -24,6 +27,7 @@ type HealthChecker interface {
 	Start(ctx context.Context)
 	Stop()
 	AddCheck(name string, checker healthcheck.Checker) (err error)
+	GetStatus() string
 }
 
 // MongoDB defines the required methods from MongoDB
@@ -31,4 +35,15 @@ type MongoDB interface {
 	Close(context.Context) error
 	Checker(context.Context, *healthcheck.CheckState) error
 	api.CollectionStore
+	api.IdempotencyStore
+	api.SubscriptionStore
+	api.VersionStore
+	webhooks.Store
+	metrics.CollectionStatsStore
+}
+
+// Outbox defines the required methods from a datastore that stores the outbox of Kafka messages
+type Outbox interface {
+	api.Outbox
+	kafka.OutboxStore
 }
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ONSdigital/dp-collection-api/config"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// Migrate applies any pending database migrations without starting the service. With dryRun, it only logs the
+// pending migrations and the number of documents each would change.
+func Migrate(ctx context.Context, cfg *config.Config, dryRun bool) error {
+	mongodb, err := initMongo(cfg.MongoConfig)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := mongodb.Close(ctx); err != nil {
+			log.Error(ctx, "error closing mongo db client", err)
+		}
+	}()
+
+	results, err := mongodb.Migrate(ctx, dryRun)
+	if err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		log.Info(ctx, "no pending migrations")
+	}
+	return nil
+}
@@ -17,32 +17,38 @@ var _ service.HealthChecker = &HealthCheckerMock{}
 
 // HealthCheckerMock is a mock implementation of service.HealthChecker.
 //
-//     func TestSomethingThatUsesHealthChecker(t *testing.T) {
+//	func TestSomethingThatUsesHealthChecker(t *testing.T) {
 //
-//         // make and configure a mocked service.HealthChecker
-//         mockedHealthChecker := &HealthCheckerMock{
-//             AddCheckFunc: func(name string, checker healthcheck.Checker) error {
-// 	               panic("mock out the AddCheck method")
-//             },
-//             HandlerFunc: func(w http.ResponseWriter, req *http.Request)  {
-// 	               panic("mock out the Handler method")
-//             },
-//             StartFunc: func(ctx context.Context)  {
-// 	               panic("mock out the Start method")
-//             },
-//             StopFunc: func()  {
-// 	               panic("mock out the Stop method")
-//             },
-//         }
+//		// make and configure a mocked service.HealthChecker
+//		mockedHealthChecker := &HealthCheckerMock{
+//			AddCheckFunc: func(name string, checker healthcheck.Checker) error {
+//				panic("mock out the AddCheck method")
+//			},
+//			GetStatusFunc: func() string {
+//				panic("mock out the GetStatus method")
+//			},
+//			HandlerFunc: func(w http.ResponseWriter, req *http.Request)  {
+//				panic("mock out the Handler method")
+//			},
+//			StartFunc: func(ctx context.Context)  {
+//				panic("mock out the Start method")
+//			},
+//			StopFunc: func()  {
+//				panic("mock out the Stop method")
+//			},
+//		}
 //
-//         // use mockedHealthChecker in code that requires service.HealthChecker
-//         // and then make assertions.
+//		// use mockedHealthChecker in code that requires service.HealthChecker
+//		// and then make assertions.
 //
-//     }
+//	}
 type HealthCheckerMock struct {
 	// AddCheckFunc mocks the AddCheck method.
 	AddCheckFunc func(name string, checker healthcheck.Checker) error
 
+	// GetStatusFunc mocks the GetStatus method.
+	GetStatusFunc func() string
+
 	// HandlerFunc mocks the Handler method.
 	HandlerFunc func(w http.ResponseWriter, req *http.Request)
 
@@ -61,6 +67,9 @@ type HealthCheckerMock struct {
 			// Checker is the checker argument value.
 			Checker healthcheck.Checker
 		}
+		// GetStatus holds details about calls to the GetStatus method.
+		GetStatus []struct {
+		}
 		// Handler holds details about calls to the Handler method.
 		Handler []struct {
 			// W is the w argument value.
@@ -77,10 +86,11 @@ type HealthCheckerMock struct {
 		Stop []struct {
 		}
 	}
-	lockAddCheck sync.RWMutex
-	lockHandler  sync.RWMutex
-	lockStart    sync.RWMutex
-	lockStop     sync.RWMutex
+	lockAddCheck  sync.RWMutex
+	lockGetStatus sync.RWMutex
+	lockHandler   sync.RWMutex
+	lockStart     sync.RWMutex
+	lockStop      sync.RWMutex
 }
 
 // AddCheck calls AddCheckFunc.
@@ -103,7 +113,8 @@ func (mock *HealthCheckerMock) AddCheck(name string, checker healthcheck.Checker
 
 // AddCheckCalls gets all the calls that were made to AddCheck.
 // Check the length with:
-//     len(mockedHealthChecker.AddCheckCalls())
+//
+//	len(mockedHealthChecker.AddCheckCalls())
 func (mock *HealthCheckerMock) AddCheckCalls() []struct {
 	Name    string
 	Checker healthcheck.Checker
@@ -118,6 +129,33 @@ func (mock *HealthCheckerMock) AddCheckCalls() []struct {
 	return calls
 }
 
+// GetStatus calls GetStatusFunc.
+func (mock *HealthCheckerMock) GetStatus() string {
+	if mock.GetStatusFunc == nil {
+		panic("HealthCheckerMock.GetStatusFunc: method is nil but HealthChecker.GetStatus was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockGetStatus.Lock()
+	mock.calls.GetStatus = append(mock.calls.GetStatus, callInfo)
+	mock.lockGetStatus.Unlock()
+	return mock.GetStatusFunc()
+}
+
+// GetStatusCalls gets all the calls that were made to GetStatus.
+// Check the length with:
+//
+//	len(mockedHealthChecker.GetStatusCalls())
+func (mock *HealthCheckerMock) GetStatusCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockGetStatus.RLock()
+	calls = mock.calls.GetStatus
+	mock.lockGetStatus.RUnlock()
+	return calls
+}
+
 // Handler calls HandlerFunc.
 func (mock *HealthCheckerMock) Handler(w http.ResponseWriter, req *http.Request) {
 	if mock.HandlerFunc == nil {
@@ -138,7 +176,8 @@ func (mock *HealthCheckerMock) Handler(w http.ResponseWriter, req *http.Request)
 
 // HandlerCalls gets all the calls that were made to Handler.
 // Check the length with:
-//     len(mockedHealthChecker.HandlerCalls())
+//
+//	len(mockedHealthChecker.HandlerCalls())
 func (mock *HealthCheckerMock) HandlerCalls() []struct {
 	W   http.ResponseWriter
 	Req *http.Request
@@ -171,7 +210,8 @@ func (mock *HealthCheckerMock) Start(ctx context.Context) {
 
 // StartCalls gets all the calls that were made to Start.
 // Check the length with:
-//     len(mockedHealthChecker.StartCalls())
+//
+//	len(mockedHealthChecker.StartCalls())
 func (mock *HealthCheckerMock) StartCalls() []struct {
 	Ctx context.Context
 } {
@@ -199,7 +239,8 @@ func (mock *HealthCheckerMock) Stop() {
 
 // StopCalls gets all the calls that were made to Stop.
 // Check the length with:
-//     len(mockedHealthChecker.StopCalls())
+//
+//	len(mockedHealthChecker.StopCalls())
 func (mock *HealthCheckerMock) StopCalls() []struct {
 } {
 	var calls []struct {
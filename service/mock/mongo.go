@@ -10,6 +10,7 @@ import (
 	"github.com/ONSdigital/dp-collection-api/service"
 	"github.com/ONSdigital/dp-healthcheck/healthcheck"
 	"sync"
+	"time"
 )
 
 // Ensure, that MongoDBMock does implement service.MongoDB.
@@ -18,49 +19,124 @@ var _ service.MongoDB = &MongoDBMock{}
 
 // MongoDBMock is a mock implementation of service.MongoDB.
 //
-//     func TestSomethingThatUsesMongoDB(t *testing.T) {
+//	func TestSomethingThatUsesMongoDB(t *testing.T) {
 //
-//         // make and configure a mocked service.MongoDB
-//         mockedMongoDB := &MongoDBMock{
-//             AddCollectionFunc: func(ctx context.Context, collection *models.Collection) error {
-// 	               panic("mock out the AddCollection method")
-//             },
-//             CheckerFunc: func(in1 context.Context, in2 *healthcheck.CheckState) error {
-// 	               panic("mock out the Checker method")
-//             },
-//             CloseFunc: func(in1 context.Context) error {
-// 	               panic("mock out the Close method")
-//             },
-//             GetCollectionByIDFunc: func(ctx context.Context, id string, eTagSelector string) (*models.Collection, error) {
-// 	               panic("mock out the GetCollectionByID method")
-//             },
-//             GetCollectionByNameFunc: func(ctx context.Context, name string) (*models.Collection, error) {
-// 	               panic("mock out the GetCollectionByName method")
-//             },
-//             GetCollectionEventsFunc: func(ctx context.Context, queryParams collections.EventsQueryParams) ([]models.Event, int, error) {
-// 	               panic("mock out the GetCollectionEvents method")
-//             },
-//             GetCollectionsFunc: func(ctx context.Context, queryParams collections.QueryParams) ([]models.Collection, int, error) {
-// 	               panic("mock out the GetCollections method")
-//             },
-//             ReplaceCollectionFunc: func(ctx context.Context, collection *models.Collection, eTagSelector string) error {
-// 	               panic("mock out the ReplaceCollection method")
-//             },
-//         }
+//		// make and configure a mocked service.MongoDB
+//		mockedMongoDB := &MongoDBMock{
+//			AddCollectionFunc: func(ctx context.Context, collection *models.Collection) error {
+//				panic("mock out the AddCollection method")
+//			},
+//			AddDeliveryFunc: func(ctx context.Context, delivery *models.Delivery) error {
+//				panic("mock out the AddDelivery method")
+//			},
+//			AddEventFunc: func(ctx context.Context, event *models.Event) error {
+//				panic("mock out the AddEvent method")
+//			},
+//			AddIdempotencyRecordFunc: func(ctx context.Context, record *models.IdempotencyRecord) error {
+//				panic("mock out the AddIdempotencyRecord method")
+//			},
+//			AddSubscriptionFunc: func(ctx context.Context, subscription *models.Subscription) error {
+//				panic("mock out the AddSubscription method")
+//			},
+//			AddVersionFunc: func(ctx context.Context, version *models.Version) error {
+//				panic("mock out the AddVersion method")
+//			},
+//			CheckerFunc: func(contextMoqParam context.Context, checkState *healthcheck.CheckState) error {
+//				panic("mock out the Checker method")
+//			},
+//			ClaimDeliveriesFunc: func(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Delivery, error) {
+//				panic("mock out the ClaimDeliveries method")
+//			},
+//			CloseFunc: func(contextMoqParam context.Context) error {
+//				panic("mock out the Close method")
+//			},
+//			DeleteIdempotencyRecordFunc: func(ctx context.Context, key string) error {
+//				panic("mock out the DeleteIdempotencyRecord method")
+//			},
+//			DeleteSubscriptionFunc: func(ctx context.Context, id string) error {
+//				panic("mock out the DeleteSubscription method")
+//			},
+//			GetCollectionByIDFunc: func(ctx context.Context, id string, eTagSelector string) (*models.Collection, error) {
+//				panic("mock out the GetCollectionByID method")
+//			},
+//			GetCollectionByNameFunc: func(ctx context.Context, name string) (*models.Collection, error) {
+//				panic("mock out the GetCollectionByName method")
+//			},
+//			GetCollectionEventsFunc: func(ctx context.Context, queryParams collections.EventsQueryParams) ([]models.Event, int, error) {
+//				panic("mock out the GetCollectionEvents method")
+//			},
+//			GetCollectionStatsFunc: func(ctx context.Context, now time.Time, upcomingWindow time.Duration) (*models.CollectionStats, error) {
+//				panic("mock out the GetCollectionStats method")
+//			},
+//			GetCollectionsFunc: func(ctx context.Context, queryParams collections.QueryParams) ([]models.Collection, int, error) {
+//				panic("mock out the GetCollections method")
+//			},
+//			GetDeliveriesFunc: func(ctx context.Context, queryParams collections.DeliveriesQueryParams) ([]models.Delivery, int, error) {
+//				panic("mock out the GetDeliveries method")
+//			},
+//			GetIdempotencyRecordFunc: func(ctx context.Context, key string) (*models.IdempotencyRecord, error) {
+//				panic("mock out the GetIdempotencyRecord method")
+//			},
+//			GetSubscriptionByIDFunc: func(ctx context.Context, id string) (*models.Subscription, error) {
+//				panic("mock out the GetSubscriptionByID method")
+//			},
+//			GetSubscriptionsFunc: func(ctx context.Context, offset int, limit int) ([]models.Subscription, int, error) {
+//				panic("mock out the GetSubscriptions method")
+//			},
+//			GetVersionFunc: func(ctx context.Context, collectionID string, version int) (*models.Version, error) {
+//				panic("mock out the GetVersion method")
+//			},
+//			GetVersionsFunc: func(ctx context.Context, queryParams collections.VersionsQueryParams) ([]models.Version, int, error) {
+//				panic("mock out the GetVersions method")
+//			},
+//			ReplaceCollectionFunc: func(ctx context.Context, collection *models.Collection, eTagSelector string) error {
+//				panic("mock out the ReplaceCollection method")
+//			},
+//			UpdateDeliveryFunc: func(ctx context.Context, delivery *models.Delivery) error {
+//				panic("mock out the UpdateDelivery method")
+//			},
+//			UpdateIdempotencyRecordFunc: func(ctx context.Context, record *models.IdempotencyRecord) error {
+//				panic("mock out the UpdateIdempotencyRecord method")
+//			},
+//		}
 //
-//         // use mockedMongoDB in code that requires service.MongoDB
-//         // and then make assertions.
+//		// use mockedMongoDB in code that requires service.MongoDB
+//		// and then make assertions.
 //
-//     }
+//	}
 type MongoDBMock struct {
 	// AddCollectionFunc mocks the AddCollection method.
 	AddCollectionFunc func(ctx context.Context, collection *models.Collection) error
 
+	// AddDeliveryFunc mocks the AddDelivery method.
+	AddDeliveryFunc func(ctx context.Context, delivery *models.Delivery) error
+
+	// AddEventFunc mocks the AddEvent method.
+	AddEventFunc func(ctx context.Context, event *models.Event) error
+
+	// AddIdempotencyRecordFunc mocks the AddIdempotencyRecord method.
+	AddIdempotencyRecordFunc func(ctx context.Context, record *models.IdempotencyRecord) error
+
+	// AddSubscriptionFunc mocks the AddSubscription method.
+	AddSubscriptionFunc func(ctx context.Context, subscription *models.Subscription) error
+
+	// AddVersionFunc mocks the AddVersion method.
+	AddVersionFunc func(ctx context.Context, version *models.Version) error
+
 	// CheckerFunc mocks the Checker method.
-	CheckerFunc func(in1 context.Context, in2 *healthcheck.CheckState) error
+	CheckerFunc func(contextMoqParam context.Context, checkState *healthcheck.CheckState) error
+
+	// ClaimDeliveriesFunc mocks the ClaimDeliveries method.
+	ClaimDeliveriesFunc func(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Delivery, error)
 
 	// CloseFunc mocks the Close method.
-	CloseFunc func(in1 context.Context) error
+	CloseFunc func(contextMoqParam context.Context) error
+
+	// DeleteIdempotencyRecordFunc mocks the DeleteIdempotencyRecord method.
+	DeleteIdempotencyRecordFunc func(ctx context.Context, key string) error
+
+	// DeleteSubscriptionFunc mocks the DeleteSubscription method.
+	DeleteSubscriptionFunc func(ctx context.Context, id string) error
 
 	// GetCollectionByIDFunc mocks the GetCollectionByID method.
 	GetCollectionByIDFunc func(ctx context.Context, id string, eTagSelector string) (*models.Collection, error)
@@ -71,12 +147,39 @@ type MongoDBMock struct {
 	// GetCollectionEventsFunc mocks the GetCollectionEvents method.
 	GetCollectionEventsFunc func(ctx context.Context, queryParams collections.EventsQueryParams) ([]models.Event, int, error)
 
+	// GetCollectionStatsFunc mocks the GetCollectionStats method.
+	GetCollectionStatsFunc func(ctx context.Context, now time.Time, upcomingWindow time.Duration) (*models.CollectionStats, error)
+
 	// GetCollectionsFunc mocks the GetCollections method.
 	GetCollectionsFunc func(ctx context.Context, queryParams collections.QueryParams) ([]models.Collection, int, error)
 
+	// GetDeliveriesFunc mocks the GetDeliveries method.
+	GetDeliveriesFunc func(ctx context.Context, queryParams collections.DeliveriesQueryParams) ([]models.Delivery, int, error)
+
+	// GetIdempotencyRecordFunc mocks the GetIdempotencyRecord method.
+	GetIdempotencyRecordFunc func(ctx context.Context, key string) (*models.IdempotencyRecord, error)
+
+	// GetSubscriptionByIDFunc mocks the GetSubscriptionByID method.
+	GetSubscriptionByIDFunc func(ctx context.Context, id string) (*models.Subscription, error)
+
+	// GetSubscriptionsFunc mocks the GetSubscriptions method.
+	GetSubscriptionsFunc func(ctx context.Context, offset int, limit int) ([]models.Subscription, int, error)
+
+	// GetVersionFunc mocks the GetVersion method.
+	GetVersionFunc func(ctx context.Context, collectionID string, version int) (*models.Version, error)
+
+	// GetVersionsFunc mocks the GetVersions method.
+	GetVersionsFunc func(ctx context.Context, queryParams collections.VersionsQueryParams) ([]models.Version, int, error)
+
 	// ReplaceCollectionFunc mocks the ReplaceCollection method.
 	ReplaceCollectionFunc func(ctx context.Context, collection *models.Collection, eTagSelector string) error
 
+	// UpdateDeliveryFunc mocks the UpdateDelivery method.
+	UpdateDeliveryFunc func(ctx context.Context, delivery *models.Delivery) error
+
+	// UpdateIdempotencyRecordFunc mocks the UpdateIdempotencyRecord method.
+	UpdateIdempotencyRecordFunc func(ctx context.Context, record *models.IdempotencyRecord) error
+
 	// calls tracks calls to the methods.
 	calls struct {
 		// AddCollection holds details about calls to the AddCollection method.
@@ -86,17 +189,77 @@ type MongoDBMock struct {
 			// Collection is the collection argument value.
 			Collection *models.Collection
 		}
+		// AddDelivery holds details about calls to the AddDelivery method.
+		AddDelivery []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Delivery is the delivery argument value.
+			Delivery *models.Delivery
+		}
+		// AddEvent holds details about calls to the AddEvent method.
+		AddEvent []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Event is the event argument value.
+			Event *models.Event
+		}
+		// AddIdempotencyRecord holds details about calls to the AddIdempotencyRecord method.
+		AddIdempotencyRecord []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Record is the record argument value.
+			Record *models.IdempotencyRecord
+		}
+		// AddSubscription holds details about calls to the AddSubscription method.
+		AddSubscription []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Subscription is the subscription argument value.
+			Subscription *models.Subscription
+		}
+		// AddVersion holds details about calls to the AddVersion method.
+		AddVersion []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Version is the version argument value.
+			Version *models.Version
+		}
 		// Checker holds details about calls to the Checker method.
 		Checker []struct {
-			// In1 is the in1 argument value.
-			In1 context.Context
-			// In2 is the in2 argument value.
-			In2 *healthcheck.CheckState
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+			// CheckState is the checkState argument value.
+			CheckState *healthcheck.CheckState
+		}
+		// ClaimDeliveries holds details about calls to the ClaimDeliveries method.
+		ClaimDeliveries []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Now is the now argument value.
+			Now time.Time
+			// Lease is the lease argument value.
+			Lease time.Duration
+			// Limit is the limit argument value.
+			Limit int
 		}
 		// Close holds details about calls to the Close method.
 		Close []struct {
-			// In1 is the in1 argument value.
-			In1 context.Context
+			// ContextMoqParam is the contextMoqParam argument value.
+			ContextMoqParam context.Context
+		}
+		// DeleteIdempotencyRecord holds details about calls to the DeleteIdempotencyRecord method.
+		DeleteIdempotencyRecord []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Key is the key argument value.
+			Key string
+		}
+		// DeleteSubscription holds details about calls to the DeleteSubscription method.
+		DeleteSubscription []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
 		}
 		// GetCollectionByID holds details about calls to the GetCollectionByID method.
 		GetCollectionByID []struct {
@@ -121,6 +284,15 @@ type MongoDBMock struct {
 			// QueryParams is the queryParams argument value.
 			QueryParams collections.EventsQueryParams
 		}
+		// GetCollectionStats holds details about calls to the GetCollectionStats method.
+		GetCollectionStats []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Now is the now argument value.
+			Now time.Time
+			// UpcomingWindow is the upcomingWindow argument value.
+			UpcomingWindow time.Duration
+		}
 		// GetCollections holds details about calls to the GetCollections method.
 		GetCollections []struct {
 			// Ctx is the ctx argument value.
@@ -128,6 +300,52 @@ type MongoDBMock struct {
 			// QueryParams is the queryParams argument value.
 			QueryParams collections.QueryParams
 		}
+		// GetDeliveries holds details about calls to the GetDeliveries method.
+		GetDeliveries []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// QueryParams is the queryParams argument value.
+			QueryParams collections.DeliveriesQueryParams
+		}
+		// GetIdempotencyRecord holds details about calls to the GetIdempotencyRecord method.
+		GetIdempotencyRecord []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Key is the key argument value.
+			Key string
+		}
+		// GetSubscriptionByID holds details about calls to the GetSubscriptionByID method.
+		GetSubscriptionByID []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// GetSubscriptions holds details about calls to the GetSubscriptions method.
+		GetSubscriptions []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Offset is the offset argument value.
+			Offset int
+			// Limit is the limit argument value.
+			Limit int
+		}
+		// GetVersion holds details about calls to the GetVersion method.
+		GetVersion []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// CollectionID is the collectionID argument value.
+			CollectionID string
+			// Version is the version argument value.
+			Version int
+		}
+		// GetVersions holds details about calls to the GetVersions method.
+		GetVersions []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// QueryParams is the queryParams argument value.
+			QueryParams collections.VersionsQueryParams
+		}
 		// ReplaceCollection holds details about calls to the ReplaceCollection method.
 		ReplaceCollection []struct {
 			// Ctx is the ctx argument value.
@@ -137,15 +355,46 @@ type MongoDBMock struct {
 			// ETagSelector is the eTagSelector argument value.
 			ETagSelector string
 		}
+		// UpdateDelivery holds details about calls to the UpdateDelivery method.
+		UpdateDelivery []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Delivery is the delivery argument value.
+			Delivery *models.Delivery
+		}
+		// UpdateIdempotencyRecord holds details about calls to the UpdateIdempotencyRecord method.
+		UpdateIdempotencyRecord []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Record is the record argument value.
+			Record *models.IdempotencyRecord
+		}
 	}
-	lockAddCollection       sync.RWMutex
-	lockChecker             sync.RWMutex
-	lockClose               sync.RWMutex
-	lockGetCollectionByID   sync.RWMutex
-	lockGetCollectionByName sync.RWMutex
-	lockGetCollectionEvents sync.RWMutex
-	lockGetCollections      sync.RWMutex
-	lockReplaceCollection   sync.RWMutex
+	lockAddCollection           sync.RWMutex
+	lockAddDelivery             sync.RWMutex
+	lockAddEvent                sync.RWMutex
+	lockAddIdempotencyRecord    sync.RWMutex
+	lockAddSubscription         sync.RWMutex
+	lockAddVersion              sync.RWMutex
+	lockChecker                 sync.RWMutex
+	lockClaimDeliveries         sync.RWMutex
+	lockClose                   sync.RWMutex
+	lockDeleteIdempotencyRecord sync.RWMutex
+	lockDeleteSubscription      sync.RWMutex
+	lockGetCollectionByID       sync.RWMutex
+	lockGetCollectionByName     sync.RWMutex
+	lockGetCollectionEvents     sync.RWMutex
+	lockGetCollectionStats      sync.RWMutex
+	lockGetCollections          sync.RWMutex
+	lockGetDeliveries           sync.RWMutex
+	lockGetIdempotencyRecord    sync.RWMutex
+	lockGetSubscriptionByID     sync.RWMutex
+	lockGetSubscriptions        sync.RWMutex
+	lockGetVersion              sync.RWMutex
+	lockGetVersions             sync.RWMutex
+	lockReplaceCollection       sync.RWMutex
+	lockUpdateDelivery          sync.RWMutex
+	lockUpdateIdempotencyRecord sync.RWMutex
 }
 
 // AddCollection calls AddCollectionFunc.
@@ -168,7 +417,8 @@ func (mock *MongoDBMock) AddCollection(ctx context.Context, collection *models.C
 
 // AddCollectionCalls gets all the calls that were made to AddCollection.
 // Check the length with:
-//     len(mockedMongoDB.AddCollectionCalls())
+//
+//	len(mockedMongoDB.AddCollectionCalls())
 func (mock *MongoDBMock) AddCollectionCalls() []struct {
 	Ctx        context.Context
 	Collection *models.Collection
@@ -183,34 +433,215 @@ func (mock *MongoDBMock) AddCollectionCalls() []struct {
 	return calls
 }
 
+// AddDelivery calls AddDeliveryFunc.
+func (mock *MongoDBMock) AddDelivery(ctx context.Context, delivery *models.Delivery) error {
+	if mock.AddDeliveryFunc == nil {
+		panic("MongoDBMock.AddDeliveryFunc: method is nil but MongoDB.AddDelivery was just called")
+	}
+	callInfo := struct {
+		Ctx      context.Context
+		Delivery *models.Delivery
+	}{
+		Ctx:      ctx,
+		Delivery: delivery,
+	}
+	mock.lockAddDelivery.Lock()
+	mock.calls.AddDelivery = append(mock.calls.AddDelivery, callInfo)
+	mock.lockAddDelivery.Unlock()
+	return mock.AddDeliveryFunc(ctx, delivery)
+}
+
+// AddDeliveryCalls gets all the calls that were made to AddDelivery.
+// Check the length with:
+//
+//	len(mockedMongoDB.AddDeliveryCalls())
+func (mock *MongoDBMock) AddDeliveryCalls() []struct {
+	Ctx      context.Context
+	Delivery *models.Delivery
+} {
+	var calls []struct {
+		Ctx      context.Context
+		Delivery *models.Delivery
+	}
+	mock.lockAddDelivery.RLock()
+	calls = mock.calls.AddDelivery
+	mock.lockAddDelivery.RUnlock()
+	return calls
+}
+
+// AddEvent calls AddEventFunc.
+func (mock *MongoDBMock) AddEvent(ctx context.Context, event *models.Event) error {
+	if mock.AddEventFunc == nil {
+		panic("MongoDBMock.AddEventFunc: method is nil but MongoDB.AddEvent was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Event *models.Event
+	}{
+		Ctx:   ctx,
+		Event: event,
+	}
+	mock.lockAddEvent.Lock()
+	mock.calls.AddEvent = append(mock.calls.AddEvent, callInfo)
+	mock.lockAddEvent.Unlock()
+	return mock.AddEventFunc(ctx, event)
+}
+
+// AddEventCalls gets all the calls that were made to AddEvent.
+// Check the length with:
+//
+//	len(mockedMongoDB.AddEventCalls())
+func (mock *MongoDBMock) AddEventCalls() []struct {
+	Ctx   context.Context
+	Event *models.Event
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Event *models.Event
+	}
+	mock.lockAddEvent.RLock()
+	calls = mock.calls.AddEvent
+	mock.lockAddEvent.RUnlock()
+	return calls
+}
+
+// AddIdempotencyRecord calls AddIdempotencyRecordFunc.
+func (mock *MongoDBMock) AddIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	if mock.AddIdempotencyRecordFunc == nil {
+		panic("MongoDBMock.AddIdempotencyRecordFunc: method is nil but MongoDB.AddIdempotencyRecord was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Record *models.IdempotencyRecord
+	}{
+		Ctx:    ctx,
+		Record: record,
+	}
+	mock.lockAddIdempotencyRecord.Lock()
+	mock.calls.AddIdempotencyRecord = append(mock.calls.AddIdempotencyRecord, callInfo)
+	mock.lockAddIdempotencyRecord.Unlock()
+	return mock.AddIdempotencyRecordFunc(ctx, record)
+}
+
+// AddIdempotencyRecordCalls gets all the calls that were made to AddIdempotencyRecord.
+// Check the length with:
+//
+//	len(mockedMongoDB.AddIdempotencyRecordCalls())
+func (mock *MongoDBMock) AddIdempotencyRecordCalls() []struct {
+	Ctx    context.Context
+	Record *models.IdempotencyRecord
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Record *models.IdempotencyRecord
+	}
+	mock.lockAddIdempotencyRecord.RLock()
+	calls = mock.calls.AddIdempotencyRecord
+	mock.lockAddIdempotencyRecord.RUnlock()
+	return calls
+}
+
+// AddSubscription calls AddSubscriptionFunc.
+func (mock *MongoDBMock) AddSubscription(ctx context.Context, subscription *models.Subscription) error {
+	if mock.AddSubscriptionFunc == nil {
+		panic("MongoDBMock.AddSubscriptionFunc: method is nil but MongoDB.AddSubscription was just called")
+	}
+	callInfo := struct {
+		Ctx          context.Context
+		Subscription *models.Subscription
+	}{
+		Ctx:          ctx,
+		Subscription: subscription,
+	}
+	mock.lockAddSubscription.Lock()
+	mock.calls.AddSubscription = append(mock.calls.AddSubscription, callInfo)
+	mock.lockAddSubscription.Unlock()
+	return mock.AddSubscriptionFunc(ctx, subscription)
+}
+
+// AddSubscriptionCalls gets all the calls that were made to AddSubscription.
+// Check the length with:
+//
+//	len(mockedMongoDB.AddSubscriptionCalls())
+func (mock *MongoDBMock) AddSubscriptionCalls() []struct {
+	Ctx          context.Context
+	Subscription *models.Subscription
+} {
+	var calls []struct {
+		Ctx          context.Context
+		Subscription *models.Subscription
+	}
+	mock.lockAddSubscription.RLock()
+	calls = mock.calls.AddSubscription
+	mock.lockAddSubscription.RUnlock()
+	return calls
+}
+
+// AddVersion calls AddVersionFunc.
+func (mock *MongoDBMock) AddVersion(ctx context.Context, version *models.Version) error {
+	if mock.AddVersionFunc == nil {
+		panic("MongoDBMock.AddVersionFunc: method is nil but MongoDB.AddVersion was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Version *models.Version
+	}{
+		Ctx:     ctx,
+		Version: version,
+	}
+	mock.lockAddVersion.Lock()
+	mock.calls.AddVersion = append(mock.calls.AddVersion, callInfo)
+	mock.lockAddVersion.Unlock()
+	return mock.AddVersionFunc(ctx, version)
+}
+
+// AddVersionCalls gets all the calls that were made to AddVersion.
+// Check the length with:
+//
+//	len(mockedMongoDB.AddVersionCalls())
+func (mock *MongoDBMock) AddVersionCalls() []struct {
+	Ctx     context.Context
+	Version *models.Version
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Version *models.Version
+	}
+	mock.lockAddVersion.RLock()
+	calls = mock.calls.AddVersion
+	mock.lockAddVersion.RUnlock()
+	return calls
+}
+
 // Checker calls CheckerFunc.
-func (mock *MongoDBMock) Checker(in1 context.Context, in2 *healthcheck.CheckState) error {
+func (mock *MongoDBMock) Checker(contextMoqParam context.Context, checkState *healthcheck.CheckState) error {
 	if mock.CheckerFunc == nil {
 		panic("MongoDBMock.CheckerFunc: method is nil but MongoDB.Checker was just called")
 	}
 	callInfo := struct {
-		In1 context.Context
-		In2 *healthcheck.CheckState
+		ContextMoqParam context.Context
+		CheckState      *healthcheck.CheckState
 	}{
-		In1: in1,
-		In2: in2,
+		ContextMoqParam: contextMoqParam,
+		CheckState:      checkState,
 	}
 	mock.lockChecker.Lock()
 	mock.calls.Checker = append(mock.calls.Checker, callInfo)
 	mock.lockChecker.Unlock()
-	return mock.CheckerFunc(in1, in2)
+	return mock.CheckerFunc(contextMoqParam, checkState)
 }
 
 // CheckerCalls gets all the calls that were made to Checker.
 // Check the length with:
-//     len(mockedMongoDB.CheckerCalls())
+//
+//	len(mockedMongoDB.CheckerCalls())
 func (mock *MongoDBMock) CheckerCalls() []struct {
-	In1 context.Context
-	In2 *healthcheck.CheckState
+	ContextMoqParam context.Context
+	CheckState      *healthcheck.CheckState
 } {
 	var calls []struct {
-		In1 context.Context
-		In2 *healthcheck.CheckState
+		ContextMoqParam context.Context
+		CheckState      *healthcheck.CheckState
 	}
 	mock.lockChecker.RLock()
 	calls = mock.calls.Checker
@@ -218,30 +649,75 @@ func (mock *MongoDBMock) CheckerCalls() []struct {
 	return calls
 }
 
+// ClaimDeliveries calls ClaimDeliveriesFunc.
+func (mock *MongoDBMock) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Delivery, error) {
+	if mock.ClaimDeliveriesFunc == nil {
+		panic("MongoDBMock.ClaimDeliveriesFunc: method is nil but MongoDB.ClaimDeliveries was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Now   time.Time
+		Lease time.Duration
+		Limit int
+	}{
+		Ctx:   ctx,
+		Now:   now,
+		Lease: lease,
+		Limit: limit,
+	}
+	mock.lockClaimDeliveries.Lock()
+	mock.calls.ClaimDeliveries = append(mock.calls.ClaimDeliveries, callInfo)
+	mock.lockClaimDeliveries.Unlock()
+	return mock.ClaimDeliveriesFunc(ctx, now, lease, limit)
+}
+
+// ClaimDeliveriesCalls gets all the calls that were made to ClaimDeliveries.
+// Check the length with:
+//
+//	len(mockedMongoDB.ClaimDeliveriesCalls())
+func (mock *MongoDBMock) ClaimDeliveriesCalls() []struct {
+	Ctx   context.Context
+	Now   time.Time
+	Lease time.Duration
+	Limit int
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Now   time.Time
+		Lease time.Duration
+		Limit int
+	}
+	mock.lockClaimDeliveries.RLock()
+	calls = mock.calls.ClaimDeliveries
+	mock.lockClaimDeliveries.RUnlock()
+	return calls
+}
+
 // Close calls CloseFunc.
-func (mock *MongoDBMock) Close(in1 context.Context) error {
+func (mock *MongoDBMock) Close(contextMoqParam context.Context) error {
 	if mock.CloseFunc == nil {
 		panic("MongoDBMock.CloseFunc: method is nil but MongoDB.Close was just called")
 	}
 	callInfo := struct {
-		In1 context.Context
+		ContextMoqParam context.Context
 	}{
-		In1: in1,
+		ContextMoqParam: contextMoqParam,
 	}
 	mock.lockClose.Lock()
 	mock.calls.Close = append(mock.calls.Close, callInfo)
 	mock.lockClose.Unlock()
-	return mock.CloseFunc(in1)
+	return mock.CloseFunc(contextMoqParam)
 }
 
 // CloseCalls gets all the calls that were made to Close.
 // Check the length with:
-//     len(mockedMongoDB.CloseCalls())
+//
+//	len(mockedMongoDB.CloseCalls())
 func (mock *MongoDBMock) CloseCalls() []struct {
-	In1 context.Context
+	ContextMoqParam context.Context
 } {
 	var calls []struct {
-		In1 context.Context
+		ContextMoqParam context.Context
 	}
 	mock.lockClose.RLock()
 	calls = mock.calls.Close
@@ -249,6 +725,78 @@ func (mock *MongoDBMock) CloseCalls() []struct {
 	return calls
 }
 
+// DeleteIdempotencyRecord calls DeleteIdempotencyRecordFunc.
+func (mock *MongoDBMock) DeleteIdempotencyRecord(ctx context.Context, key string) error {
+	if mock.DeleteIdempotencyRecordFunc == nil {
+		panic("MongoDBMock.DeleteIdempotencyRecordFunc: method is nil but MongoDB.DeleteIdempotencyRecord was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Key string
+	}{
+		Ctx: ctx,
+		Key: key,
+	}
+	mock.lockDeleteIdempotencyRecord.Lock()
+	mock.calls.DeleteIdempotencyRecord = append(mock.calls.DeleteIdempotencyRecord, callInfo)
+	mock.lockDeleteIdempotencyRecord.Unlock()
+	return mock.DeleteIdempotencyRecordFunc(ctx, key)
+}
+
+// DeleteIdempotencyRecordCalls gets all the calls that were made to DeleteIdempotencyRecord.
+// Check the length with:
+//
+//	len(mockedMongoDB.DeleteIdempotencyRecordCalls())
+func (mock *MongoDBMock) DeleteIdempotencyRecordCalls() []struct {
+	Ctx context.Context
+	Key string
+} {
+	var calls []struct {
+		Ctx context.Context
+		Key string
+	}
+	mock.lockDeleteIdempotencyRecord.RLock()
+	calls = mock.calls.DeleteIdempotencyRecord
+	mock.lockDeleteIdempotencyRecord.RUnlock()
+	return calls
+}
+
+// DeleteSubscription calls DeleteSubscriptionFunc.
+func (mock *MongoDBMock) DeleteSubscription(ctx context.Context, id string) error {
+	if mock.DeleteSubscriptionFunc == nil {
+		panic("MongoDBMock.DeleteSubscriptionFunc: method is nil but MongoDB.DeleteSubscription was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockDeleteSubscription.Lock()
+	mock.calls.DeleteSubscription = append(mock.calls.DeleteSubscription, callInfo)
+	mock.lockDeleteSubscription.Unlock()
+	return mock.DeleteSubscriptionFunc(ctx, id)
+}
+
+// DeleteSubscriptionCalls gets all the calls that were made to DeleteSubscription.
+// Check the length with:
+//
+//	len(mockedMongoDB.DeleteSubscriptionCalls())
+func (mock *MongoDBMock) DeleteSubscriptionCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockDeleteSubscription.RLock()
+	calls = mock.calls.DeleteSubscription
+	mock.lockDeleteSubscription.RUnlock()
+	return calls
+}
+
 // GetCollectionByID calls GetCollectionByIDFunc.
 func (mock *MongoDBMock) GetCollectionByID(ctx context.Context, id string, eTagSelector string) (*models.Collection, error) {
 	if mock.GetCollectionByIDFunc == nil {
@@ -271,7 +819,8 @@ func (mock *MongoDBMock) GetCollectionByID(ctx context.Context, id string, eTagS
 
 // GetCollectionByIDCalls gets all the calls that were made to GetCollectionByID.
 // Check the length with:
-//     len(mockedMongoDB.GetCollectionByIDCalls())
+//
+//	len(mockedMongoDB.GetCollectionByIDCalls())
 func (mock *MongoDBMock) GetCollectionByIDCalls() []struct {
 	Ctx          context.Context
 	ID           string
@@ -308,7 +857,8 @@ func (mock *MongoDBMock) GetCollectionByName(ctx context.Context, name string) (
 
 // GetCollectionByNameCalls gets all the calls that were made to GetCollectionByName.
 // Check the length with:
-//     len(mockedMongoDB.GetCollectionByNameCalls())
+//
+//	len(mockedMongoDB.GetCollectionByNameCalls())
 func (mock *MongoDBMock) GetCollectionByNameCalls() []struct {
 	Ctx  context.Context
 	Name string
@@ -343,7 +893,8 @@ func (mock *MongoDBMock) GetCollectionEvents(ctx context.Context, queryParams co
 
 // GetCollectionEventsCalls gets all the calls that were made to GetCollectionEvents.
 // Check the length with:
-//     len(mockedMongoDB.GetCollectionEventsCalls())
+//
+//	len(mockedMongoDB.GetCollectionEventsCalls())
 func (mock *MongoDBMock) GetCollectionEventsCalls() []struct {
 	Ctx         context.Context
 	QueryParams collections.EventsQueryParams
@@ -358,6 +909,46 @@ func (mock *MongoDBMock) GetCollectionEventsCalls() []struct {
 	return calls
 }
 
+// GetCollectionStats calls GetCollectionStatsFunc.
+func (mock *MongoDBMock) GetCollectionStats(ctx context.Context, now time.Time, upcomingWindow time.Duration) (*models.CollectionStats, error) {
+	if mock.GetCollectionStatsFunc == nil {
+		panic("MongoDBMock.GetCollectionStatsFunc: method is nil but MongoDB.GetCollectionStats was just called")
+	}
+	callInfo := struct {
+		Ctx            context.Context
+		Now            time.Time
+		UpcomingWindow time.Duration
+	}{
+		Ctx:            ctx,
+		Now:            now,
+		UpcomingWindow: upcomingWindow,
+	}
+	mock.lockGetCollectionStats.Lock()
+	mock.calls.GetCollectionStats = append(mock.calls.GetCollectionStats, callInfo)
+	mock.lockGetCollectionStats.Unlock()
+	return mock.GetCollectionStatsFunc(ctx, now, upcomingWindow)
+}
+
+// GetCollectionStatsCalls gets all the calls that were made to GetCollectionStats.
+// Check the length with:
+//
+//	len(mockedMongoDB.GetCollectionStatsCalls())
+func (mock *MongoDBMock) GetCollectionStatsCalls() []struct {
+	Ctx            context.Context
+	Now            time.Time
+	UpcomingWindow time.Duration
+} {
+	var calls []struct {
+		Ctx            context.Context
+		Now            time.Time
+		UpcomingWindow time.Duration
+	}
+	mock.lockGetCollectionStats.RLock()
+	calls = mock.calls.GetCollectionStats
+	mock.lockGetCollectionStats.RUnlock()
+	return calls
+}
+
 // GetCollections calls GetCollectionsFunc.
 func (mock *MongoDBMock) GetCollections(ctx context.Context, queryParams collections.QueryParams) ([]models.Collection, int, error) {
 	if mock.GetCollectionsFunc == nil {
@@ -378,7 +969,8 @@ func (mock *MongoDBMock) GetCollections(ctx context.Context, queryParams collect
 
 // GetCollectionsCalls gets all the calls that were made to GetCollections.
 // Check the length with:
-//     len(mockedMongoDB.GetCollectionsCalls())
+//
+//	len(mockedMongoDB.GetCollectionsCalls())
 func (mock *MongoDBMock) GetCollectionsCalls() []struct {
 	Ctx         context.Context
 	QueryParams collections.QueryParams
@@ -393,6 +985,230 @@ func (mock *MongoDBMock) GetCollectionsCalls() []struct {
 	return calls
 }
 
+// GetDeliveries calls GetDeliveriesFunc.
+func (mock *MongoDBMock) GetDeliveries(ctx context.Context, queryParams collections.DeliveriesQueryParams) ([]models.Delivery, int, error) {
+	if mock.GetDeliveriesFunc == nil {
+		panic("MongoDBMock.GetDeliveriesFunc: method is nil but MongoDB.GetDeliveries was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		QueryParams collections.DeliveriesQueryParams
+	}{
+		Ctx:         ctx,
+		QueryParams: queryParams,
+	}
+	mock.lockGetDeliveries.Lock()
+	mock.calls.GetDeliveries = append(mock.calls.GetDeliveries, callInfo)
+	mock.lockGetDeliveries.Unlock()
+	return mock.GetDeliveriesFunc(ctx, queryParams)
+}
+
+// GetDeliveriesCalls gets all the calls that were made to GetDeliveries.
+// Check the length with:
+//
+//	len(mockedMongoDB.GetDeliveriesCalls())
+func (mock *MongoDBMock) GetDeliveriesCalls() []struct {
+	Ctx         context.Context
+	QueryParams collections.DeliveriesQueryParams
+} {
+	var calls []struct {
+		Ctx         context.Context
+		QueryParams collections.DeliveriesQueryParams
+	}
+	mock.lockGetDeliveries.RLock()
+	calls = mock.calls.GetDeliveries
+	mock.lockGetDeliveries.RUnlock()
+	return calls
+}
+
+// GetIdempotencyRecord calls GetIdempotencyRecordFunc.
+func (mock *MongoDBMock) GetIdempotencyRecord(ctx context.Context, key string) (*models.IdempotencyRecord, error) {
+	if mock.GetIdempotencyRecordFunc == nil {
+		panic("MongoDBMock.GetIdempotencyRecordFunc: method is nil but MongoDB.GetIdempotencyRecord was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Key string
+	}{
+		Ctx: ctx,
+		Key: key,
+	}
+	mock.lockGetIdempotencyRecord.Lock()
+	mock.calls.GetIdempotencyRecord = append(mock.calls.GetIdempotencyRecord, callInfo)
+	mock.lockGetIdempotencyRecord.Unlock()
+	return mock.GetIdempotencyRecordFunc(ctx, key)
+}
+
+// GetIdempotencyRecordCalls gets all the calls that were made to GetIdempotencyRecord.
+// Check the length with:
+//
+//	len(mockedMongoDB.GetIdempotencyRecordCalls())
+func (mock *MongoDBMock) GetIdempotencyRecordCalls() []struct {
+	Ctx context.Context
+	Key string
+} {
+	var calls []struct {
+		Ctx context.Context
+		Key string
+	}
+	mock.lockGetIdempotencyRecord.RLock()
+	calls = mock.calls.GetIdempotencyRecord
+	mock.lockGetIdempotencyRecord.RUnlock()
+	return calls
+}
+
+// GetSubscriptionByID calls GetSubscriptionByIDFunc.
+func (mock *MongoDBMock) GetSubscriptionByID(ctx context.Context, id string) (*models.Subscription, error) {
+	if mock.GetSubscriptionByIDFunc == nil {
+		panic("MongoDBMock.GetSubscriptionByIDFunc: method is nil but MongoDB.GetSubscriptionByID was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockGetSubscriptionByID.Lock()
+	mock.calls.GetSubscriptionByID = append(mock.calls.GetSubscriptionByID, callInfo)
+	mock.lockGetSubscriptionByID.Unlock()
+	return mock.GetSubscriptionByIDFunc(ctx, id)
+}
+
+// GetSubscriptionByIDCalls gets all the calls that were made to GetSubscriptionByID.
+// Check the length with:
+//
+//	len(mockedMongoDB.GetSubscriptionByIDCalls())
+func (mock *MongoDBMock) GetSubscriptionByIDCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockGetSubscriptionByID.RLock()
+	calls = mock.calls.GetSubscriptionByID
+	mock.lockGetSubscriptionByID.RUnlock()
+	return calls
+}
+
+// GetSubscriptions calls GetSubscriptionsFunc.
+func (mock *MongoDBMock) GetSubscriptions(ctx context.Context, offset int, limit int) ([]models.Subscription, int, error) {
+	if mock.GetSubscriptionsFunc == nil {
+		panic("MongoDBMock.GetSubscriptionsFunc: method is nil but MongoDB.GetSubscriptions was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Offset int
+		Limit  int
+	}{
+		Ctx:    ctx,
+		Offset: offset,
+		Limit:  limit,
+	}
+	mock.lockGetSubscriptions.Lock()
+	mock.calls.GetSubscriptions = append(mock.calls.GetSubscriptions, callInfo)
+	mock.lockGetSubscriptions.Unlock()
+	return mock.GetSubscriptionsFunc(ctx, offset, limit)
+}
+
+// GetSubscriptionsCalls gets all the calls that were made to GetSubscriptions.
+// Check the length with:
+//
+//	len(mockedMongoDB.GetSubscriptionsCalls())
+func (mock *MongoDBMock) GetSubscriptionsCalls() []struct {
+	Ctx    context.Context
+	Offset int
+	Limit  int
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Offset int
+		Limit  int
+	}
+	mock.lockGetSubscriptions.RLock()
+	calls = mock.calls.GetSubscriptions
+	mock.lockGetSubscriptions.RUnlock()
+	return calls
+}
+
+// GetVersion calls GetVersionFunc.
+func (mock *MongoDBMock) GetVersion(ctx context.Context, collectionID string, version int) (*models.Version, error) {
+	if mock.GetVersionFunc == nil {
+		panic("MongoDBMock.GetVersionFunc: method is nil but MongoDB.GetVersion was just called")
+	}
+	callInfo := struct {
+		Ctx          context.Context
+		CollectionID string
+		Version      int
+	}{
+		Ctx:          ctx,
+		CollectionID: collectionID,
+		Version:      version,
+	}
+	mock.lockGetVersion.Lock()
+	mock.calls.GetVersion = append(mock.calls.GetVersion, callInfo)
+	mock.lockGetVersion.Unlock()
+	return mock.GetVersionFunc(ctx, collectionID, version)
+}
+
+// GetVersionCalls gets all the calls that were made to GetVersion.
+// Check the length with:
+//
+//	len(mockedMongoDB.GetVersionCalls())
+func (mock *MongoDBMock) GetVersionCalls() []struct {
+	Ctx          context.Context
+	CollectionID string
+	Version      int
+} {
+	var calls []struct {
+		Ctx          context.Context
+		CollectionID string
+		Version      int
+	}
+	mock.lockGetVersion.RLock()
+	calls = mock.calls.GetVersion
+	mock.lockGetVersion.RUnlock()
+	return calls
+}
+
+// GetVersions calls GetVersionsFunc.
+func (mock *MongoDBMock) GetVersions(ctx context.Context, queryParams collections.VersionsQueryParams) ([]models.Version, int, error) {
+	if mock.GetVersionsFunc == nil {
+		panic("MongoDBMock.GetVersionsFunc: method is nil but MongoDB.GetVersions was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		QueryParams collections.VersionsQueryParams
+	}{
+		Ctx:         ctx,
+		QueryParams: queryParams,
+	}
+	mock.lockGetVersions.Lock()
+	mock.calls.GetVersions = append(mock.calls.GetVersions, callInfo)
+	mock.lockGetVersions.Unlock()
+	return mock.GetVersionsFunc(ctx, queryParams)
+}
+
+// GetVersionsCalls gets all the calls that were made to GetVersions.
+// Check the length with:
+//
+//	len(mockedMongoDB.GetVersionsCalls())
+func (mock *MongoDBMock) GetVersionsCalls() []struct {
+	Ctx         context.Context
+	QueryParams collections.VersionsQueryParams
+} {
+	var calls []struct {
+		Ctx         context.Context
+		QueryParams collections.VersionsQueryParams
+	}
+	mock.lockGetVersions.RLock()
+	calls = mock.calls.GetVersions
+	mock.lockGetVersions.RUnlock()
+	return calls
+}
+
 // ReplaceCollection calls ReplaceCollectionFunc.
 func (mock *MongoDBMock) ReplaceCollection(ctx context.Context, collection *models.Collection, eTagSelector string) error {
 	if mock.ReplaceCollectionFunc == nil {
@@ -415,7 +1231,8 @@ func (mock *MongoDBMock) ReplaceCollection(ctx context.Context, collection *mode
 
 // ReplaceCollectionCalls gets all the calls that were made to ReplaceCollection.
 // Check the length with:
-//     len(mockedMongoDB.ReplaceCollectionCalls())
+//
+//	len(mockedMongoDB.ReplaceCollectionCalls())
 func (mock *MongoDBMock) ReplaceCollectionCalls() []struct {
 	Ctx          context.Context
 	Collection   *models.Collection
@@ -431,3 +1248,75 @@ func (mock *MongoDBMock) ReplaceCollectionCalls() []struct {
 	mock.lockReplaceCollection.RUnlock()
 	return calls
 }
+
+// UpdateDelivery calls UpdateDeliveryFunc.
+func (mock *MongoDBMock) UpdateDelivery(ctx context.Context, delivery *models.Delivery) error {
+	if mock.UpdateDeliveryFunc == nil {
+		panic("MongoDBMock.UpdateDeliveryFunc: method is nil but MongoDB.UpdateDelivery was just called")
+	}
+	callInfo := struct {
+		Ctx      context.Context
+		Delivery *models.Delivery
+	}{
+		Ctx:      ctx,
+		Delivery: delivery,
+	}
+	mock.lockUpdateDelivery.Lock()
+	mock.calls.UpdateDelivery = append(mock.calls.UpdateDelivery, callInfo)
+	mock.lockUpdateDelivery.Unlock()
+	return mock.UpdateDeliveryFunc(ctx, delivery)
+}
+
+// UpdateDeliveryCalls gets all the calls that were made to UpdateDelivery.
+// Check the length with:
+//
+//	len(mockedMongoDB.UpdateDeliveryCalls())
+func (mock *MongoDBMock) UpdateDeliveryCalls() []struct {
+	Ctx      context.Context
+	Delivery *models.Delivery
+} {
+	var calls []struct {
+		Ctx      context.Context
+		Delivery *models.Delivery
+	}
+	mock.lockUpdateDelivery.RLock()
+	calls = mock.calls.UpdateDelivery
+	mock.lockUpdateDelivery.RUnlock()
+	return calls
+}
+
+// UpdateIdempotencyRecord calls UpdateIdempotencyRecordFunc.
+func (mock *MongoDBMock) UpdateIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	if mock.UpdateIdempotencyRecordFunc == nil {
+		panic("MongoDBMock.UpdateIdempotencyRecordFunc: method is nil but MongoDB.UpdateIdempotencyRecord was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Record *models.IdempotencyRecord
+	}{
+		Ctx:    ctx,
+		Record: record,
+	}
+	mock.lockUpdateIdempotencyRecord.Lock()
+	mock.calls.UpdateIdempotencyRecord = append(mock.calls.UpdateIdempotencyRecord, callInfo)
+	mock.lockUpdateIdempotencyRecord.Unlock()
+	return mock.UpdateIdempotencyRecordFunc(ctx, record)
+}
+
+// UpdateIdempotencyRecordCalls gets all the calls that were made to UpdateIdempotencyRecord.
+// Check the length with:
+//
+//	len(mockedMongoDB.UpdateIdempotencyRecordCalls())
+func (mock *MongoDBMock) UpdateIdempotencyRecordCalls() []struct {
+	Ctx    context.Context
+	Record *models.IdempotencyRecord
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Record *models.IdempotencyRecord
+	}
+	mock.lockUpdateIdempotencyRecord.RLock()
+	calls = mock.calls.UpdateIdempotencyRecord
+	mock.lockUpdateIdempotencyRecord.RUnlock()
+	return calls
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+const (
+	statusReady    = "READY"
+	statusNotReady = "NOT_READY"
+)
+
+// probeResponse is the body returned by the liveness and readiness endpoints
+type probeResponse struct {
+	Status string `json:"status"`
+	Health string `json:"health,omitempty"`
+}
+
+// readiness records whether the service is accepting traffic. It is only set between a successful Start and
+// the beginning of Close, so that the readiness endpoint reports not-ready while starting up and shutting down.
+type readiness struct {
+	accepting int32
+}
+
+func (r *readiness) set(accepting bool) {
+	var v int32
+	if accepting {
+		v = 1
+	}
+	atomic.StoreInt32(&r.accepting, v)
+}
+
+func (r *readiness) isSet() bool {
+	return atomic.LoadInt32(&r.accepting) == 1
+}
+
+// liveHandler reports that the process is responsive. It does not depend on any checks, so that a
+// dependency outage does not cause the process to be restarted.
+func (svc *Service) liveHandler(w http.ResponseWriter, req *http.Request) {
+	writeProbeResponse(w, req, http.StatusOK, probeResponse{Status: healthcheck.StatusOK})
+}
+
+// readyHandler reports whether the service should be sent traffic. It is ready once started, and while
+// the health is not critical, and stops being ready as soon as graceful shutdown begins. A warning, such as
+// Kafka being unreachable while its messages wait in the outbox, does not stop the service serving requests.
+func (svc *Service) readyHandler(w http.ResponseWriter, req *http.Request) {
+	health := svc.healthCheck.GetStatus()
+
+	if !svc.readiness.isSet() || health == healthcheck.StatusCritical {
+		writeProbeResponse(w, req, http.StatusServiceUnavailable, probeResponse{Status: statusNotReady, Health: health})
+		return
+	}
+
+	writeProbeResponse(w, req, http.StatusOK, probeResponse{Status: statusReady, Health: health})
+}
+
+func writeProbeResponse(w http.ResponseWriter, req *http.Request, status int, response probeResponse) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error(req.Context(), "failed to write probe response", err)
+	}
+}
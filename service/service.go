@@ -5,8 +5,16 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/ONSdigital/dp-collection-api/boltdb"
+	"github.com/ONSdigital/dp-collection-api/cache"
+	"github.com/ONSdigital/dp-collection-api/kafka"
+	"github.com/ONSdigital/dp-collection-api/memory"
+	"github.com/ONSdigital/dp-collection-api/metrics"
 	"github.com/ONSdigital/dp-collection-api/mongo"
 	"github.com/ONSdigital/dp-collection-api/pagination"
+	"github.com/ONSdigital/dp-collection-api/stream"
+	"github.com/ONSdigital/dp-collection-api/tracing"
+	"github.com/ONSdigital/dp-collection-api/webhooks"
 	"github.com/ONSdigital/dp-healthcheck/healthcheck"
 	dphttp "github.com/ONSdigital/dp-net/v2/http"
 
@@ -15,6 +23,7 @@ import (
 	"github.com/ONSdigital/log.go/v2/log"
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
 )
 
 var GetHealthCheck = func(version healthcheck.VersionInfo, criticalTimeout, interval time.Duration) HealthChecker {
@@ -22,37 +31,219 @@ var GetHealthCheck = func(version healthcheck.VersionInfo, criticalTimeout, inte
 	return &hc
 }
 
-var GetHTTPServer = func(bindAddr string, router http.Handler) HTTPServer {
+var GetHTTPServer = func(bindAddr string, writeTimeout time.Duration, router http.Handler) HTTPServer {
 	s := dphttp.NewServer(bindAddr, router)
 	s.HandleOSSignals = false
+	s.WriteTimeout = writeTimeout
+	s.ConnContext = api.ConnContext
 	return s
 }
 
-var GetMongoDB = func(ctx context.Context, cfg config.MongoConfig) (MongoDB, error) {
-	mongodb := &mongo.Mongo{
-		CollectionsCollection: cfg.CollectionsCollection,
-		EventsCollection:      cfg.EventsCollection,
-		Database:              cfg.CollectionsDatabase,
-		Username:              cfg.Username,
-		Password:              cfg.Password,
-		IsSSL:                 cfg.IsSSL,
-		URI:                   cfg.BindAddr,
-	}
-	err := mongodb.Init()
+// Datastores that can be selected with the DATASTORE config
+const (
+	DatastoreMongoDB = "mongodb"
+	DatastoreMemory  = "memory"
+	DatastoreBolt    = "bolt"
+)
+
+// ErrUnknownDatastore is returned when the configured datastore is not one of the supported datastores
+var ErrUnknownDatastore = errors.New("unknown datastore")
+
+// datastoreCheckNames are the names of the health checks for each datastore
+var datastoreCheckNames = map[string]string{
+	"":               "Mongo DB",
+	DatastoreMongoDB: "Mongo DB",
+	DatastoreMemory:  "In-memory datastore",
+	DatastoreBolt:    "Bolt DB",
+}
+
+// GetDatastore returns the datastore selected by the config. The in-memory datastore holds no data between restarts,
+// and is intended for local development and tests. The bolt datastore is held in a single file, for small
+// environments and offline demos.
+var GetDatastore = func(ctx context.Context, cfg *config.Config) (MongoDB, error) {
+	switch cfg.Datastore {
+	case "", DatastoreMongoDB:
+		return getMongoDB(ctx, cfg.MongoConfig)
+	case DatastoreMemory:
+		log.Warn(ctx, "using the in-memory datastore, data will be lost when the service stops")
+		return memory.New(), nil
+	case DatastoreBolt:
+		log.Info(ctx, "using the bolt datastore", log.Data{"path": cfg.BoltPath})
+		return boltdb.Open(cfg.BoltPath)
+	default:
+		return nil, errors.Wrap(ErrUnknownDatastore, cfg.Datastore)
+	}
+}
+
+// getMongoDB returns a MongoDB client, having applied any pending migrations and created any missing indexes
+func getMongoDB(ctx context.Context, cfg config.MongoConfig) (MongoDB, error) {
+	mongodb, err := initMongo(cfg)
 	if err != nil {
 		return nil, err
 	}
+	if cfg.MigrateOnStartup {
+		if _, err := mongodb.Migrate(ctx, false); err != nil {
+			if err != mongo.ErrMigrationsLocked {
+				return nil, err
+			}
+			// another instance started at the same time, and is applying the migrations
+			log.Warn(ctx, "skipping migrations on startup", log.Data{"reason": err.Error()})
+		}
+	}
+	if err := mongodb.EnsureIndexes(ctx); err != nil {
+		return nil, err
+	}
 	return mongodb, nil
 }
 
+// initMongo returns a connected Mongo client for the config
+func initMongo(cfg config.MongoConfig) (*mongo.Mongo, error) {
+	mongodb := &mongo.Mongo{
+		CollectionsCollection:   cfg.CollectionsCollection,
+		EventsCollection:        cfg.EventsCollection,
+		VersionsCollection:      cfg.VersionsCollection,
+		IdempotencyCollection:   cfg.IdempotencyCollection,
+		SubscriptionsCollection: cfg.SubscriptionsCollection,
+		DeliveriesCollection:    cfg.DeliveriesCollection,
+		OutboxCollection:        cfg.OutboxCollection,
+		MigrationsCollection:    cfg.MigrationsCollection,
+		Database:                cfg.CollectionsDatabase,
+		Username:                cfg.Username,
+		Password:                cfg.Password,
+		IsSSL:                   cfg.IsSSL,
+		URI:                     cfg.BindAddr,
+		VerifyIndexesOnly:       cfg.VerifyIndexesOnly,
+		WriteConcernMajority:    cfg.WriteConcernMajority,
+		StrongReadConcern:       cfg.StrongReadConcern,
+		ListReadPreference:      cfg.ListReadPreference,
+	}
+	if err := mongodb.Init(); err != nil {
+		return nil, err
+	}
+	return mongodb, nil
+}
+
+// Notifiers that can be selected with the CACHE_NOTIFIER config
+const (
+	CacheNotifierLocal   = "local"
+	CacheNotifierMongoDB = "mongodb"
+)
+
+// ErrUnknownCacheNotifier is returned when the configured cache notifier is not one of the supported notifiers
+var ErrUnknownCacheNotifier = errors.New("unknown cache notifier")
+
+// ErrUnsupportedCacheNotifier is returned when the configured cache notifier cannot be used with the datastore
+var ErrUnsupportedCacheNotifier = errors.New("cache notifier is not supported by the datastore")
+
+// GetCacheNotifier returns the notifier selected by the config, which invalidates cached collections when they
+// change. The local notifier only reaches this instance. The mongodb notifier watches a change stream, so that
+// changes made by any instance are seen.
+var GetCacheNotifier = func(ctx context.Context, cfg *config.Config, datastore MongoDB) (cache.Notifier, error) {
+	switch cfg.CacheNotifier {
+	case "", CacheNotifierLocal:
+		return cache.NewLocalNotifier(), nil
+	case CacheNotifierMongoDB:
+		mongodb, ok := datastore.(*mongo.Mongo)
+		if !ok {
+			return nil, errors.Wrap(ErrUnsupportedCacheNotifier, cfg.Datastore)
+		}
+		return mongodb.WatchCollections(ctx)
+	default:
+		return nil, errors.Wrap(ErrUnknownCacheNotifier, cfg.CacheNotifier)
+	}
+}
+
+// Sources of events that can be selected with the EVENT_STREAM_SOURCE config
+const (
+	EventStreamSourceLocal   = "local"
+	EventStreamSourceMongoDB = "mongodb"
+)
+
+// eventStreamBufferSize is the number of events held for each event stream that is not keeping up
+const eventStreamBufferSize = 100
+
+// ErrUnknownEventStreamSource is returned when the configured event stream source is not one of the supported sources
+var ErrUnknownEventStreamSource = errors.New("unknown event stream source")
+
+// ErrUnsupportedEventStreamSource is returned when the configured event stream source cannot be used with the
+// datastore
+var ErrUnsupportedEventStreamSource = errors.New("event stream source is not supported by the datastore")
+
+// GetEventStreamSource returns the source of events selected by the config, which reports the events recorded for
+// collections to their event streams. The local source only reports the events recorded by this instance. The mongodb
+// source watches a change stream, so that the events recorded by any instance are reported.
+var GetEventStreamSource = func(ctx context.Context, cfg *config.Config, datastore MongoDB) (stream.Source, error) {
+	switch cfg.EventStreamSource {
+	case "", EventStreamSourceLocal:
+		return stream.NewLocalSource(), nil
+	case EventStreamSourceMongoDB:
+		mongodb, ok := datastore.(*mongo.Mongo)
+		if !ok {
+			return nil, errors.Wrap(ErrUnsupportedEventStreamSource, cfg.Datastore)
+		}
+		return mongodb.WatchEvents(ctx)
+	default:
+		return nil, errors.Wrap(ErrUnknownEventStreamSource, cfg.EventStreamSource)
+	}
+}
+
+// ErrUnsupportedOutbox is returned when Kafka is enabled with a datastore that cannot store an outbox
+var ErrUnsupportedOutbox = errors.New("the outbox of kafka messages is not supported by the datastore")
+
+// GetKafkaProducer returns a producer of collection events for the Kafka config. It connects to Kafka when it is first
+// used, so that the service can start while Kafka is unavailable.
+var GetKafkaProducer = func(ctx context.Context, cfg *config.KafkaConfig) (kafka.Producer, error) {
+	return kafka.NewProducer(kafka.ProducerConfig{
+		Brokers: cfg.Brokers,
+		Version: cfg.Version,
+		TLS:     cfg.SecProtocol == "TLS",
+		Topic:   cfg.CollectionEventsTopic,
+	})
+}
+
+// GetKafkaConsumer returns a consumer of publish results for the Kafka config, which passes the results it reads to
+// the handler. Like the producer, it connects to Kafka in the background.
+var GetKafkaConsumer = func(ctx context.Context, cfg *config.KafkaConfig, handler kafka.PublishResultHandler) (kafka.Consumer, error) {
+	return kafka.NewConsumer(kafka.ConsumerConfig{
+		Brokers:               cfg.Brokers,
+		Version:               cfg.Version,
+		TLS:                   cfg.SecProtocol == "TLS",
+		Group:                 cfg.ConsumerGroup,
+		PublishCompletedTopic: cfg.PublishCompletedTopic,
+		PublishFailedTopic:    cfg.PublishFailedTopic,
+	}, handler)
+}
+
+// getOutbox returns the datastore as an outbox, if it can store one
+func getOutbox(cfg *config.Config, datastore MongoDB) (Outbox, error) {
+	outbox, ok := datastore.(Outbox)
+	if !ok {
+		return nil, errors.Wrap(ErrUnsupportedOutbox, cfg.Datastore)
+	}
+	return outbox, nil
+}
+
+var GetTracing = func(ctx context.Context, cfg *config.Config) (tracing.ShutdownFunc, error) {
+	return tracing.Init(ctx, cfg)
+}
+
 // Service contains all the configs, server and clients to run the dp-topic-api API
 type Service struct {
-	cfg         *config.Config
-	server      HTTPServer
-	router      *mux.Router
-	api         *api.API
-	healthCheck HealthChecker
-	mongoDB     MongoDB
+	cfg             *config.Config
+	server          HTTPServer
+	router          *mux.Router
+	api             *api.API
+	healthCheck     HealthChecker
+	mongoDB         MongoDB
+	cacheNotifier   cache.Notifier
+	webhooks        *webhooks.Dispatcher
+	eventSource     stream.Source
+	eventBroker     *stream.Broker
+	kafkaProducer   kafka.Producer
+	kafkaRelay      *kafka.Relay
+	kafkaConsumer   kafka.Consumer
+	shutdownTracing tracing.ShutdownFunc
+	readiness       readiness
 }
 
 // New initialises all the service dependencies
@@ -65,34 +256,131 @@ func New(ctx context.Context, cfg *config.Config, buildTime, gitCommit, version
 		return nil, err
 	}
 
-	mongoDB, err := GetMongoDB(ctx, cfg.MongoConfig)
+	shutdownTracing, err := GetTracing(ctx, cfg)
 	if err != nil {
-		log.Fatal(ctx, "failed to initialise mongo db", err)
+		log.Fatal(ctx, "failed to initialise tracing", err)
 		return nil, err
 	}
 
+	mongoDB, err := GetDatastore(ctx, cfg)
+	if err != nil {
+		log.Fatal(ctx, "failed to initialise datastore", err)
+		return nil, err
+	}
+
+	var kafkaProducer kafka.Producer
+	var outbox Outbox
+	if cfg.KafkaConfig.Enabled {
+		if outbox, err = getOutbox(cfg, mongoDB); err != nil {
+			log.Fatal(ctx, "failed to initialise kafka outbox", err)
+			return nil, err
+		}
+		if kafkaProducer, err = GetKafkaProducer(ctx, &cfg.KafkaConfig); err != nil {
+			log.Fatal(ctx, "failed to initialise kafka producer", err)
+			return nil, err
+		}
+	}
+
 	healthCheck := GetHealthCheck(versionInfo, cfg.HealthCheckCriticalTimeout, cfg.HealthCheckInterval)
-	if err := registerHealthChecks(ctx, healthCheck, mongoDB); err != nil {
+	if err := registerHealthChecks(ctx, healthCheck, datastoreCheckNames[cfg.Datastore], mongoDB, kafkaProducer); err != nil {
 		return nil, errors.Wrap(err, "unable to register health checks")
 	}
 
 	// Get HTTP router and server with middleware
 	r := mux.NewRouter()
+	r.Use(otelmux.Middleware(cfg.OTServiceName))
 	r.StrictSlash(true).Path("/health").HandlerFunc(healthCheck.Handler)
-	server := GetHTTPServer(cfg.BindAddr, r)
+	server := GetHTTPServer(cfg.BindAddr, cfg.HTTPWriteTimeout, r)
+
+	svc := &Service{
+		cfg:             cfg,
+		server:          server,
+		router:          r,
+		healthCheck:     healthCheck,
+		mongoDB:         mongoDB,
+		kafkaProducer:   kafkaProducer,
+		shutdownTracing: shutdownTracing,
+	}
+	r.Path("/health/live").HandlerFunc(svc.liveHandler).Methods(http.MethodGet)
+	r.Path("/health/ready").HandlerFunc(svc.readyHandler).Methods(http.MethodGet)
+
+	m := metrics.New()
+	if err := m.RegisterCollectionStats(mongoDB, cfg.UpcomingPublishWindow); err != nil {
+		return nil, errors.Wrap(err, "unable to register collection metrics")
+	}
+	r.Path("/metrics").Handler(m.Handler()).Methods(http.MethodGet)
+	r.Use(m.Middleware)
+
+	if cfg.EnableRequestValidation {
+		validator, err := api.NewRequestValidator(ctx, cfg.MaxRequestBodyBytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to load the API specification for request validation")
+		}
+		r.Use(validator.Middleware)
+	}
 
 	paginator := pagination.NewPaginator(cfg.DefaultLimit, cfg.DefaultOffset, cfg.DefaultMaxLimit)
 
-	api := api.Setup(ctx, r, paginator, mongoDB)
+	var collectionStore api.CollectionStore = metrics.NewCollectionStore(mongoDB, m)
+	var cachedStore *cache.CollectionStore
+	if cfg.CacheEnabled {
+		notifier, err := GetCacheNotifier(ctx, cfg, mongoDB)
+		if err != nil {
+			log.Fatal(ctx, "failed to initialise cache notifier", err)
+			return nil, err
+		}
+		svc.cacheNotifier = notifier
+		cachedStore = cache.NewCollectionStore(collectionStore, notifier, cfg.CacheTTL, cfg.CacheMaxEntries)
+		collectionStore = cachedStore
+	}
+	svc.api = api.Setup(ctx, cfg, r, paginator, collectionStore, mongoDB)
+	if cachedStore != nil {
+		// the first listener, so the cache is invalidated once a write commits, before any other listener reads it
+		svc.api.AddEventListener(cachedStore)
+	}
+	svc.api.SetupVersions(mongoDB)
+	if cfg.ZebedeeFacadeEnabled {
+		svc.api.SetupZebedee()
+	}
+
+	eventSource, err := GetEventStreamSource(ctx, cfg, mongoDB)
+	if err != nil {
+		log.Fatal(ctx, "failed to initialise event stream source", err)
+		return nil, err
+	}
+	svc.eventSource = eventSource
+	svc.eventBroker = stream.NewBroker(eventSource, eventStreamBufferSize)
+	svc.api.AddEventListener(eventSource)
+	svc.api.SetupEventStream(svc.eventBroker, cfg.HTTPWriteTimeout)
+	if cfg.WebhooksEnabled {
+		svc.webhooks = webhooks.New(mongoDB, webhooks.Config{
+			MaxAttempts:    cfg.WebhookMaxAttempts,
+			InitialBackoff: cfg.WebhookInitialBackoff,
+			MaxBackoff:     cfg.WebhookMaxBackoff,
+			PollInterval:   cfg.WebhookPollInterval,
+			Timeout:        cfg.WebhookTimeout,
+
+			AllowPrivateNetworks: cfg.WebhookAllowPrivateIPs,
+		})
+		svc.api.AddTransactionalListener(svc.webhooks)
+		svc.api.SetupSubscriptions(mongoDB)
+	}
+	if kafkaProducer != nil {
+		svc.kafkaRelay = kafka.NewRelay(outbox, kafkaProducer, cfg.KafkaConfig.OutboxPollInterval)
+		svc.api.SetOutbox(outbox)
+		svc.api.AddEventListener(svc.kafkaRelay)
+
+		// the consumer is created once the API exists, as publish results are stored through it
+		if svc.kafkaConsumer, err = GetKafkaConsumer(ctx, &cfg.KafkaConfig, svc.api); err != nil {
+			log.Fatal(ctx, "failed to initialise kafka consumer", err)
+			return nil, err
+		}
+		if err := healthCheck.AddCheck("Kafka consumer", svc.kafkaConsumer.Checker); err != nil {
+			return nil, errors.Wrap(err, "unable to register health checks")
+		}
+	}
 
-	return &Service{
-		cfg:         cfg,
-		server:      server,
-		router:      r,
-		api:         api,
-		healthCheck: healthCheck,
-		mongoDB:     mongoDB,
-	}, nil
+	return svc, nil
 }
 
 // Start the service, allowing it to serve HTTP requests
@@ -100,6 +388,18 @@ func (svc *Service) Start(ctx context.Context, svcErrors chan error) {
 
 	svc.healthCheck.Start(ctx)
 
+	if svc.webhooks != nil {
+		svc.webhooks.Start(ctx)
+	}
+
+	if svc.kafkaRelay != nil {
+		svc.kafkaRelay.Start(ctx)
+	}
+
+	if svc.kafkaConsumer != nil {
+		svc.kafkaConsumer.Start(ctx)
+	}
+
 	// Run the http server in a new go-routine
 	go func() {
 		log.Info(ctx, "starting api")
@@ -107,6 +407,8 @@ func (svc *Service) Start(ctx context.Context, svcErrors chan error) {
 			svcErrors <- errors.Wrap(err, "failure in http listen and serve")
 		}
 	}()
+
+	svc.readiness.set(true)
 }
 
 // Close gracefully shuts the service down in the required order, with timeout
@@ -120,11 +422,27 @@ func (svc *Service) Close(ctx context.Context) error {
 	go func() {
 		defer cancel()
 
+		// report not-ready first, and give load balancers time to notice, so that traffic is drained before
+		// the server stops accepting requests
+		svc.readiness.set(false)
+		if delay := svc.cfg.ReadinessDrainDelay; delay > 0 {
+			log.Info(ctx, "waiting for traffic to drain", log.Data{"readiness_drain_delay": delay})
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+			}
+		}
+
 		// stop health check, as it depends on everything else
 		if svc.healthCheck != nil {
 			svc.healthCheck.Stop()
 		}
 
+		// end the open event streams, so that the server does not wait for them to end by themselves
+		if svc.eventBroker != nil {
+			svc.eventBroker.Close()
+		}
+
 		// stop any incoming requests
 		if svc.server != nil {
 			if err := svc.server.Shutdown(ctx); err != nil {
@@ -133,12 +451,65 @@ func (svc *Service) Close(ctx context.Context) error {
 			}
 		}
 
+		if svc.eventSource != nil {
+			if err := svc.eventSource.Close(ctx); err != nil {
+				log.Error(ctx, "error closing event stream source", err)
+				hasShutdownError = true
+			}
+		}
+
+		// stop making deliveries before the datastore they are claimed from is closed
+		if svc.webhooks != nil {
+			if err := svc.webhooks.Close(ctx); err != nil {
+				log.Error(ctx, "error stopping webhook deliveries", err)
+				hasShutdownError = true
+			}
+		}
+
+		// stop consuming publish results before the datastore they are stored in is closed
+		if svc.kafkaConsumer != nil {
+			if err := svc.kafkaConsumer.Close(ctx); err != nil {
+				log.Error(ctx, "error closing kafka consumer", err)
+				hasShutdownError = true
+			}
+		}
+
+		// stop publishing the outbox before the producer and the datastore it is read from are closed
+		if svc.kafkaRelay != nil {
+			if err := svc.kafkaRelay.Close(ctx); err != nil {
+				log.Error(ctx, "error stopping kafka outbox relay", err)
+				hasShutdownError = true
+			}
+		}
+
+		if svc.kafkaProducer != nil {
+			if err := svc.kafkaProducer.Close(ctx); err != nil {
+				log.Error(ctx, "error closing kafka producer", err)
+				hasShutdownError = true
+			}
+		}
+
+		if svc.cacheNotifier != nil {
+			if err := svc.cacheNotifier.Close(ctx); err != nil {
+				log.Error(ctx, "error closing cache notifier", err)
+				hasShutdownError = true
+			}
+		}
+
 		if svc.mongoDB != nil {
 			if err := svc.mongoDB.Close(ctx); err != nil {
 				log.Error(ctx, "error closing mongo db client", err)
 				hasShutdownError = true
 			}
 		}
+
+		// flush any remaining spans once everything that creates them has stopped
+		if svc.shutdownTracing != nil {
+			if err := svc.shutdownTracing(ctx); err != nil {
+				log.Error(ctx, "error shutting down tracing", err)
+				hasShutdownError = true
+			}
+		}
 	}()
 
 	// wait for shutdown success (via cancel) or failure (timeout)
@@ -162,13 +533,21 @@ func (svc *Service) Close(ctx context.Context) error {
 }
 
 // registerHealthChecks adds the checkers for the service clients to the health check object.
-func registerHealthChecks(ctx context.Context, hc HealthChecker, mongoDB MongoDB) (err error) {
+// The Kafka producer is only checked if Kafka is enabled.
+func registerHealthChecks(ctx context.Context, hc HealthChecker, datastoreName string, mongoDB MongoDB, kafkaProducer kafka.Producer) (err error) {
 
 	hasErrors := false
 
-	if err = hc.AddCheck("Mongo DB", mongoDB.Checker); err != nil {
+	if err = hc.AddCheck(datastoreName, mongoDB.Checker); err != nil {
 		hasErrors = true
-		log.Error(ctx, "error adding check for mongo db client", err)
+		log.Error(ctx, "error adding check for datastore", err, log.Data{"datastore": datastoreName})
+	}
+
+	if kafkaProducer != nil {
+		if err = hc.AddCheck("Kafka producer", kafkaProducer.Checker); err != nil {
+			hasErrors = true
+			log.Error(ctx, "error adding check for kafka producer", err)
+		}
 	}
 
 	if hasErrors {
@@ -4,15 +4,26 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/ONSdigital/dp-healthcheck/healthcheck"
 
+	"github.com/ONSdigital/dp-collection-api/boltdb"
+	"github.com/ONSdigital/dp-collection-api/cache"
 	"github.com/ONSdigital/dp-collection-api/config"
+	"github.com/ONSdigital/dp-collection-api/kafka"
+	kafkamock "github.com/ONSdigital/dp-collection-api/kafka/mock"
+	"github.com/ONSdigital/dp-collection-api/memory"
+	"github.com/ONSdigital/dp-collection-api/models"
 	"github.com/ONSdigital/dp-collection-api/service"
 	"github.com/ONSdigital/dp-collection-api/service/mock"
+	"github.com/ONSdigital/dp-collection-api/stream"
+	streammock "github.com/ONSdigital/dp-collection-api/stream/mock"
+	"github.com/ONSdigital/dp-collection-api/tracing"
 	"github.com/pkg/errors"
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -41,12 +52,12 @@ func TestNew(t *testing.T) {
 		}
 
 		serverMock := &mock.HTTPServerMock{}
-		service.GetHTTPServer = func(bindAddr string, router http.Handler) service.HTTPServer {
+		service.GetHTTPServer = func(bindAddr string, writeTimeout time.Duration, router http.Handler) service.HTTPServer {
 			return serverMock
 		}
 
 		mongoDBMock := &mock.MongoDBMock{}
-		service.GetMongoDB = func(ctx context.Context, cfg config.MongoConfig) (service.MongoDB, error) {
+		service.GetDatastore = func(ctx context.Context, cfg *config.Config) (service.MongoDB, error) {
 			return mongoDBMock, nil
 		}
 
@@ -94,12 +105,12 @@ func TestNew(t *testing.T) {
 		}
 
 		serverMock := &mock.HTTPServerMock{}
-		service.GetHTTPServer = func(bindAddr string, router http.Handler) service.HTTPServer {
+		service.GetHTTPServer = func(bindAddr string, writeTimeout time.Duration, router http.Handler) service.HTTPServer {
 			return serverMock
 		}
 
 		expectedErr := errors.New("mongodb failed to initialised")
-		service.GetMongoDB = func(ctx context.Context, cfg config.MongoConfig) (service.MongoDB, error) {
+		service.GetDatastore = func(ctx context.Context, cfg *config.Config) (service.MongoDB, error) {
 			return nil, expectedErr
 		}
 
@@ -115,6 +126,257 @@ func TestNew(t *testing.T) {
 	})
 }
 
+func TestNew_tracingError(t *testing.T) {
+
+	Convey("Given an error occurs when tracing is initialised", t, func() {
+
+		cfg := &config.Config{}
+
+		mongoDBMock := &mock.MongoDBMock{}
+		service.GetDatastore = func(ctx context.Context, cfg *config.Config) (service.MongoDB, error) {
+			return mongoDBMock, nil
+		}
+
+		expectedErr := errors.New("tracing failed to initialise")
+		service.GetTracing = func(ctx context.Context, cfg *config.Config) (tracing.ShutdownFunc, error) {
+			return nil, expectedErr
+		}
+		defer func() {
+			service.GetTracing = func(ctx context.Context, cfg *config.Config) (tracing.ShutdownFunc, error) {
+				return tracing.Init(ctx, cfg)
+			}
+		}()
+
+		Convey("When service.New is called", func() {
+			svc, err := service.New(ctx, cfg, testBuildTime, testGitCommit, testVersion)
+			So(svc, ShouldBeNil)
+
+			Convey("Then the expected error is returned", func() {
+				So(err, ShouldEqual, expectedErr)
+			})
+		})
+	})
+}
+
+// getDatastore is the default datastore constructor, as the tests replace service.GetDatastore
+var getDatastore = service.GetDatastore
+
+// getEventStreamSource is the default event stream source constructor, as a test replaces it
+var getEventStreamSource = service.GetEventStreamSource
+
+func TestGetDatastore(t *testing.T) {
+
+	Convey("Given the in-memory datastore is configured", t, func() {
+		cfg := &config.Config{Datastore: service.DatastoreMemory}
+
+		Convey("When the datastore is created", func() {
+			datastore, err := getDatastore(ctx, cfg)
+
+			Convey("Then an in-memory store is returned", func() {
+				So(err, ShouldBeNil)
+				So(datastore, ShouldHaveSameTypeAs, &memory.Store{})
+			})
+		})
+	})
+
+	Convey("Given the bolt datastore is configured", t, func() {
+		cfg := &config.Config{Datastore: service.DatastoreBolt, BoltPath: filepath.Join(t.TempDir(), "collections.db")}
+
+		Convey("When the datastore is created", func() {
+			datastore, err := getDatastore(ctx, cfg)
+
+			Convey("Then a bolt store is returned", func() {
+				So(err, ShouldBeNil)
+				So(datastore, ShouldHaveSameTypeAs, &boltdb.Store{})
+				So(datastore.Close(ctx), ShouldBeNil)
+			})
+		})
+	})
+
+	Convey("Given an unknown datastore is configured", t, func() {
+		cfg := &config.Config{Datastore: "unknown"}
+
+		Convey("When the datastore is created", func() {
+			datastore, err := getDatastore(ctx, cfg)
+
+			Convey("Then an error is returned", func() {
+				So(datastore, ShouldBeNil)
+				So(errors.Cause(err), ShouldEqual, service.ErrUnknownDatastore)
+			})
+		})
+	})
+}
+
+func TestGetCacheNotifier(t *testing.T) {
+
+	Convey("Given the local cache notifier is configured", t, func() {
+		cfg := &config.Config{CacheNotifier: service.CacheNotifierLocal}
+
+		Convey("When the notifier is created", func() {
+			notifier, err := service.GetCacheNotifier(ctx, cfg, memory.New())
+
+			Convey("Then a local notifier is returned", func() {
+				So(err, ShouldBeNil)
+				So(notifier, ShouldHaveSameTypeAs, &cache.LocalNotifier{})
+			})
+		})
+	})
+
+	Convey("Given the mongodb cache notifier is configured with the in-memory datastore", t, func() {
+		cfg := &config.Config{Datastore: service.DatastoreMemory, CacheNotifier: service.CacheNotifierMongoDB}
+
+		Convey("When the notifier is created", func() {
+			notifier, err := service.GetCacheNotifier(ctx, cfg, memory.New())
+
+			Convey("Then an error is returned", func() {
+				So(notifier, ShouldBeNil)
+				So(errors.Cause(err), ShouldEqual, service.ErrUnsupportedCacheNotifier)
+			})
+		})
+	})
+
+	Convey("Given an unknown cache notifier is configured", t, func() {
+		cfg := &config.Config{CacheNotifier: "unknown"}
+
+		Convey("When the notifier is created", func() {
+			notifier, err := service.GetCacheNotifier(ctx, cfg, memory.New())
+
+			Convey("Then an error is returned", func() {
+				So(notifier, ShouldBeNil)
+				So(errors.Cause(err), ShouldEqual, service.ErrUnknownCacheNotifier)
+			})
+		})
+	})
+}
+
+func TestGetEventStreamSource(t *testing.T) {
+
+	Convey("Given the local event stream source is configured", t, func() {
+		cfg := &config.Config{EventStreamSource: service.EventStreamSourceLocal}
+
+		Convey("When the source is created", func() {
+			source, err := service.GetEventStreamSource(ctx, cfg, memory.New())
+
+			Convey("Then a local source is returned", func() {
+				So(err, ShouldBeNil)
+				So(source, ShouldHaveSameTypeAs, &stream.LocalSource{})
+			})
+		})
+	})
+
+	Convey("Given the mongodb event stream source is configured with the in-memory datastore", t, func() {
+		cfg := &config.Config{Datastore: service.DatastoreMemory, EventStreamSource: service.EventStreamSourceMongoDB}
+
+		Convey("When the source is created", func() {
+			source, err := service.GetEventStreamSource(ctx, cfg, memory.New())
+
+			Convey("Then an error is returned", func() {
+				So(source, ShouldBeNil)
+				So(errors.Cause(err), ShouldEqual, service.ErrUnsupportedEventStreamSource)
+			})
+		})
+	})
+
+	Convey("Given an unknown event stream source is configured", t, func() {
+		cfg := &config.Config{EventStreamSource: "unknown"}
+
+		Convey("When the source is created", func() {
+			source, err := service.GetEventStreamSource(ctx, cfg, memory.New())
+
+			Convey("Then an error is returned", func() {
+				So(source, ShouldBeNil)
+				So(errors.Cause(err), ShouldEqual, service.ErrUnknownEventStreamSource)
+			})
+		})
+	})
+}
+
+func TestNew_datastoreHealthCheck(t *testing.T) {
+
+	Convey("Given the in-memory datastore is configured", t, func() {
+		cfg := &config.Config{Datastore: service.DatastoreMemory}
+
+		hcMock := &mock.HealthCheckerMock{
+			AddCheckFunc: func(name string, checker healthcheck.Checker) error { return nil },
+		}
+		service.GetHealthCheck = func(version healthcheck.VersionInfo, criticalTimeout, interval time.Duration) service.HealthChecker {
+			return hcMock
+		}
+		service.GetHTTPServer = func(bindAddr string, writeTimeout time.Duration, router http.Handler) service.HTTPServer {
+			return &mock.HTTPServerMock{}
+		}
+		service.GetDatastore = getDatastore
+
+		Convey("When service.New is called", func() {
+			svc, err := service.New(ctx, cfg, testBuildTime, testGitCommit, testVersion)
+
+			Convey("Then the health check is named for the datastore", func() {
+				So(err, ShouldBeNil)
+				So(svc, ShouldNotBeNil)
+				So(len(hcMock.AddCheckCalls()), ShouldEqual, 1)
+				So(hcMock.AddCheckCalls()[0].Name, ShouldEqual, "In-memory datastore")
+			})
+		})
+	})
+}
+
+func TestNew_kafka(t *testing.T) {
+
+	Convey("Given Kafka is enabled", t, func() {
+		hcMock := &mock.HealthCheckerMock{
+			AddCheckFunc: func(name string, checker healthcheck.Checker) error { return nil },
+		}
+		service.GetHealthCheck = func(version healthcheck.VersionInfo, criticalTimeout, interval time.Duration) service.HealthChecker {
+			return hcMock
+		}
+		service.GetHTTPServer = func(bindAddr string, writeTimeout time.Duration, router http.Handler) service.HTTPServer {
+			return &mock.HTTPServerMock{}
+		}
+		service.GetDatastore = getDatastore
+
+		producerMock := &kafkamock.ProducerMock{
+			CloseFunc: func(ctx context.Context) error { return nil },
+		}
+		service.GetKafkaProducer = func(ctx context.Context, cfg *config.KafkaConfig) (kafka.Producer, error) {
+			return producerMock, nil
+		}
+
+		var consumerHandler kafka.PublishResultHandler
+		consumerMock := &kafkamock.ConsumerMock{}
+		service.GetKafkaConsumer = func(ctx context.Context, cfg *config.KafkaConfig, handler kafka.PublishResultHandler) (kafka.Consumer, error) {
+			consumerHandler = handler
+			return consumerMock, nil
+		}
+
+		Convey("When service.New is called with the in-memory datastore", func() {
+			cfg := &config.Config{Datastore: service.DatastoreMemory, KafkaConfig: config.KafkaConfig{Enabled: true, OutboxPollInterval: time.Second}}
+			svc, err := service.New(ctx, cfg, testBuildTime, testGitCommit, testVersion)
+
+			Convey("Then the producer and consumer health checks are registered", func() {
+				So(err, ShouldBeNil)
+				So(svc, ShouldNotBeNil)
+				So(len(hcMock.AddCheckCalls()), ShouldEqual, 3)
+				So(hcMock.AddCheckCalls()[1].Name, ShouldEqual, "Kafka producer")
+				So(hcMock.AddCheckCalls()[2].Name, ShouldEqual, "Kafka consumer")
+			})
+
+			Convey("Then the consumer stores publish results through the API", func() {
+				So(consumerHandler, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When service.New is called with the bolt datastore", func() {
+			cfg := &config.Config{Datastore: service.DatastoreBolt, BoltPath: filepath.Join(t.TempDir(), "collections.db"), KafkaConfig: config.KafkaConfig{Enabled: true}}
+			svc, err := service.New(ctx, cfg, testBuildTime, testGitCommit, testVersion)
+
+			Convey("Then an error is returned, as the bolt datastore has no outbox", func() {
+				So(svc, ShouldBeNil)
+				So(errors.Cause(err), ShouldEqual, service.ErrUnsupportedOutbox)
+			})
+		})
+	})
+}
+
 func TestStart(t *testing.T) {
 
 	Convey("Having a correctly initialised Service with mocked dependencies", t, func() {
@@ -130,12 +392,12 @@ func TestStart(t *testing.T) {
 		}
 
 		serverMock := &mock.HTTPServerMock{}
-		service.GetHTTPServer = func(bindAddr string, router http.Handler) service.HTTPServer {
+		service.GetHTTPServer = func(bindAddr string, writeTimeout time.Duration, router http.Handler) service.HTTPServer {
 			return serverMock
 		}
 
 		mongoDBMock := &mock.MongoDBMock{}
-		service.GetMongoDB = func(ctx context.Context, cfg config.MongoConfig) (service.MongoDB, error) {
+		service.GetDatastore = func(ctx context.Context, cfg *config.Config) (service.MongoDB, error) {
 			return mongoDBMock, nil
 		}
 
@@ -205,7 +467,7 @@ func TestClose(t *testing.T) {
 				return nil
 			},
 		}
-		service.GetHTTPServer = func(bindAddr string, router http.Handler) service.HTTPServer {
+		service.GetHTTPServer = func(bindAddr string, writeTimeout time.Duration, router http.Handler) service.HTTPServer {
 			return serverMock
 		}
 
@@ -214,10 +476,24 @@ func TestClose(t *testing.T) {
 				return nil
 			},
 		}
-		service.GetMongoDB = func(ctx context.Context, cfg config.MongoConfig) (service.MongoDB, error) {
+		service.GetDatastore = func(ctx context.Context, cfg *config.Config) (service.MongoDB, error) {
 			return mongoDBMock, nil
 		}
 
+		sourceMock := &streammock.SourceMock{
+			SubscribeFunc: func(publish func(event *models.Event)) {},
+			CloseFunc: func(ctx context.Context) error {
+				if len(serverMock.ShutdownCalls()) == 0 {
+					return errors.New("Event stream source was closed before the server")
+				}
+				return nil
+			},
+		}
+		service.GetEventStreamSource = func(ctx context.Context, cfg *config.Config, datastore service.MongoDB) (stream.Source, error) {
+			return sourceMock, nil
+		}
+		defer func() { service.GetEventStreamSource = getEventStreamSource }()
+
 		svc, err := service.New(ctx, cfg, testBuildTime, testGitCommit, testVersion)
 		So(err, ShouldBeNil)
 		So(svc, ShouldNotBeNil)
@@ -228,6 +504,7 @@ func TestClose(t *testing.T) {
 				So(err, ShouldBeNil)
 				So(len(hcMock.StopCalls()), ShouldEqual, 1)
 				So(len(serverMock.ShutdownCalls()), ShouldEqual, 1)
+				So(len(sourceMock.CloseCalls()), ShouldEqual, 1)
 				So(len(mongoDBMock.CloseCalls()), ShouldEqual, 1)
 			})
 		})
@@ -262,3 +539,89 @@ func TestClose(t *testing.T) {
 		})
 	})
 }
+
+func TestHealthProbes(t *testing.T) {
+
+	Convey("Having a correctly initialised service with mocked dependencies", t, func() {
+		cfg := &config.Config{
+			GracefulShutdownTimeout: 5 * time.Second,
+		}
+
+		healthStatus := healthcheck.StatusOK
+		hcMock := &mock.HealthCheckerMock{
+			AddCheckFunc:  func(name string, checker healthcheck.Checker) error { return nil },
+			StartFunc:     func(ctx context.Context) {},
+			StopFunc:      func() {},
+			GetStatusFunc: func() string { return healthStatus },
+		}
+		service.GetHealthCheck = func(version healthcheck.VersionInfo, criticalTimeout, interval time.Duration) service.HealthChecker {
+			return hcMock
+		}
+
+		var router http.Handler
+		serverMock := &mock.HTTPServerMock{
+			ListenAndServeFunc: func() error { return nil },
+		}
+		service.GetHTTPServer = func(bindAddr string, writeTimeout time.Duration, r http.Handler) service.HTTPServer {
+			router = r
+			return serverMock
+		}
+
+		mongoDBMock := &mock.MongoDBMock{
+			CloseFunc: func(ctx context.Context) error { return nil },
+		}
+		service.GetDatastore = func(ctx context.Context, cfg *config.Config) (service.MongoDB, error) {
+			return mongoDBMock, nil
+		}
+
+		probe := func(path string) int {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+			return w.Code
+		}
+
+		svc, err := service.New(ctx, cfg, testBuildTime, testGitCommit, testVersion)
+		So(err, ShouldBeNil)
+
+		Convey("When the service has not been started", func() {
+
+			Convey("Then it is live but not ready", func() {
+				So(probe("/health/live"), ShouldEqual, http.StatusOK)
+				So(probe("/health/ready"), ShouldEqual, http.StatusServiceUnavailable)
+			})
+		})
+
+		Convey("When the service has been started", func() {
+			svc.Start(ctx, make(chan error, 1))
+
+			Convey("Then it is ready while all checks are OK", func() {
+				So(probe("/health/ready"), ShouldEqual, http.StatusOK)
+			})
+
+			Convey("Then it is still ready while the health is a warning", func() {
+				healthStatus = healthcheck.StatusWarning
+				So(probe("/health/ready"), ShouldEqual, http.StatusOK)
+			})
+
+			Convey("Then it is not ready while the health is critical", func() {
+				healthStatus = healthcheck.StatusCritical
+				So(probe("/health/ready"), ShouldEqual, http.StatusServiceUnavailable)
+				So(probe("/health/live"), ShouldEqual, http.StatusOK)
+			})
+
+			Convey("And the service is closed", func() {
+				readyAtShutdown := 0
+				serverMock.ShutdownFunc = func(ctx context.Context) error {
+					readyAtShutdown = probe("/health/ready")
+					return nil
+				}
+				err := svc.Close(ctx)
+				So(err, ShouldBeNil)
+
+				Convey("Then it reports not ready before the server is shut down", func() {
+					So(readyAtShutdown, ShouldEqual, http.StatusServiceUnavailable)
+				})
+			})
+		})
+	})
+}
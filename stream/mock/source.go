@@ -0,0 +1,177 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/dp-collection-api/stream"
+	"sync"
+)
+
+// Ensure, that SourceMock does implement stream.Source.
+// If this is not the case, regenerate this file with moq.
+var _ stream.Source = &SourceMock{}
+
+// SourceMock is a mock implementation of stream.Source.
+//
+//	func TestSomethingThatUsesSource(t *testing.T) {
+//
+//		// make and configure a mocked stream.Source
+//		mockedSource := &SourceMock{
+//			CloseFunc: func(ctx context.Context) error {
+//				panic("mock out the Close method")
+//			},
+//			HandleCollectionEventFunc: func(ctx context.Context, event *models.Event, collection *models.Collection) error {
+//				panic("mock out the HandleCollectionEvent method")
+//			},
+//			SubscribeFunc: func(publish func(event *models.Event))  {
+//				panic("mock out the Subscribe method")
+//			},
+//		}
+//
+//		// use mockedSource in code that requires stream.Source
+//		// and then make assertions.
+//
+//	}
+type SourceMock struct {
+	// CloseFunc mocks the Close method.
+	CloseFunc func(ctx context.Context) error
+
+	// HandleCollectionEventFunc mocks the HandleCollectionEvent method.
+	HandleCollectionEventFunc func(ctx context.Context, event *models.Event, collection *models.Collection) error
+
+	// SubscribeFunc mocks the Subscribe method.
+	SubscribeFunc func(publish func(event *models.Event))
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Close holds details about calls to the Close method.
+		Close []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// HandleCollectionEvent holds details about calls to the HandleCollectionEvent method.
+		HandleCollectionEvent []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Event is the event argument value.
+			Event *models.Event
+			// Collection is the collection argument value.
+			Collection *models.Collection
+		}
+		// Subscribe holds details about calls to the Subscribe method.
+		Subscribe []struct {
+			// Publish is the publish argument value.
+			Publish func(event *models.Event)
+		}
+	}
+	lockClose                 sync.RWMutex
+	lockHandleCollectionEvent sync.RWMutex
+	lockSubscribe             sync.RWMutex
+}
+
+// Close calls CloseFunc.
+func (mock *SourceMock) Close(ctx context.Context) error {
+	if mock.CloseFunc == nil {
+		panic("SourceMock.CloseFunc: method is nil but Source.Close was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockClose.Lock()
+	mock.calls.Close = append(mock.calls.Close, callInfo)
+	mock.lockClose.Unlock()
+	return mock.CloseFunc(ctx)
+}
+
+// CloseCalls gets all the calls that were made to Close.
+// Check the length with:
+//
+//	len(mockedSource.CloseCalls())
+func (mock *SourceMock) CloseCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockClose.RLock()
+	calls = mock.calls.Close
+	mock.lockClose.RUnlock()
+	return calls
+}
+
+// HandleCollectionEvent calls HandleCollectionEventFunc.
+func (mock *SourceMock) HandleCollectionEvent(ctx context.Context, event *models.Event, collection *models.Collection) error {
+	if mock.HandleCollectionEventFunc == nil {
+		panic("SourceMock.HandleCollectionEventFunc: method is nil but Source.HandleCollectionEvent was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Event      *models.Event
+		Collection *models.Collection
+	}{
+		Ctx:        ctx,
+		Event:      event,
+		Collection: collection,
+	}
+	mock.lockHandleCollectionEvent.Lock()
+	mock.calls.HandleCollectionEvent = append(mock.calls.HandleCollectionEvent, callInfo)
+	mock.lockHandleCollectionEvent.Unlock()
+	return mock.HandleCollectionEventFunc(ctx, event, collection)
+}
+
+// HandleCollectionEventCalls gets all the calls that were made to HandleCollectionEvent.
+// Check the length with:
+//
+//	len(mockedSource.HandleCollectionEventCalls())
+func (mock *SourceMock) HandleCollectionEventCalls() []struct {
+	Ctx        context.Context
+	Event      *models.Event
+	Collection *models.Collection
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Event      *models.Event
+		Collection *models.Collection
+	}
+	mock.lockHandleCollectionEvent.RLock()
+	calls = mock.calls.HandleCollectionEvent
+	mock.lockHandleCollectionEvent.RUnlock()
+	return calls
+}
+
+// Subscribe calls SubscribeFunc.
+func (mock *SourceMock) Subscribe(publish func(event *models.Event)) {
+	if mock.SubscribeFunc == nil {
+		panic("SourceMock.SubscribeFunc: method is nil but Source.Subscribe was just called")
+	}
+	callInfo := struct {
+		Publish func(event *models.Event)
+	}{
+		Publish: publish,
+	}
+	mock.lockSubscribe.Lock()
+	mock.calls.Subscribe = append(mock.calls.Subscribe, callInfo)
+	mock.lockSubscribe.Unlock()
+	mock.SubscribeFunc(publish)
+}
+
+// SubscribeCalls gets all the calls that were made to Subscribe.
+// Check the length with:
+//
+//	len(mockedSource.SubscribeCalls())
+func (mock *SourceMock) SubscribeCalls() []struct {
+	Publish func(event *models.Event)
+} {
+	var calls []struct {
+		Publish func(event *models.Event)
+	}
+	mock.lockSubscribe.RLock()
+	calls = mock.calls.Subscribe
+	mock.lockSubscribe.RUnlock()
+	return calls
+}
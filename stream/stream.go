@@ -0,0 +1,144 @@
+// Package stream delivers the events of collections to the clients of the event stream endpoint as they are recorded.
+// A source reports each event recorded by the API, and a broker passes it to the subscribers to its collection.
+package stream
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+//go:generate moq -out mock/source.go -pkg mock . Source
+
+// Source reports each event recorded for a collection to its subscribers. An implementation may report the events
+// recorded by other instances, for example by watching the datastore for new events.
+type Source interface {
+	HandleCollectionEvent(ctx context.Context, event *models.Event, collection *models.Collection) error
+	Subscribe(publish func(event *models.Event))
+	Close(ctx context.Context) error
+}
+
+// LocalSource reports the events recorded by this instance only. It is suitable for a single instance, or where
+// clients are expected to reconnect to the instance that records the events they follow.
+type LocalSource struct {
+	mutex       sync.RWMutex
+	subscribers []func(event *models.Event)
+}
+
+// NewLocalSource returns a source with no subscribers
+func NewLocalSource() *LocalSource {
+	return &LocalSource{}
+}
+
+// HandleCollectionEvent calls every subscriber with an event recorded by the API
+func (s *LocalSource) HandleCollectionEvent(ctx context.Context, event *models.Event, collection *models.Collection) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, publish := range s.subscribers {
+		publish(event)
+	}
+	return nil
+}
+
+// Subscribe adds a function to be called with each recorded event
+func (s *LocalSource) Subscribe(publish func(event *models.Event)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.subscribers = append(s.subscribers, publish)
+}
+
+// Close does nothing, as there is nothing to release
+func (s *LocalSource) Close(ctx context.Context) error {
+	return nil
+}
+
+// Broker passes each event reported by a source to the subscribers to its collection. A subscriber that does not keep
+// up with its events is unsubscribed, by closing its channel, rather than holding up the others, and is expected to
+// subscribe again and read the events it missed from the datastore.
+type Broker struct {
+	bufferSize  int
+	mutex       sync.Mutex
+	subscribers map[string]map[chan models.Event]struct{}
+	closed      bool
+}
+
+// NewBroker returns a broker of the events reported by the source, which holds up to bufferSize events for each
+// subscriber
+func NewBroker(source Source, bufferSize int) *Broker {
+	b := &Broker{
+		bufferSize:  bufferSize,
+		subscribers: make(map[string]map[chan models.Event]struct{}),
+	}
+	source.Subscribe(b.Publish)
+	return b
+}
+
+// Subscribe returns a channel of the events of a collection, and a function that unsubscribes from them. The channel
+// is closed if the subscriber falls behind, or the broker is closed.
+func (b *Broker) Subscribe(collectionID string) (<-chan models.Event, func()) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	events := make(chan models.Event, b.bufferSize)
+	if b.closed {
+		close(events)
+		return events, func() {}
+	}
+
+	if b.subscribers[collectionID] == nil {
+		b.subscribers[collectionID] = make(map[chan models.Event]struct{})
+	}
+	b.subscribers[collectionID][events] = struct{}{}
+
+	return events, func() {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		b.remove(collectionID, events)
+	}
+}
+
+// Publish passes an event to the subscribers to its collection
+func (b *Broker) Publish(event *models.Event) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for events := range b.subscribers[event.CollectionID] {
+		select {
+		case events <- *event:
+		default:
+			log.Warn(context.Background(), "event stream subscriber is not keeping up, and is unsubscribed", log.Data{"collection_id": event.CollectionID})
+			b.remove(event.CollectionID, events)
+		}
+	}
+}
+
+// Close unsubscribes every subscriber, and closes any later subscription straight away, so that open streams end
+// before the server is shut down
+func (b *Broker) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for collectionID, subscribers := range b.subscribers {
+		for events := range subscribers {
+			b.remove(collectionID, events)
+		}
+	}
+	b.closed = true
+}
+
+// remove closes a subscriber's channel, if it is still subscribed. The mutex must be held.
+func (b *Broker) remove(collectionID string, events chan models.Event) {
+	if _, ok := b.subscribers[collectionID][events]; !ok {
+		return
+	}
+
+	close(events)
+	delete(b.subscribers[collectionID], events)
+	if len(b.subscribers[collectionID]) == 0 {
+		delete(b.subscribers, collectionID)
+	}
+}
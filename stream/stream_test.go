@@ -0,0 +1,82 @@
+package stream_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/dp-collection-api/stream"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const (
+	collectionID      = "93a5e4b7-0a2e-4a8e-9f5c-4c9f1f4b1a2b"
+	otherCollectionID = "0b8e7a52-8a1e-4f0e-b1e6-3d1c6c1f2a77"
+)
+
+func TestBroker(t *testing.T) {
+
+	ctx := context.Background()
+
+	Convey("Given a broker of the events recorded by this instance", t, func() {
+		source := stream.NewLocalSource()
+		broker := stream.NewBroker(source, 2)
+
+		Convey("When a client subscribes to a collection and an event is recorded for it", func() {
+			events, unsubscribe := broker.Subscribe(collectionID)
+			defer unsubscribe()
+			other, unsubscribeOther := broker.Subscribe(otherCollectionID)
+			defer unsubscribeOther()
+
+			err := source.HandleCollectionEvent(ctx, &models.Event{ID: "event-1", Type: models.EventUpdated, CollectionID: collectionID}, nil)
+			So(err, ShouldBeNil)
+
+			Convey("Then the event is passed to the subscriber to the collection only", func() {
+				So(<-events, ShouldResemble, models.Event{ID: "event-1", Type: models.EventUpdated, CollectionID: collectionID})
+				So(other, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When a client unsubscribes", func() {
+			events, unsubscribe := broker.Subscribe(collectionID)
+			unsubscribe()
+			broker.Publish(&models.Event{ID: "event-1", CollectionID: collectionID})
+
+			Convey("Then its channel is closed, and no more events are passed to it", func() {
+				_, ok := <-events
+				So(ok, ShouldBeFalse)
+			})
+		})
+
+		Convey("When a subscriber does not keep up with its events", func() {
+			events, unsubscribe := broker.Subscribe(collectionID)
+			defer unsubscribe()
+			for _, id := range []string{"event-1", "event-2", "event-3"} {
+				broker.Publish(&models.Event{ID: id, CollectionID: collectionID})
+			}
+
+			Convey("Then it is passed the events that fit its buffer, and then its channel is closed", func() {
+				So((<-events).ID, ShouldEqual, "event-1")
+				So((<-events).ID, ShouldEqual, "event-2")
+				_, ok := <-events
+				So(ok, ShouldBeFalse)
+			})
+		})
+
+		Convey("When the broker is closed", func() {
+			events, unsubscribe := broker.Subscribe(collectionID)
+			defer unsubscribe()
+			broker.Close()
+
+			Convey("Then every subscription is closed, including any later subscription", func() {
+				_, ok := <-events
+				So(ok, ShouldBeFalse)
+
+				later, _ := broker.Subscribe(collectionID)
+				_, ok = <-later
+				So(ok, ShouldBeFalse)
+			})
+		})
+	})
+}
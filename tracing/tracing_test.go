@@ -0,0 +1,80 @@
+package tracing_test
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/ONSdigital/dp-collection-api/config"
+	"github.com/ONSdigital/dp-collection-api/tracing"
+	"go.opentelemetry.io/otel"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestInit(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given no exporter is configured", t, func() {
+		cfg := &config.Config{OTExporter: tracing.ExporterNone}
+
+		Convey("When tracing is initialised", func() {
+			shutdown, err := tracing.Init(ctx, cfg)
+
+			Convey("Then no error is returned", func() {
+				So(err, ShouldBeNil)
+				So(shutdown(ctx), ShouldBeNil)
+			})
+
+			Convey("Then W3C trace context is propagated", func() {
+				So(otel.GetTextMapPropagator().Fields(), ShouldContain, "traceparent")
+			})
+		})
+	})
+
+	Convey("Given the file exporter is configured", t, func() {
+		path := filepath.Join(t.TempDir(), "traces.json")
+		cfg := &config.Config{OTExporter: tracing.ExporterFile, OTExporterFilePath: path, OTServiceName: "dp-collection-api"}
+
+		Convey("When a span is created and tracing is shut down", func() {
+			shutdown, err := tracing.Init(ctx, cfg)
+			So(err, ShouldBeNil)
+
+			_, span := otel.Tracer("test").Start(ctx, "test-span")
+			span.End()
+			So(shutdown(ctx), ShouldBeNil)
+
+			Convey("Then the span is written to the file", func() {
+				b, err := ioutil.ReadFile(path)
+				So(err, ShouldBeNil)
+				So(string(b), ShouldContainSubstring, `"Name":"test-span"`)
+				So(string(b), ShouldContainSubstring, "dp-collection-api")
+			})
+		})
+	})
+
+	Convey("Given the file exporter is configured with a path that cannot be written", t, func() {
+		cfg := &config.Config{OTExporter: tracing.ExporterFile, OTExporterFilePath: filepath.Join(t.TempDir(), "missing", "traces.json")}
+
+		Convey("When tracing is initialised", func() {
+			_, err := tracing.Init(ctx, cfg)
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+
+	Convey("Given an unknown exporter is configured", t, func() {
+		cfg := &config.Config{OTExporter: "carrier-pigeon"}
+
+		Convey("When tracing is initialised", func() {
+			_, err := tracing.Init(ctx, cfg)
+
+			Convey("Then the expected error is returned", func() {
+				So(err, ShouldEqual, tracing.ErrUnknownExporter)
+			})
+		})
+	})
+}
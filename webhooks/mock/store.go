@@ -0,0 +1,302 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/dp-collection-api/webhooks"
+	"sync"
+	"time"
+)
+
+// Ensure, that StoreMock does implement webhooks.Store.
+// If this is not the case, regenerate this file with moq.
+var _ webhooks.Store = &StoreMock{}
+
+// StoreMock is a mock implementation of webhooks.Store.
+//
+//	func TestSomethingThatUsesStore(t *testing.T) {
+//
+//		// make and configure a mocked webhooks.Store
+//		mockedStore := &StoreMock{
+//			AddDeliveryFunc: func(ctx context.Context, delivery *models.Delivery) error {
+//				panic("mock out the AddDelivery method")
+//			},
+//			ClaimDeliveriesFunc: func(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Delivery, error) {
+//				panic("mock out the ClaimDeliveries method")
+//			},
+//			GetSubscriptionByIDFunc: func(ctx context.Context, id string) (*models.Subscription, error) {
+//				panic("mock out the GetSubscriptionByID method")
+//			},
+//			GetSubscriptionsFunc: func(ctx context.Context, offset int, limit int) ([]models.Subscription, int, error) {
+//				panic("mock out the GetSubscriptions method")
+//			},
+//			UpdateDeliveryFunc: func(ctx context.Context, delivery *models.Delivery) error {
+//				panic("mock out the UpdateDelivery method")
+//			},
+//		}
+//
+//		// use mockedStore in code that requires webhooks.Store
+//		// and then make assertions.
+//
+//	}
+type StoreMock struct {
+	// AddDeliveryFunc mocks the AddDelivery method.
+	AddDeliveryFunc func(ctx context.Context, delivery *models.Delivery) error
+
+	// ClaimDeliveriesFunc mocks the ClaimDeliveries method.
+	ClaimDeliveriesFunc func(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Delivery, error)
+
+	// GetSubscriptionByIDFunc mocks the GetSubscriptionByID method.
+	GetSubscriptionByIDFunc func(ctx context.Context, id string) (*models.Subscription, error)
+
+	// GetSubscriptionsFunc mocks the GetSubscriptions method.
+	GetSubscriptionsFunc func(ctx context.Context, offset int, limit int) ([]models.Subscription, int, error)
+
+	// UpdateDeliveryFunc mocks the UpdateDelivery method.
+	UpdateDeliveryFunc func(ctx context.Context, delivery *models.Delivery) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// AddDelivery holds details about calls to the AddDelivery method.
+		AddDelivery []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Delivery is the delivery argument value.
+			Delivery *models.Delivery
+		}
+		// ClaimDeliveries holds details about calls to the ClaimDeliveries method.
+		ClaimDeliveries []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Now is the now argument value.
+			Now time.Time
+			// Lease is the lease argument value.
+			Lease time.Duration
+			// Limit is the limit argument value.
+			Limit int
+		}
+		// GetSubscriptionByID holds details about calls to the GetSubscriptionByID method.
+		GetSubscriptionByID []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// GetSubscriptions holds details about calls to the GetSubscriptions method.
+		GetSubscriptions []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Offset is the offset argument value.
+			Offset int
+			// Limit is the limit argument value.
+			Limit int
+		}
+		// UpdateDelivery holds details about calls to the UpdateDelivery method.
+		UpdateDelivery []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Delivery is the delivery argument value.
+			Delivery *models.Delivery
+		}
+	}
+	lockAddDelivery         sync.RWMutex
+	lockClaimDeliveries     sync.RWMutex
+	lockGetSubscriptionByID sync.RWMutex
+	lockGetSubscriptions    sync.RWMutex
+	lockUpdateDelivery      sync.RWMutex
+}
+
+// AddDelivery calls AddDeliveryFunc.
+func (mock *StoreMock) AddDelivery(ctx context.Context, delivery *models.Delivery) error {
+	if mock.AddDeliveryFunc == nil {
+		panic("StoreMock.AddDeliveryFunc: method is nil but Store.AddDelivery was just called")
+	}
+	callInfo := struct {
+		Ctx      context.Context
+		Delivery *models.Delivery
+	}{
+		Ctx:      ctx,
+		Delivery: delivery,
+	}
+	mock.lockAddDelivery.Lock()
+	mock.calls.AddDelivery = append(mock.calls.AddDelivery, callInfo)
+	mock.lockAddDelivery.Unlock()
+	return mock.AddDeliveryFunc(ctx, delivery)
+}
+
+// AddDeliveryCalls gets all the calls that were made to AddDelivery.
+// Check the length with:
+//
+//	len(mockedStore.AddDeliveryCalls())
+func (mock *StoreMock) AddDeliveryCalls() []struct {
+	Ctx      context.Context
+	Delivery *models.Delivery
+} {
+	var calls []struct {
+		Ctx      context.Context
+		Delivery *models.Delivery
+	}
+	mock.lockAddDelivery.RLock()
+	calls = mock.calls.AddDelivery
+	mock.lockAddDelivery.RUnlock()
+	return calls
+}
+
+// ClaimDeliveries calls ClaimDeliveriesFunc.
+func (mock *StoreMock) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Delivery, error) {
+	if mock.ClaimDeliveriesFunc == nil {
+		panic("StoreMock.ClaimDeliveriesFunc: method is nil but Store.ClaimDeliveries was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Now   time.Time
+		Lease time.Duration
+		Limit int
+	}{
+		Ctx:   ctx,
+		Now:   now,
+		Lease: lease,
+		Limit: limit,
+	}
+	mock.lockClaimDeliveries.Lock()
+	mock.calls.ClaimDeliveries = append(mock.calls.ClaimDeliveries, callInfo)
+	mock.lockClaimDeliveries.Unlock()
+	return mock.ClaimDeliveriesFunc(ctx, now, lease, limit)
+}
+
+// ClaimDeliveriesCalls gets all the calls that were made to ClaimDeliveries.
+// Check the length with:
+//
+//	len(mockedStore.ClaimDeliveriesCalls())
+func (mock *StoreMock) ClaimDeliveriesCalls() []struct {
+	Ctx   context.Context
+	Now   time.Time
+	Lease time.Duration
+	Limit int
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Now   time.Time
+		Lease time.Duration
+		Limit int
+	}
+	mock.lockClaimDeliveries.RLock()
+	calls = mock.calls.ClaimDeliveries
+	mock.lockClaimDeliveries.RUnlock()
+	return calls
+}
+
+// GetSubscriptionByID calls GetSubscriptionByIDFunc.
+func (mock *StoreMock) GetSubscriptionByID(ctx context.Context, id string) (*models.Subscription, error) {
+	if mock.GetSubscriptionByIDFunc == nil {
+		panic("StoreMock.GetSubscriptionByIDFunc: method is nil but Store.GetSubscriptionByID was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockGetSubscriptionByID.Lock()
+	mock.calls.GetSubscriptionByID = append(mock.calls.GetSubscriptionByID, callInfo)
+	mock.lockGetSubscriptionByID.Unlock()
+	return mock.GetSubscriptionByIDFunc(ctx, id)
+}
+
+// GetSubscriptionByIDCalls gets all the calls that were made to GetSubscriptionByID.
+// Check the length with:
+//
+//	len(mockedStore.GetSubscriptionByIDCalls())
+func (mock *StoreMock) GetSubscriptionByIDCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockGetSubscriptionByID.RLock()
+	calls = mock.calls.GetSubscriptionByID
+	mock.lockGetSubscriptionByID.RUnlock()
+	return calls
+}
+
+// GetSubscriptions calls GetSubscriptionsFunc.
+func (mock *StoreMock) GetSubscriptions(ctx context.Context, offset int, limit int) ([]models.Subscription, int, error) {
+	if mock.GetSubscriptionsFunc == nil {
+		panic("StoreMock.GetSubscriptionsFunc: method is nil but Store.GetSubscriptions was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Offset int
+		Limit  int
+	}{
+		Ctx:    ctx,
+		Offset: offset,
+		Limit:  limit,
+	}
+	mock.lockGetSubscriptions.Lock()
+	mock.calls.GetSubscriptions = append(mock.calls.GetSubscriptions, callInfo)
+	mock.lockGetSubscriptions.Unlock()
+	return mock.GetSubscriptionsFunc(ctx, offset, limit)
+}
+
+// GetSubscriptionsCalls gets all the calls that were made to GetSubscriptions.
+// Check the length with:
+//
+//	len(mockedStore.GetSubscriptionsCalls())
+func (mock *StoreMock) GetSubscriptionsCalls() []struct {
+	Ctx    context.Context
+	Offset int
+	Limit  int
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Offset int
+		Limit  int
+	}
+	mock.lockGetSubscriptions.RLock()
+	calls = mock.calls.GetSubscriptions
+	mock.lockGetSubscriptions.RUnlock()
+	return calls
+}
+
+// UpdateDelivery calls UpdateDeliveryFunc.
+func (mock *StoreMock) UpdateDelivery(ctx context.Context, delivery *models.Delivery) error {
+	if mock.UpdateDeliveryFunc == nil {
+		panic("StoreMock.UpdateDeliveryFunc: method is nil but Store.UpdateDelivery was just called")
+	}
+	callInfo := struct {
+		Ctx      context.Context
+		Delivery *models.Delivery
+	}{
+		Ctx:      ctx,
+		Delivery: delivery,
+	}
+	mock.lockUpdateDelivery.Lock()
+	mock.calls.UpdateDelivery = append(mock.calls.UpdateDelivery, callInfo)
+	mock.lockUpdateDelivery.Unlock()
+	return mock.UpdateDeliveryFunc(ctx, delivery)
+}
+
+// UpdateDeliveryCalls gets all the calls that were made to UpdateDelivery.
+// Check the length with:
+//
+//	len(mockedStore.UpdateDeliveryCalls())
+func (mock *StoreMock) UpdateDeliveryCalls() []struct {
+	Ctx      context.Context
+	Delivery *models.Delivery
+} {
+	var calls []struct {
+		Ctx      context.Context
+		Delivery *models.Delivery
+	}
+	mock.lockUpdateDelivery.RLock()
+	calls = mock.calls.UpdateDelivery
+	mock.lockUpdateDelivery.RUnlock()
+	return calls
+}
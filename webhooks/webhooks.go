@@ -0,0 +1,315 @@
+// Package webhooks delivers collection lifecycle events to the URLs registered by subscriptions. Each event is stored
+// as a delivery for every subscription that matches it, and a worker makes the deliveries that are due, retrying
+// failed deliveries with exponential backoff until they succeed or run out of attempts. The deliveries are stored in
+// the transaction that records the event when there is an outbox. Without one they are stored after the write, so an
+// event whose deliveries cannot be stored, or that is recorded as the service stops, is never delivered.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/gofrs/uuid"
+)
+
+//go:generate moq -out mock/store.go -pkg mock . Store
+
+// Headers sent with each delivery. The signature is the hex encoded HMAC-SHA256 of the timestamp, a full stop and the
+// body, keyed with the subscription's secret, prefixed with "sha256=".
+const (
+	HeaderDeliveryID = "X-Webhook-ID"
+	HeaderEvent      = "X-Webhook-Event"
+	HeaderTimestamp  = "X-Webhook-Timestamp"
+	HeaderSignature  = "X-Webhook-Signature"
+)
+
+const (
+	// subscriptionsPageSize is the number of subscriptions read at a time when an event is stored for delivery
+	subscriptionsPageSize = 100
+
+	// claimLimit is the number of due deliveries claimed at a time
+	claimLimit = 20
+
+	// leaseMargin is added to the delivery timeout to give the time a claimed delivery is kept from other workers,
+	// after which a delivery claimed by a worker that stopped is made again
+	leaseMargin = 30 * time.Second
+)
+
+// Now returns the current time, and can be replaced in tests
+var Now = time.Now
+
+// Store defines the required methods from the data store of subscriptions and their deliveries
+type Store interface {
+	GetSubscriptions(ctx context.Context, offset, limit int) (subscriptions []models.Subscription, totalCount int, err error)
+	GetSubscriptionByID(ctx context.Context, id string) (*models.Subscription, error)
+	AddDelivery(ctx context.Context, delivery *models.Delivery) error
+	UpdateDelivery(ctx context.Context, delivery *models.Delivery) error
+	ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Delivery, error)
+}
+
+// Config defines how deliveries are made and retried. The delay before the first retry is the initial backoff, and
+// it doubles for each retry after that, up to the maximum backoff. Deliveries are only made to public addresses,
+// unless private networks are allowed.
+type Config struct {
+	MaxAttempts          int
+	InitialBackoff       time.Duration
+	MaxBackoff           time.Duration
+	PollInterval         time.Duration
+	Timeout              time.Duration
+	AllowPrivateNetworks bool
+}
+
+// Dispatcher stores a delivery of each lifecycle event for the subscriptions that match it, and makes the deliveries
+// in the background once started
+type Dispatcher struct {
+	store  Store
+	cfg    Config
+	client *http.Client
+	wake   chan struct{}
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New returns a dispatcher that stores and makes deliveries using the store
+func New(store Store, cfg Config) *Dispatcher {
+	return &Dispatcher{
+		store:  store,
+		cfg:    cfg,
+		client: newClient(cfg),
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// HandleCollectionEvent stores a delivery of the event for every subscription that matches it. It is called in the
+// transaction that records the event, if there is one, so that the deliveries are stored if and only if the write is
+// committed.
+func (d *Dispatcher) HandleCollectionEvent(ctx context.Context, event *models.Event, collection *models.Collection) error {
+	now := Now().UTC()
+	payload := models.WebhookPayload{
+		Event:      event.Type,
+		Date:       event.Date,
+		Collection: *collection,
+	}
+
+	for offset := 0; ; {
+		subscriptions, totalCount, err := d.store.GetSubscriptions(ctx, offset, subscriptionsPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, subscription := range subscriptions {
+			if !subscription.Matches(event.Type) {
+				continue
+			}
+
+			id, err := uuid.NewV4()
+			if err != nil {
+				return err
+			}
+
+			delivery := &models.Delivery{
+				ID:             id.String(),
+				SubscriptionID: subscription.ID,
+				Payload:        payload,
+				Status:         models.DeliveryPending,
+				NextAttemptAt:  now,
+				CreatedAt:      now,
+			}
+			if err := d.store.AddDelivery(ctx, delivery); err != nil {
+				return err
+			}
+		}
+
+		offset += len(subscriptions)
+		if len(subscriptions) == 0 || offset >= totalCount {
+			break
+		}
+	}
+	return nil
+}
+
+// Committed wakes the worker once the write has been committed, so that its deliveries are made straight away
+func (d *Dispatcher) Committed(ctx context.Context) {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start starts the worker that makes the deliveries that are due, until Close is called
+func (d *Dispatcher) Start(ctx context.Context) {
+	ctx, d.cancel = context.WithCancel(ctx)
+	d.done = make(chan struct{})
+
+	go func() {
+		defer close(d.done)
+
+		ticker := time.NewTicker(d.cfg.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := d.DeliverDue(ctx); err != nil && ctx.Err() == nil {
+				log.Error(ctx, "failed to make webhook deliveries", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-d.wake:
+			}
+		}
+	}()
+}
+
+// Close stops the worker, waiting for any delivery in progress to finish. A delivery interrupted by Close is made
+// again once its claim has expired.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	if d.cancel == nil {
+		return nil
+	}
+	d.cancel()
+
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// DeliverDue makes every delivery that is due, claiming them from the store a few at a time so that other instances
+// can share the work
+func (d *Dispatcher) DeliverDue(ctx context.Context) error {
+	lease := d.cfg.Timeout + leaseMargin
+
+	for {
+		deliveries, err := d.store.ClaimDeliveries(ctx, Now().UTC(), lease, claimLimit)
+		if err != nil {
+			return err
+		}
+
+		for i := range deliveries {
+			if err := d.attempt(ctx, &deliveries[i]); err != nil {
+				return err
+			}
+		}
+
+		if len(deliveries) < claimLimit {
+			return nil
+		}
+	}
+}
+
+// attempt makes a delivery, and stores its outcome
+func (d *Dispatcher) attempt(ctx context.Context, delivery *models.Delivery) error {
+	logData := log.Data{"delivery_id": delivery.ID, "subscription_id": delivery.SubscriptionID}
+
+	subscription, err := d.store.GetSubscriptionByID(ctx, delivery.SubscriptionID)
+	if err == collections.ErrSubscriptionNotFound {
+		// the subscription was removed after the delivery was claimed, and its deliveries with it
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	statusCode, err := d.send(ctx, subscription, delivery)
+	if ctx.Err() != nil {
+		// the worker is stopping, so the attempt is not counted
+		return ctx.Err()
+	}
+
+	now := Now().UTC()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.LastStatusCode = statusCode
+	delivery.LastError = ""
+
+	switch {
+	case err == nil:
+		delivery.Status = models.DeliveryDelivered
+		log.Info(ctx, "webhook delivered", logData)
+	case delivery.Attempts >= d.cfg.MaxAttempts:
+		delivery.Status = models.DeliveryDead
+		delivery.LastError = err.Error()
+		logData["error"] = err.Error()
+		log.Warn(ctx, "webhook delivery failed for the last time", logData)
+	default:
+		delivery.NextAttemptAt = now.Add(d.backoff(delivery.Attempts))
+		delivery.LastError = err.Error()
+		logData["error"] = err.Error()
+		logData["next_attempt_at"] = delivery.NextAttemptAt
+		log.Info(ctx, "webhook delivery failed, and will be retried", logData)
+	}
+
+	return d.store.UpdateDelivery(ctx, delivery)
+}
+
+// send posts the delivery's payload to the subscription's URL, returning the status code of the response, if any.
+// Any status other than 2xx is an error.
+func (d *Dispatcher) send(ctx context.Context, subscription *models.Subscription, delivery *models.Delivery) (int, error) {
+	body, err := json.Marshal(delivery.Payload)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderDeliveryID, delivery.ID)
+	req.Header.Set(HeaderEvent, delivery.Payload.Event)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(subscription.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before the next attempt, after the given number of attempts
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.InitialBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.cfg.MaxBackoff {
+			return d.cfg.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// Sign returns the signature of a delivery, as sent in the X-Webhook-Signature header. A subscriber verifies a
+// delivery by calculating the signature of the body and timestamp it received, and comparing the two in constant time.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,341 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/collections"
+	"github.com/ONSdigital/dp-collection-api/memory"
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/ONSdigital/dp-collection-api/webhooks"
+	"github.com/ONSdigital/dp-collection-api/webhooks/mock"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var cfg = webhooks.Config{
+	MaxAttempts:    3,
+	InitialBackoff: time.Minute,
+	MaxBackoff:     90 * time.Second,
+	PollInterval:   time.Hour,
+	Timeout:        time.Second,
+
+	// the subscribers in these tests listen on the loopback address
+	AllowPrivateNetworks: true,
+}
+
+// received is a request received by a subscriber
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+// subscriber returns a server that records each request it receives, and responds with the given status
+func subscriber(status *int, requests *[]received) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		*requests = append(*requests, received{header: req.Header, body: body})
+		w.WriteHeader(*status)
+	}))
+}
+
+func deliveries(store *memory.Store) []models.Delivery {
+	values, _, err := store.GetDeliveries(context.Background(), collections.DeliveriesQueryParams{Limit: 100})
+	So(err, ShouldBeNil)
+	return values
+}
+
+func TestDispatcher(t *testing.T) {
+
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	webhooks.Now = func() time.Time { return now }
+	defer func() { webhooks.Now = time.Now }()
+	event := &models.Event{Type: models.EventPublished, Date: now, CollectionID: "id1"}
+	collection := &models.Collection{ID: "id1", Name: "collection 1"}
+
+	Convey("Given subscriptions to every event, to published events and to created events", t, func() {
+		ctx := context.Background()
+		status := http.StatusOK
+		var requests []received
+		server := subscriber(&status, &requests)
+		defer server.Close()
+
+		store := memory.New()
+		So(store.AddSubscription(ctx, &models.Subscription{ID: "sub1", URL: server.URL, EventTypes: []string{}, Secret: "secret1", CreatedAt: now}), ShouldBeNil)
+		So(store.AddSubscription(ctx, &models.Subscription{ID: "sub2", URL: server.URL, EventTypes: []string{models.EventPublished}, Secret: "secret2", CreatedAt: now}), ShouldBeNil)
+		So(store.AddSubscription(ctx, &models.Subscription{ID: "sub3", URL: server.URL, EventTypes: []string{models.EventCreated}, Secret: "secret3", CreatedAt: now}), ShouldBeNil)
+		dispatcher := webhooks.New(store, cfg)
+
+		Convey("When a published event is handled", func() {
+			So(dispatcher.HandleCollectionEvent(ctx, event, collection), ShouldBeNil)
+
+			Convey("Then a pending delivery is stored for each matching subscription", func() {
+				values := deliveries(store)
+				So(values, ShouldHaveLength, 2)
+				for _, delivery := range values {
+					So(delivery.SubscriptionID, ShouldBeIn, "sub1", "sub2")
+					So(delivery.Status, ShouldEqual, models.DeliveryPending)
+					So(delivery.Payload.Event, ShouldEqual, models.EventPublished)
+					So(delivery.Payload.Collection.Name, ShouldEqual, "collection 1")
+				}
+				So(requests, ShouldBeEmpty)
+			})
+
+			Convey("And the deliveries are made", func() {
+				So(dispatcher.DeliverDue(ctx), ShouldBeNil)
+
+				Convey("Then each subscriber receives the payload, signed with its secret", func() {
+					So(requests, ShouldHaveLength, 2)
+					for _, request := range requests {
+						var payload models.WebhookPayload
+						So(json.Unmarshal(request.body, &payload), ShouldBeNil)
+						So(payload.Event, ShouldEqual, models.EventPublished)
+						So(payload.Collection.ID, ShouldEqual, "id1")
+
+						So(request.header.Get(webhooks.HeaderEvent), ShouldEqual, models.EventPublished)
+						So(request.header.Get(webhooks.HeaderDeliveryID), ShouldNotBeEmpty)
+						timestamp := request.header.Get(webhooks.HeaderTimestamp)
+						So(timestamp, ShouldEqual, strconv.FormatInt(now.Unix(), 10))
+						So(request.header.Get(webhooks.HeaderSignature), ShouldBeIn,
+							webhooks.Sign("secret1", timestamp, request.body),
+							webhooks.Sign("secret2", timestamp, request.body))
+					}
+				})
+
+				Convey("Then the deliveries are marked as delivered", func() {
+					for _, delivery := range deliveries(store) {
+						So(delivery.Status, ShouldEqual, models.DeliveryDelivered)
+						So(delivery.Attempts, ShouldEqual, 1)
+						So(delivery.LastStatusCode, ShouldEqual, http.StatusOK)
+					}
+				})
+			})
+		})
+
+		Convey("When a delivery is rejected by its subscriber", func() {
+			status = http.StatusInternalServerError
+			So(dispatcher.HandleCollectionEvent(ctx, &models.Event{Type: models.EventCreated, Date: now}, collection), ShouldBeNil)
+			So(dispatcher.DeliverDue(ctx), ShouldBeNil)
+
+			Convey("Then it is retried after the initial backoff", func() {
+				values := deliveries(store)
+				So(values, ShouldHaveLength, 2)
+				for _, delivery := range values {
+					So(delivery.Status, ShouldEqual, models.DeliveryPending)
+					So(delivery.Attempts, ShouldEqual, 1)
+					So(delivery.LastStatusCode, ShouldEqual, http.StatusInternalServerError)
+					So(delivery.LastError, ShouldEqual, "unexpected status code 500")
+					So(delivery.NextAttemptAt.Equal(now.Add(time.Minute)), ShouldBeTrue)
+				}
+			})
+
+			Convey("Then it is not retried before the backoff has passed", func() {
+				So(dispatcher.DeliverDue(ctx), ShouldBeNil)
+				So(requests, ShouldHaveLength, 2)
+			})
+
+			Convey("And it is rejected again", func() {
+				now = now.Add(time.Minute)
+				So(dispatcher.DeliverDue(ctx), ShouldBeNil)
+
+				Convey("Then the backoff doubles, up to the maximum backoff", func() {
+					for _, delivery := range deliveries(store) {
+						So(delivery.Attempts, ShouldEqual, 2)
+						So(delivery.NextAttemptAt.Equal(now.Add(90*time.Second)), ShouldBeTrue)
+					}
+				})
+
+				Convey("And it is rejected for the last time", func() {
+					now = now.Add(90 * time.Second)
+					So(dispatcher.DeliverDue(ctx), ShouldBeNil)
+
+					Convey("Then it is dead, and not retried", func() {
+						for _, delivery := range deliveries(store) {
+							So(delivery.Status, ShouldEqual, models.DeliveryDead)
+							So(delivery.Attempts, ShouldEqual, 3)
+						}
+
+						now = now.Add(time.Hour)
+						So(dispatcher.DeliverDue(ctx), ShouldBeNil)
+						So(requests, ShouldHaveLength, 6)
+					})
+				})
+			})
+
+			Reset(func() {
+				now = time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+			})
+		})
+
+		Convey("When the dispatcher is started and an event is handled", func() {
+			dispatcher.Start(ctx)
+			So(dispatcher.HandleCollectionEvent(ctx, event, collection), ShouldBeNil)
+			dispatcher.Committed(ctx)
+
+			Convey("Then the deliveries are made straight away", func() {
+				delivered := func() bool {
+					for _, delivery := range deliveries(store) {
+						if delivery.Status != models.DeliveryDelivered {
+							return false
+						}
+					}
+					return true
+				}
+				for i := 0; i < 100 && !delivered(); i++ {
+					time.Sleep(10 * time.Millisecond)
+				}
+				So(dispatcher.Close(ctx), ShouldBeNil)
+				So(delivered(), ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func TestDispatcher_addresses(t *testing.T) {
+
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	webhooks.Now = func() time.Time { return now }
+	defer func() { webhooks.Now = time.Now }()
+	collection := &models.Collection{ID: "id1", Name: "collection 1"}
+
+	Convey("Given a subscription to a subscriber on the loopback address", t, func() {
+		ctx := context.Background()
+		status := http.StatusOK
+		var requests []received
+		server := subscriber(&status, &requests)
+		defer server.Close()
+
+		store := memory.New()
+		So(store.AddSubscription(ctx, &models.Subscription{ID: "sub1", URL: server.URL, EventTypes: []string{}, Secret: "secret1", CreatedAt: now}), ShouldBeNil)
+
+		Convey("When a delivery is made without allowing private networks", func() {
+			publicOnly := cfg
+			publicOnly.AllowPrivateNetworks = false
+			dispatcher := webhooks.New(store, publicOnly)
+			So(dispatcher.HandleCollectionEvent(ctx, &models.Event{Type: models.EventCreated, Date: now}, collection), ShouldBeNil)
+			So(dispatcher.DeliverDue(ctx), ShouldBeNil)
+
+			Convey("Then no request is made, and the delivery fails", func() {
+				So(requests, ShouldBeEmpty)
+
+				values := deliveries(store)
+				So(values, ShouldHaveLength, 1)
+				So(values[0].Status, ShouldEqual, models.DeliveryPending)
+				So(values[0].LastError, ShouldContainSubstring, webhooks.ErrPrivateAddress.Error())
+			})
+		})
+
+		Convey("When the subscriber redirects the delivery", func() {
+			redirect := httptest.NewServer(http.RedirectHandler(server.URL, http.StatusFound))
+			defer redirect.Close()
+			So(store.DeleteSubscription(ctx, "sub1"), ShouldBeNil)
+			So(store.AddSubscription(ctx, &models.Subscription{ID: "sub2", URL: redirect.URL, EventTypes: []string{}, Secret: "secret2", CreatedAt: now}), ShouldBeNil)
+
+			dispatcher := webhooks.New(store, cfg)
+			So(dispatcher.HandleCollectionEvent(ctx, &models.Event{Type: models.EventCreated, Date: now}, collection), ShouldBeNil)
+			So(dispatcher.DeliverDue(ctx), ShouldBeNil)
+
+			Convey("Then the redirect is not followed, and the delivery fails", func() {
+				So(requests, ShouldBeEmpty)
+
+				values := deliveries(store)
+				So(values, ShouldHaveLength, 1)
+				So(values[0].Status, ShouldEqual, models.DeliveryPending)
+				So(values[0].LastStatusCode, ShouldEqual, http.StatusFound)
+			})
+		})
+	})
+}
+
+func TestIsPublicHost(t *testing.T) {
+
+	Convey("Given hosts that are not public", t, func() {
+		for _, host := range []string{"localhost", "api.localhost", "127.0.0.1", "::1", "169.254.169.254", "10.0.0.1", "172.16.0.1", "192.168.1.1", "0.0.0.0", "fd00::1", "fe80::1"} {
+
+			Convey("Then "+host+" is rejected", func() {
+				So(webhooks.IsPublicHost(host), ShouldBeFalse)
+			})
+		}
+	})
+
+	Convey("Given hosts that are public, or are names", t, func() {
+		for _, host := range []string{"example.com", "8.8.8.8", "2001:4860:4860::8888"} {
+
+			Convey("Then "+host+" is accepted", func() {
+				So(webhooks.IsPublicHost(host), ShouldBeTrue)
+			})
+		}
+	})
+}
+
+func TestDispatcher_storeErrors(t *testing.T) {
+
+	Convey("Given a store that cannot be read", t, func() {
+		store := &mock.StoreMock{
+			GetSubscriptionsFunc: func(ctx context.Context, offset, limit int) ([]models.Subscription, int, error) {
+				return nil, 0, errors.New("db is broken")
+			},
+			ClaimDeliveriesFunc: func(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Delivery, error) {
+				return nil, errors.New("db is broken")
+			},
+		}
+		dispatcher := webhooks.New(store, cfg)
+
+		Convey("When an event is handled", func() {
+			err := dispatcher.HandleCollectionEvent(context.Background(), &models.Event{Type: models.EventCreated}, &models.Collection{})
+
+			Convey("Then the error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When the due deliveries are made", func() {
+			err := dispatcher.DeliverDue(context.Background())
+
+			Convey("Then the error is returned, and the claim lasts longer than the timeout", func() {
+				So(err, ShouldNotBeNil)
+				So(store.ClaimDeliveriesCalls(), ShouldHaveLength, 1)
+				So(store.ClaimDeliveriesCalls()[0].Lease, ShouldBeGreaterThan, cfg.Timeout)
+			})
+		})
+	})
+
+	Convey("Given a delivery to a subscription that has been removed", t, func() {
+		store := &mock.StoreMock{
+			ClaimDeliveriesFunc: func(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.Delivery, error) {
+				return []models.Delivery{{ID: "d1", SubscriptionID: "sub1", Status: models.DeliveryPending}}, nil
+			},
+			GetSubscriptionByIDFunc: func(ctx context.Context, id string) (*models.Subscription, error) {
+				return nil, collections.ErrSubscriptionNotFound
+			},
+		}
+
+		Convey("When the due deliveries are made", func() {
+			err := webhooks.New(store, cfg).DeliverDue(context.Background())
+
+			Convey("Then the delivery is skipped", func() {
+				So(err, ShouldBeNil)
+				So(store.UpdateDeliveryCalls(), ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+func TestSign(t *testing.T) {
+
+	Convey("Given a secret, a timestamp and a body", t, func() {
+		body := []byte(`{"event":"CREATED"}`)
+
+		Convey("Then the signature is the HMAC-SHA256 of the timestamp and the body", func() {
+			So(webhooks.Sign("secret", "1622548800", body), ShouldEqual,
+				"sha256=2710a1bfc880da6579fd710d984192f7713535c189bfc4c7b40e2094201b6ca9")
+		})
+	})
+}
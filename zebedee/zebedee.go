@@ -0,0 +1,279 @@
+// Package zebedee converts between the collections of Zebedee, the legacy publishing system, and the collection API's
+// models. Collections are read from Zebedee's collections directory, so that they can be imported without retyping
+// them, and converted to and from the JSON of Zebedee's collection endpoints, for clients that still call them.
+package zebedee
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/models"
+	"github.com/gofrs/uuid"
+)
+
+// Zebedee collection types
+const (
+	TypeManual    = "manual"
+	TypeScheduled = "scheduled"
+)
+
+// dateLayout is the layout of the dates in the descriptions returned for Zebedee clients
+const dateLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// dateLayouts are the layouts of the dates written by Zebedee, which has used both RFC 3339 and Java's
+// yyyy-MM-dd'T'HH:mm:ss.SSSZ
+var dateLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.000-0700"}
+
+// Errors returned for a Zebedee collection that cannot be converted to a collection
+var (
+	ErrUnknownType         = errors.New("the collection type must be manual or scheduled")
+	ErrPublishDateRequired = errors.New("a scheduled collection must have a publish date")
+	ErrInvalidPublishDate  = errors.New("the publish date is not a valid date")
+	ErrUnschedule          = errors.New("a scheduled collection cannot be made manual, as its publish date cannot be removed")
+)
+
+// namespace is the namespace of the IDs generated from Zebedee collection IDs. It must never change, so that a
+// collection is given the same ID each time it is read, and importing it again finds the collection already imported.
+var namespace = uuid.Must(uuid.FromString("5b2f7f4e-1f7a-4c55-8d8e-3c1a6f0b9e27"))
+
+// Now returns the current time, and can be replaced in tests
+var Now = time.Now
+
+// Collection is a collection's description, as written by Zebedee to <collection name>.json in its collections
+// directory, and sent to and returned from its collection endpoints. Only the fields that are mapped, or reported as
+// not mapped, are read.
+type Collection struct {
+	ID             string   `json:"id"`
+	Name           string   `json:"name"`
+	Type           string   `json:"type"`
+	PublishDate    string   `json:"publishDate,omitempty"`
+	Teams          []string `json:"teams"`
+	Events         []Event  `json:"events"`
+	InProgressURIs []string `json:"inProgressUris"`
+	CompleteURIs   []string `json:"completeUris"`
+	ReviewedURIs   []string `json:"reviewedUris"`
+}
+
+// Event is an entry in a Zebedee collection's history
+type Event struct {
+	Date  string `json:"date"`
+	Type  string `json:"type"`
+	Email string `json:"email"`
+}
+
+// Issue is something in a Zebedee collection that could not be mapped. The field is the name of the field in the
+// Zebedee collection, if the issue relates to one.
+type Issue struct {
+	File    string `json:"file"`
+	Name    string `json:"name,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// Result is the collections read from a Zebedee collections directory, in the export format of the collection API,
+// and the issues found in them
+type Result struct {
+	Collections []models.ExportedCollection
+	Issues      []Issue
+}
+
+// ReadDir reads every collection in a Zebedee collections directory, in the order of their file names. A file that
+// is not a Zebedee collection, or a collection that cannot be imported at all, is reported as an issue rather than
+// returned as an error, so that one bad file does not stop a migration.
+func ReadDir(dir string) (*Result, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	result := &Result{Collections: []models.ExportedCollection{}}
+
+	for _, entry := range entries {
+		// each collection's content is in a directory alongside its description, and is not read
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		b, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		var collection Collection
+		if err := json.Unmarshal(b, &collection); err != nil {
+			result.Issues = append(result.Issues, Issue{
+				File:    entry.Name(),
+				Message: fmt.Sprintf("not a Zebedee collection, and not imported: %s", err.Error()),
+			})
+			continue
+		}
+
+		exported, issues := Map(&collection)
+		for _, issue := range issues {
+			issue.File = entry.Name()
+			result.Issues = append(result.Issues, issue)
+		}
+		if exported != nil {
+			result.Collections = append(result.Collections, *exported)
+		}
+	}
+
+	return result, nil
+}
+
+// Map maps a Zebedee collection onto a collection and its events, returning an issue for anything that could not be
+// mapped. The collection is nil if it cannot be imported at all.
+//
+// The collection API has no collection types, as the state of a collection is derived from its publish date, so only
+// a scheduled collection with a publish date in the future is given a publish date. It also has no teams, so any teams
+// are reported.
+func Map(collection *Collection) (*models.ExportedCollection, []Issue) {
+	var issues []Issue
+	report := func(field, format string, args ...interface{}) {
+		issues = append(issues, Issue{Name: collection.Name, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(collection.ID) == 0 {
+		report("id", "the collection has no id, and is not imported")
+	}
+	if len(collection.Name) == 0 {
+		report("name", "the collection has no name, and is not imported")
+	}
+	if len(issues) > 0 {
+		return nil, issues
+	}
+
+	exported := &models.ExportedCollection{
+		Collection: models.Collection{
+			ID:   CollectionID(collection.ID),
+			Name: collection.Name,
+		},
+	}
+
+	switch collection.Type {
+	case TypeScheduled:
+		if len(collection.PublishDate) == 0 {
+			report("publishDate", "the scheduled collection has no publish date, and is imported as unscheduled")
+			break
+		}
+		publishDate, err := parseDate(collection.PublishDate)
+		if err != nil {
+			report("publishDate", "the publish date %q is not valid, and the collection is imported as unscheduled", collection.PublishDate)
+			break
+		}
+		if !publishDate.After(Now()) {
+			// a published collection is removed from the collections directory, so this one failed to publish
+			report("publishDate", "the publish date %s has passed without the collection being published, and it is imported as unscheduled", collection.PublishDate)
+			break
+		}
+		exported.PublishDate = &publishDate
+	case TypeManual:
+		if len(collection.PublishDate) > 0 {
+			report("publishDate", "the publish date of a manual collection is not imported")
+		}
+	default:
+		report("type", "the collection type %q is not known, and the collection is imported as unscheduled", collection.Type)
+	}
+
+	if len(collection.Teams) > 0 {
+		report("teams", "the teams %s are not imported, as collections have no teams", strings.Join(collection.Teams, ", "))
+	}
+
+	if pages := len(collection.InProgressURIs) + len(collection.CompleteURIs) + len(collection.ReviewedURIs); pages > 0 {
+		report("", "the content of %d pages is not imported, and remains in Zebedee", pages)
+	}
+
+	for i, event := range collection.Events {
+		date, err := parseDate(event.Date)
+		if err != nil {
+			report("events", "event %d has an invalid date %q, and is not imported", i+1, event.Date)
+			continue
+		}
+		exported.Events = append(exported.Events, models.Event{
+			Type:  event.Type,
+			Email: event.Email,
+			Date:  date,
+		})
+	}
+
+	return exported, issues
+}
+
+// CollectionID returns the ID given to the collection imported from the Zebedee collection with the given ID
+func CollectionID(zebedeeID string) string {
+	return uuid.NewV5(namespace, zebedeeID).String()
+}
+
+// NewCollection returns the description of a collection and its events in the format written by Zebedee. A
+// collection with a publish date is scheduled, and any other collection is manual.
+func NewCollection(collection *models.Collection, events []models.Event) *Collection {
+	description := &Collection{
+		ID:             collection.ID,
+		Name:           collection.Name,
+		Type:           TypeManual,
+		Teams:          []string{},
+		Events:         make([]Event, 0, len(events)),
+		InProgressURIs: []string{},
+		CompleteURIs:   []string{},
+		ReviewedURIs:   []string{},
+	}
+
+	if collection.PublishDate != nil {
+		description.Type = TypeScheduled
+		description.PublishDate = collection.PublishDate.UTC().Format(dateLayout)
+	}
+
+	for _, event := range events {
+		description.Events = append(description.Events, Event{
+			Date:  event.Date.UTC().Format(dateLayout),
+			Type:  event.Type,
+			Email: event.Email,
+		})
+	}
+
+	return description
+}
+
+// Model returns the collection that a Zebedee client asked for, with the name and the publish date of a scheduled
+// collection. The publish date of a manual collection, and the fields that collections do not have, are ignored.
+func (c *Collection) Model() (*models.Collection, error) {
+	collection := &models.Collection{
+		ID:   c.ID,
+		Name: c.Name,
+	}
+
+	switch c.Type {
+	case TypeScheduled:
+		if len(c.PublishDate) == 0 {
+			return nil, ErrPublishDateRequired
+		}
+		publishDate, err := parseDate(c.PublishDate)
+		if err != nil {
+			return nil, ErrInvalidPublishDate
+		}
+		collection.PublishDate = &publishDate
+	case TypeManual:
+	default:
+		return nil, ErrUnknownType
+	}
+
+	return collection, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	var err error
+	for _, layout := range dateLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, err
+}
@@ -0,0 +1,236 @@
+package zebedee_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-collection-api/zebedee"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const scheduledCollection = `{
+  "id": "release1-4d5c8a0b1e",
+  "name": "Release 1",
+  "type": "scheduled",
+  "publishDate": "2030-01-16T09:30:00.000Z",
+  "approvalStatus": "NOT_STARTED",
+  "teams": ["Economy", "Health"],
+  "inProgressUris": ["/economy/data.json"],
+  "completeUris": [],
+  "reviewedUris": ["/health/data.json"],
+  "events": [
+    {"date": "2021-06-01T09:00:00.000+0000", "type": "CREATED", "email": "a@b.c"},
+    {"date": "yesterday", "type": "APPROVED", "email": "a@b.c"}
+  ]
+}`
+
+const manualCollection = `{
+  "id": "manual-9f8e7d",
+  "name": "Manual",
+  "type": "manual",
+  "events": [{"date": "2021-06-01T09:00:00Z", "type": "CREATED", "email": "d@e.f"}]
+}`
+
+func TestMap(t *testing.T) {
+
+	zebedee.Now = func() time.Time {
+		return time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	}
+	defer func() { zebedee.Now = time.Now }()
+
+	Convey("Given a scheduled Zebedee collection", t, func() {
+		collection := &zebedee.Collection{ID: "release1-4d5c8a0b1e", Name: "Release 1", Type: zebedee.TypeScheduled, PublishDate: "2030-01-16T09:30:00Z"}
+
+		Convey("When it is mapped", func() {
+			exported, issues := zebedee.Map(collection)
+
+			Convey("Then it is given a publish date and an ID generated from its Zebedee ID", func() {
+				So(issues, ShouldBeEmpty)
+				So(exported.Name, ShouldEqual, "Release 1")
+				So(exported.PublishDate.Equal(time.Date(2030, 1, 16, 9, 30, 0, 0, time.UTC)), ShouldBeTrue)
+
+				again, _ := zebedee.Map(collection)
+				So(again.ID, ShouldEqual, exported.ID)
+			})
+		})
+
+		Convey("When its publish date has passed", func() {
+			collection.PublishDate = "2021-01-16T09:30:00Z"
+			exported, issues := zebedee.Map(collection)
+
+			Convey("Then it is mapped without one, as it was not published, and reported", func() {
+				So(exported.PublishDate, ShouldBeNil)
+				So(issues, ShouldHaveLength, 1)
+				So(issues[0].Field, ShouldEqual, "publishDate")
+				So(issues[0].Message, ShouldContainSubstring, "imported as unscheduled")
+			})
+		})
+
+		Convey("When it has no publish date", func() {
+			collection.PublishDate = ""
+			exported, issues := zebedee.Map(collection)
+
+			Convey("Then it is mapped without one, and reported", func() {
+				So(exported.PublishDate, ShouldBeNil)
+				So(issues, ShouldHaveLength, 1)
+				So(issues[0].Field, ShouldEqual, "publishDate")
+				So(issues[0].Name, ShouldEqual, "Release 1")
+			})
+		})
+	})
+
+	Convey("Given a manual Zebedee collection with a publish date", t, func() {
+		collection := &zebedee.Collection{ID: "manual-9f8e7d", Name: "Manual", Type: zebedee.TypeManual, PublishDate: "2030-01-16T09:30:00Z"}
+
+		Convey("When it is mapped", func() {
+			exported, issues := zebedee.Map(collection)
+
+			Convey("Then it is unscheduled, and the publish date is reported", func() {
+				So(exported.PublishDate, ShouldBeNil)
+				So(issues, ShouldHaveLength, 1)
+				So(issues[0].Field, ShouldEqual, "publishDate")
+			})
+		})
+	})
+
+	Convey("Given a Zebedee collection of an unknown type", t, func() {
+		collection := &zebedee.Collection{ID: "other-1a2b", Name: "Other", Type: "automatic"}
+
+		Convey("When it is mapped", func() {
+			exported, issues := zebedee.Map(collection)
+
+			Convey("Then it is unscheduled, and the type is reported", func() {
+				So(exported.PublishDate, ShouldBeNil)
+				So(issues, ShouldHaveLength, 1)
+				So(issues[0].Field, ShouldEqual, "type")
+			})
+		})
+	})
+
+	Convey("Given a Zebedee collection without a name", t, func() {
+		collection := &zebedee.Collection{ID: "abc", Type: zebedee.TypeManual}
+
+		Convey("When it is mapped", func() {
+			exported, issues := zebedee.Map(collection)
+
+			Convey("Then it is not mapped", func() {
+				So(exported, ShouldBeNil)
+				So(issues, ShouldHaveLength, 1)
+				So(issues[0].Field, ShouldEqual, "name")
+			})
+		})
+	})
+}
+
+func TestReadDir(t *testing.T) {
+
+	zebedee.Now = func() time.Time {
+		return time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	}
+	defer func() { zebedee.Now = time.Now }()
+
+	Convey("Given a Zebedee collections directory", t, func() {
+		dir := t.TempDir()
+		files := map[string]string{
+			"release1.json": scheduledCollection,
+			"manual.json":   manualCollection,
+			"broken.json":   `{"name": `,
+			"notes.txt":     "not a collection",
+		}
+		for name, content := range files {
+			So(os.WriteFile(filepath.Join(dir, name), []byte(content), 0600), ShouldBeNil)
+		}
+		So(os.MkdirAll(filepath.Join(dir, "release1", "inprogress"), 0700), ShouldBeNil)
+
+		Convey("When it is read", func() {
+			result, err := zebedee.ReadDir(dir)
+			So(err, ShouldBeNil)
+
+			Convey("Then each collection is mapped, in the order of the file names", func() {
+				So(result.Collections, ShouldHaveLength, 2)
+				So(result.Collections[0].Name, ShouldEqual, "Manual")
+				So(result.Collections[1].Name, ShouldEqual, "Release 1")
+			})
+
+			Convey("Then the events with a valid date are mapped", func() {
+				events := result.Collections[1].Events
+				So(events, ShouldHaveLength, 1)
+				So(events[0].Type, ShouldEqual, "CREATED")
+				So(events[0].Email, ShouldEqual, "a@b.c")
+				So(events[0].Date.Equal(time.Date(2021, 6, 1, 9, 0, 0, 0, time.UTC)), ShouldBeTrue)
+			})
+
+			Convey("Then anything that could not be mapped is reported against its file", func() {
+				So(result.Issues, ShouldHaveLength, 4)
+
+				So(result.Issues[0].File, ShouldEqual, "broken.json")
+
+				So(result.Issues[1].File, ShouldEqual, "release1.json")
+				So(result.Issues[1].Field, ShouldEqual, "teams")
+				So(result.Issues[1].Message, ShouldContainSubstring, "Economy, Health")
+
+				So(result.Issues[2].File, ShouldEqual, "release1.json")
+				So(result.Issues[2].Message, ShouldContainSubstring, "2 pages")
+
+				So(result.Issues[3].File, ShouldEqual, "release1.json")
+				So(result.Issues[3].Field, ShouldEqual, "events")
+			})
+		})
+	})
+
+	Convey("Given a directory that does not exist", t, func() {
+		_, err := zebedee.ReadDir(filepath.Join(t.TempDir(), "missing"))
+
+		Convey("Then an error is returned", func() {
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestCollection_Model(t *testing.T) {
+
+	Convey("Given a scheduled Zebedee collection", t, func() {
+		description := &zebedee.Collection{Name: "Release 1", Type: zebedee.TypeScheduled, PublishDate: "2030-01-16T09:30:00.000Z", Teams: []string{"Economy"}}
+
+		Convey("Then the collection has its name and publish date", func() {
+			collection, err := description.Model()
+			So(err, ShouldBeNil)
+			So(collection.Name, ShouldEqual, "Release 1")
+			So(collection.PublishDate.Equal(time.Date(2030, 1, 16, 9, 30, 0, 0, time.UTC)), ShouldBeTrue)
+		})
+
+		Convey("Then an error is returned without a publish date", func() {
+			description.PublishDate = ""
+			_, err := description.Model()
+			So(err, ShouldEqual, zebedee.ErrPublishDateRequired)
+		})
+
+		Convey("Then an error is returned for an invalid publish date", func() {
+			description.PublishDate = "tomorrow"
+			_, err := description.Model()
+			So(err, ShouldEqual, zebedee.ErrInvalidPublishDate)
+		})
+	})
+
+	Convey("Given a manual Zebedee collection with a publish date", t, func() {
+		description := &zebedee.Collection{Name: "Manual", Type: zebedee.TypeManual, PublishDate: "2030-01-16T09:30:00.000Z"}
+
+		Convey("Then the publish date is ignored", func() {
+			collection, err := description.Model()
+			So(err, ShouldBeNil)
+			So(collection.PublishDate, ShouldBeNil)
+		})
+	})
+
+	Convey("Given a Zebedee collection of an unknown type", t, func() {
+		description := &zebedee.Collection{Name: "Other", Type: "automatic"}
+
+		Convey("Then an error is returned", func() {
+			_, err := description.Model()
+			So(err, ShouldEqual, zebedee.ErrUnknownType)
+		})
+	})
+}